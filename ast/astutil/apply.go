@@ -0,0 +1,582 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Apply and Cursor are adapted from golang.org/x/tools/go/ast/astutil to the
+// nodes of this module.
+
+// Package astutil provides utilities to rewrite the AST.
+package astutil
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/ghosind/gjs/ast"
+)
+
+// An ApplyFunc is invoked by Apply for each node n, before and/or after the
+// node's children, using a Cursor describing the current node and providing
+// operations on it.
+//
+// The return value of ApplyFunc controls the syntax tree traversal.
+// See Apply for details.
+type ApplyFunc func(*Cursor) bool
+
+// Apply traverses a syntax tree recursively, starting with root, and calling
+// pre and post for each node:
+//
+//   - If pre is not nil, it is called for each node before the node's
+//     children are traversed (pre-order). If pre returns false, no children
+//     are traversed, and post is not called for that node.
+//   - If post is not nil, and a prior call of pre didn't return false, post is
+//     called for each node after its children are traversed (post-order). If
+//     post returns false, traversal is terminated and Apply returns
+//     immediately.
+//
+// Only fields that refer to AST nodes are considered children, and nil
+// children are skipped. The children are traversed in the order they appear
+// in the source, as with ast.Walk. If pre replaces the current node, the
+// children of the replacement are traversed instead.
+//
+// Apply returns the syntax tree, possibly modified. If pre or post replace
+// the root, the new root is returned.
+func Apply(root ast.Node, pre, post ApplyFunc) (result ast.Node) {
+	parent := &struct{ ast.Node }{root}
+	defer func() {
+		if r := recover(); r != nil && r != abort {
+			panic(r)
+		}
+		result = parent.Node
+	}()
+	a := &application{pre: pre, post: post}
+	a.apply(parent, "Node", nil, root)
+	return
+}
+
+var abort = new(int) // singleton, to signal termination of Apply
+
+// A Cursor describes a node encountered during Apply. Information about the
+// node and its parent is available from the Node, Parent, Name, and Index
+// methods.
+//
+// If p is a variable of type and value of the current parent node c.Parent(),
+// and f is the field identifier with name c.Name(), the following invariants
+// hold:
+//
+//	p.f            == c.Node()  if c.Index() <  0
+//	p.f[c.Index()] == c.Node()  if c.Index() >= 0
+//
+// The elements of ast.SwitchStatement.Cases are values, and the node of their
+// cursors is the address of the element.
+//
+// The methods Replace, Delete, InsertBefore, and InsertAfter can be used to
+// change the AST without disrupting Apply.
+type Cursor struct {
+	parent ast.Node
+	name   string
+	iter   *iterator // valid if non-nil
+	node   ast.Node
+}
+
+// Node returns the current Node, which is nil once it has been deleted.
+func (c *Cursor) Node() ast.Node { return c.node }
+
+// Parent returns the parent of the current Node.
+func (c *Cursor) Parent() ast.Node { return c.parent }
+
+// Name returns the name of the parent Node field that contains the current
+// Node. If the parent is the root of Apply, Name returns "Node".
+func (c *Cursor) Name() string { return c.name }
+
+// Index reports the index >= 0 of the current Node in the slice of Nodes
+// that contains it, or a value < 0 if the current Node is not part of a
+// slice. The index of the current node changes if InsertBefore is called
+// while processing the current node.
+func (c *Cursor) Index() int {
+	if c.iter != nil {
+		return c.iter.index
+	}
+	return -1
+}
+
+// field returns the current node's parent field value.
+func (c *Cursor) field() reflect.Value {
+	return reflect.Indirect(reflect.ValueOf(c.parent)).FieldByName(c.name)
+}
+
+// Replace replaces the current Node with n. The replacement node is not
+// walked by Apply, but its children are if Replace is called by pre.
+func (c *Cursor) Replace(n ast.Node) {
+	v := c.field()
+	if i := c.Index(); i >= 0 {
+		v = v.Index(i)
+	}
+	v.Set(value(v.Type(), n))
+	c.node = n
+	if v.Kind() == reflect.Struct {
+		c.node = v.Addr().Interface().(ast.Node)
+	}
+}
+
+// Delete deletes the current Node from its containing slice. If the current
+// Node is not part of a slice, Delete panics. The children of a deleted node
+// are not walked by Apply.
+func (c *Cursor) Delete() {
+	i := c.Index()
+	if i < 0 {
+		panic("Delete node not contained in slice")
+	}
+	v := c.field()
+	l := v.Len()
+	reflect.Copy(v.Slice(i, l), v.Slice(i+1, l))
+	v.Index(l - 1).Set(reflect.Zero(v.Type().Elem()))
+	v.SetLen(l - 1)
+	c.iter.step--
+	c.node = nil
+}
+
+// InsertAfter inserts n after the current Node in its containing slice. If
+// the current Node is not part of a slice, InsertAfter panics. Apply does not
+// walk n.
+func (c *Cursor) InsertAfter(n ast.Node) {
+	i := c.Index()
+	if i < 0 {
+		panic("InsertAfter node not contained in slice")
+	}
+	v := c.field()
+	v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+	l := v.Len()
+	reflect.Copy(v.Slice(i+2, l), v.Slice(i+1, l))
+	v.Index(i + 1).Set(value(v.Type().Elem(), n))
+	c.iter.step++
+	c.refresh(v.Index(i))
+}
+
+// InsertBefore inserts n before the current Node in its containing slice. If
+// the current Node is not part of a slice, InsertBefore panics. Apply will
+// not walk n.
+func (c *Cursor) InsertBefore(n ast.Node) {
+	i := c.Index()
+	if i < 0 {
+		panic("InsertBefore node not contained in slice")
+	}
+	v := c.field()
+	v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+	l := v.Len()
+	reflect.Copy(v.Slice(i+1, l), v.Slice(i, l))
+	v.Index(i).Set(value(v.Type().Elem(), n))
+	c.iter.index++
+	c.refresh(v.Index(i + 1))
+}
+
+// refresh updates the current node after its slice has been reallocated, as
+// the address of a value element may have changed.
+func (c *Cursor) refresh(v reflect.Value) {
+	if c.node != nil && v.Kind() == reflect.Struct {
+		c.node = v.Addr().Interface().(ast.Node)
+	}
+}
+
+// value returns n as a value that can be assigned to the type typ, which is
+// the type of a node field or of the elements of a node slice.
+func value(typ reflect.Type, n ast.Node) reflect.Value {
+	if n == nil {
+		return reflect.Zero(typ)
+	}
+	v := reflect.ValueOf(n)
+	if typ.Kind() == reflect.Struct {
+		return v.Elem()
+	}
+	return v
+}
+
+// application carries all the shared data so we can pass it around cheaply.
+type application struct {
+	pre, post ApplyFunc
+	cursor    Cursor
+	iter      iterator
+}
+
+// An iterator controls iteration over a slice of nodes.
+type iterator struct {
+	index, step int
+}
+
+func (a *application) apply(parent ast.Node, name string, iter *iterator, n ast.Node) {
+	// convert typed nil into untyped nil
+	if v := reflect.ValueOf(n); v.Kind() == reflect.Pointer && v.IsNil() {
+		n = nil
+	}
+
+	// avoid heap-allocating a new cursor for each apply call; reuse a.cursor
+	// instead
+	saved := a.cursor
+	a.cursor.parent = parent
+	a.cursor.name = name
+	a.cursor.iter = iter
+	a.cursor.node = n
+
+	if a.pre != nil && !a.pre(&a.cursor) {
+		a.cursor = saved
+		return
+	}
+
+	// walk children of the current node, which may have been replaced
+	switch n := a.cursor.node.(type) {
+	case nil:
+		// nothing to do
+
+	case *ast.Program:
+		a.applyList(n, "Statements")
+
+	// Expressions
+	case *ast.Identifier, *ast.Literal, *ast.Elision, *ast.ThisExpression, *ast.BadExpression:
+		// nothing to do
+
+	case *ast.SpreadElement:
+		a.apply(n, "Value", nil, n.Value)
+
+	case *ast.ArrayLiteral:
+		a.applyList(n, "ElementList")
+
+	case *ast.UnaryExpression:
+		a.apply(n, "Value", nil, n.Value)
+
+	case *ast.BinaryExpression:
+		a.apply(n, "Left", nil, n.Left)
+		a.apply(n, "Right", nil, n.Right)
+
+	case *ast.LogicalExpression:
+		a.apply(n, "Left", nil, n.Left)
+		a.apply(n, "Right", nil, n.Right)
+
+	case *ast.UpdateExpression:
+		a.apply(n, "Argument", nil, n.Argument)
+
+	case *ast.TernaryExpression:
+		a.apply(n, "Condition", nil, n.Condition)
+		a.apply(n, "TrueBranch", nil, n.TrueBranch)
+		a.apply(n, "FalseBranch", nil, n.FalseBranch)
+
+	case *ast.SequenceExpression:
+		a.applyList(n, "Expressions")
+
+	case *ast.Property:
+		if n.Key != nil {
+			a.apply(n, "Key", nil, n.Key)
+		}
+		a.apply(n, "Value", nil, n.Value)
+
+	case *ast.ObjectLiteral:
+		a.applyList(n, "Properties")
+
+	case *ast.FunctionExpression:
+		if n.Name != nil {
+			a.apply(n, "Name", nil, n.Name)
+		}
+		a.applyList(n, "Params")
+		if n.Body != nil {
+			a.apply(n, "Body", nil, n.Body)
+		}
+
+	case *ast.ArrowFunctionExpression:
+		a.applyList(n, "Params")
+		a.apply(n, "Body", nil, n.Body)
+
+	case *ast.AssignmentPattern:
+		a.apply(n, "Left", nil, n.Left)
+		a.apply(n, "Right", nil, n.Right)
+
+	case *ast.RestElement:
+		a.apply(n, "Argument", nil, n.Argument)
+
+	case *ast.MemberExpression:
+		a.apply(n, "Object", nil, n.Object)
+		a.apply(n, "Property", nil, n.Property)
+
+	case *ast.CallExpression:
+		a.apply(n, "Callee", nil, n.Callee)
+		a.applyList(n, "Arguments")
+
+	case *ast.NewExpression:
+		a.apply(n, "Callee", nil, n.Callee)
+		a.applyList(n, "Arguments")
+
+	case *ast.AwaitExpression:
+		a.apply(n, "Argument", nil, n.Argument)
+
+	case *ast.YieldExpression:
+		if n.Argument != nil {
+			a.apply(n, "Argument", nil, n.Argument)
+		}
+
+	case *ast.AssignmentExpression:
+		a.apply(n, "Left", nil, n.Left)
+		a.apply(n, "Right", nil, n.Right)
+
+	case *ast.ArrayPattern:
+		a.applyList(n, "Elements")
+
+	case *ast.ObjectPattern:
+		a.applyList(n, "Properties")
+
+	case *ast.ImportExpression:
+		a.apply(n, "Source", nil, n.Source)
+		if n.Options != nil {
+			a.apply(n, "Options", nil, n.Options)
+		}
+
+	case *ast.MetaProperty:
+		a.apply(n, "Meta", nil, n.Meta)
+		a.apply(n, "Property", nil, n.Property)
+
+	// Declarations
+	case *ast.VariableDeclaration:
+		a.apply(n, "Name", nil, n.Name)
+		if n.Value != nil {
+			a.apply(n, "Value", nil, n.Value)
+		}
+
+	case *ast.FunctionDeclaration:
+		if n.Name != nil {
+			a.apply(n, "Name", nil, n.Name)
+		}
+		a.applyList(n, "Params")
+		if n.Body != nil {
+			a.apply(n, "Body", nil, n.Body)
+		}
+
+	// Statements
+	case *ast.EmptyStatement, *ast.DebuggerStatement, *ast.BadStatement:
+		// nothing to do
+
+	case *ast.BlockStatement:
+		a.applyList(n, "StatementList")
+
+	case *ast.VarStatement:
+		a.applyList(n, "Declarations")
+
+	case *ast.LexicalDeclaration:
+		a.applyList(n, "Declarations")
+
+	case *ast.ExpressionStatement:
+		a.apply(n, "Expression", nil, n.Expression)
+
+	case *ast.IfStatement:
+		a.apply(n, "Condition", nil, n.Condition)
+		a.apply(n, "TrueBranch", nil, n.TrueBranch)
+		if n.FalseBranch != nil {
+			a.apply(n, "FalseBranch", nil, n.FalseBranch)
+		}
+
+	case *ast.ForStatement:
+		if n.Init != nil {
+			a.apply(n, "Init", nil, n.Init)
+		}
+		if n.Condition != nil {
+			a.apply(n, "Condition", nil, n.Condition)
+		}
+		if n.Update != nil {
+			a.apply(n, "Update", nil, n.Update)
+		}
+		a.apply(n, "Body", nil, n.Body)
+
+	case *ast.ForInStatement:
+		a.apply(n, "Left", nil, n.Left)
+		a.apply(n, "Right", nil, n.Right)
+		a.apply(n, "Body", nil, n.Body)
+
+	case *ast.ForOfStatement:
+		a.apply(n, "Left", nil, n.Left)
+		a.apply(n, "Right", nil, n.Right)
+		a.apply(n, "Body", nil, n.Body)
+
+	case *ast.WhileStatement:
+		a.apply(n, "Condition", nil, n.Condition)
+		a.apply(n, "Body", nil, n.Body)
+
+	case *ast.WithStatement:
+		a.apply(n, "Object", nil, n.Object)
+		a.apply(n, "Body", nil, n.Body)
+
+	case *ast.DoWhileStatement:
+		a.apply(n, "Body", nil, n.Body)
+		a.apply(n, "Condition", nil, n.Condition)
+
+	case *ast.ContinueStatement:
+		if n.Label != nil {
+			a.apply(n, "Label", nil, n.Label)
+		}
+
+	case *ast.BreakStatement:
+		if n.Label != nil {
+			a.apply(n, "Label", nil, n.Label)
+		}
+
+	case *ast.ReturnStatement:
+		if n.Result != nil {
+			a.apply(n, "Result", nil, n.Result)
+		}
+
+	case *ast.SwitchStatement:
+		a.apply(n, "Discriminant", nil, n.Discriminant)
+		a.applyList(n, "Cases")
+		if n.DefaultCase != nil {
+			a.apply(n, "DefaultCase", nil, n.DefaultCase)
+		}
+
+	case *ast.SwitchCase:
+		if n.Test != nil {
+			a.apply(n, "Test", nil, n.Test)
+		}
+		a.applyList(n, "Consequent")
+
+	case *ast.LabeledStatement:
+		a.apply(n, "Label", nil, n.Label)
+		a.apply(n, "Statement", nil, n.Statement)
+
+	case *ast.ThrowStatement:
+		a.apply(n, "Argument", nil, n.Argument)
+
+	case *ast.TryStatement:
+		a.apply(n, "Block", nil, n.Block)
+		if n.CatchClause != nil {
+			a.apply(n, "CatchClause", nil, n.CatchClause)
+		}
+		if n.Finally != nil {
+			a.apply(n, "Finally", nil, n.Finally)
+		}
+
+	case *ast.CatchClause:
+		if n.Param != nil {
+			a.apply(n, "Param", nil, n.Param)
+		}
+		a.apply(n, "Body", nil, n.Body)
+
+	// Modules
+	case *ast.ImportDeclaration:
+		a.applyList(n, "Specifiers")
+		a.apply(n, "Source", nil, n.Source)
+		a.applyList(n, "Attributes")
+
+	case *ast.ImportSpecifier:
+		a.apply(n, "Imported", nil, n.Imported)
+		a.apply(n, "Local", nil, n.Local)
+
+	case *ast.ImportDefaultSpecifier:
+		a.apply(n, "Local", nil, n.Local)
+
+	case *ast.ImportNamespaceSpecifier:
+		a.apply(n, "Local", nil, n.Local)
+
+	case *ast.ImportAttribute:
+		a.apply(n, "Key", nil, n.Key)
+		a.apply(n, "Value", nil, n.Value)
+
+	case *ast.ExportNamedDeclaration:
+		if n.Declaration != nil {
+			a.apply(n, "Declaration", nil, n.Declaration)
+		}
+		a.applyList(n, "Specifiers")
+		if n.Source != nil {
+			a.apply(n, "Source", nil, n.Source)
+		}
+		a.applyList(n, "Attributes")
+
+	case *ast.ExportSpecifier:
+		a.apply(n, "Local", nil, n.Local)
+		a.apply(n, "Exported", nil, n.Exported)
+
+	case *ast.ExportDefaultDeclaration:
+		a.apply(n, "Declaration", nil, n.Declaration)
+
+	case *ast.ExportAllDeclaration:
+		if n.Exported != nil {
+			a.apply(n, "Exported", nil, n.Exported)
+		}
+		a.apply(n, "Source", nil, n.Source)
+		a.applyList(n, "Attributes")
+
+	// JSX
+	case *ast.JSXOpeningFragment, *ast.JSXClosingFragment, *ast.JSXIdentifier, *ast.JSXEmptyExpression, *ast.JSXText:
+		// nothing to do
+
+	case *ast.JSXElement:
+		a.apply(n, "OpeningElement", nil, n.OpeningElement)
+		a.applyList(n, "Children")
+		if n.ClosingElement != nil {
+			a.apply(n, "ClosingElement", nil, n.ClosingElement)
+		}
+
+	case *ast.JSXOpeningElement:
+		a.apply(n, "Name", nil, n.Name)
+		a.applyList(n, "Attributes")
+
+	case *ast.JSXClosingElement:
+		a.apply(n, "Name", nil, n.Name)
+
+	case *ast.JSXFragment:
+		a.apply(n, "OpeningFragment", nil, n.OpeningFragment)
+		a.applyList(n, "Children")
+		a.apply(n, "ClosingFragment", nil, n.ClosingFragment)
+
+	case *ast.JSXNamespacedName:
+		a.apply(n, "Namespace", nil, n.Namespace)
+		a.apply(n, "Name", nil, n.Name)
+
+	case *ast.JSXMemberExpression:
+		a.apply(n, "Object", nil, n.Object)
+		a.apply(n, "Property", nil, n.Property)
+
+	case *ast.JSXAttribute:
+		a.apply(n, "Name", nil, n.Name)
+		if n.Value != nil {
+			a.apply(n, "Value", nil, n.Value)
+		}
+
+	case *ast.JSXSpreadAttribute:
+		a.apply(n, "Argument", nil, n.Argument)
+
+	case *ast.JSXExpressionContainer:
+		a.apply(n, "Expression", nil, n.Expression)
+
+	case *ast.JSXSpreadChild:
+		a.apply(n, "Expression", nil, n.Expression)
+
+	default:
+		panic(fmt.Sprintf("Apply: unexpected node type %T", n))
+	}
+
+	if a.post != nil && !a.post(&a.cursor) {
+		panic(abort)
+	}
+
+	a.cursor = saved
+}
+
+func (a *application) applyList(parent ast.Node, name string) {
+	// avoid heap-allocating a new iterator for each applyList call; reuse
+	// a.iter instead
+	saved := a.iter
+	a.iter.index = 0
+	for {
+		// must reload parent.name each time, since cursor modifications
+		// might change it
+		v := reflect.Indirect(reflect.ValueOf(parent)).FieldByName(name)
+		if a.iter.index >= v.Len() {
+			break
+		}
+
+		var x ast.Node
+		if e := v.Index(a.iter.index); e.Kind() == reflect.Struct {
+			x = e.Addr().Interface().(ast.Node)
+		} else if !e.IsNil() {
+			x = e.Interface().(ast.Node)
+		}
+		a.iter.step = 1
+		a.apply(parent, name, &a.iter, x)
+		a.iter.index += a.iter.step
+	}
+	a.iter = saved
+}
@@ -0,0 +1,265 @@
+package astutil_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/ast/astutil"
+	"github.com/ghosind/gjs/lexer"
+	"github.com/ghosind/gjs/parser"
+	"github.com/ghosind/go-assert"
+)
+
+func parse(a *assert.Assertion, source string, opts parser.Options) *ast.Program {
+	program, err := parser.NewWithOptions(lexer.New([]byte(source)), opts).Parse()
+	a.NilNow(err, source)
+	return program
+}
+
+func identifier(name string) *ast.Identifier {
+	return &ast.Identifier{Value: name}
+}
+
+func statement(name string) ast.Statement {
+	return &ast.ExpressionStatement{Expression: identifier(name)}
+}
+
+func TestApplyOrder(t *testing.T) {
+	a := assert.New(t)
+
+	programs := []ast.Node{
+		parse(a, "var o = {a, b: [1, , ...c], get d() { return this; }};\nlabel: for (let i = 0; i < 10; i++) {\n"+
+			"if (!i) continue label; else break;\n}\nfor (var k in o) while (k) do k--; while (k)\n"+
+			"x = a ? b : c, typeof a, a && b || c, new F(1), async () => { await f(...a); };\n"+
+			"var {p, q: [r = 1, ...s]} = o; with (o) { debugger; }\nfunction* g() { yield* a; }", parser.Options{}),
+		parse(a, "import a, {b as c} from 'm' with {type: 'json'};\nimport * as ns from 'n';\n"+
+			"export default function () { return import.meta; }\nexport {a as aa, c};\n"+
+			"export * as x from 'y';\nexport const z = import('z');\n",
+			parser.Options{SourceType: ast.SourceModule}),
+		parse(a, "<div a=\"1\" {...p} b={x}>hi {y}{/* c */}{...z}<a.b c:d=\"2\" /><></></div>;",
+			parser.Options{JSX: true}),
+		&ast.BlockStatement{StatementList: []ast.Statement{
+			&ast.SwitchStatement{
+				Discriminant: identifier("x"),
+				Cases: []ast.SwitchCase{
+					{Test: identifier("a"), Consequent: []ast.Statement{statement("b")}},
+					{Test: identifier("c")},
+				},
+				DefaultCase: &ast.SwitchCase{Consequent: []ast.Statement{statement("d")}},
+			},
+			&ast.TryStatement{
+				Block: &ast.BlockStatement{StatementList: []ast.Statement{
+					&ast.ThrowStatement{Argument: identifier("e")},
+				}},
+				CatchClause: &ast.CatchClause{Param: identifier("e"), Body: &ast.BlockStatement{}},
+				Finally:     &ast.BlockStatement{},
+			},
+		}},
+	}
+
+	// Apply visits the same nodes in the same order as ast.Inspect.
+	for _, program := range programs {
+		expected := make([]string, 0)
+		ast.Inspect(program, func(node ast.Node) bool {
+			if node == nil {
+				expected = append(expected, ")")
+			} else {
+				expected = append(expected, fmt.Sprintf("%T %p", node, node))
+			}
+			return true
+		})
+
+		visited := make([]string, 0)
+		result := astutil.Apply(program, func(c *astutil.Cursor) bool {
+			visited = append(visited, fmt.Sprintf("%T %p", c.Node(), c.Node()))
+			return true
+		}, func(c *astutil.Cursor) bool {
+			visited = append(visited, ")")
+			return true
+		})
+		a.EqualNow(result, program)
+		a.DeepEqualNow(visited, expected)
+	}
+}
+
+func TestApplyCursor(t *testing.T) {
+	a := assert.New(t)
+
+	program := parse(a, "f(a, b);", parser.Options{})
+	call := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.CallExpression)
+
+	astutil.Apply(program, func(c *astutil.Cursor) bool {
+		switch c.Node() {
+		case program:
+			a.EqualNow(c.Name(), "Node")
+			a.EqualNow(c.Index(), -1)
+		case call.Callee:
+			a.EqualNow(c.Parent(), call)
+			a.EqualNow(c.Name(), "Callee")
+			a.EqualNow(c.Index(), -1)
+		case call.Arguments[1]:
+			a.EqualNow(c.Parent(), call)
+			a.EqualNow(c.Name(), "Arguments")
+			a.EqualNow(c.Index(), 1)
+		}
+		return true
+	}, nil)
+}
+
+func TestApplyReplace(t *testing.T) {
+	a := assert.New(t)
+
+	program := parse(a, "var x = a + b; f(a);", parser.Options{})
+	astutil.Apply(program, func(c *astutil.Cursor) bool {
+		if id, ok := c.Node().(*ast.Identifier); ok && id.Value == "a" {
+			c.Replace(&ast.Literal{Value: "1", Kind: ast.LitNumber})
+		}
+		return true
+	}, nil)
+	a.EqualNow(program.String(), "var x = 1 + b;f(1);")
+
+	// The children of a node replaced by pre are traversed, and the root can
+	// be replaced.
+	visited := make([]string, 0)
+	root := astutil.Apply(parse(a, "a;", parser.Options{}), func(c *astutil.Cursor) bool {
+		switch n := c.Node().(type) {
+		case *ast.Program:
+			c.Replace(&ast.BlockStatement{StatementList: n.Statements})
+		case *ast.Identifier:
+			visited = append(visited, n.Value)
+		}
+		return true
+	}, nil)
+	_, ok := root.(*ast.BlockStatement)
+	a.TrueNow(ok)
+	a.DeepEqualNow(visited, []string{"a"})
+}
+
+func TestApplyStatementList(t *testing.T) {
+	a := assert.New(t)
+
+	program := parse(a, "function f() { a; b; c; d; }", parser.Options{})
+	visited := make([]string, 0)
+	astutil.Apply(program, func(c *astutil.Cursor) bool {
+		stmt, ok := c.Node().(*ast.ExpressionStatement)
+		if !ok {
+			return true
+		}
+		name := stmt.Expression.(*ast.Identifier).Value
+		visited = append(visited, name)
+		switch name {
+		case "a":
+			c.InsertBefore(statement("before"))
+			a.EqualNow(c.Index(), 1)
+		case "b":
+			c.Delete()
+			a.EqualNow(c.Node(), nil)
+		case "c":
+			c.InsertAfter(statement("after"))
+		case "d":
+			c.Replace(statement("e"))
+		}
+		return true
+	}, nil)
+
+	// The inserted nodes are not visited.
+	a.DeepEqualNow(visited, []string{"a", "b", "c", "d"})
+	a.EqualNow(program.String(), "function f() {\nbefore;\na;\nc;\nafter;\ne;\n}")
+}
+
+func TestApplySwitchCase(t *testing.T) {
+	a := assert.New(t)
+
+	stmt := &ast.SwitchStatement{
+		Discriminant: identifier("x"),
+		Cases: []ast.SwitchCase{
+			{Test: identifier("a"), Consequent: []ast.Statement{statement("b"), statement("c")}},
+			{Test: identifier("d")},
+		},
+		DefaultCase: &ast.SwitchCase{Consequent: []ast.Statement{statement("e")}},
+	}
+	visited := make([]string, 0)
+	astutil.Apply(stmt, func(c *astutil.Cursor) bool {
+		switch n := c.Node().(type) {
+		case *ast.SwitchCase:
+			if c.Name() != "Cases" {
+				break
+			}
+			a.EqualNow(n, &stmt.Cases[c.Index()])
+			switch n.Test.(*ast.Identifier).Value {
+			case "a":
+				c.InsertBefore(&ast.SwitchCase{Test: identifier("z")})
+				a.EqualNow(c.Node(), &stmt.Cases[1])
+			case "d":
+				c.Delete()
+			}
+		case *ast.ExpressionStatement:
+			name := n.Expression.(*ast.Identifier).Value
+			visited = append(visited, name)
+			switch name {
+			case "b":
+				c.InsertAfter(statement("f"))
+			case "c":
+				c.Delete()
+			case "e":
+				c.InsertBefore(statement("g"))
+			}
+		}
+		return true
+	}, nil)
+
+	a.DeepEqualNow(visited, []string{"b", "c", "e"})
+	a.EqualNow(len(stmt.Cases), 2)
+	a.EqualNow(stmt.Cases[0].Test.String(), "z")
+	a.EqualNow(stmt.Cases[1].Test.String(), "a")
+	a.EqualNow(len(stmt.Cases[1].Consequent), 2)
+	a.EqualNow(stmt.Cases[1].Consequent[1].String(), "f;")
+	a.EqualNow(len(stmt.DefaultCase.Consequent), 2)
+	a.EqualNow(stmt.DefaultCase.Consequent[0].String(), "g;")
+}
+
+func TestApplyAbort(t *testing.T) {
+	a := assert.New(t)
+
+	program := parse(a, "a; b; c;", parser.Options{})
+	visited := make([]string, 0)
+	result := astutil.Apply(program, func(c *astutil.Cursor) bool {
+		_, ok := c.Node().(*ast.ExpressionStatement)
+		return !ok
+	}, func(c *astutil.Cursor) bool {
+		if id, ok := c.Node().(*ast.Identifier); ok {
+			visited = append(visited, id.Value)
+			return id.Value != "b"
+		}
+		return true
+	})
+	a.EqualNow(result, program)
+	// pre skips the children of statements.
+	a.DeepEqualNow(visited, []string{})
+
+	result = astutil.Apply(program, nil, func(c *astutil.Cursor) bool {
+		if id, ok := c.Node().(*ast.Identifier); ok {
+			visited = append(visited, id.Value)
+			return id.Value != "b"
+		}
+		return true
+	})
+	a.EqualNow(result, program)
+	a.DeepEqualNow(visited, []string{"a", "b"})
+}
+
+func TestApplyNotInSlice(t *testing.T) {
+	a := assert.New(t)
+
+	program := parse(a, "a + b;", parser.Options{})
+	defer func() {
+		a.EqualNow(recover(), "Delete node not contained in slice")
+	}()
+	astutil.Apply(program, func(c *astutil.Cursor) bool {
+		if _, ok := c.Node().(*ast.Identifier); ok {
+			c.Delete()
+		}
+		return true
+	}, nil)
+}
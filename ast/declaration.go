@@ -1,6 +1,10 @@
 package ast
 
-import "bytes"
+import (
+	"bytes"
+
+	"github.com/ghosind/gjs/token"
+)
 
 type Declaration interface {
 	Node
@@ -8,7 +12,9 @@ type Declaration interface {
 
 type VariableDeclaration struct {
 	Declaration
-	Name  *Identifier
+	// Name is an *Identifier, or an *ArrayPattern or *ObjectPattern for
+	// destructuring declarations.
+	Name  Expression
 	Value Expression
 }
 
@@ -21,3 +27,50 @@ func (d *VariableDeclaration) String() string {
 	}
 	return buf.String()
 }
+
+type FunctionDeclaration struct {
+	Declaration
+	// Token is the async or function keyword that starts the declaration.
+	Token     token.Token
+	Name      *Identifier
+	Params    []Expression
+	Body      *BlockStatement
+	Async     bool
+	Generator bool
+	Strict    bool
+}
+
+func (d *FunctionDeclaration) String() string {
+	return functionString(d.Async, d.Generator, d.Name, d.Params, d.Body)
+}
+
+func functionString(async, generator bool, name *Identifier, params []Expression, body *BlockStatement) string {
+	buf := new(bytes.Buffer)
+	if async {
+		buf.WriteString("async ")
+	}
+	buf.WriteString("function")
+	if generator {
+		buf.WriteString("*")
+	}
+	if name != nil {
+		buf.WriteString(" " + name.String())
+	}
+	buf.WriteString(paramsString(params))
+	buf.WriteString(" ")
+	buf.WriteString(body.String())
+	return buf.String()
+}
+
+func paramsString(params []Expression) string {
+	buf := new(bytes.Buffer)
+	buf.WriteString("(")
+	for i, param := range params {
+		buf.WriteString(param.String())
+		if i < len(params)-1 {
+			buf.WriteString(", ")
+		}
+	}
+	buf.WriteString(")")
+	return buf.String()
+}
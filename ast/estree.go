@@ -0,0 +1,526 @@
+package ast
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ghosind/gjs/token"
+)
+
+// The nodes are encoded to and decoded from JSON in the format of the ESTree
+// specification, which is also used by acorn and esprima. Each node is an
+// object with its type, such as "BinaryExpression", its location as loc and
+// range, and its children under the names of ESTree. Literals keep their
+// source text as raw, and the directives of a prologue are marked by the
+// directive of their expression statements.
+//
+// The columns of loc are counted from 0 in characters, and range is the byte
+// offsets of the node in the source. Nodes that are not created by the parser
+// have no location. The nodes of this package that have no counterpart in
+// ESTree, such as BadExpression, are encoded with their own type names.
+
+// MarshalESTree returns the ESTree JSON encoding of node.
+func MarshalESTree(node Node) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(encodeNode(node)); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// MarshalJSON returns the ESTree JSON encoding of the program.
+func (p *Program) MarshalJSON() ([]byte, error) {
+	return MarshalESTree(p)
+}
+
+// object is a JSON object whose fields are encoded in order, so the type of a
+// node comes first.
+type object []field
+
+type field struct {
+	key   string
+	value any
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+
+	buf.WriteString("{")
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString(strconv.Quote(f.key))
+		buf.WriteString(":")
+		if err := enc.Encode(f.value); err != nil {
+			return nil, err
+		}
+		buf.Truncate(buf.Len() - 1)
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
+
+// isNil reports whether node is nil or a nil pointer.
+func isNil(node Node) bool {
+	if node == nil {
+		return true
+	}
+	v := reflect.ValueOf(node)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
+
+// encodeNode returns the ESTree object of node, or nil if node is nil.
+func encodeNode(node Node) any {
+	if isNil(node) {
+		return nil
+	}
+
+	var typ string
+	var fields object
+	switch n := node.(type) {
+	case *Program:
+		typ = "Program"
+		fields = object{{"body", encodeBody(n.Statements)}, {"sourceType", "script"}}
+		if n.SourceType == SourceModule {
+			fields[1].value = "module"
+		}
+		if n.HashBang != nil {
+			fields = append(fields, field{"hashbang", n.HashBang.Literal})
+		}
+
+	// Expressions
+	case *Identifier:
+		typ = "Identifier"
+		fields = object{{"name", n.Value}}
+	case *Literal:
+		typ = "Literal"
+		fields = object{{"value", literalValue(n)}, {"raw", n.Token.Raw}}
+	case *Elision:
+		// The holes of arrays are null.
+		return nil
+	case *SpreadElement:
+		typ = "SpreadElement"
+		fields = object{{"argument", encodeNode(n.Value)}}
+	case *ArrayLiteral:
+		typ = "ArrayExpression"
+		fields = object{{"elements", encodeList(n.ElementList)}}
+	case *UnaryExpression:
+		typ = "UnaryExpression"
+		fields = object{{"operator", n.Operator.Literal}, {"prefix", true}, {"argument", encodeNode(n.Value)}}
+	case *BinaryExpression:
+		typ = "BinaryExpression"
+		fields = object{{"left", encodeNode(n.Left)}, {"operator", n.Operator.Literal}, {"right", encodeNode(n.Right)}}
+	case *LogicalExpression:
+		typ = "LogicalExpression"
+		fields = object{{"left", encodeNode(n.Left)}, {"operator", n.Operator.Literal}, {"right", encodeNode(n.Right)}}
+	case *UpdateExpression:
+		typ = "UpdateExpression"
+		fields = object{{"operator", n.Operator.Literal}, {"prefix", n.Prefix}, {"argument", encodeNode(n.Argument)}}
+	case *TernaryExpression:
+		typ = "ConditionalExpression"
+		fields = object{
+			{"test", encodeNode(n.Condition)},
+			{"consequent", encodeNode(n.TrueBranch)},
+			{"alternate", encodeNode(n.FalseBranch)},
+		}
+	case *SequenceExpression:
+		typ = "SequenceExpression"
+		fields = object{{"expressions", encodeList(n.Expressions)}}
+	case *ThisExpression:
+		typ = "ThisExpression"
+	case *Property:
+		typ = "Property"
+		kind := "init"
+		switch n.Kind {
+		case PropertyGet:
+			kind = "get"
+		case PropertySet:
+			kind = "set"
+		}
+		fields = object{
+			{"method", n.Method},
+			{"shorthand", n.Shorthand},
+			{"computed", n.Computed},
+			{"key", encodeNode(n.Key)},
+			{"value", encodeNode(n.Value)},
+			{"kind", kind},
+		}
+	case *ObjectLiteral:
+		typ = "ObjectExpression"
+		fields = object{{"properties", encodeList(n.Properties)}}
+	case *FunctionExpression:
+		typ = "FunctionExpression"
+		fields = encodeFunction(n.Name, n.Params, n.Body, n.Async, n.Generator)
+	case *ArrowFunctionExpression:
+		typ = "ArrowFunctionExpression"
+		body, block := n.Body.(*BlockStatement)
+		fields = object{
+			{"id", nil},
+			{"expression", !block},
+			{"generator", false},
+			{"async", n.Async},
+			{"params", encodeList(n.Params)},
+			{"body", encodeNode(n.Body)},
+		}
+		if block {
+			fields[5].value = encodeFunctionBody(body)
+		}
+	case *AssignmentPattern:
+		typ = "AssignmentPattern"
+		fields = object{{"left", encodeNode(n.Left)}, {"right", encodeNode(n.Right)}}
+	case *RestElement:
+		typ = "RestElement"
+		fields = object{{"argument", encodeNode(n.Argument)}}
+	case *MemberExpression:
+		typ = "MemberExpression"
+		fields = object{
+			{"object", encodeNode(n.Object)},
+			{"property", encodeNode(n.Property)},
+			{"computed", n.Computed},
+			{"optional", false},
+		}
+	case *CallExpression:
+		typ = "CallExpression"
+		fields = object{{"callee", encodeNode(n.Callee)}, {"arguments", encodeList(n.Arguments)}, {"optional", false}}
+	case *NewExpression:
+		typ = "NewExpression"
+		fields = object{{"callee", encodeNode(n.Callee)}, {"arguments", encodeList(n.Arguments)}}
+	case *AwaitExpression:
+		typ = "AwaitExpression"
+		fields = object{{"argument", encodeNode(n.Argument)}}
+	case *YieldExpression:
+		typ = "YieldExpression"
+		fields = object{{"delegate", n.Delegate}, {"argument", encodeNode(n.Argument)}}
+	case *AssignmentExpression:
+		typ = "AssignmentExpression"
+		fields = object{{"operator", n.Operator.Literal}, {"left", encodeNode(n.Left)}, {"right", encodeNode(n.Right)}}
+	case *ArrayPattern:
+		typ = "ArrayPattern"
+		fields = object{{"elements", encodeList(n.Elements)}}
+	case *ObjectPattern:
+		typ = "ObjectPattern"
+		fields = object{{"properties", encodeList(n.Properties)}}
+	case *BadExpression:
+		typ = "BadExpression"
+	case *ImportExpression:
+		typ = "ImportExpression"
+		fields = object{{"source", encodeNode(n.Source)}, {"options", encodeNode(n.Options)}}
+	case *MetaProperty:
+		typ = "MetaProperty"
+		fields = object{{"meta", encodeNode(n.Meta)}, {"property", encodeNode(n.Property)}}
+
+	// Declarations
+	case *VariableDeclaration:
+		typ = "VariableDeclarator"
+		fields = object{{"id", encodeNode(n.Name)}, {"init", encodeNode(n.Value)}}
+	case *FunctionDeclaration:
+		typ = "FunctionDeclaration"
+		fields = encodeFunction(n.Name, n.Params, n.Body, n.Async, n.Generator)
+
+	// Statements
+	case *BlockStatement:
+		typ = "BlockStatement"
+		fields = object{{"body", encodeList(n.StatementList)}}
+	case *VarStatement:
+		typ = "VariableDeclaration"
+		fields = object{{"declarations", encodeList(n.Declarations)}, {"kind", "var"}}
+	case *LexicalDeclaration:
+		typ = "VariableDeclaration"
+		fields = object{{"declarations", encodeList(n.Declarations)}, {"kind", "let"}}
+		if n.Const {
+			fields[1].value = "const"
+		}
+	case *EmptyStatement:
+		typ = "EmptyStatement"
+	case *ExpressionStatement:
+		typ = "ExpressionStatement"
+		fields = object{{"expression", encodeNode(n.Expression)}}
+	case *IfStatement:
+		typ = "IfStatement"
+		fields = object{
+			{"test", encodeNode(n.Condition)},
+			{"consequent", encodeNode(n.TrueBranch)},
+			{"alternate", encodeNode(n.FalseBranch)},
+		}
+	case *ForStatement:
+		typ = "ForStatement"
+		fields = object{
+			{"init", encodeNode(n.Init)},
+			{"test", encodeNode(n.Condition)},
+			{"update", encodeNode(n.Update)},
+			{"body", encodeNode(n.Body)},
+		}
+	case *ForInStatement:
+		typ = "ForInStatement"
+		fields = object{{"left", encodeNode(n.Left)}, {"right", encodeNode(n.Right)}, {"body", encodeNode(n.Body)}}
+	case *ForOfStatement:
+		typ = "ForOfStatement"
+		fields = object{
+			{"await", n.Await},
+			{"left", encodeNode(n.Left)},
+			{"right", encodeNode(n.Right)},
+			{"body", encodeNode(n.Body)},
+		}
+	case *WhileStatement:
+		typ = "WhileStatement"
+		fields = object{{"test", encodeNode(n.Condition)}, {"body", encodeNode(n.Body)}}
+	case *WithStatement:
+		typ = "WithStatement"
+		fields = object{{"object", encodeNode(n.Object)}, {"body", encodeNode(n.Body)}}
+	case *DoWhileStatement:
+		typ = "DoWhileStatement"
+		fields = object{{"body", encodeNode(n.Body)}, {"test", encodeNode(n.Condition)}}
+	case *ContinueStatement:
+		typ = "ContinueStatement"
+		fields = object{{"label", encodeNode(n.Label)}}
+	case *BreakStatement:
+		typ = "BreakStatement"
+		fields = object{{"label", encodeNode(n.Label)}}
+	case *ReturnStatement:
+		typ = "ReturnStatement"
+		fields = object{{"argument", encodeNode(n.Result)}}
+	case *SwitchStatement:
+		typ = "SwitchStatement"
+		cases := make([]any, 0, len(n.Cases)+1)
+		for i := range n.Cases {
+			cases = append(cases, encodeNode(&n.Cases[i]))
+		}
+		if n.DefaultCase != nil {
+			cases = append(cases, encodeNode(n.DefaultCase))
+		}
+		fields = object{{"discriminant", encodeNode(n.Discriminant)}, {"cases", cases}}
+	case *SwitchCase:
+		typ = "SwitchCase"
+		fields = object{{"test", encodeNode(n.Test)}, {"consequent", encodeList(n.Consequent)}}
+	case *LabeledStatement:
+		typ = "LabeledStatement"
+		fields = object{{"label", encodeNode(n.Label)}, {"body", encodeNode(n.Statement)}}
+	case *ThrowStatement:
+		typ = "ThrowStatement"
+		fields = object{{"argument", encodeNode(n.Argument)}}
+	case *TryStatement:
+		typ = "TryStatement"
+		fields = object{
+			{"block", encodeNode(n.Block)},
+			{"handler", encodeNode(n.CatchClause)},
+			{"finalizer", encodeNode(n.Finally)},
+		}
+	case *CatchClause:
+		typ = "CatchClause"
+		fields = object{{"param", encodeNode(n.Param)}, {"body", encodeNode(n.Body)}}
+	case *DebuggerStatement:
+		typ = "DebuggerStatement"
+	case *BadStatement:
+		typ = "BadStatement"
+
+	// Modules
+	case *ImportDeclaration:
+		typ = "ImportDeclaration"
+		fields = object{
+			{"specifiers", encodeList(n.Specifiers)},
+			{"source", encodeNode(n.Source)},
+			{"attributes", encodeList(n.Attributes)},
+		}
+	case *ImportSpecifier:
+		typ = "ImportSpecifier"
+		fields = object{{"imported", encodeNode(n.Imported)}, {"local", encodeNode(n.Local)}}
+	case *ImportDefaultSpecifier:
+		typ = "ImportDefaultSpecifier"
+		fields = object{{"local", encodeNode(n.Local)}}
+	case *ImportNamespaceSpecifier:
+		typ = "ImportNamespaceSpecifier"
+		fields = object{{"local", encodeNode(n.Local)}}
+	case *ImportAttribute:
+		typ = "ImportAttribute"
+		fields = object{{"key", encodeNode(n.Key)}, {"value", encodeNode(n.Value)}}
+	case *ExportNamedDeclaration:
+		typ = "ExportNamedDeclaration"
+		fields = object{
+			{"declaration", encodeNode(n.Declaration)},
+			{"specifiers", encodeList(n.Specifiers)},
+			{"source", encodeNode(n.Source)},
+			{"attributes", encodeList(n.Attributes)},
+		}
+	case *ExportSpecifier:
+		typ = "ExportSpecifier"
+		fields = object{{"local", encodeNode(n.Local)}, {"exported", encodeNode(n.Exported)}}
+	case *ExportDefaultDeclaration:
+		typ = "ExportDefaultDeclaration"
+		fields = object{{"declaration", encodeNode(n.Declaration)}}
+	case *ExportAllDeclaration:
+		typ = "ExportAllDeclaration"
+		fields = object{
+			{"exported", encodeNode(n.Exported)},
+			{"source", encodeNode(n.Source)},
+			{"attributes", encodeList(n.Attributes)},
+		}
+
+	// JSX
+	case *JSXElement:
+		typ = "JSXElement"
+		fields = object{
+			{"openingElement", encodeNode(n.OpeningElement)},
+			{"children", encodeList(n.Children)},
+			{"closingElement", encodeNode(n.ClosingElement)},
+		}
+	case *JSXOpeningElement:
+		typ = "JSXOpeningElement"
+		fields = object{{"name", encodeNode(n.Name)}, {"attributes", encodeList(n.Attributes)}, {"selfClosing", n.SelfClosing}}
+	case *JSXClosingElement:
+		typ = "JSXClosingElement"
+		fields = object{{"name", encodeNode(n.Name)}}
+	case *JSXFragment:
+		typ = "JSXFragment"
+		fields = object{
+			{"openingFragment", encodeNode(n.OpeningFragment)},
+			{"children", encodeList(n.Children)},
+			{"closingFragment", encodeNode(n.ClosingFragment)},
+		}
+	case *JSXOpeningFragment:
+		typ = "JSXOpeningFragment"
+	case *JSXClosingFragment:
+		typ = "JSXClosingFragment"
+	case *JSXIdentifier:
+		typ = "JSXIdentifier"
+		fields = object{{"name", n.Name}}
+	case *JSXNamespacedName:
+		typ = "JSXNamespacedName"
+		fields = object{{"namespace", encodeNode(n.Namespace)}, {"name", encodeNode(n.Name)}}
+	case *JSXMemberExpression:
+		typ = "JSXMemberExpression"
+		fields = object{{"object", encodeNode(n.Object)}, {"property", encodeNode(n.Property)}}
+	case *JSXAttribute:
+		typ = "JSXAttribute"
+		fields = object{{"name", encodeNode(n.Name)}, {"value", encodeNode(n.Value)}}
+	case *JSXSpreadAttribute:
+		typ = "JSXSpreadAttribute"
+		fields = object{{"argument", encodeNode(n.Argument)}}
+	case *JSXExpressionContainer:
+		typ = "JSXExpressionContainer"
+		fields = object{{"expression", encodeNode(n.Expression)}}
+	case *JSXEmptyExpression:
+		typ = "JSXEmptyExpression"
+	case *JSXSpreadChild:
+		typ = "JSXSpreadChild"
+		fields = object{{"expression", encodeNode(n.Expression)}}
+	case *JSXText:
+		typ = "JSXText"
+		fields = object{{"value", n.Value}, {"raw", n.Token.Raw}}
+	default:
+		typ = reflect.TypeOf(node).Elem().Name()
+	}
+
+	obj := append(object{{"type", typ}}, encodeLocation(node)...)
+	return append(obj, fields...)
+}
+
+// encodeLocation returns the loc and range fields of node, or nothing if the
+// node has no location.
+func encodeLocation(node Node) object {
+	start, end := node.Pos(), node.End()
+	if !start.IsValid() || !end.IsValid() {
+		return nil
+	}
+	return object{
+		{"loc", object{{"start", encodePosition(start)}, {"end", encodePosition(end)}}},
+		{"range", []int{start.Offset, end.Offset}},
+	}
+}
+
+func encodePosition(pos token.Position) object {
+	return object{{"line", pos.Line}, {"column", pos.Col - 1}}
+}
+
+func encodeList[T Node](list []T) []any {
+	nodes := make([]any, 0, len(list))
+	for _, node := range list {
+		nodes = append(nodes, encodeNode(node))
+	}
+	return nodes
+}
+
+// encodeBody returns the statements of a program or a function body, whose
+// directives are marked by the directive field.
+func encodeBody(list []Statement) []any {
+	nodes := encodeList(list)
+	for i, stmt := range list {
+		directive, ok := directiveOf(stmt)
+		if !ok {
+			break
+		}
+		nodes[i] = append(nodes[i].(object), field{"directive", directive})
+	}
+	return nodes
+}
+
+// directiveOf returns the directive of stmt as written between its quotes,
+// and whether stmt is a string literal that may be a directive.
+func directiveOf(stmt Statement) (string, bool) {
+	expr, ok := stmt.(*ExpressionStatement)
+	if !ok {
+		return "", false
+	}
+	lit, ok := expr.Expression.(*Literal)
+	if !ok || lit.Kind != LitString || strings.HasPrefix(lit.Token.Raw, "`") || len(lit.Token.Raw) < 2 {
+		return "", false
+	} else if expr.Token.Pos().IsValid() && expr.Token.Offset != lit.Token.Offset {
+		// A parenthesized string is not a directive.
+		return "", false
+	}
+	return lit.Token.Raw[1 : len(lit.Token.Raw)-1], true
+}
+
+func encodeFunction(name *Identifier, params []Expression, body *BlockStatement, async, generator bool) object {
+	return object{
+		{"id", encodeNode(name)},
+		{"expression", false},
+		{"generator", generator},
+		{"async", async},
+		{"params", encodeList(params)},
+		{"body", encodeFunctionBody(body)},
+	}
+}
+
+func encodeFunctionBody(body *BlockStatement) any {
+	if body == nil {
+		return nil
+	}
+	obj := encodeNode(body).(object)
+	obj[len(obj)-1].value = encodeBody(body.StatementList)
+	return obj
+}
+
+// literalValue returns the value of a literal as a JSON value. Numbers that
+// are out of the range of JSON, such as 1e400, are null as in acorn.
+func literalValue(lit *Literal) any {
+	switch lit.Kind {
+	case LitBoolean:
+		return lit.Value == "true"
+	case LitNumber:
+		if n, err := numberValue(lit.Value); err == nil {
+			return n
+		}
+		return nil
+	case LitString:
+		return lit.Value
+	}
+	return nil
+}
+
+// numberValue parses a numeric literal, which is in base 8 if it is a legacy
+// octal literal such as 010.
+func numberValue(lit string) (float64, error) {
+	if len(lit) > 1 && lit[0] == '0' && strings.Trim(lit, "01234567") == "" {
+		n, err := strconv.ParseInt(lit[1:], 8, 64)
+		return float64(n), err
+	}
+	return strconv.ParseFloat(lit, 64)
+}
@@ -0,0 +1,688 @@
+package ast
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/ghosind/gjs/token"
+)
+
+// UnmarshalESTree decodes a node from its ESTree JSON encoding. The tokens of
+// the nodes are positioned by the locations of the encoding, if there are
+// any.
+func UnmarshalESTree(data []byte) (Node, error) {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+
+	d := new(decoder)
+	node := d.node(v)
+	if d.err != nil {
+		return nil, d.err
+	}
+	return node, nil
+}
+
+// UnmarshalJSON decodes the program from its ESTree JSON encoding.
+func (p *Program) UnmarshalJSON(data []byte) error {
+	node, err := UnmarshalESTree(data)
+	if err != nil {
+		return err
+	}
+	program, ok := node.(*Program)
+	if !ok {
+		return fmt.Errorf("ast: unexpected %s node, expected Program", nodeType(node))
+	}
+	*p = *program
+	return nil
+}
+
+// decoder decodes the ESTree objects of nodes. The first error is kept, and
+// the nodes decoded after it are nil.
+type decoder struct {
+	err error
+	// strict is set in strict mode code.
+	strict bool
+}
+
+func (d *decoder) fail(format string, args ...any) {
+	if d.err == nil {
+		d.err = fmt.Errorf("ast: "+format, args...)
+	}
+}
+
+// nodeType returns the type name of an ESTree object, or of node.
+func nodeType(node any) string {
+	if m, ok := node.(map[string]any); ok {
+		typ, _ := m["type"].(string)
+		return strconv.Quote(typ)
+	}
+	return fmt.Sprintf("%T", node)
+}
+
+// node decodes the ESTree object v, which is nil if v is null.
+func (d *decoder) node(v any) Node {
+	if v == nil || d.err != nil {
+		return nil
+	}
+	m, ok := v.(map[string]any)
+	if !ok {
+		d.fail("unexpected %s value, expected a node", nodeType(v))
+		return nil
+	}
+	start, end := position(m)
+
+	switch typ, _ := m["type"].(string); typ {
+	case "Program":
+		body := list(m["body"])
+		program := &Program{
+			EndToken:   token.Token{TokenType: token.TOKEN_EOF, Line: end.Line, Col: end.Col, Offset: end.Offset},
+			SourceType: SourceScript,
+			Directives: directives(body),
+		}
+		if str(m, "sourceType") == "module" {
+			program.SourceType = SourceModule
+		}
+		program.Strict = program.SourceType == SourceModule || hasUseStrict(program.Directives)
+		if hashBang, ok := m["hashbang"].(string); ok {
+			program.HashBang = &token.Token{TokenType: token.TOKEN_HASH_BANG, Line: 1, Col: 1, Literal: hashBang, Raw: "#!" + hashBang}
+		}
+		strict := d.strict
+		d.strict = program.Strict
+		program.Statements = children[Statement](d, m, "body")
+		d.strict = strict
+		return program
+
+	// Expressions
+	case "Identifier":
+		name := str(m, "name")
+		return &Identifier{Token: at(token.LookupIdent(name), name, start), Value: name}
+	case "Literal":
+		return d.literal(m, start)
+	case "SpreadElement":
+		return &SpreadElement{Token: at(token.TOKEN_DOT_DOT_DOT, "...", start), Value: child[Expression](d, m, "argument")}
+	case "ArrayExpression":
+		return &ArrayLiteral{
+			LBracket:    at(token.TOKEN_LEFT_BRACKET, "[", start),
+			RBracket:    before(token.TOKEN_RIGHT_BRACKET, "]", end),
+			ElementList: d.elements(m),
+		}
+	case "UnaryExpression":
+		op := d.operator(m, start)
+		return &UnaryExpression{Token: *op, Operator: op, Value: child[Expression](d, m, "argument")}
+	case "BinaryExpression":
+		return &BinaryExpression{Operator: d.operator(m, token.Position{}), Left: child[Expression](d, m, "left"), Right: child[Expression](d, m, "right")}
+	case "LogicalExpression":
+		return &LogicalExpression{Operator: d.operator(m, token.Position{}), Left: child[Expression](d, m, "left"), Right: child[Expression](d, m, "right")}
+	case "UpdateExpression":
+		expr := &UpdateExpression{Argument: child[Expression](d, m, "argument"), Prefix: boolean(m, "prefix")}
+		if expr.Prefix {
+			expr.Operator = d.operator(m, start)
+		} else {
+			op := before(token.TOKEN_EOF, str(m, "operator"), end)
+			expr.Operator = d.operator(m, op.Pos())
+		}
+		return expr
+	case "ConditionalExpression":
+		return &TernaryExpression{
+			Condition:   child[Expression](d, m, "test"),
+			TrueBranch:  child[Expression](d, m, "consequent"),
+			FalseBranch: child[Expression](d, m, "alternate"),
+		}
+	case "SequenceExpression":
+		return &SequenceExpression{Expressions: children[Expression](d, m, "expressions")}
+	case "ThisExpression":
+		return &ThisExpression{Token: at(token.TOKEN_THIS, "this", start)}
+	case "Property":
+		prop := &Property{
+			Token:     at(token.TOKEN_IDENTIFIER, "", start),
+			Key:       child[Expression](d, m, "key"),
+			Value:     child[Expression](d, m, "value"),
+			Computed:  boolean(m, "computed"),
+			Method:    boolean(m, "method"),
+			Shorthand: boolean(m, "shorthand"),
+		}
+		switch str(m, "kind") {
+		case "get":
+			prop.Kind = PropertyGet
+		case "set":
+			prop.Kind = PropertySet
+		}
+		return prop
+	case "ObjectExpression":
+		return &ObjectLiteral{
+			LBrace:     at(token.TOKEN_LEFT_BRACE, "{", start),
+			RBrace:     before(token.TOKEN_RIGHT_BRACE, "}", end),
+			Properties: children[Expression](d, m, "properties"),
+		}
+	case "FunctionExpression":
+		fn := &FunctionExpression{Token: at(token.TOKEN_FUNCTION, "", start), Async: boolean(m, "async"), Generator: boolean(m, "generator")}
+		fn.Name, fn.Params, fn.Body, fn.Strict = d.function(m)
+		return fn
+	case "ArrowFunctionExpression":
+		fn := &ArrowFunctionExpression{Token: at(token.TOKEN_LEFT_PAREN, "", start), Async: boolean(m, "async")}
+		if boolean(m, "expression") {
+			fn.Params = children[Expression](d, m, "params")
+			fn.Body = child[Expression](d, m, "body")
+			fn.Strict = d.strict
+		} else {
+			_, fn.Params, fn.Body, fn.Strict = d.function(m)
+		}
+		return fn
+	case "AssignmentPattern":
+		return &AssignmentPattern{Left: child[Expression](d, m, "left"), Right: child[Expression](d, m, "right")}
+	case "RestElement":
+		return &RestElement{Token: at(token.TOKEN_DOT_DOT_DOT, "...", start), Argument: child[Expression](d, m, "argument")}
+	case "MemberExpression":
+		expr := &MemberExpression{
+			Object:   child[Expression](d, m, "object"),
+			Property: child[Expression](d, m, "property"),
+			Computed: boolean(m, "computed"),
+		}
+		if expr.Computed {
+			expr.RBracket = before(token.TOKEN_RIGHT_BRACKET, "]", end)
+		}
+		return expr
+	case "CallExpression":
+		return &CallExpression{
+			RParen:    before(token.TOKEN_RIGHT_PAREN, ")", end),
+			Callee:    child[Expression](d, m, "callee"),
+			Arguments: children[Expression](d, m, "arguments"),
+		}
+	case "NewExpression":
+		expr := &NewExpression{
+			Token:     at(token.TOKEN_NEW, "new", start),
+			Callee:    child[Expression](d, m, "callee"),
+			Arguments: children[Expression](d, m, "arguments"),
+		}
+		// The arguments are omitted if the expression ends with its callee.
+		if len(expr.Arguments) > 0 || expr.Callee == nil || expr.Callee.End() != end {
+			rparen := before(token.TOKEN_RIGHT_PAREN, ")", end)
+			expr.RParen = &rparen
+		}
+		return expr
+	case "AwaitExpression":
+		return &AwaitExpression{Token: at(token.TOKEN_AWAIT, "await", start), Argument: child[Expression](d, m, "argument")}
+	case "YieldExpression":
+		return &YieldExpression{Token: at(token.TOKEN_YIELD, "yield", start), Argument: child[Expression](d, m, "argument"), Delegate: boolean(m, "delegate")}
+	case "AssignmentExpression":
+		op := d.operator(m, token.Position{})
+		return &AssignmentExpression{Token: *op, Operator: op, Left: child[Expression](d, m, "left"), Right: child[Expression](d, m, "right")}
+	case "ArrayPattern":
+		return &ArrayPattern{
+			LBracket: at(token.TOKEN_LEFT_BRACKET, "[", start),
+			RBracket: before(token.TOKEN_RIGHT_BRACKET, "]", end),
+			Elements: d.elements(m),
+		}
+	case "ObjectPattern":
+		return &ObjectPattern{
+			LBrace:     at(token.TOKEN_LEFT_BRACE, "{", start),
+			RBrace:     before(token.TOKEN_RIGHT_BRACE, "}", end),
+			Properties: children[Expression](d, m, "properties"),
+		}
+	case "BadExpression":
+		return &BadExpression{From: at(token.TOKEN_EOF, "", start), To: before(token.TOKEN_EOF, "", end)}
+	case "ImportExpression":
+		return &ImportExpression{
+			Token:   at(token.TOKEN_IMPORT, "import", start),
+			RParen:  before(token.TOKEN_RIGHT_PAREN, ")", end),
+			Source:  child[Expression](d, m, "source"),
+			Options: child[Expression](d, m, "options"),
+		}
+	case "MetaProperty":
+		return &MetaProperty{Meta: child[*Identifier](d, m, "meta"), Property: child[*Identifier](d, m, "property")}
+
+	// Declarations
+	case "VariableDeclarator":
+		return &VariableDeclaration{Name: child[Expression](d, m, "id"), Value: child[Expression](d, m, "init")}
+	case "FunctionDeclaration":
+		fn := &FunctionDeclaration{Token: at(token.TOKEN_FUNCTION, "", start), Async: boolean(m, "async"), Generator: boolean(m, "generator")}
+		fn.Name, fn.Params, fn.Body, fn.Strict = d.function(m)
+		return fn
+
+	// Statements
+	case "BlockStatement":
+		return &BlockStatement{
+			LBrace:        at(token.TOKEN_LEFT_BRACE, "{", start),
+			RBrace:        before(token.TOKEN_RIGHT_BRACE, "}", end),
+			StatementList: children[Statement](d, m, "body"),
+		}
+	case "VariableDeclaration":
+		decls := children[Declaration](d, m, "declarations")
+		if len(decls) == 0 {
+			d.fail("VariableDeclaration has no declarations")
+			return nil
+		}
+		switch kind := str(m, "kind"); kind {
+		case "var":
+			return &VarStatement{Token: at(token.TOKEN_VAR, kind, start), EndToken: before(token.TOKEN_SEMICOLON, ";", end), Declarations: decls}
+		case "let", "const":
+			return &LexicalDeclaration{
+				Token:        at(token.LookupIdent(kind), kind, start),
+				EndToken:     before(token.TOKEN_SEMICOLON, ";", end),
+				Const:        kind == "const",
+				Declarations: decls,
+			}
+		default:
+			d.fail("unknown VariableDeclaration kind %q", kind)
+			return nil
+		}
+	case "EmptyStatement":
+		return &EmptyStatement{Token: at(token.TOKEN_SEMICOLON, ";", start)}
+	case "ExpressionStatement":
+		return &ExpressionStatement{
+			Token:      at(token.TOKEN_EOF, "", start),
+			EndToken:   before(token.TOKEN_SEMICOLON, ";", end),
+			Expression: child[Expression](d, m, "expression"),
+		}
+	case "IfStatement":
+		return &IfStatement{
+			Token:       at(token.TOKEN_IF, "if", start),
+			Condition:   child[Expression](d, m, "test"),
+			TrueBranch:  child[Statement](d, m, "consequent"),
+			FalseBranch: child[Statement](d, m, "alternate"),
+		}
+	case "ForStatement":
+		return &ForStatement{
+			Token:     at(token.TOKEN_FOR, "for", start),
+			Init:      child[Statement](d, m, "init"),
+			Condition: child[Expression](d, m, "test"),
+			Update:    child[Expression](d, m, "update"),
+			Body:      child[Statement](d, m, "body"),
+		}
+	case "ForInStatement":
+		return &ForInStatement{
+			Token: at(token.TOKEN_FOR, "for", start),
+			Left:  child[Node](d, m, "left"),
+			Right: child[Expression](d, m, "right"),
+			Body:  child[Statement](d, m, "body"),
+		}
+	case "ForOfStatement":
+		return &ForOfStatement{
+			Token: at(token.TOKEN_FOR, "for", start),
+			Left:  child[Node](d, m, "left"),
+			Right: child[Expression](d, m, "right"),
+			Body:  child[Statement](d, m, "body"),
+			Await: boolean(m, "await"),
+		}
+	case "WhileStatement":
+		return &WhileStatement{Token: at(token.TOKEN_WHILE, "while", start), Condition: child[Expression](d, m, "test"), Body: child[Statement](d, m, "body")}
+	case "WithStatement":
+		return &WithStatement{Token: at(token.TOKEN_WITH, "with", start), Object: child[Expression](d, m, "object"), Body: child[Statement](d, m, "body")}
+	case "DoWhileStatement":
+		return &DoWhileStatement{
+			Token:     at(token.TOKEN_DO, "do", start),
+			EndToken:  before(token.TOKEN_SEMICOLON, ";", end),
+			Body:      child[Statement](d, m, "body"),
+			Condition: child[Expression](d, m, "test"),
+		}
+	case "ContinueStatement":
+		return &ContinueStatement{Token: at(token.TOKEN_CONTINUE, "continue", start), EndToken: before(token.TOKEN_SEMICOLON, ";", end), Label: child[Expression](d, m, "label")}
+	case "BreakStatement":
+		return &BreakStatement{Token: at(token.TOKEN_BREAK, "break", start), EndToken: before(token.TOKEN_SEMICOLON, ";", end), Label: child[Expression](d, m, "label")}
+	case "ReturnStatement":
+		return &ReturnStatement{Token: at(token.TOKEN_RETURN, "return", start), EndToken: before(token.TOKEN_SEMICOLON, ";", end), Result: child[Expression](d, m, "argument")}
+	case "SwitchStatement":
+		stmt := &SwitchStatement{
+			Token:        at(token.TOKEN_SWITCH, "switch", start),
+			RBrace:       before(token.TOKEN_RIGHT_BRACE, "}", end),
+			Discriminant: child[Expression](d, m, "discriminant"),
+			Cases:        make([]SwitchCase, 0),
+		}
+		for _, c := range children[*SwitchCase](d, m, "cases") {
+			if c.Test == nil {
+				stmt.DefaultCase = c
+			} else {
+				stmt.Cases = append(stmt.Cases, *c)
+			}
+		}
+		return stmt
+	case "SwitchCase":
+		c := &SwitchCase{Test: child[Expression](d, m, "test"), Consequent: children[Statement](d, m, "consequent")}
+		if c.Test == nil {
+			c.Token = at(token.TOKEN_DEFAULT, "default", start)
+		} else {
+			c.Token = at(token.TOKEN_CASE, "case", start)
+		}
+		if len(c.Consequent) == 0 {
+			c.Colon = before(token.TOKEN_COLON, ":", end)
+		}
+		return c
+	case "LabeledStatement":
+		return &LabeledStatement{Label: child[Expression](d, m, "label"), Statement: child[Statement](d, m, "body")}
+	case "ThrowStatement":
+		return &ThrowStatement{Token: at(token.TOKEN_THROW, "throw", start), EndToken: before(token.TOKEN_SEMICOLON, ";", end), Argument: child[Expression](d, m, "argument")}
+	case "TryStatement":
+		return &TryStatement{
+			Token:       at(token.TOKEN_TRY, "try", start),
+			Block:       child[*BlockStatement](d, m, "block"),
+			CatchClause: child[*CatchClause](d, m, "handler"),
+			Finally:     child[*BlockStatement](d, m, "finalizer"),
+		}
+	case "CatchClause":
+		return &CatchClause{Token: at(token.TOKEN_CATCH, "catch", start), Param: child[*Identifier](d, m, "param"), Body: child[*BlockStatement](d, m, "body")}
+	case "DebuggerStatement":
+		return &DebuggerStatement{Token: at(token.TOKEN_DEBUGGER, "debugger", start), EndToken: before(token.TOKEN_SEMICOLON, ";", end)}
+	case "BadStatement":
+		return &BadStatement{From: at(token.TOKEN_EOF, "", start), To: before(token.TOKEN_EOF, "", end)}
+
+	// Modules
+	case "ImportDeclaration":
+		return &ImportDeclaration{
+			Token:      at(token.TOKEN_IMPORT, "import", start),
+			EndToken:   before(token.TOKEN_SEMICOLON, ";", end),
+			Specifiers: children[Expression](d, m, "specifiers"),
+			Source:     child[*Literal](d, m, "source"),
+			Attributes: children[*ImportAttribute](d, m, "attributes"),
+		}
+	case "ImportSpecifier":
+		return &ImportSpecifier{Imported: child[Expression](d, m, "imported"), Local: child[*Identifier](d, m, "local")}
+	case "ImportDefaultSpecifier":
+		return &ImportDefaultSpecifier{Local: child[*Identifier](d, m, "local")}
+	case "ImportNamespaceSpecifier":
+		return &ImportNamespaceSpecifier{Token: at(token.TOKEN_STAR, "*", start), Local: child[*Identifier](d, m, "local")}
+	case "ImportAttribute":
+		return &ImportAttribute{Key: child[Expression](d, m, "key"), Value: child[*Literal](d, m, "value")}
+	case "ExportNamedDeclaration":
+		decl := &ExportNamedDeclaration{
+			Token:       at(token.TOKEN_EXPORT, "export", start),
+			Declaration: child[Statement](d, m, "declaration"),
+			Specifiers:  children[*ExportSpecifier](d, m, "specifiers"),
+			Source:      child[*Literal](d, m, "source"),
+			Attributes:  children[*ImportAttribute](d, m, "attributes"),
+		}
+		if decl.Declaration == nil {
+			decl.EndToken = before(token.TOKEN_SEMICOLON, ";", end)
+		}
+		return decl
+	case "ExportSpecifier":
+		return &ExportSpecifier{Local: child[Expression](d, m, "local"), Exported: child[Expression](d, m, "exported")}
+	case "ExportDefaultDeclaration":
+		decl := &ExportDefaultDeclaration{Token: at(token.TOKEN_EXPORT, "export", start), Declaration: child[Node](d, m, "declaration")}
+		if _, ok := decl.Declaration.(*FunctionDeclaration); !ok {
+			decl.EndToken = before(token.TOKEN_SEMICOLON, ";", end)
+		}
+		return decl
+	case "ExportAllDeclaration":
+		return &ExportAllDeclaration{
+			Token:      at(token.TOKEN_EXPORT, "export", start),
+			EndToken:   before(token.TOKEN_SEMICOLON, ";", end),
+			Exported:   child[Expression](d, m, "exported"),
+			Source:     child[*Literal](d, m, "source"),
+			Attributes: children[*ImportAttribute](d, m, "attributes"),
+		}
+
+	// JSX
+	case "JSXElement":
+		return &JSXElement{
+			OpeningElement: child[*JSXOpeningElement](d, m, "openingElement"),
+			Children:       children[Expression](d, m, "children"),
+			ClosingElement: child[*JSXClosingElement](d, m, "closingElement"),
+		}
+	case "JSXOpeningElement":
+		return &JSXOpeningElement{
+			LessThan:    at(token.TOKEN_LESS, "<", start),
+			GreaterThan: before(token.TOKEN_GREATER, ">", end),
+			Name:        child[Expression](d, m, "name"),
+			Attributes:  children[Expression](d, m, "attributes"),
+			SelfClosing: boolean(m, "selfClosing"),
+		}
+	case "JSXClosingElement":
+		return &JSXClosingElement{
+			LessThan:    at(token.TOKEN_LESS, "<", start),
+			GreaterThan: before(token.TOKEN_GREATER, ">", end),
+			Name:        child[Expression](d, m, "name"),
+		}
+	case "JSXFragment":
+		return &JSXFragment{
+			OpeningFragment: child[*JSXOpeningFragment](d, m, "openingFragment"),
+			Children:        children[Expression](d, m, "children"),
+			ClosingFragment: child[*JSXClosingFragment](d, m, "closingFragment"),
+		}
+	case "JSXOpeningFragment":
+		return &JSXOpeningFragment{LessThan: at(token.TOKEN_LESS, "<", start), GreaterThan: before(token.TOKEN_GREATER, ">", end)}
+	case "JSXClosingFragment":
+		return &JSXClosingFragment{LessThan: at(token.TOKEN_LESS, "<", start), GreaterThan: before(token.TOKEN_GREATER, ">", end)}
+	case "JSXIdentifier":
+		name := str(m, "name")
+		tok := at(token.TOKEN_IDENTIFIER, name, start)
+		return &JSXIdentifier{Token: tok, EndToken: tok, Name: name}
+	case "JSXNamespacedName":
+		return &JSXNamespacedName{Namespace: child[*JSXIdentifier](d, m, "namespace"), Name: child[*JSXIdentifier](d, m, "name")}
+	case "JSXMemberExpression":
+		return &JSXMemberExpression{Object: child[Expression](d, m, "object"), Property: child[*JSXIdentifier](d, m, "property")}
+	case "JSXAttribute":
+		return &JSXAttribute{Name: child[Expression](d, m, "name"), Value: child[Expression](d, m, "value")}
+	case "JSXSpreadAttribute":
+		return &JSXSpreadAttribute{
+			LBrace:   at(token.TOKEN_LEFT_BRACE, "{", start),
+			RBrace:   before(token.TOKEN_RIGHT_BRACE, "}", end),
+			Argument: child[Expression](d, m, "argument"),
+		}
+	case "JSXExpressionContainer":
+		return &JSXExpressionContainer{
+			LBrace:     at(token.TOKEN_LEFT_BRACE, "{", start),
+			RBrace:     before(token.TOKEN_RIGHT_BRACE, "}", end),
+			Expression: child[Expression](d, m, "expression"),
+		}
+	case "JSXEmptyExpression":
+		return &JSXEmptyExpression{RBrace: at(token.TOKEN_RIGHT_BRACE, "}", start)}
+	case "JSXSpreadChild":
+		return &JSXSpreadChild{
+			LBrace:     at(token.TOKEN_LEFT_BRACE, "{", start),
+			RBrace:     before(token.TOKEN_RIGHT_BRACE, "}", end),
+			Expression: child[Expression](d, m, "expression"),
+		}
+	case "JSXText":
+		raw, ok := m["raw"].(string)
+		if !ok {
+			raw = str(m, "value")
+		}
+		return &JSXText{Token: at(token.TOKEN_JSX_TEXT, raw, start), Value: str(m, "value")}
+	default:
+		d.fail("unknown node type %q", typ)
+		return nil
+	}
+}
+
+// child decodes the node in the field key of m as a T, which is the zero
+// value if the field is null or missing.
+func child[T Node](d *decoder, m map[string]any, key string) T {
+	var zero T
+	node := d.node(m[key])
+	if node == nil {
+		return zero
+	}
+	t, ok := node.(T)
+	if !ok {
+		d.fail("unexpected %s node in the %s of %s", nodeType(m[key]), key, nodeType(m))
+		return zero
+	}
+	return t
+}
+
+// children decodes the list of nodes in the field key of m.
+func children[T Node](d *decoder, m map[string]any, key string) []T {
+	items := list(m[key])
+	nodes := make([]T, 0, len(items))
+	for _, item := range items {
+		node := d.node(item)
+		t, ok := node.(T)
+		if !ok {
+			d.fail("unexpected %s node in the %s of %s", nodeType(item), key, nodeType(m))
+			return nil
+		}
+		nodes = append(nodes, t)
+	}
+	return nodes
+}
+
+// elements decodes the elements of an array, whose holes are null.
+func (d *decoder) elements(m map[string]any) []Expression {
+	items := list(m["elements"])
+	elems := make([]Expression, 0, len(items))
+	for _, item := range items {
+		if item == nil {
+			elems = append(elems, &Elision{})
+		} else {
+			elems = append(elems, d.node(item))
+		}
+	}
+	return elems
+}
+
+// function decodes the name, the parameters and the body of a function, and
+// reports whether it is strict mode code.
+func (d *decoder) function(m map[string]any) (*Identifier, []Expression, *BlockStatement, bool) {
+	strict := d.strict
+	defer func() {
+		d.strict = strict
+	}()
+	if body, ok := m["body"].(map[string]any); ok {
+		d.strict = d.strict || hasUseStrict(directives(list(body["body"])))
+	}
+	return child[*Identifier](d, m, "id"), children[Expression](d, m, "params"), child[*BlockStatement](d, m, "body"), d.strict
+}
+
+// operator returns the token of the operator of m, positioned at pos.
+func (d *decoder) operator(m map[string]any, pos token.Position) *token.Token {
+	op := str(m, "operator")
+	typ, ok := operators[op]
+	if !ok {
+		d.fail("unknown operator %q of %s", op, nodeType(m))
+	}
+	tok := at(typ, op, pos)
+	return &tok
+}
+
+func (d *decoder) literal(m map[string]any, start token.Position) *Literal {
+	raw, hasRaw := m["raw"].(string)
+	switch v := m["value"].(type) {
+	case nil:
+		if _, ok := m["regex"]; ok {
+			d.fail("regular expression literals are not supported")
+			return nil
+		} else if hasRaw && raw != "null" {
+			// A number that is out of the range of JSON.
+			return &Literal{Token: at(token.TOKEN_NUMBER, raw, start), Value: raw, Kind: LitNumber}
+		}
+		return &Literal{Token: at(token.TOKEN_NULL, "null", start), Value: "null", Kind: LitNull}
+	case bool:
+		lit := strconv.FormatBool(v)
+		return &Literal{Token: at(token.LookupIdent(lit), lit, start), Value: lit, Kind: LitBoolean}
+	case float64:
+		if !hasRaw {
+			raw = strconv.FormatFloat(v, 'g', -1, 64)
+		}
+		return &Literal{Token: at(token.TOKEN_NUMBER, raw, start), Value: raw, Kind: LitNumber}
+	case string:
+		if !hasRaw {
+			raw = strconv.Quote(v)
+		}
+		tok := at(token.TOKEN_STRING, raw, start)
+		tok.Literal = v
+		return &Literal{Token: tok, Value: v, Kind: LitString}
+	}
+	d.fail("unexpected value of Literal %v", m["value"])
+	return nil
+}
+
+// operators maps the operators of the expressions to their token types.
+var operators = func() map[string]token.TokenType {
+	ops := make(map[string]token.TokenType)
+	for typ := token.TOKEN_LEFT_PAREN; typ <= token.TOKEN_TILDE; typ++ {
+		ops[typ.Text()] = typ
+	}
+	for _, typ := range []token.TokenType{token.TOKEN_DELETE, token.TOKEN_IN, token.TOKEN_INSTANCEOF, token.TOKEN_TYPEOF, token.TOKEN_VOID} {
+		ops[typ.Text()] = typ
+	}
+	return ops
+}()
+
+// directives returns the directives of the statements of a program or a
+// function body.
+func directives(body []any) []string {
+	list := make([]string, 0)
+	for _, stmt := range body {
+		m, _ := stmt.(map[string]any)
+		directive, ok := m["directive"].(string)
+		if !ok {
+			break
+		}
+		list = append(list, directive)
+	}
+	return list
+}
+
+func hasUseStrict(directives []string) bool {
+	for _, directive := range directives {
+		if directive == "use strict" {
+			return true
+		}
+	}
+	return false
+}
+
+// position returns the start and the end of the location of m, which are
+// invalid if m has no location.
+func position(m map[string]any) (start, end token.Position) {
+	loc, _ := m["loc"].(map[string]any)
+	start, end = linePosition(loc["start"]), linePosition(loc["end"])
+	if r := list(m["range"]); len(r) == 2 {
+		start.Offset, end.Offset = number(r[0]), number(r[1])
+	} else {
+		// The offsets of acorn.
+		start.Offset, end.Offset = number(m["start"]), number(m["end"])
+	}
+	return start, end
+}
+
+func linePosition(v any) token.Position {
+	m, _ := v.(map[string]any)
+	line, ok := m["line"].(float64)
+	if !ok {
+		return token.Position{}
+	}
+	return token.Position{Line: int(line), Col: number(m["column"]) + 1}
+}
+
+// at returns a token of typ and raw starting at pos, which has no position if
+// pos is invalid.
+func at(typ token.TokenType, raw string, pos token.Position) token.Token {
+	if !pos.IsValid() {
+		return token.Token{TokenType: typ, Literal: raw, Raw: raw}
+	}
+	return token.Token{TokenType: typ, Line: pos.Line, Col: pos.Col, Literal: raw, Offset: pos.Offset, Raw: raw}
+}
+
+// before returns a token of typ and raw ending at end.
+func before(typ token.TokenType, raw string, end token.Position) token.Token {
+	if end.IsValid() {
+		end.Offset -= len(raw)
+		end.Col -= utf8.RuneCountInString(raw)
+	}
+	return at(typ, raw, end)
+}
+
+func list(v any) []any {
+	items, _ := v.([]any)
+	return items
+}
+
+func str(m map[string]any, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+func boolean(m map[string]any, key string) bool {
+	b, _ := m[key].(bool)
+	return b
+}
+
+func number(v any) int {
+	n, _ := v.(float64)
+	return int(n)
+}
@@ -0,0 +1,127 @@
+package ast_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/lexer"
+	"github.com/ghosind/gjs/parser"
+	"github.com/ghosind/go-assert"
+)
+
+func TestMarshalESTree(t *testing.T) {
+	a := assert.New(t)
+
+	program, err := parser.New(lexer.New([]byte("a + 1;"))).ParseProgram()
+	a.NilNow(err)
+
+	data, err := json.Marshal(program)
+	a.NilNow(err)
+	a.EqualNow(string(data), `{"type":"Program","loc":{"start":{"line":1,"column":0},"end":{"line":1,"column":6}},"range":[0,6],`+
+		`"body":[{"type":"ExpressionStatement","loc":{"start":{"line":1,"column":0},"end":{"line":1,"column":6}},"range":[0,6],`+
+		`"expression":{"type":"BinaryExpression","loc":{"start":{"line":1,"column":0},"end":{"line":1,"column":5}},"range":[0,5],`+
+		`"left":{"type":"Identifier","loc":{"start":{"line":1,"column":0},"end":{"line":1,"column":1}},"range":[0,1],"name":"a"},`+
+		`"operator":"+",`+
+		`"right":{"type":"Literal","loc":{"start":{"line":1,"column":4},"end":{"line":1,"column":5}},"range":[4,5],"value":1,"raw":"1"}}}],`+
+		`"sourceType":"script"}`)
+
+	// Nodes without a location, such as the ones created by transforms, are
+	// encoded without loc and range.
+	data, err = ast.MarshalESTree(&ast.ArrayLiteral{ElementList: []ast.Expression{
+		&ast.Elision{},
+		&ast.Literal{Value: "<a>", Kind: ast.LitString},
+	}})
+	a.NilNow(err)
+	a.EqualNow(string(data), `{"type":"ArrayExpression","elements":[null,{"type":"Literal","value":"<a>","raw":""}]}`)
+}
+
+func TestESTreeRoundTrip(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []struct {
+		input string
+		opts  parser.Options
+	}{
+		{"'use strict';\n\"a\\x62\";\nvar a = [1, , 'x'], b = a[0] + -1;\nfunction f(x = 1, ...y) { return new Foo; }\n" +
+			"label: for (let i of a) { if (i) continue label; else break; }\n", parser.Options{}},
+		{"var o = {a, b: 1, get c() { return 1.5; }, [d]() {}, 'e': 2};\nvar {p, q: [r = 1, ...s]} = o;\n" +
+			"x = a ? b : c, y++, --z, typeof a, a && b || c;\nfor (var k in o) while (k) do k--; while (k)\n" +
+			"(function* () { yield* a; })(); new Bar(1);", parser.Options{}},
+		{"#!/usr/bin/env gjs\nimport a, {b as c, 'd' as e} from 'm' with {type: 'json'};\nimport * as ns from 'n';\n" +
+			"export default function () { 'use strict'; return import.meta; }\nexport {a as aa, c};\n" +
+			"export * as x from 'y';\nexport const z = async () => { await import('z'); }, w = (v) => v;\n",
+			parser.Options{SourceType: ast.SourceModule, AllowHashBang: true}},
+		{"const el = <div a=\"1\" {...p} b={x}>hi &amp; {y}{/* c */}{...z}<a.b c:d=\"2\" /><></></div>;",
+			parser.Options{JSX: true}},
+	}
+
+	for _, test := range tests {
+		program, err := parser.NewWithOptions(lexer.New([]byte(test.input)), test.opts).Parse()
+		a.NilNow(err, test.input)
+		data, err := json.Marshal(program)
+		a.NilNow(err, test.input)
+
+		decoded := new(ast.Program)
+		a.NilNow(json.Unmarshal(data, decoded), test.input)
+		a.EqualNow(decoded.String(), program.String(), test.input)
+		a.EqualNow(decoded.SourceType, program.SourceType, test.input)
+		a.EqualNow(decoded.Strict, program.Strict, test.input)
+		a.DeepEqualNow(decoded.Directives, program.Directives, test.input)
+		a.EqualNow(decoded.End(), program.End(), test.input)
+
+		again, err := json.Marshal(decoded)
+		a.NilNow(err, test.input)
+		a.EqualNow(string(again), string(data), test.input)
+	}
+}
+
+func TestUnmarshalESTree(t *testing.T) {
+	a := assert.New(t)
+
+	// The output of acorn, whose offsets are start and end.
+	node, err := ast.UnmarshalESTree([]byte(`{"type": "ExpressionStatement", "start": 0, "end": 14,
+		"loc": {"start": {"line": 1, "column": 0}, "end": {"line": 1, "column": 14}},
+		"expression": {"type": "CallExpression", "start": 0, "end": 13,
+			"loc": {"start": {"line": 1, "column": 0}, "end": {"line": 1, "column": 13}},
+			"callee": {"type": "Identifier", "start": 0, "end": 1,
+				"loc": {"start": {"line": 1, "column": 0}, "end": {"line": 1, "column": 1}}, "name": "f"},
+			"arguments": [{"type": "Literal", "start": 2, "end": 12,
+				"loc": {"start": {"line": 1, "column": 2}, "end": {"line": 1, "column": 12}}, "value": "a\"b", "raw": "'a\\\"b'"}],
+			"optional": false}}`))
+	a.NilNow(err)
+	stmt, ok := node.(*ast.ExpressionStatement)
+	a.TrueNow(ok)
+	call := stmt.Expression.(*ast.CallExpression)
+	a.EqualNow(call.String(), `f(a"b)`)
+	a.EqualNow(call.RParen.Pos().Offset, 12)
+	a.EqualNow(call.Arguments[0].Pos().Col, 3)
+	a.EqualNow(stmt.End().Offset, 14)
+
+	// Literals may have no raw, and nodes no location.
+	node, err = ast.UnmarshalESTree([]byte(`{"type": "ArrayExpression", "elements": [
+		{"type": "Literal", "value": 1.5}, null, {"type": "Literal", "value": "s"}, {"type": "Literal", "value": null}]}`))
+	a.NilNow(err)
+	a.EqualNow(node.String(), "[1.5, , s, null]")
+	a.NotTrueNow(node.Pos().IsValid())
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{"type": "ClassDeclaration"}`, `ast: unknown node type "ClassDeclaration"`},
+		{`{"type": "BinaryExpression", "operator": "<>"}`, `ast: unknown operator "<>" of "BinaryExpression"`},
+		{`{"type": "CatchClause", "param": {"type": "Literal", "value": 1}}`, `ast: unexpected "Literal" node in the param of "CatchClause"`},
+		{`{"type": "Literal", "value": null, "regex": {"pattern": "a", "flags": ""}}`, "ast: regular expression literals are not supported"},
+		{`[1]`, "ast: unexpected []interface {} value, expected a node"},
+	}
+	for _, test := range tests {
+		_, err := ast.UnmarshalESTree([]byte(test.input))
+		a.NotNilNow(err, test.input)
+		a.EqualNow(err.Error(), test.expected, test.input)
+	}
+
+	err = json.Unmarshal([]byte(`{"type": "EmptyStatement"}`), new(ast.Program))
+	a.NotNilNow(err)
+	a.EqualNow(err.Error(), "ast: unexpected *ast.EmptyStatement node, expected Program")
+}
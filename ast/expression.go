@@ -23,19 +23,27 @@ type Literal struct {
 	Token token.Token
 	Value string
 	Kind  LitKind
+	// Number is the value of a number literal converted ahead of the
+	// evaluation, such as by the optimize package, or nil if the literal is
+	// converted when it is evaluated.
+	Number *float64
 }
 
 func (l *Literal) String() string {
 	return l.Value
 }
 
-type Elision struct{}
+type Elision struct {
+	// Token is the comma that follows the hole.
+	Token token.Token
+}
 
 func (e *Elision) String() string {
 	return ""
 }
 
 type SpreadElement struct {
+	Token token.Token
 	Value Expression
 }
 
@@ -44,6 +52,8 @@ func (s *SpreadElement) String() string {
 }
 
 type ArrayLiteral struct {
+	LBracket    token.Token
+	RBracket    token.Token
 	ElementList []Expression
 }
 
@@ -88,6 +98,39 @@ func (b *BinaryExpression) String() string {
 	return buf.String()
 }
 
+// LogicalExpression is an expression of a short-circuiting operator, which is
+// &&, || or ??. The right operand is evaluated only if the value of the left
+// one does not decide the result.
+type LogicalExpression struct {
+	Operator *token.Token
+	Left     Expression
+	Right    Expression
+}
+
+func (l *LogicalExpression) String() string {
+	buf := new(bytes.Buffer)
+	buf.WriteString(l.Left.String())
+	buf.WriteString(" " + l.Operator.Literal + " ")
+	buf.WriteString(l.Right.String())
+	return buf.String()
+}
+
+// UpdateExpression is an increment or a decrement of a reference by ++ or --.
+// Prefix is set if the operator precedes the argument, and the expression
+// evaluates to the new value. Otherwise, it evaluates to the old one.
+type UpdateExpression struct {
+	Operator *token.Token
+	Argument Expression
+	Prefix   bool
+}
+
+func (u *UpdateExpression) String() string {
+	if u.Prefix {
+		return u.Operator.Literal + u.Argument.String()
+	}
+	return u.Argument.String() + u.Operator.Literal
+}
+
 type TernaryExpression struct {
 	Token       token.Token
 	Condition   Expression
@@ -104,3 +147,285 @@ func (t *TernaryExpression) String() string {
 	buf.WriteString(t.FalseBranch.String())
 	return buf.String()
 }
+
+// SequenceExpression is a list of expressions separated by the comma
+// operator, which evaluates to the value of the last one.
+type SequenceExpression struct {
+	Expressions []Expression
+}
+
+func (s *SequenceExpression) String() string {
+	buf := new(bytes.Buffer)
+	for i, expr := range s.Expressions {
+		buf.WriteString(expr.String())
+		if i < len(s.Expressions)-1 {
+			buf.WriteString(", ")
+		}
+	}
+	return buf.String()
+}
+
+type ThisExpression struct {
+	Token token.Token
+}
+
+func (t *ThisExpression) String() string {
+	return "this"
+}
+
+type PropertyKind int
+
+const (
+	PropertyInit PropertyKind = iota
+	PropertyGet
+	PropertySet
+)
+
+type Property struct {
+	// Token is the first token of the property definition.
+	Token     token.Token
+	Key       Expression
+	Value     Expression
+	Kind      PropertyKind
+	Computed  bool
+	Method    bool
+	Shorthand bool
+}
+
+func (p *Property) String() string {
+	if p.Shorthand {
+		return p.Value.String()
+	}
+
+	key := p.Key.String()
+	if p.Computed {
+		key = "[" + key + "]"
+	}
+
+	switch p.Kind {
+	case PropertyGet:
+		key = "get " + key
+	case PropertySet:
+		key = "set " + key
+	}
+
+	if fn, ok := p.Value.(*FunctionExpression); ok && (p.Method || p.Kind != PropertyInit) {
+		buf := new(bytes.Buffer)
+		if fn.Async {
+			buf.WriteString("async ")
+		}
+		if fn.Generator {
+			buf.WriteString("*")
+		}
+		buf.WriteString(key)
+		buf.WriteString(paramsString(fn.Params))
+		buf.WriteString(" ")
+		buf.WriteString(fn.Body.String())
+		return buf.String()
+	}
+
+	return key + ": " + p.Value.String()
+}
+
+type ObjectLiteral struct {
+	LBrace     token.Token
+	RBrace     token.Token
+	Properties []Expression
+}
+
+func (o *ObjectLiteral) String() string {
+	buf := new(bytes.Buffer)
+	buf.WriteString("{")
+	for i, prop := range o.Properties {
+		buf.WriteString(prop.String())
+		if i < len(o.Properties)-1 {
+			buf.WriteString(", ")
+		}
+	}
+	buf.WriteString("}")
+	return buf.String()
+}
+
+type FunctionExpression struct {
+	// Token is the async or function keyword that starts the expression.
+	Token     token.Token
+	Name      *Identifier
+	Params    []Expression
+	Body      *BlockStatement
+	Async     bool
+	Generator bool
+	Strict    bool
+}
+
+func (f *FunctionExpression) String() string {
+	return functionString(f.Async, f.Generator, f.Name, f.Params, f.Body)
+}
+
+type ArrowFunctionExpression struct {
+	// Token is the first token of the expression.
+	Token  token.Token
+	Params []Expression
+	// Body is a *BlockStatement, or an Expression for concise bodies.
+	Body   Node
+	Async  bool
+	Strict bool
+}
+
+func (a *ArrowFunctionExpression) String() string {
+	buf := new(bytes.Buffer)
+	if a.Async {
+		buf.WriteString("async ")
+	}
+	buf.WriteString(paramsString(a.Params))
+	buf.WriteString(" => ")
+	if _, ok := a.Body.(*ObjectLiteral); ok {
+		buf.WriteString("(" + a.Body.String() + ")")
+	} else {
+		buf.WriteString(a.Body.String())
+	}
+	return buf.String()
+}
+
+type AssignmentPattern struct {
+	Left  Expression
+	Right Expression
+}
+
+func (a *AssignmentPattern) String() string {
+	return a.Left.String() + " = " + a.Right.String()
+}
+
+type RestElement struct {
+	Token    token.Token
+	Argument Expression
+}
+
+func (r *RestElement) String() string {
+	return "..." + r.Argument.String()
+}
+
+type MemberExpression struct {
+	// RBracket is the closing bracket of a computed property.
+	RBracket token.Token
+	Object   Expression
+	Property Expression
+	Computed bool
+}
+
+func (m *MemberExpression) String() string {
+	if m.Computed {
+		return m.Object.String() + "[" + m.Property.String() + "]"
+	}
+	return m.Object.String() + "." + m.Property.String()
+}
+
+type CallExpression struct {
+	RParen    token.Token
+	Callee    Expression
+	Arguments []Expression
+}
+
+func (c *CallExpression) String() string {
+	return c.Callee.String() + paramsString(c.Arguments)
+}
+
+type NewExpression struct {
+	Token token.Token
+	// RParen is the closing parenthesis of the arguments, or nil if the
+	// arguments are omitted.
+	RParen    *token.Token
+	Callee    Expression
+	Arguments []Expression
+}
+
+func (n *NewExpression) String() string {
+	return "new " + n.Callee.String() + paramsString(n.Arguments)
+}
+
+type AwaitExpression struct {
+	Token    token.Token
+	Argument Expression
+}
+
+func (a *AwaitExpression) String() string {
+	return "await " + a.Argument.String()
+}
+
+type YieldExpression struct {
+	Token    token.Token
+	Argument Expression
+	Delegate bool
+}
+
+func (y *YieldExpression) String() string {
+	buf := new(bytes.Buffer)
+	buf.WriteString("yield")
+	if y.Delegate {
+		buf.WriteString("*")
+	}
+	if y.Argument != nil {
+		buf.WriteString(" " + y.Argument.String())
+	}
+	return buf.String()
+}
+
+type AssignmentExpression struct {
+	Token    token.Token
+	Operator *token.Token
+	Left     Expression
+	Right    Expression
+}
+
+func (a *AssignmentExpression) String() string {
+	return a.Left.String() + " " + a.Operator.Literal + " " + a.Right.String()
+}
+
+type ArrayPattern struct {
+	LBracket token.Token
+	RBracket token.Token
+	Elements []Expression
+}
+
+func (a *ArrayPattern) String() string {
+	buf := new(bytes.Buffer)
+	buf.WriteString("[")
+	for i, elem := range a.Elements {
+		buf.WriteString(elem.String())
+		if i < len(a.Elements)-1 {
+			buf.WriteString(", ")
+		}
+	}
+	buf.WriteString("]")
+	return buf.String()
+}
+
+type ObjectPattern struct {
+	LBrace     token.Token
+	RBrace     token.Token
+	Properties []Expression
+}
+
+func (o *ObjectPattern) String() string {
+	buf := new(bytes.Buffer)
+	buf.WriteString("{")
+	for i, prop := range o.Properties {
+		buf.WriteString(prop.String())
+		if i < len(o.Properties)-1 {
+			buf.WriteString(", ")
+		}
+	}
+	buf.WriteString("}")
+	return buf.String()
+}
+
+// BadExpression is a placeholder for an expression that contains syntax
+// errors, created by the parser in tolerant mode. From and To are the first
+// and the last tokens of the skipped source.
+type BadExpression struct {
+	From token.Token
+	To   token.Token
+}
+
+func (e *BadExpression) String() string {
+	return "<bad expression>"
+}
@@ -0,0 +1,211 @@
+package ast
+
+import (
+	"bytes"
+
+	"github.com/ghosind/gjs/token"
+)
+
+// JSXElement is an element of JSX. ClosingElement is nil if the element is
+// self-closing.
+type JSXElement struct {
+	OpeningElement *JSXOpeningElement
+	// Children are *JSXText, *JSXExpressionContainer, *JSXSpreadChild,
+	// *JSXElement and *JSXFragment nodes.
+	Children       []Expression
+	ClosingElement *JSXClosingElement
+}
+
+func (e *JSXElement) String() string {
+	buf := new(bytes.Buffer)
+	buf.WriteString(e.OpeningElement.String())
+	for _, child := range e.Children {
+		buf.WriteString(child.String())
+	}
+	if e.ClosingElement != nil {
+		buf.WriteString(e.ClosingElement.String())
+	}
+	return buf.String()
+}
+
+type JSXOpeningElement struct {
+	// LessThan and GreaterThan are the angle brackets around the tag.
+	LessThan    token.Token
+	GreaterThan token.Token
+	// Name is a *JSXIdentifier, *JSXNamespacedName or *JSXMemberExpression.
+	Name Expression
+	// Attributes are *JSXAttribute and *JSXSpreadAttribute nodes.
+	Attributes  []Expression
+	SelfClosing bool
+}
+
+func (e *JSXOpeningElement) String() string {
+	buf := new(bytes.Buffer)
+	buf.WriteString("<")
+	buf.WriteString(e.Name.String())
+	for _, attr := range e.Attributes {
+		buf.WriteString(" ")
+		buf.WriteString(attr.String())
+	}
+	if e.SelfClosing {
+		buf.WriteString(" /")
+	}
+	buf.WriteString(">")
+	return buf.String()
+}
+
+type JSXClosingElement struct {
+	LessThan    token.Token
+	GreaterThan token.Token
+	Name        Expression
+}
+
+func (e *JSXClosingElement) String() string {
+	return "</" + e.Name.String() + ">"
+}
+
+// JSXFragment is a fragment of JSX, which groups its children without an
+// element.
+type JSXFragment struct {
+	OpeningFragment *JSXOpeningFragment
+	Children        []Expression
+	ClosingFragment *JSXClosingFragment
+}
+
+func (f *JSXFragment) String() string {
+	buf := new(bytes.Buffer)
+	buf.WriteString("<>")
+	for _, child := range f.Children {
+		buf.WriteString(child.String())
+	}
+	buf.WriteString("</>")
+	return buf.String()
+}
+
+type JSXOpeningFragment struct {
+	LessThan    token.Token
+	GreaterThan token.Token
+}
+
+func (f *JSXOpeningFragment) String() string {
+	return "<>"
+}
+
+type JSXClosingFragment struct {
+	LessThan    token.Token
+	GreaterThan token.Token
+}
+
+func (f *JSXClosingFragment) String() string {
+	return "</>"
+}
+
+// JSXIdentifier is the name of an element or an attribute, which may contain
+// dashes.
+type JSXIdentifier struct {
+	// Token is the first token of the name.
+	Token token.Token
+	// EndToken is the last token of the name.
+	EndToken token.Token
+	Name     string
+}
+
+func (i *JSXIdentifier) String() string {
+	return i.Name
+}
+
+// JSXNamespacedName is a name with a namespace, such as xlink:href.
+type JSXNamespacedName struct {
+	Namespace *JSXIdentifier
+	Name      *JSXIdentifier
+}
+
+func (n *JSXNamespacedName) String() string {
+	return n.Namespace.String() + ":" + n.Name.String()
+}
+
+// JSXMemberExpression is an element name that is a property of an object,
+// such as Foo.Bar.
+type JSXMemberExpression struct {
+	// Object is a *JSXIdentifier or a *JSXMemberExpression.
+	Object   Expression
+	Property *JSXIdentifier
+}
+
+func (e *JSXMemberExpression) String() string {
+	return e.Object.String() + "." + e.Property.String()
+}
+
+type JSXAttribute struct {
+	// Name is a *JSXIdentifier or a *JSXNamespacedName.
+	Name Expression
+	// Value is a string *Literal, a *JSXExpressionContainer, a *JSXElement or
+	// a *JSXFragment, or nil if the attribute has no value.
+	Value Expression
+}
+
+func (a *JSXAttribute) String() string {
+	if a.Value == nil {
+		return a.Name.String()
+	}
+	if lit, ok := a.Value.(*Literal); ok {
+		return a.Name.String() + "=" + lit.Token.Raw
+	}
+	return a.Name.String() + "=" + a.Value.String()
+}
+
+type JSXSpreadAttribute struct {
+	LBrace   token.Token
+	RBrace   token.Token
+	Argument Expression
+}
+
+func (a *JSXSpreadAttribute) String() string {
+	return "{..." + a.Argument.String() + "}"
+}
+
+// JSXExpressionContainer is an expression in braces, as a child or as the
+// value of an attribute. Expression is a *JSXEmptyExpression if there is no
+// expression in the braces.
+type JSXExpressionContainer struct {
+	LBrace     token.Token
+	RBrace     token.Token
+	Expression Expression
+}
+
+func (c *JSXExpressionContainer) String() string {
+	return "{" + c.Expression.String() + "}"
+}
+
+// JSXEmptyExpression is the missing expression of a container that holds
+// nothing but comments.
+type JSXEmptyExpression struct {
+	// RBrace is the closing brace of the container.
+	RBrace token.Token
+}
+
+func (e *JSXEmptyExpression) String() string {
+	return ""
+}
+
+// JSXSpreadChild is a child that spreads an iterable into the children.
+type JSXSpreadChild struct {
+	LBrace     token.Token
+	RBrace     token.Token
+	Expression Expression
+}
+
+func (c *JSXSpreadChild) String() string {
+	return "{..." + c.Expression.String() + "}"
+}
+
+// JSXText is text between the tags of an element. Value is the text with its
+// character references decoded.
+type JSXText struct {
+	Token token.Token
+	Value string
+}
+
+func (t *JSXText) String() string {
+	return t.Token.Raw
+}
@@ -0,0 +1,218 @@
+package ast
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/ghosind/gjs/token"
+)
+
+type ImportDeclaration struct {
+	Token    token.Token
+	EndToken token.Token
+	// Specifiers are *ImportDefaultSpecifier, *ImportNamespaceSpecifier and
+	// *ImportSpecifier nodes, empty for a side-effect import.
+	Specifiers []Expression
+	Source     *Literal
+	Attributes []*ImportAttribute
+}
+
+func (d *ImportDeclaration) String() string {
+	buf := new(bytes.Buffer)
+	buf.WriteString("import ")
+
+	named := make([]string, 0)
+	clauses := make([]string, 0)
+	for _, spec := range d.Specifiers {
+		if _, ok := spec.(*ImportSpecifier); ok {
+			named = append(named, spec.String())
+		} else {
+			clauses = append(clauses, spec.String())
+		}
+	}
+	if len(named) > 0 {
+		clauses = append(clauses, "{ "+strings.Join(named, ", ")+" }")
+	}
+	if len(clauses) > 0 {
+		buf.WriteString(strings.Join(clauses, ", "))
+		buf.WriteString(" from ")
+	}
+
+	buf.WriteString(moduleNameString(d.Source))
+	buf.WriteString(attributesString(d.Attributes))
+	buf.WriteString(";")
+	return buf.String()
+}
+
+type ImportSpecifier struct {
+	// Imported is an *Identifier or a string *Literal.
+	Imported Expression
+	Local    *Identifier
+}
+
+func (s *ImportSpecifier) String() string {
+	imported := moduleNameString(s.Imported)
+	if imported == s.Local.String() {
+		return imported
+	}
+	return imported + " as " + s.Local.String()
+}
+
+type ImportDefaultSpecifier struct {
+	Local *Identifier
+}
+
+func (s *ImportDefaultSpecifier) String() string {
+	return s.Local.String()
+}
+
+type ImportNamespaceSpecifier struct {
+	Token token.Token
+	Local *Identifier
+}
+
+func (s *ImportNamespaceSpecifier) String() string {
+	return "* as " + s.Local.String()
+}
+
+type ImportAttribute struct {
+	// Key is an *Identifier or a string *Literal.
+	Key   Expression
+	Value *Literal
+}
+
+func (a *ImportAttribute) String() string {
+	return moduleNameString(a.Key) + ": " + moduleNameString(a.Value)
+}
+
+type ExportNamedDeclaration struct {
+	Token    token.Token
+	EndToken token.Token
+	// Declaration is the exported declaration, or nil for an export list.
+	Declaration Statement
+	Specifiers  []*ExportSpecifier
+	Source      *Literal
+	Attributes  []*ImportAttribute
+}
+
+func (d *ExportNamedDeclaration) String() string {
+	if d.Declaration != nil {
+		return "export " + d.Declaration.String()
+	}
+
+	buf := new(bytes.Buffer)
+	buf.WriteString("export {")
+	specs := make([]string, 0, len(d.Specifiers))
+	for _, spec := range d.Specifiers {
+		specs = append(specs, spec.String())
+	}
+	if len(specs) > 0 {
+		buf.WriteString(" " + strings.Join(specs, ", ") + " ")
+	}
+	buf.WriteString("}")
+
+	if d.Source != nil {
+		buf.WriteString(" from ")
+		buf.WriteString(moduleNameString(d.Source))
+		buf.WriteString(attributesString(d.Attributes))
+	}
+	buf.WriteString(";")
+	return buf.String()
+}
+
+type ExportSpecifier struct {
+	// Local and Exported are *Identifier or string *Literal nodes.
+	Local    Expression
+	Exported Expression
+}
+
+func (s *ExportSpecifier) String() string {
+	local := moduleNameString(s.Local)
+	exported := moduleNameString(s.Exported)
+	if local == exported {
+		return local
+	}
+	return local + " as " + exported
+}
+
+type ExportDefaultDeclaration struct {
+	Token    token.Token
+	EndToken token.Token
+	// Declaration is a *FunctionDeclaration, whose name may be nil, or an
+	// Expression.
+	Declaration Node
+}
+
+func (d *ExportDefaultDeclaration) String() string {
+	if _, ok := d.Declaration.(*FunctionDeclaration); ok {
+		return "export default " + d.Declaration.String()
+	}
+	return "export default " + d.Declaration.String() + ";"
+}
+
+type ExportAllDeclaration struct {
+	Token    token.Token
+	EndToken token.Token
+	// Exported is the name of the namespace for export * as name, or nil.
+	Exported   Expression
+	Source     *Literal
+	Attributes []*ImportAttribute
+}
+
+func (d *ExportAllDeclaration) String() string {
+	buf := new(bytes.Buffer)
+	buf.WriteString("export *")
+	if d.Exported != nil {
+		buf.WriteString(" as ")
+		buf.WriteString(moduleNameString(d.Exported))
+	}
+	buf.WriteString(" from ")
+	buf.WriteString(moduleNameString(d.Source))
+	buf.WriteString(attributesString(d.Attributes))
+	buf.WriteString(";")
+	return buf.String()
+}
+
+type ImportExpression struct {
+	Token   token.Token
+	RParen  token.Token
+	Source  Expression
+	Options Expression
+}
+
+func (e *ImportExpression) String() string {
+	if e.Options != nil {
+		return "import(" + e.Source.String() + ", " + e.Options.String() + ")"
+	}
+	return "import(" + e.Source.String() + ")"
+}
+
+// MetaProperty is a meta property like import.meta.
+type MetaProperty struct {
+	Meta     *Identifier
+	Property *Identifier
+}
+
+func (e *MetaProperty) String() string {
+	return e.Meta.String() + "." + e.Property.String()
+}
+
+func moduleNameString(name Expression) string {
+	if lit, ok := name.(*Literal); ok && lit.Kind == LitString {
+		return strconv.Quote(lit.Value)
+	}
+	return name.String()
+}
+
+func attributesString(attrs []*ImportAttribute) string {
+	if len(attrs) == 0 {
+		return ""
+	}
+
+	list := make([]string, 0, len(attrs))
+	for _, attr := range attrs {
+		list = append(list, attr.String())
+	}
+	return " with { " + strings.Join(list, ", ") + " }"
+}
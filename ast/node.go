@@ -1,5 +1,11 @@
 package ast
 
+import "github.com/ghosind/gjs/token"
+
 type Node interface {
+	// Pos returns the position of the first character of the node.
+	Pos() token.Position
+	// End returns the position immediately after the node.
+	End() token.Position
 	String() string
 }
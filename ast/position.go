@@ -0,0 +1,357 @@
+package ast
+
+import "github.com/ghosind/gjs/token"
+
+// The Pos and End methods of the nodes return the position of the first
+// character of a node and the position immediately after it. The positions
+// are invalid for nodes that are not created by the parser.
+
+// tokenEnd returns the end of tok, or the end of node if tok is not set.
+func tokenEnd(tok *token.Token, node Node) token.Position {
+	if tok.Pos().IsValid() || node == nil {
+		return tok.End()
+	}
+	return node.End()
+}
+
+// listEnd returns the end of the last node of a list, or the end of tok if the
+// list is empty.
+func listEnd[T Node](list []T, tok *token.Token) token.Position {
+	if len(list) > 0 {
+		return list[len(list)-1].End()
+	}
+	return tok.End()
+}
+
+func (p *Program) Pos() token.Position {
+	return token.Position{Offset: 0, Line: 1, Col: 1}
+}
+
+func (p *Program) End() token.Position {
+	return tokenEnd(&p.EndToken, nil)
+}
+
+// Expressions
+
+func (i *Identifier) Pos() token.Position { return i.Token.Pos() }
+func (i *Identifier) End() token.Position { return i.Token.End() }
+
+func (l *Literal) Pos() token.Position { return l.Token.Pos() }
+func (l *Literal) End() token.Position { return l.Token.End() }
+
+func (e *Elision) Pos() token.Position { return e.Token.Pos() }
+func (e *Elision) End() token.Position { return e.Token.Pos() }
+
+func (s *SpreadElement) Pos() token.Position { return s.Token.Pos() }
+func (s *SpreadElement) End() token.Position { return s.Value.End() }
+
+func (a *ArrayLiteral) Pos() token.Position { return a.LBracket.Pos() }
+func (a *ArrayLiteral) End() token.Position { return a.RBracket.End() }
+
+func (u *UnaryExpression) Pos() token.Position { return u.Operator.Pos() }
+func (u *UnaryExpression) End() token.Position { return u.Value.End() }
+
+func (u *UpdateExpression) Pos() token.Position {
+	if u.Prefix {
+		return u.Operator.Pos()
+	}
+	return u.Argument.Pos()
+}
+
+func (u *UpdateExpression) End() token.Position {
+	if u.Prefix {
+		return u.Argument.End()
+	}
+	return u.Operator.End()
+}
+
+func (b *BinaryExpression) Pos() token.Position { return b.Left.Pos() }
+func (b *BinaryExpression) End() token.Position { return b.Right.End() }
+
+func (l *LogicalExpression) Pos() token.Position { return l.Left.Pos() }
+func (l *LogicalExpression) End() token.Position { return l.Right.End() }
+
+func (t *TernaryExpression) Pos() token.Position { return t.Condition.Pos() }
+func (t *TernaryExpression) End() token.Position { return t.FalseBranch.End() }
+
+func (s *SequenceExpression) Pos() token.Position { return s.Expressions[0].Pos() }
+func (s *SequenceExpression) End() token.Position { return listEnd(s.Expressions, nil) }
+
+func (t *ThisExpression) Pos() token.Position { return t.Token.Pos() }
+func (t *ThisExpression) End() token.Position { return t.Token.End() }
+
+func (p *Property) Pos() token.Position {
+	if p.Token.Pos().IsValid() {
+		return p.Token.Pos()
+	}
+	return p.Key.Pos()
+}
+
+func (p *Property) End() token.Position { return p.Value.End() }
+
+func (o *ObjectLiteral) Pos() token.Position { return o.LBrace.Pos() }
+func (o *ObjectLiteral) End() token.Position { return o.RBrace.End() }
+
+func (f *FunctionExpression) Pos() token.Position { return f.Token.Pos() }
+func (f *FunctionExpression) End() token.Position { return f.Body.End() }
+
+func (a *ArrowFunctionExpression) Pos() token.Position { return a.Token.Pos() }
+func (a *ArrowFunctionExpression) End() token.Position { return a.Body.End() }
+
+func (a *AssignmentPattern) Pos() token.Position { return a.Left.Pos() }
+func (a *AssignmentPattern) End() token.Position { return a.Right.End() }
+
+func (r *RestElement) Pos() token.Position { return r.Token.Pos() }
+func (r *RestElement) End() token.Position { return r.Argument.End() }
+
+func (m *MemberExpression) Pos() token.Position { return m.Object.Pos() }
+
+func (m *MemberExpression) End() token.Position {
+	if m.Computed {
+		return tokenEnd(&m.RBracket, m.Property)
+	}
+	return m.Property.End()
+}
+
+func (c *CallExpression) Pos() token.Position { return c.Callee.Pos() }
+func (c *CallExpression) End() token.Position { return c.RParen.End() }
+
+func (n *NewExpression) Pos() token.Position { return n.Token.Pos() }
+
+func (n *NewExpression) End() token.Position {
+	if n.RParen != nil {
+		return n.RParen.End()
+	}
+	return n.Callee.End()
+}
+
+func (a *AwaitExpression) Pos() token.Position { return a.Token.Pos() }
+func (a *AwaitExpression) End() token.Position { return a.Argument.End() }
+
+func (y *YieldExpression) Pos() token.Position { return y.Token.Pos() }
+
+func (y *YieldExpression) End() token.Position {
+	if y.Argument != nil {
+		return y.Argument.End()
+	}
+	return y.Token.End()
+}
+
+func (a *AssignmentExpression) Pos() token.Position { return a.Left.Pos() }
+func (a *AssignmentExpression) End() token.Position { return a.Right.End() }
+
+func (a *ArrayPattern) Pos() token.Position { return a.LBracket.Pos() }
+func (a *ArrayPattern) End() token.Position { return a.RBracket.End() }
+
+func (o *ObjectPattern) Pos() token.Position { return o.LBrace.Pos() }
+func (o *ObjectPattern) End() token.Position { return o.RBrace.End() }
+
+func (e *BadExpression) Pos() token.Position { return e.From.Pos() }
+func (e *BadExpression) End() token.Position { return e.To.End() }
+
+func (e *ImportExpression) Pos() token.Position { return e.Token.Pos() }
+func (e *ImportExpression) End() token.Position { return e.RParen.End() }
+
+func (e *MetaProperty) Pos() token.Position { return e.Meta.Pos() }
+func (e *MetaProperty) End() token.Position { return e.Property.End() }
+
+// Declarations
+
+func (d *VariableDeclaration) Pos() token.Position { return d.Name.Pos() }
+
+func (d *VariableDeclaration) End() token.Position {
+	if d.Value != nil {
+		return d.Value.End()
+	}
+	return d.Name.End()
+}
+
+func (d *FunctionDeclaration) Pos() token.Position { return d.Token.Pos() }
+func (d *FunctionDeclaration) End() token.Position { return d.Body.End() }
+
+// Statements
+
+func (s *BlockStatement) Pos() token.Position { return s.LBrace.Pos() }
+func (s *BlockStatement) End() token.Position { return s.RBrace.End() }
+
+func (s *VarStatement) Pos() token.Position { return s.Token.Pos() }
+
+func (s *VarStatement) End() token.Position {
+	return tokenEnd(&s.EndToken, s.Declarations[len(s.Declarations)-1])
+}
+
+func (s *LexicalDeclaration) Pos() token.Position { return s.Token.Pos() }
+
+func (s *LexicalDeclaration) End() token.Position {
+	return tokenEnd(&s.EndToken, s.Declarations[len(s.Declarations)-1])
+}
+
+func (s *EmptyStatement) Pos() token.Position { return s.Token.Pos() }
+func (s *EmptyStatement) End() token.Position { return s.Token.End() }
+
+func (s *ExpressionStatement) Pos() token.Position {
+	if s.Token.Pos().IsValid() {
+		return s.Token.Pos()
+	}
+	return s.Expression.Pos()
+}
+
+func (s *ExpressionStatement) End() token.Position {
+	return tokenEnd(&s.EndToken, s.Expression)
+}
+
+func (s *IfStatement) Pos() token.Position { return s.Token.Pos() }
+
+func (s *IfStatement) End() token.Position {
+	if s.FalseBranch != nil {
+		return s.FalseBranch.End()
+	}
+	return s.TrueBranch.End()
+}
+
+func (s *ForStatement) Pos() token.Position { return s.Token.Pos() }
+func (s *ForStatement) End() token.Position { return s.Body.End() }
+
+func (s *ForInStatement) Pos() token.Position { return s.Token.Pos() }
+func (s *ForInStatement) End() token.Position { return s.Body.End() }
+
+func (s *ForOfStatement) Pos() token.Position { return s.Token.Pos() }
+func (s *ForOfStatement) End() token.Position { return s.Body.End() }
+
+func (s *WhileStatement) Pos() token.Position { return s.Token.Pos() }
+func (s *WhileStatement) End() token.Position { return s.Body.End() }
+
+func (s *WithStatement) Pos() token.Position { return s.Token.Pos() }
+func (s *WithStatement) End() token.Position { return s.Body.End() }
+
+func (s *DoWhileStatement) Pos() token.Position { return s.Token.Pos() }
+func (s *DoWhileStatement) End() token.Position { return tokenEnd(&s.EndToken, s.Condition) }
+
+func (s *ContinueStatement) Pos() token.Position { return s.Token.Pos() }
+func (s *ContinueStatement) End() token.Position { return tokenEnd(&s.EndToken, s.Label) }
+
+func (s *BreakStatement) Pos() token.Position { return s.Token.Pos() }
+func (s *BreakStatement) End() token.Position { return tokenEnd(&s.EndToken, s.Label) }
+
+func (s *ReturnStatement) Pos() token.Position { return s.Token.Pos() }
+func (s *ReturnStatement) End() token.Position { return tokenEnd(&s.EndToken, s.Result) }
+
+func (s *SwitchStatement) Pos() token.Position { return s.Token.Pos() }
+func (s *SwitchStatement) End() token.Position { return s.RBrace.End() }
+
+func (c *SwitchCase) Pos() token.Position { return c.Token.Pos() }
+func (c *SwitchCase) End() token.Position { return listEnd(c.Consequent, &c.Colon) }
+
+func (s *LabeledStatement) Pos() token.Position { return s.Label.Pos() }
+func (s *LabeledStatement) End() token.Position { return s.Statement.End() }
+
+func (s *ThrowStatement) Pos() token.Position { return s.Token.Pos() }
+func (s *ThrowStatement) End() token.Position { return tokenEnd(&s.EndToken, s.Argument) }
+
+func (s *TryStatement) Pos() token.Position { return s.Token.Pos() }
+
+func (s *TryStatement) End() token.Position {
+	if s.Finally != nil {
+		return s.Finally.End()
+	} else if s.CatchClause != nil {
+		return s.CatchClause.End()
+	}
+	return s.Block.End()
+}
+
+func (c *CatchClause) Pos() token.Position { return c.Token.Pos() }
+func (c *CatchClause) End() token.Position { return c.Body.End() }
+
+func (s *DebuggerStatement) Pos() token.Position { return s.Token.Pos() }
+func (s *DebuggerStatement) End() token.Position { return tokenEnd(&s.EndToken, nil) }
+
+func (s *BadStatement) Pos() token.Position { return s.From.Pos() }
+func (s *BadStatement) End() token.Position { return s.To.End() }
+
+// Modules
+
+func (d *ImportDeclaration) Pos() token.Position { return d.Token.Pos() }
+func (d *ImportDeclaration) End() token.Position { return tokenEnd(&d.EndToken, d.Source) }
+
+func (s *ImportSpecifier) Pos() token.Position { return s.Imported.Pos() }
+func (s *ImportSpecifier) End() token.Position { return s.Local.End() }
+
+func (s *ImportDefaultSpecifier) Pos() token.Position { return s.Local.Pos() }
+func (s *ImportDefaultSpecifier) End() token.Position { return s.Local.End() }
+
+func (s *ImportNamespaceSpecifier) Pos() token.Position { return s.Token.Pos() }
+func (s *ImportNamespaceSpecifier) End() token.Position { return s.Local.End() }
+
+func (a *ImportAttribute) Pos() token.Position { return a.Key.Pos() }
+func (a *ImportAttribute) End() token.Position { return a.Value.End() }
+
+func (d *ExportNamedDeclaration) Pos() token.Position { return d.Token.Pos() }
+func (d *ExportNamedDeclaration) End() token.Position { return tokenEnd(&d.EndToken, d.Declaration) }
+
+func (s *ExportSpecifier) Pos() token.Position { return s.Local.Pos() }
+func (s *ExportSpecifier) End() token.Position { return s.Exported.End() }
+
+func (d *ExportDefaultDeclaration) Pos() token.Position { return d.Token.Pos() }
+func (d *ExportDefaultDeclaration) End() token.Position { return tokenEnd(&d.EndToken, d.Declaration) }
+
+func (d *ExportAllDeclaration) Pos() token.Position { return d.Token.Pos() }
+func (d *ExportAllDeclaration) End() token.Position { return tokenEnd(&d.EndToken, d.Source) }
+
+// JSX
+
+func (e *JSXElement) Pos() token.Position { return e.OpeningElement.Pos() }
+
+func (e *JSXElement) End() token.Position {
+	if e.ClosingElement != nil {
+		return e.ClosingElement.End()
+	}
+	return e.OpeningElement.End()
+}
+
+func (e *JSXOpeningElement) Pos() token.Position { return e.LessThan.Pos() }
+func (e *JSXOpeningElement) End() token.Position { return e.GreaterThan.End() }
+
+func (e *JSXClosingElement) Pos() token.Position { return e.LessThan.Pos() }
+func (e *JSXClosingElement) End() token.Position { return e.GreaterThan.End() }
+
+func (f *JSXFragment) Pos() token.Position { return f.OpeningFragment.Pos() }
+func (f *JSXFragment) End() token.Position { return f.ClosingFragment.End() }
+
+func (f *JSXOpeningFragment) Pos() token.Position { return f.LessThan.Pos() }
+func (f *JSXOpeningFragment) End() token.Position { return f.GreaterThan.End() }
+
+func (f *JSXClosingFragment) Pos() token.Position { return f.LessThan.Pos() }
+func (f *JSXClosingFragment) End() token.Position { return f.GreaterThan.End() }
+
+func (i *JSXIdentifier) Pos() token.Position { return i.Token.Pos() }
+func (i *JSXIdentifier) End() token.Position { return tokenEnd(&i.EndToken, nil) }
+
+func (n *JSXNamespacedName) Pos() token.Position { return n.Namespace.Pos() }
+func (n *JSXNamespacedName) End() token.Position { return n.Name.End() }
+
+func (e *JSXMemberExpression) Pos() token.Position { return e.Object.Pos() }
+func (e *JSXMemberExpression) End() token.Position { return e.Property.End() }
+
+func (a *JSXAttribute) Pos() token.Position { return a.Name.Pos() }
+
+func (a *JSXAttribute) End() token.Position {
+	if a.Value != nil {
+		return a.Value.End()
+	}
+	return a.Name.End()
+}
+
+func (a *JSXSpreadAttribute) Pos() token.Position { return a.LBrace.Pos() }
+func (a *JSXSpreadAttribute) End() token.Position { return a.RBrace.End() }
+
+func (c *JSXExpressionContainer) Pos() token.Position { return c.LBrace.Pos() }
+func (c *JSXExpressionContainer) End() token.Position { return c.RBrace.End() }
+
+func (e *JSXEmptyExpression) Pos() token.Position { return e.RBrace.Pos() }
+func (e *JSXEmptyExpression) End() token.Position { return e.RBrace.Pos() }
+
+func (c *JSXSpreadChild) Pos() token.Position { return c.LBrace.Pos() }
+func (c *JSXSpreadChild) End() token.Position { return c.RBrace.End() }
+
+func (t *JSXText) Pos() token.Position { return t.Token.Pos() }
+func (t *JSXText) End() token.Position { return t.Token.End() }
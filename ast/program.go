@@ -1,9 +1,35 @@
 package ast
 
-import "bytes"
+import (
+	"bytes"
+
+	"github.com/ghosind/gjs/token"
+)
+
+type SourceType int
+
+const (
+	SourceScript SourceType = iota
+	SourceModule
+)
 
 type Program struct {
+	// EndToken is the end of input token of the source.
+	EndToken token.Token
+	// HashBang is the hashbang comment at the start of the source, or nil if
+	// there is none. Its literal is the interpreter line after the #!.
+	HashBang   *token.Token
 	Statements []Statement
+	SourceType SourceType
+	Strict     bool
+	// Directives are the directives of the directive prologue, such as "use
+	// strict", as written between their quotes.
+	Directives []string
+	// FileName is the name of the source file, if it is known.
+	FileName string
+	// Comments are the comments of the source in order, if the parser was
+	// configured to keep them. The hashbang is not one of them.
+	Comments []token.Token
 }
 
 func (p *Program) String() string {
@@ -1,12 +1,19 @@
 package ast
 
-import "bytes"
+import (
+	"bytes"
+	"strings"
+
+	"github.com/ghosind/gjs/token"
+)
 
 type Statement interface {
 	Node
 }
 
 type BlockStatement struct {
+	LBrace        token.Token
+	RBrace        token.Token
 	StatementList []Statement
 }
 
@@ -22,6 +29,10 @@ func (s *BlockStatement) String() string {
 }
 
 type VarStatement struct {
+	Token token.Token
+	// EndToken is the last token of the statement, which is the semicolon
+	// unless it is inserted automatically.
+	EndToken     token.Token
 	Declarations []Declaration
 }
 
@@ -38,13 +49,43 @@ func (s *VarStatement) String() string {
 	return buf.String()
 }
 
-type EmptyStatement struct{}
+type LexicalDeclaration struct {
+	Token        token.Token
+	EndToken     token.Token
+	Const        bool
+	Declarations []Declaration
+}
+
+func (s *LexicalDeclaration) String() string {
+	buf := new(bytes.Buffer)
+	if s.Const {
+		buf.WriteString("const ")
+	} else {
+		buf.WriteString("let ")
+	}
+	for i, decl := range s.Declarations {
+		buf.WriteString(decl.String())
+		if i < len(s.Declarations)-1 {
+			buf.WriteString(", ")
+		}
+	}
+	buf.WriteString(";")
+	return buf.String()
+}
+
+type EmptyStatement struct {
+	Token token.Token
+}
 
 func (s *EmptyStatement) String() string {
 	return ";"
 }
 
 type ExpressionStatement struct {
+	// Token is the first token of the statement, which may be a parenthesis
+	// around the expression.
+	Token      token.Token
+	EndToken   token.Token
 	Expression Expression
 }
 
@@ -53,6 +94,7 @@ func (s *ExpressionStatement) String() string {
 }
 
 type IfStatement struct {
+	Token       token.Token
 	Condition   Expression
 	TrueBranch  Statement
 	FalseBranch Statement
@@ -73,6 +115,7 @@ func (s *IfStatement) String() string {
 }
 
 type ForStatement struct {
+	Token     token.Token
 	Init      Statement
 	Condition Expression
 	Update    Expression
@@ -100,7 +143,44 @@ func (s *ForStatement) String() string {
 	return buf.String()
 }
 
+type ForInStatement struct {
+	Token token.Token
+	// Left is a *VarStatement or *LexicalDeclaration with a single binding, or
+	// an assignment target expression.
+	Left  Node
+	Right Expression
+	Body  Statement
+}
+
+func (s *ForInStatement) String() string {
+	return "for (" + forHeadString(s.Left) + " in " + s.Right.String() + ") " + s.Body.String()
+}
+
+type ForOfStatement struct {
+	Token token.Token
+	Left  Node
+	Right Expression
+	Body  Statement
+	Await bool
+}
+
+func (s *ForOfStatement) String() string {
+	buf := new(bytes.Buffer)
+	buf.WriteString("for ")
+	if s.Await {
+		buf.WriteString("await ")
+	}
+	buf.WriteString("(" + forHeadString(s.Left) + " of " + s.Right.String() + ") ")
+	buf.WriteString(s.Body.String())
+	return buf.String()
+}
+
+func forHeadString(left Node) string {
+	return strings.TrimSuffix(left.String(), ";")
+}
+
 type WhileStatement struct {
+	Token     token.Token
 	Condition Expression
 	Body      Statement
 }
@@ -114,7 +194,19 @@ func (s *WhileStatement) String() string {
 	return buf.String()
 }
 
+type WithStatement struct {
+	Token  token.Token
+	Object Expression
+	Body   Statement
+}
+
+func (s *WithStatement) String() string {
+	return "with (" + s.Object.String() + ") " + s.Body.String()
+}
+
 type DoWhileStatement struct {
+	Token     token.Token
+	EndToken  token.Token
 	Body      Statement
 	Condition Expression
 }
@@ -130,7 +222,9 @@ func (s *DoWhileStatement) String() string {
 }
 
 type ContinueStatement struct {
-	Label Expression
+	Token    token.Token
+	EndToken token.Token
+	Label    Expression
 }
 
 func (s *ContinueStatement) String() string {
@@ -141,7 +235,9 @@ func (s *ContinueStatement) String() string {
 }
 
 type BreakStatement struct {
-	Label Expression
+	Token    token.Token
+	EndToken token.Token
+	Label    Expression
 }
 
 func (s *BreakStatement) String() string {
@@ -152,7 +248,9 @@ func (s *BreakStatement) String() string {
 }
 
 type ReturnStatement struct {
-	Result Expression
+	Token    token.Token
+	EndToken token.Token
+	Result   Expression
 }
 
 func (s *ReturnStatement) String() string {
@@ -163,6 +261,8 @@ func (s *ReturnStatement) String() string {
 }
 
 type SwitchStatement struct {
+	Token        token.Token
+	RBrace       token.Token
 	Discriminant Expression
 	Cases        []SwitchCase
 	DefaultCase  *SwitchCase
@@ -187,6 +287,9 @@ func (s *SwitchStatement) String() string {
 }
 
 type SwitchCase struct {
+	// Token is the case or default keyword.
+	Token      token.Token
+	Colon      token.Token
 	Test       Expression
 	Consequent []Statement
 }
@@ -210,6 +313,8 @@ func (s *LabeledStatement) String() string {
 }
 
 type ThrowStatement struct {
+	Token    token.Token
+	EndToken token.Token
 	Argument Expression
 }
 
@@ -218,6 +323,7 @@ func (s *ThrowStatement) String() string {
 }
 
 type TryStatement struct {
+	Token       token.Token
 	Block       *BlockStatement
 	CatchClause *CatchClause
 	Finally     *BlockStatement
@@ -238,6 +344,7 @@ func (s *TryStatement) String() string {
 }
 
 type CatchClause struct {
+	Token token.Token
 	Param *Identifier
 	Body  *BlockStatement
 }
@@ -250,8 +357,22 @@ func (c *CatchClause) String() string {
 }
 
 type DebuggerStatement struct {
+	Token    token.Token
+	EndToken token.Token
 }
 
 func (s *DebuggerStatement) String() string {
 	return "debugger;"
 }
+
+// BadStatement is a placeholder for a statement that contains syntax errors,
+// created by the parser in tolerant mode. From and To are the first and the
+// last tokens of the skipped source.
+type BadStatement struct {
+	From token.Token
+	To   token.Token
+}
+
+func (s *BadStatement) String() string {
+	return "<bad statement>"
+}
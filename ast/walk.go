@@ -0,0 +1,373 @@
+package ast
+
+import "fmt"
+
+// A Visitor's Visit method is invoked for each node encountered by Walk. If
+// the result visitor w is not nil, Walk visits each of the children of node
+// with the visitor w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+func walkList[N Node](v Visitor, list []N) {
+	for _, node := range list {
+		Walk(v, node)
+	}
+}
+
+// Walk traverses an AST in depth-first order: It starts by calling
+// v.Visit(node); node must not be nil. If the visitor w returned by
+// v.Visit(node) is not nil, Walk is invoked recursively with visitor w for
+// each of the non-nil children of node, in the order they appear in the
+// source, followed by a call of w.Visit(nil).
+//
+// The holes of arrays are visited as *Elision nodes, and the default case of
+// a switch statement is visited after the other cases.
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Program:
+		walkList(v, n.Statements)
+
+	// Expressions
+	case *Identifier, *Literal, *Elision, *ThisExpression, *BadExpression:
+		// nothing to do
+
+	case *SpreadElement:
+		Walk(v, n.Value)
+
+	case *ArrayLiteral:
+		walkList(v, n.ElementList)
+
+	case *UnaryExpression:
+		Walk(v, n.Value)
+
+	case *BinaryExpression:
+		Walk(v, n.Left)
+		Walk(v, n.Right)
+
+	case *LogicalExpression:
+		Walk(v, n.Left)
+		Walk(v, n.Right)
+
+	case *UpdateExpression:
+		Walk(v, n.Argument)
+
+	case *TernaryExpression:
+		Walk(v, n.Condition)
+		Walk(v, n.TrueBranch)
+		Walk(v, n.FalseBranch)
+
+	case *SequenceExpression:
+		walkList(v, n.Expressions)
+
+	case *Property:
+		if n.Key != nil {
+			Walk(v, n.Key)
+		}
+		Walk(v, n.Value)
+
+	case *ObjectLiteral:
+		walkList(v, n.Properties)
+
+	case *FunctionExpression:
+		if n.Name != nil {
+			Walk(v, n.Name)
+		}
+		walkList(v, n.Params)
+		if n.Body != nil {
+			Walk(v, n.Body)
+		}
+
+	case *ArrowFunctionExpression:
+		walkList(v, n.Params)
+		Walk(v, n.Body)
+
+	case *AssignmentPattern:
+		Walk(v, n.Left)
+		Walk(v, n.Right)
+
+	case *RestElement:
+		Walk(v, n.Argument)
+
+	case *MemberExpression:
+		Walk(v, n.Object)
+		Walk(v, n.Property)
+
+	case *CallExpression:
+		Walk(v, n.Callee)
+		walkList(v, n.Arguments)
+
+	case *NewExpression:
+		Walk(v, n.Callee)
+		walkList(v, n.Arguments)
+
+	case *AwaitExpression:
+		Walk(v, n.Argument)
+
+	case *YieldExpression:
+		if n.Argument != nil {
+			Walk(v, n.Argument)
+		}
+
+	case *AssignmentExpression:
+		Walk(v, n.Left)
+		Walk(v, n.Right)
+
+	case *ArrayPattern:
+		walkList(v, n.Elements)
+
+	case *ObjectPattern:
+		walkList(v, n.Properties)
+
+	case *ImportExpression:
+		Walk(v, n.Source)
+		if n.Options != nil {
+			Walk(v, n.Options)
+		}
+
+	case *MetaProperty:
+		Walk(v, n.Meta)
+		Walk(v, n.Property)
+
+	// Declarations
+	case *VariableDeclaration:
+		Walk(v, n.Name)
+		if n.Value != nil {
+			Walk(v, n.Value)
+		}
+
+	case *FunctionDeclaration:
+		if n.Name != nil {
+			Walk(v, n.Name)
+		}
+		walkList(v, n.Params)
+		if n.Body != nil {
+			Walk(v, n.Body)
+		}
+
+	// Statements
+	case *EmptyStatement, *DebuggerStatement, *BadStatement:
+		// nothing to do
+
+	case *BlockStatement:
+		walkList(v, n.StatementList)
+
+	case *VarStatement:
+		walkList(v, n.Declarations)
+
+	case *LexicalDeclaration:
+		walkList(v, n.Declarations)
+
+	case *ExpressionStatement:
+		Walk(v, n.Expression)
+
+	case *IfStatement:
+		Walk(v, n.Condition)
+		Walk(v, n.TrueBranch)
+		if n.FalseBranch != nil {
+			Walk(v, n.FalseBranch)
+		}
+
+	case *ForStatement:
+		if n.Init != nil {
+			Walk(v, n.Init)
+		}
+		if n.Condition != nil {
+			Walk(v, n.Condition)
+		}
+		if n.Update != nil {
+			Walk(v, n.Update)
+		}
+		Walk(v, n.Body)
+
+	case *ForInStatement:
+		Walk(v, n.Left)
+		Walk(v, n.Right)
+		Walk(v, n.Body)
+
+	case *ForOfStatement:
+		Walk(v, n.Left)
+		Walk(v, n.Right)
+		Walk(v, n.Body)
+
+	case *WhileStatement:
+		Walk(v, n.Condition)
+		Walk(v, n.Body)
+
+	case *WithStatement:
+		Walk(v, n.Object)
+		Walk(v, n.Body)
+
+	case *DoWhileStatement:
+		Walk(v, n.Body)
+		Walk(v, n.Condition)
+
+	case *ContinueStatement:
+		if n.Label != nil {
+			Walk(v, n.Label)
+		}
+
+	case *BreakStatement:
+		if n.Label != nil {
+			Walk(v, n.Label)
+		}
+
+	case *ReturnStatement:
+		if n.Result != nil {
+			Walk(v, n.Result)
+		}
+
+	case *SwitchStatement:
+		Walk(v, n.Discriminant)
+		for i := range n.Cases {
+			Walk(v, &n.Cases[i])
+		}
+		if n.DefaultCase != nil {
+			Walk(v, n.DefaultCase)
+		}
+
+	case *SwitchCase:
+		if n.Test != nil {
+			Walk(v, n.Test)
+		}
+		walkList(v, n.Consequent)
+
+	case *LabeledStatement:
+		Walk(v, n.Label)
+		Walk(v, n.Statement)
+
+	case *ThrowStatement:
+		Walk(v, n.Argument)
+
+	case *TryStatement:
+		Walk(v, n.Block)
+		if n.CatchClause != nil {
+			Walk(v, n.CatchClause)
+		}
+		if n.Finally != nil {
+			Walk(v, n.Finally)
+		}
+
+	case *CatchClause:
+		if n.Param != nil {
+			Walk(v, n.Param)
+		}
+		Walk(v, n.Body)
+
+	// Modules
+	case *ImportDeclaration:
+		walkList(v, n.Specifiers)
+		Walk(v, n.Source)
+		walkList(v, n.Attributes)
+
+	case *ImportSpecifier:
+		Walk(v, n.Imported)
+		Walk(v, n.Local)
+
+	case *ImportDefaultSpecifier:
+		Walk(v, n.Local)
+
+	case *ImportNamespaceSpecifier:
+		Walk(v, n.Local)
+
+	case *ImportAttribute:
+		Walk(v, n.Key)
+		Walk(v, n.Value)
+
+	case *ExportNamedDeclaration:
+		if n.Declaration != nil {
+			Walk(v, n.Declaration)
+		}
+		walkList(v, n.Specifiers)
+		if n.Source != nil {
+			Walk(v, n.Source)
+		}
+		walkList(v, n.Attributes)
+
+	case *ExportSpecifier:
+		Walk(v, n.Local)
+		Walk(v, n.Exported)
+
+	case *ExportDefaultDeclaration:
+		Walk(v, n.Declaration)
+
+	case *ExportAllDeclaration:
+		if n.Exported != nil {
+			Walk(v, n.Exported)
+		}
+		Walk(v, n.Source)
+		walkList(v, n.Attributes)
+
+	// JSX
+	case *JSXOpeningFragment, *JSXClosingFragment, *JSXIdentifier, *JSXEmptyExpression, *JSXText:
+		// nothing to do
+
+	case *JSXElement:
+		Walk(v, n.OpeningElement)
+		walkList(v, n.Children)
+		if n.ClosingElement != nil {
+			Walk(v, n.ClosingElement)
+		}
+
+	case *JSXOpeningElement:
+		Walk(v, n.Name)
+		walkList(v, n.Attributes)
+
+	case *JSXClosingElement:
+		Walk(v, n.Name)
+
+	case *JSXFragment:
+		Walk(v, n.OpeningFragment)
+		walkList(v, n.Children)
+		Walk(v, n.ClosingFragment)
+
+	case *JSXNamespacedName:
+		Walk(v, n.Namespace)
+		Walk(v, n.Name)
+
+	case *JSXMemberExpression:
+		Walk(v, n.Object)
+		Walk(v, n.Property)
+
+	case *JSXAttribute:
+		Walk(v, n.Name)
+		if n.Value != nil {
+			Walk(v, n.Value)
+		}
+
+	case *JSXSpreadAttribute:
+		Walk(v, n.Argument)
+
+	case *JSXExpressionContainer:
+		Walk(v, n.Expression)
+
+	case *JSXSpreadChild:
+		Walk(v, n.Expression)
+
+	default:
+		panic(fmt.Sprintf("ast.Walk: unexpected node type %T", n))
+	}
+
+	v.Visit(nil)
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses an AST in depth-first order: It starts by calling
+// f(node); node must not be nil. If f returns true, Inspect invokes f
+// recursively for each of the non-nil children of node, followed by a call
+// of f(nil).
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
@@ -0,0 +1,279 @@
+package ast_test
+
+import (
+	"fmt"
+	goast "go/ast"
+	goparser "go/parser"
+	gotoken "go/token"
+	"io/fs"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/lexer"
+	"github.com/ghosind/gjs/parser"
+	"github.com/ghosind/go-assert"
+)
+
+// nodes holds a value of every node type of the ast package, it must be
+// updated with the new node types.
+var nodes = []ast.Node{
+	&ast.Program{},
+
+	// Expressions
+	&ast.Identifier{},
+	&ast.Literal{},
+	&ast.Elision{},
+	&ast.SpreadElement{},
+	&ast.ArrayLiteral{},
+	&ast.UnaryExpression{},
+	&ast.UpdateExpression{},
+	&ast.BinaryExpression{},
+	&ast.LogicalExpression{},
+	&ast.TernaryExpression{},
+	&ast.SequenceExpression{},
+	&ast.ThisExpression{},
+	&ast.Property{},
+	&ast.ObjectLiteral{},
+	&ast.FunctionExpression{},
+	&ast.ArrowFunctionExpression{},
+	&ast.AssignmentPattern{},
+	&ast.RestElement{},
+	&ast.MemberExpression{},
+	&ast.CallExpression{},
+	&ast.NewExpression{},
+	&ast.AwaitExpression{},
+	&ast.YieldExpression{},
+	&ast.AssignmentExpression{},
+	&ast.ArrayPattern{},
+	&ast.ObjectPattern{},
+	&ast.BadExpression{},
+	&ast.ImportExpression{},
+	&ast.MetaProperty{},
+
+	// Declarations
+	&ast.VariableDeclaration{},
+	&ast.FunctionDeclaration{},
+
+	// Statements
+	&ast.BlockStatement{},
+	&ast.VarStatement{},
+	&ast.LexicalDeclaration{},
+	&ast.EmptyStatement{},
+	&ast.ExpressionStatement{},
+	&ast.IfStatement{},
+	&ast.ForStatement{},
+	&ast.ForInStatement{},
+	&ast.ForOfStatement{},
+	&ast.WhileStatement{},
+	&ast.WithStatement{},
+	&ast.DoWhileStatement{},
+	&ast.ContinueStatement{},
+	&ast.BreakStatement{},
+	&ast.ReturnStatement{},
+	&ast.SwitchStatement{},
+	&ast.SwitchCase{},
+	&ast.LabeledStatement{},
+	&ast.ThrowStatement{},
+	&ast.TryStatement{},
+	&ast.CatchClause{},
+	&ast.DebuggerStatement{},
+	&ast.BadStatement{},
+
+	// Modules
+	&ast.ImportDeclaration{},
+	&ast.ImportSpecifier{},
+	&ast.ImportDefaultSpecifier{},
+	&ast.ImportNamespaceSpecifier{},
+	&ast.ImportAttribute{},
+	&ast.ExportNamedDeclaration{},
+	&ast.ExportSpecifier{},
+	&ast.ExportDefaultDeclaration{},
+	&ast.ExportAllDeclaration{},
+
+	// JSX
+	&ast.JSXElement{},
+	&ast.JSXOpeningElement{},
+	&ast.JSXClosingElement{},
+	&ast.JSXFragment{},
+	&ast.JSXOpeningFragment{},
+	&ast.JSXClosingFragment{},
+	&ast.JSXIdentifier{},
+	&ast.JSXNamespacedName{},
+	&ast.JSXMemberExpression{},
+	&ast.JSXAttribute{},
+	&ast.JSXSpreadAttribute{},
+	&ast.JSXExpressionContainer{},
+	&ast.JSXEmptyExpression{},
+	&ast.JSXSpreadChild{},
+	&ast.JSXText{},
+}
+
+func TestWalkNodeTypes(t *testing.T) {
+	a := assert.New(t)
+
+	// Every type of the package with a Pos method is a node.
+	pkgs, err := goparser.ParseDir(gotoken.NewFileSet(), ".", func(fi fs.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	a.NilNow(err)
+
+	declared := make([]string, 0)
+	for _, file := range pkgs["ast"].Files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*goast.FuncDecl)
+			if !ok || fn.Recv == nil || fn.Name.Name != "Pos" {
+				continue
+			}
+			if star, ok := fn.Recv.List[0].Type.(*goast.StarExpr); ok {
+				declared = append(declared, star.X.(*goast.Ident).Name)
+			}
+		}
+	}
+	sort.Strings(declared)
+
+	listed := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		listed = append(listed, reflect.TypeOf(node).Elem().Name())
+	}
+	sort.Strings(listed)
+
+	a.DeepEqualNow(listed, declared)
+}
+
+var (
+	nodeType = reflect.TypeOf((*ast.Node)(nil)).Elem()
+	astPath  = nodeType.PkgPath()
+)
+
+// fill sets every child field of the node value v, and returns the children
+// that have been set.
+func fill(v reflect.Value) []ast.Node {
+	children := make([]ast.Node, 0)
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if !field.CanSet() || v.Type().Field(i).Anonymous {
+			continue
+		}
+
+		switch typ := field.Type(); {
+		case isNodeInterface(typ):
+			child := &ast.Identifier{Value: fmt.Sprintf("%s.%s", v.Type().Name(), v.Type().Field(i).Name)}
+			field.Set(reflect.ValueOf(child))
+			children = append(children, child)
+		case isNodePointer(typ):
+			child := reflect.New(typ.Elem())
+			fill(child.Elem())
+			field.Set(child)
+			children = append(children, child.Interface().(ast.Node))
+		case typ.Kind() == reflect.Slice:
+			elem := typ.Elem()
+			switch {
+			case isNodeInterface(elem):
+				child := &ast.Identifier{Value: fmt.Sprintf("%s.%s[0]", v.Type().Name(), v.Type().Field(i).Name)}
+				list := reflect.MakeSlice(typ, 1, 1)
+				list.Index(0).Set(reflect.ValueOf(child))
+				field.Set(list)
+				children = append(children, child)
+			case isNodePointer(elem):
+				child := reflect.New(elem.Elem())
+				fill(child.Elem())
+				list := reflect.MakeSlice(typ, 1, 1)
+				list.Index(0).Set(child)
+				field.Set(list)
+				children = append(children, child.Interface().(ast.Node))
+			case isNodePointer(reflect.PointerTo(elem)):
+				field.Set(reflect.MakeSlice(typ, 1, 1))
+				child := field.Index(0)
+				fill(child)
+				children = append(children, child.Addr().Interface().(ast.Node))
+			}
+		}
+	}
+
+	return children
+}
+
+func isNodeInterface(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Interface && typ.NumMethod() > 0 && nodeType.Implements(typ)
+}
+
+func isNodePointer(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Pointer && typ.Elem().PkgPath() == astPath && typ.Implements(nodeType)
+}
+
+func TestWalkChildren(t *testing.T) {
+	a := assert.New(t)
+
+	for _, node := range nodes {
+		name := reflect.TypeOf(node).Elem().Name()
+		children := fill(reflect.ValueOf(node).Elem())
+
+		visited := make(map[ast.Node]bool)
+		depth := 0
+		ast.Inspect(node, func(n ast.Node) bool {
+			if n == nil {
+				depth--
+				return false
+			}
+			if depth == 1 {
+				visited[n] = true
+			}
+			depth++
+			return true
+		})
+		a.EqualNow(depth, 0, name)
+
+		for _, child := range children {
+			a.TrueNow(visited[child], fmt.Sprintf("%s: %T child is not visited", name, child))
+		}
+		a.EqualNow(len(visited), len(children), name)
+	}
+}
+
+type collector []string
+
+func (c *collector) Visit(node ast.Node) ast.Visitor {
+	if node == nil {
+		*c = append(*c, ")")
+		return nil
+	}
+	*c = append(*c, strings.TrimPrefix(fmt.Sprintf("%T", node), "*ast."))
+	return c
+}
+
+func TestWalk(t *testing.T) {
+	a := assert.New(t)
+
+	program, err := parser.New(lexer.New([]byte("if (a) { b = [1, , c]; } else f(...d);"))).ParseProgram()
+	a.NilNow(err)
+
+	var c collector
+	ast.Walk(&c, program)
+	a.EqualNow(strings.Join(c, " "), "Program IfStatement Identifier ) BlockStatement ExpressionStatement "+
+		"AssignmentExpression Identifier ) ArrayLiteral Literal ) Elision ) Identifier ) ) ) ) ) "+
+		"ExpressionStatement CallExpression Identifier ) SpreadElement Identifier ) ) ) ) ) )")
+}
+
+func TestInspect(t *testing.T) {
+	a := assert.New(t)
+
+	program, err := parser.New(lexer.New([]byte("var x = 1; function f(y) { return x + y; }"))).ParseProgram()
+	a.NilNow(err)
+
+	// The children of the functions are skipped.
+	names := make([]string, 0)
+	ast.Inspect(program, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.Identifier:
+			names = append(names, n.Value)
+		case *ast.FunctionDeclaration:
+			return false
+		}
+		return true
+	})
+	a.DeepEqualNow(names, []string{"x"})
+}
@@ -0,0 +1,499 @@
+package cfg
+
+import (
+	"slices"
+
+	"github.com/ghosind/gjs/ast"
+)
+
+type builder struct {
+	cfg *CFG
+	// current is the block the statements are added to.
+	current *Block
+	// targets are the statements that may be the targets of break and
+	// continue statements, from the outermost one.
+	targets []*target
+	// tries are the try statements being built, from the outermost one.
+	tries []*tryContext
+	// labels are the labels of the statement built next, if it is a loop.
+	labels []string
+}
+
+// target is a statement that a break or a continue statement jumps out of.
+type target struct {
+	labels []string
+	// breakTo is the block after the statement, and continueTo the block of
+	// the next iteration of a loop, or nil if the statement is not a loop.
+	breakTo    *Block
+	continueTo *Block
+	// depth is the number of try statements the statement is in.
+	depth int
+	// loop is set for loops and switch statements, the targets of break and
+	// continue statements without labels.
+	loop bool
+}
+
+// tryContext is a try statement whose try block or catch clause is being
+// built.
+type tryContext struct {
+	catch   *Block
+	finally *Block
+	// inCatch is set while the catch clause is being built.
+	inCatch bool
+	// exits are the jumps out of the statement that go through its finally
+	// block, and throws is set if an exception goes through it.
+	exits  []exit
+	throws bool
+}
+
+type exit struct {
+	to    *Block
+	depth int
+}
+
+func newBuilder() *builder {
+	b := &builder{cfg: new(CFG)}
+	b.cfg.Entry = b.newBlock("entry")
+	b.cfg.Exit = b.newBlock("exit")
+	b.cfg.Throw = b.newBlock("throw")
+	b.current = b.cfg.Entry
+	return b
+}
+
+func (b *builder) newBlock(comment string) *Block {
+	block := &Block{Comment: comment}
+	b.cfg.Blocks = append(b.cfg.Blocks, block)
+	return block
+}
+
+// add adds node to the current block.
+func (b *builder) add(node ast.Node) {
+	b.current.Nodes = append(b.current.Nodes, node)
+}
+
+func edge(from, to *Block, kind EdgeKind) {
+	from.Succs = append(from.Succs, Edge{Kind: kind, To: to})
+}
+
+// jump ends the current block with an edge to the block to, which is the next
+// current block.
+func (b *builder) jump(to *Block) {
+	edge(b.current, to, EdgeNormal)
+	b.current = to
+}
+
+// branch ends the current block with the edges taken by its condition.
+func (b *builder) branch(ifTrue, ifFalse *Block) {
+	edge(b.current, ifTrue, EdgeTrue)
+	edge(b.current, ifFalse, EdgeFalse)
+}
+
+// unreachable starts a block after a jump, which is not reachable unless it
+// is the target of another jump.
+func (b *builder) unreachable() {
+	b.current = b.newBlock("unreachable")
+}
+
+// jumpOut ends the current block with a jump out of the statements to the
+// block to, which is in depth try statements. The jump goes through the
+// finally blocks of the try statements it leaves.
+func (b *builder) jumpOut(to *Block, depth int) {
+	for i := len(b.tries) - 1; i >= depth; i-- {
+		if t := b.tries[i]; t.finally != nil {
+			t.exits = append(t.exits, exit{to: to, depth: depth})
+			to = t.finally
+			break
+		}
+	}
+	edge(b.current, to, EdgeNormal)
+	b.unreachable()
+}
+
+// throwTarget returns the block that an exception thrown in the current
+// block goes to.
+func (b *builder) throwTarget() *Block {
+	for i := len(b.tries) - 1; i >= 0; i-- {
+		t := b.tries[i]
+		if t.catch != nil && !t.inCatch {
+			return t.catch
+		}
+		if t.finally != nil {
+			t.throws = true
+			return t.finally
+		}
+	}
+	return b.cfg.Throw
+}
+
+func (b *builder) statements(list []ast.Statement) {
+	for _, stmt := range list {
+		b.statement(stmt)
+	}
+}
+
+func (b *builder) statement(node ast.Node) {
+	labels := b.labels
+	b.labels = nil
+
+	switch n := node.(type) {
+	case *ast.BlockStatement:
+		b.statements(n.StatementList)
+
+	case *ast.IfStatement:
+		b.add(n.Condition)
+		then := b.newBlock("if.then")
+		done := b.newBlock("if.done")
+		otherwise := done
+		if n.FalseBranch != nil {
+			otherwise = b.newBlock("if.else")
+		}
+		b.branch(then, otherwise)
+		b.current = then
+		b.statement(n.TrueBranch)
+		b.jump(done)
+		if n.FalseBranch != nil {
+			b.current = otherwise
+			b.statement(n.FalseBranch)
+			b.jump(done)
+		}
+
+	case *ast.WhileStatement:
+		loop := b.newBlock("while.loop")
+		body := b.newBlock("while.body")
+		done := b.newBlock("while.done")
+		b.jump(loop)
+		b.add(n.Condition)
+		b.condition(n.Condition, body, done)
+		b.loop(labels, n.Body, body, done, loop)
+		b.jump(loop)
+		b.current = done
+
+	case *ast.DoWhileStatement:
+		body := b.newBlock("do.body")
+		cond := b.newBlock("do.cond")
+		done := b.newBlock("do.done")
+		b.jump(body)
+		b.loop(labels, n.Body, body, done, cond)
+		b.jump(cond)
+		b.add(n.Condition)
+		b.condition(n.Condition, body, done)
+		b.current = done
+
+	case *ast.ForStatement:
+		if n.Init != nil {
+			b.add(n.Init)
+		}
+		loop := b.newBlock("for.loop")
+		body := b.newBlock("for.body")
+		post := b.newBlock("for.post")
+		done := b.newBlock("for.done")
+		b.jump(loop)
+		if n.Condition != nil {
+			b.add(n.Condition)
+			b.condition(n.Condition, body, done)
+		} else {
+			edge(b.current, body, EdgeNormal)
+		}
+		b.loop(labels, n.Body, body, done, post)
+		b.jump(post)
+		if n.Update != nil {
+			b.add(n.Update)
+		}
+		b.jump(loop)
+		b.current = done
+
+	case *ast.ForInStatement:
+		b.forEach(labels, n.Left, n.Right, n.Body, "for.in")
+
+	case *ast.ForOfStatement:
+		b.forEach(labels, n.Left, n.Right, n.Body, "for.of")
+
+	case *ast.LabeledStatement:
+		// The labels of nested labeled statements all label the innermost
+		// statement.
+		var stmt ast.Statement = n
+		for {
+			labeled, ok := stmt.(*ast.LabeledStatement)
+			if !ok {
+				break
+			}
+			labels = append(labels, labeled.Label.String())
+			stmt = labeled.Statement
+		}
+		switch stmt.(type) {
+		case *ast.WhileStatement, *ast.DoWhileStatement, *ast.ForStatement, *ast.ForInStatement,
+			*ast.ForOfStatement:
+			b.labels = labels
+			b.statement(stmt)
+			return
+		}
+		done := b.newBlock("label.done")
+		b.targets = append(b.targets, &target{labels: labels, breakTo: done, depth: len(b.tries)})
+		b.statement(stmt)
+		b.targets = b.targets[:len(b.targets)-1]
+		b.jump(done)
+
+	case *ast.BreakStatement:
+		b.add(n)
+		if t := b.target(n.Label, false); t != nil {
+			b.jumpOut(t.breakTo, t.depth)
+		} else {
+			b.unreachable()
+		}
+
+	case *ast.ContinueStatement:
+		b.add(n)
+		if t := b.target(n.Label, true); t != nil {
+			b.jumpOut(t.continueTo, t.depth)
+		} else {
+			b.unreachable()
+		}
+
+	case *ast.ReturnStatement:
+		b.add(n)
+		b.jumpOut(b.cfg.Exit, 0)
+
+	case *ast.ThrowStatement:
+		b.add(n)
+		edge(b.current, b.throwTarget(), EdgeException)
+		b.unreachable()
+
+	case *ast.SwitchStatement:
+		b.switchStatement(n)
+
+	case *ast.TryStatement:
+		b.tryStatement(n)
+
+	case *ast.WithStatement:
+		b.add(n.Object)
+		b.statement(n.Body)
+
+	case *ast.ExportNamedDeclaration:
+		if n.Declaration != nil {
+			b.add(n.Declaration)
+		} else {
+			b.add(n)
+		}
+
+	case *ast.ExportDefaultDeclaration:
+		b.add(n.Declaration)
+
+	default:
+		b.add(node)
+	}
+}
+
+// condition ends the current block with the edges taken by cond. A condition
+// that is always true does not exit the loop it tests.
+func (b *builder) condition(cond ast.Expression, ifTrue, ifFalse *Block) {
+	if lit, ok := cond.(*ast.Literal); ok && (lit.Kind == ast.LitBoolean && lit.Value == "true" ||
+		lit.Kind == ast.LitNumber && lit.Value != "0") {
+		edge(b.current, ifTrue, EdgeNormal)
+		return
+	}
+	b.branch(ifTrue, ifFalse)
+}
+
+// loop builds the body of a loop, from the block body. A break statement in
+// the body jumps to breakTo, and a continue statement to continueTo.
+func (b *builder) loop(labels []string, stmt ast.Statement, body, breakTo, continueTo *Block) {
+	b.targets = append(b.targets, &target{
+		labels:     labels,
+		breakTo:    breakTo,
+		continueTo: continueTo,
+		depth:      len(b.tries),
+		loop:       true,
+	})
+	b.current = body
+	b.statement(stmt)
+	b.targets = b.targets[:len(b.targets)-1]
+}
+
+// forEach builds a for-in or a for-of statement, which assigns left each
+// value of right before the body.
+func (b *builder) forEach(labels []string, left ast.Node, right ast.Expression, stmt ast.Statement, comment string) {
+	b.add(right)
+	loop := b.newBlock(comment + ".loop")
+	body := b.newBlock(comment + ".body")
+	done := b.newBlock(comment + ".done")
+	b.jump(loop)
+	b.branch(body, done)
+	b.current = body
+	b.add(left)
+	b.loop(labels, stmt, body, done, loop)
+	b.jump(loop)
+	b.current = done
+}
+
+// target returns the target of a break statement, or of a continue
+// statement if cont is set, with the label. It returns nil if there is no
+// such statement.
+func (b *builder) target(label ast.Expression, cont bool) *target {
+	for i := len(b.targets) - 1; i >= 0; i-- {
+		t := b.targets[i]
+		if cont && t.continueTo == nil {
+			continue
+		}
+		if label == nil && t.loop || label != nil && slices.Contains(t.labels, label.String()) {
+			return t
+		}
+	}
+	return nil
+}
+
+// switchStatement builds a switch statement. The tests of the cases are
+// evaluated in order, and the default case is taken if none matches. The
+// body of a case falls through to the next one.
+func (b *builder) switchStatement(n *ast.SwitchStatement) {
+	b.add(n.Discriminant)
+	done := b.newBlock("switch.done")
+	cases := switchCases(n)
+	bodies := make([]*Block, len(cases))
+	for i := range cases {
+		bodies[i] = b.newBlock("switch.body")
+	}
+
+	otherwise := done
+	for i, c := range cases {
+		if c == n.DefaultCase {
+			otherwise = bodies[i]
+			continue
+		}
+		test := b.newBlock("switch.case")
+		b.jump(test)
+		b.add(c.Test)
+		next := b.newBlock("switch.next")
+		b.branch(bodies[i], next)
+		b.current = next
+	}
+	b.jump(otherwise)
+
+	b.targets = append(b.targets, &target{breakTo: done, depth: len(b.tries), loop: true})
+	for i, c := range cases {
+		b.current = bodies[i]
+		b.statements(c.Consequent)
+		if i+1 < len(cases) {
+			b.jump(bodies[i+1])
+		}
+	}
+	b.targets = b.targets[:len(b.targets)-1]
+	b.jump(done)
+}
+
+// switchCases returns the cases of a switch statement in the order of the
+// source, where the default case may be between the other ones.
+func switchCases(n *ast.SwitchStatement) []*ast.SwitchCase {
+	cases := make([]*ast.SwitchCase, 0, len(n.Cases)+1)
+	for i := range n.Cases {
+		c := &n.Cases[i]
+		if n.DefaultCase != nil && len(cases) == i && n.DefaultCase.Token.Pos().IsValid() &&
+			c.Token.Pos().IsValid() && n.DefaultCase.Token.Offset < c.Token.Offset {
+			cases = append(cases, n.DefaultCase)
+		}
+		cases = append(cases, c)
+	}
+	if n.DefaultCase != nil && len(cases) == len(n.Cases) {
+		cases = append(cases, n.DefaultCase)
+	}
+	return cases
+}
+
+// tryStatement builds a try statement. Any block of the try block may throw
+// to the catch clause, or to the finally block if there is no catch clause.
+// The finally block is entered after the try block and the catch clause, and
+// by the jumps and the exceptions out of them, which it goes on with after its
+// end.
+func (b *builder) tryStatement(n *ast.TryStatement) {
+	t := new(tryContext)
+	done := b.newBlock("try.done")
+	if n.CatchClause != nil {
+		t.catch = b.newBlock("catch.body")
+	}
+	if n.Finally != nil {
+		t.finally = b.newBlock("finally.body")
+	}
+	after := done
+	if t.finally != nil {
+		after = t.finally
+	}
+
+	b.tries = append(b.tries, t)
+	start := len(b.cfg.Blocks)
+	b.jump(b.newBlock("try.body"))
+	b.statement(n.Block)
+	end := len(b.cfg.Blocks)
+	b.jump(after)
+	handler := b.throwTarget()
+	for _, block := range b.cfg.Blocks[start:end] {
+		edge(block, handler, EdgeException)
+	}
+
+	if t.catch != nil {
+		t.inCatch = true
+		b.current = t.catch
+		if n.CatchClause.Param != nil {
+			b.add(n.CatchClause.Param)
+		}
+		b.statement(n.CatchClause.Body)
+		b.jump(after)
+	}
+	b.tries = b.tries[:len(b.tries)-1]
+
+	if t.finally != nil {
+		b.current = t.finally
+		b.statement(n.Finally)
+		end := b.current
+		b.jump(done)
+		for _, exit := range t.exits {
+			b.current = end
+			b.jumpOut(exit.to, exit.depth)
+		}
+		if t.throws {
+			edge(end, b.throwTarget(), EdgeException)
+		}
+	}
+	b.current = done
+}
+
+// finish ends the graph, marks the blocks reachable from the entry, and
+// removes the empty blocks that are not.
+func (b *builder) finish() *CFG {
+	b.jump(b.cfg.Exit)
+
+	g := b.cfg
+	var mark func(block *Block)
+	mark = func(block *Block) {
+		if block.Live {
+			return
+		}
+		block.Live = true
+		for _, e := range block.Succs {
+			mark(e.To)
+		}
+	}
+	mark(g.Entry)
+
+	blocks := g.Blocks[:0]
+	for _, block := range g.Blocks {
+		if block.Live || len(block.Nodes) > 0 || block == g.Exit || block == g.Throw {
+			block.Index = len(blocks)
+			blocks = append(blocks, block)
+		}
+	}
+	g.Blocks = blocks
+	kept := make(map[*Block]bool, len(blocks))
+	for _, block := range blocks {
+		kept[block] = true
+	}
+	for _, block := range blocks {
+		succs := block.Succs[:0]
+		for _, e := range block.Succs {
+			if kept[e.To] {
+				succs = append(succs, e)
+				e.To.Preds = append(e.To.Preds, block)
+			}
+		}
+		block.Succs = succs
+	}
+	return g
+}
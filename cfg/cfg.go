@@ -0,0 +1,176 @@
+// Package cfg builds the control-flow graphs of the functions of a program.
+//
+// A graph is made of basic blocks, which hold the statements and the
+// expressions evaluated one after the other, and the edges the control flows
+// along from a block to another. The expressions are not split, so the
+// operands of the short-circuiting and the conditional operators are in the
+// block of the expression.
+package cfg
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ghosind/gjs/ast"
+)
+
+// CFG is the control-flow graph of a function or of the top level of a
+// program.
+type CFG struct {
+	// Blocks are the blocks of the graph, by their indexes.
+	Blocks []*Block
+	// Entry is the block the control enters the function at.
+	Entry *Block
+	// Exit is the block the control leaves the function at, when it returns
+	// or reaches the end of its body.
+	Exit *Block
+	// Throw is the block the control leaves the function at when an
+	// exception is thrown and not caught.
+	Throw *Block
+}
+
+// Block is a basic block of a graph.
+type Block struct {
+	Index int
+	// Comment tells where the block is in the source, such as "if.then" for
+	// the true branch of an if statement.
+	Comment string
+	// Nodes are the statements and the expressions evaluated in the block,
+	// in order. The compound statements are split into their parts, such as
+	// the condition of an if statement, which is the last node of the block
+	// that branches on it.
+	Nodes []ast.Node
+	Succs []Edge
+	Preds []*Block
+	// Live is set if the block is reachable from the entry.
+	Live bool
+}
+
+// EdgeKind is the kind of an edge of a graph.
+type EdgeKind int
+
+const (
+	// EdgeNormal is an unconditional edge.
+	EdgeNormal EdgeKind = iota
+	// EdgeTrue and EdgeFalse are taken when the condition at the end of the
+	// block is true or false, or when a for-in or a for-of statement has a
+	// next value or not.
+	EdgeTrue
+	EdgeFalse
+	// EdgeException is taken when an exception is thrown.
+	EdgeException
+)
+
+func (k EdgeKind) String() string {
+	switch k {
+	case EdgeNormal:
+		return "normal"
+	case EdgeTrue:
+		return "true"
+	case EdgeFalse:
+		return "false"
+	case EdgeException:
+		return "exception"
+	}
+	return "unknown"
+}
+
+// Edge is an edge from a block to its successor To.
+type Edge struct {
+	Kind EdgeKind
+	To   *Block
+}
+
+// New builds the graph of a function, which is an *ast.FunctionDeclaration,
+// an *ast.FunctionExpression or an *ast.ArrowFunctionExpression, or of the
+// top level of an *ast.Program. Any other node is taken as a statement. The
+// functions nested in node are not entered.
+func New(node ast.Node) *CFG {
+	b := newBuilder()
+	switch n := node.(type) {
+	case *ast.Program:
+		b.statements(n.Statements)
+	case *ast.FunctionDeclaration:
+		b.statements(n.Body.StatementList)
+	case *ast.FunctionExpression:
+		b.statements(n.Body.StatementList)
+	case *ast.ArrowFunctionExpression:
+		if block, ok := n.Body.(*ast.BlockStatement); ok {
+			b.statements(block.StatementList)
+		} else {
+			b.add(n.Body)
+		}
+	default:
+		b.statement(node)
+	}
+	return b.finish()
+}
+
+// All builds the graphs of program and of all the functions in it, by their
+// nodes.
+func All(program *ast.Program) map[ast.Node]*CFG {
+	graphs := make(map[ast.Node]*CFG)
+	ast.Inspect(program, func(node ast.Node) bool {
+		switch node.(type) {
+		case *ast.Program, *ast.FunctionDeclaration, *ast.FunctionExpression, *ast.ArrowFunctionExpression:
+			graphs[node] = New(node)
+		}
+		return true
+	})
+	return graphs
+}
+
+func (b *Block) String() string {
+	return fmt.Sprintf("%d: %s", b.Index, b.Comment)
+}
+
+// Dot returns the graph in the DOT language of Graphviz.
+func (g *CFG) Dot() string {
+	buf := new(strings.Builder)
+	buf.WriteString("digraph cfg {\n\tnode [shape=box];\n")
+	for _, block := range g.Blocks {
+		label := block.String()
+		for _, node := range block.Nodes {
+			label += "\n" + node.String()
+		}
+		style := ""
+		if !block.Live {
+			style = ", style=dashed"
+		}
+		fmt.Fprintf(buf, "\tb%d [label=%s%s];\n", block.Index, dotQuote(label), style)
+	}
+	for _, block := range g.Blocks {
+		for _, edge := range block.Succs {
+			attrs := ""
+			switch edge.Kind {
+			case EdgeTrue, EdgeFalse:
+				attrs = fmt.Sprintf(" [label=%s]", edge.Kind)
+			case EdgeException:
+				attrs = fmt.Sprintf(" [label=%s, style=dashed]", edge.Kind)
+			}
+			fmt.Fprintf(buf, "\tb%d -> b%d%s;\n", block.Index, edge.To.Index, attrs)
+		}
+	}
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+// dotQuote returns s as a quoted string of DOT, whose lines are justified to
+// the left.
+func dotQuote(s string) string {
+	buf := new(strings.Builder)
+	buf.WriteByte('"')
+	for _, c := range s {
+		switch c {
+		case '"', '\\':
+			buf.WriteByte('\\')
+			buf.WriteRune(c)
+		case '\n':
+			buf.WriteString(`\l`)
+		default:
+			buf.WriteRune(c)
+		}
+	}
+	buf.WriteString(`\l"`)
+	return buf.String()
+}
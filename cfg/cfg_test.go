@@ -0,0 +1,237 @@
+package cfg_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/cfg"
+	"github.com/ghosind/gjs/lexer"
+	"github.com/ghosind/gjs/parser"
+	"github.com/ghosind/go-assert"
+)
+
+func build(a *assert.Assertion, src string) (*ast.Program, *cfg.CFG) {
+	program, err := parser.New(lexer.New([]byte(src))).Parse()
+	a.NilNow(err, src)
+	return program, cfg.New(program)
+}
+
+// describe returns the blocks of g, each with the number of its nodes and its
+// successors, and a mark if it is not live.
+func describe(g *cfg.CFG) string {
+	lines := make([]string, 0, len(g.Blocks))
+	for _, block := range g.Blocks {
+		line := fmt.Sprintf("%s(%d)", block, len(block.Nodes))
+		if !block.Live {
+			line += " dead"
+		}
+		for _, e := range block.Succs {
+			switch e.Kind {
+			case cfg.EdgeNormal:
+				line += fmt.Sprintf(" ->%d", e.To.Index)
+			default:
+				line += fmt.Sprintf(" -%s->%d", e.Kind, e.To.Index)
+			}
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestStatements(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a(); if (b) { c(); } else d(); e();", `0: entry(2) -true->3 -false->5
+1: exit(0)
+2: throw(0) dead
+3: if.then(1) ->4
+4: if.done(1) ->1
+5: if.else(1) ->4`},
+		{"while (a) { if (b) break; if (c) continue; d(); } e();", `0: entry(0) ->3
+1: exit(0)
+2: throw(0) dead
+3: while.loop(1) -true->4 -false->5
+4: while.body(1) -true->6 -false->7
+5: while.done(1) ->1
+6: if.then(1) ->5
+7: if.done(1) -true->8 -false->9
+8: if.then(1) ->3
+9: if.done(1) ->3`},
+		{"outer: for (let i = 0; i < 1; i++) { for (const x of y) { if (x) continue outer; break outer; } }", `0: entry(1) ->3
+1: exit(0)
+2: throw(0) dead
+3: for.loop(1) -true->4 -false->6
+4: for.body(1) ->7
+5: for.post(1) ->3
+6: for.done(0) ->1
+7: for.of.loop(0) -true->8 -false->9
+8: for.of.body(2) -true->10 -false->11
+9: for.of.done(0) ->5
+10: if.then(1) ->5
+11: if.done(1) ->6`},
+		{"do { a(); } while (b); while (true) { c(); } d();", `0: entry(0) ->3
+1: exit(0) dead
+2: throw(0) dead
+3: do.body(1) ->4
+4: do.cond(1) -true->3 -false->5
+5: do.done(0) ->6
+6: while.loop(1) ->7
+7: while.body(1) ->6
+8: while.done(1) dead ->1`},
+		{"a: b: while (x) { if (y) continue a; break b; }", `0: entry(0) ->3
+1: exit(0)
+2: throw(0) dead
+3: while.loop(1) -true->4 -false->5
+4: while.body(1) -true->6 -false->7
+5: while.done(0) ->1
+6: if.then(1) ->3
+7: if.done(1) ->5`},
+		{"l: { if (a) break l; b(); } c(); throw d; e();", `0: entry(1) -true->4 -false->5
+1: exit(0) dead
+2: throw(0)
+3: label.done(2) -exception->2
+4: if.then(1) ->3
+5: if.done(1) ->3
+6: unreachable(1) dead ->1`},
+	}
+	for _, test := range tests {
+		_, g := build(a, test.input)
+		a.EqualNow(describe(g), test.expected, test.input)
+	}
+}
+
+func TestFunctions(t *testing.T) {
+	a := assert.New(t)
+
+	program, err := parser.New(lexer.New([]byte(
+		"function f(x) { if (x) { return 1; a(); } } var g = (y) => y * 2;"))).Parse()
+	a.NilNow(err)
+	graphs := cfg.All(program)
+	a.EqualNow(len(graphs), 3)
+
+	f := graphs[program.Statements[0]]
+	a.EqualNow(describe(f), `0: entry(1) -true->3 -false->4
+1: exit(0)
+2: throw(0) dead
+3: if.then(1) ->1
+4: if.done(0) ->1
+5: unreachable(1) dead ->4`)
+	// The end of the body is reached without a return statement.
+	a.EqualNow(len(f.Exit.Preds), 2)
+	a.EqualNow(f.Exit.Preds[1].Comment, "if.done")
+	a.NotTrueNow(f.Blocks[5].Live)
+
+	arrow := program.Statements[1].(*ast.VarStatement).Declarations[0].(*ast.VariableDeclaration).Value
+	a.EqualNow(describe(graphs[arrow]), `0: entry(1) ->1
+1: exit(0)
+2: throw(0) dead`)
+	a.EqualNow(describe(graphs[program]), `0: entry(2) ->1
+1: exit(0)
+2: throw(0) dead`)
+}
+
+func id(name string) *ast.Identifier {
+	return &ast.Identifier{Value: name}
+}
+
+func call(name string) ast.Statement {
+	return &ast.ExpressionStatement{Expression: &ast.CallExpression{Callee: id(name)}}
+}
+
+func block(list ...ast.Statement) *ast.BlockStatement {
+	return &ast.BlockStatement{StatementList: list}
+}
+
+func TestTryStatement(t *testing.T) {
+	a := assert.New(t)
+
+	// The parser does not parse try statements, so the statement is built
+	// by hand, as
+	// while (a) { try { if (b) break; c(); } catch (e) { throw e; } finally { d(); } }
+	g := cfg.New(&ast.WhileStatement{
+		Condition: id("a"),
+		Body: block(&ast.TryStatement{
+			Block:       block(&ast.IfStatement{Condition: id("b"), TrueBranch: &ast.BreakStatement{}}, call("c")),
+			CatchClause: &ast.CatchClause{Param: id("e"), Body: block(&ast.ThrowStatement{Argument: id("e")})},
+			Finally:     block(call("d")),
+		}),
+	})
+	a.EqualNow(describe(g), `0: entry(0) ->3
+1: exit(0)
+2: throw(0)
+3: while.loop(1) -true->4 -false->5
+4: while.body(0) ->9
+5: while.done(0) ->1
+6: try.done(0) ->3
+7: catch.body(2) -exception->8
+8: finally.body(1) ->6 ->5 -exception->2
+9: try.body(1) -true->10 -false->11 -exception->7
+10: if.then(1) ->8 -exception->7
+11: if.done(1) ->8 -exception->7`)
+
+	// try { return; } finally { a(); }
+	g = cfg.New(&ast.TryStatement{
+		Block:   block(&ast.ReturnStatement{}),
+		Finally: block(call("a")),
+	})
+	a.EqualNow(describe(g), `0: entry(0) ->5
+1: exit(0)
+2: throw(0)
+3: try.done(0) ->1
+4: finally.body(1) ->3 ->1 -exception->2
+5: try.body(1) ->4 -exception->4`)
+}
+
+func TestSwitchStatement(t *testing.T) {
+	a := assert.New(t)
+
+	// The parser does not parse switch statements, so the statement is built
+	// by hand, as
+	// switch (a) { case 1: b(); case 2: c(); break; default: d(); }
+	one := &ast.Literal{Kind: ast.LitNumber, Value: "1"}
+	two := &ast.Literal{Kind: ast.LitNumber, Value: "2"}
+	g := cfg.New(&ast.SwitchStatement{
+		Discriminant: id("a"),
+		Cases: []ast.SwitchCase{
+			{Test: one, Consequent: []ast.Statement{call("b")}},
+			{Test: two, Consequent: []ast.Statement{call("c"), &ast.BreakStatement{}}},
+		},
+		DefaultCase: &ast.SwitchCase{Consequent: []ast.Statement{call("d")}},
+	})
+	a.EqualNow(describe(g), `0: entry(1) ->7
+1: exit(0)
+2: throw(0) dead
+3: switch.done(0) ->1
+4: switch.body(1) ->5
+5: switch.body(2) ->3
+6: switch.body(1) ->3
+7: switch.case(1) -true->4 -false->8
+8: switch.next(0) ->9
+9: switch.case(1) -true->5 -false->10
+10: switch.next(0) ->6`)
+}
+
+func TestDot(t *testing.T) {
+	a := assert.New(t)
+
+	_, g := build(a, `if (a) b();`)
+	a.EqualNow(g.Dot(), `digraph cfg {
+	node [shape=box];
+	b0 [label="0: entry\la\l"];
+	b1 [label="1: exit\l"];
+	b2 [label="2: throw\l", style=dashed];
+	b3 [label="3: if.then\lb();\l"];
+	b4 [label="4: if.done\l"];
+	b0 -> b3 [label=true];
+	b0 -> b4 [label=false];
+	b3 -> b4;
+	b4 -> b1;
+}
+`)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ghosind/gjs/printer"
+)
+
+// runFmt runs the fmt command, which formats files, or the standard input if
+// no files are given, and prints the result to the standard output.
+func runFmt(args []string) int {
+	cfg := new(config)
+	fs := flag.NewFlagSet("gjs fmt", flag.ContinueOnError)
+	fs.BoolVar(&cfg.module, "module", false, "parse the files as modules")
+	fs.IntVar(&cfg.ecmaVersion, "ecma", 0, "the `edition` of ECMAScript to parse, such as 5 or 2020")
+	fs.BoolVar(&cfg.jsx, "jsx", false, "parse JSX")
+	write := fs.Bool("w", false, "write the result to the files instead of the standard output")
+	diff := fs.Bool("d", false, "print the diffs of the files instead of the result")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gjs fmt [flags] [file ...]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	} else if fs.NArg() == 0 && *write {
+		fmt.Fprintln(os.Stderr, "gjs fmt: cannot use -w with the standard input")
+		return 2
+	}
+
+	if fs.NArg() == 0 {
+		src, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if err := formatFile(cfg, "<standard input>", src, false, *diff); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	}
+
+	code := 0
+	for _, file := range fs.Args() {
+		src, err := os.ReadFile(file)
+		if err == nil {
+			err = formatFile(cfg, file, src, *write, *diff)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			code = 1
+		}
+	}
+	return code
+}
+
+// formatFile formats the source of a file, and writes the result back to it,
+// or prints the result or its diff to the standard output.
+func formatFile(cfg *config, file string, src []byte, write, diff bool) error {
+	opts := cfg.options(file)
+	opts.Comments = true
+	program, err := parse(src, opts)
+	if err != nil {
+		return fmt.Errorf("%s:%s", file, err)
+	}
+	buf := new(bytes.Buffer)
+	if err := printer.Fprint(buf, program); err != nil {
+		return fmt.Errorf("%s: %s", file, err)
+	}
+	res := buf.Bytes()
+
+	if diff && !bytes.Equal(src, res) {
+		fmt.Printf("diff %s.orig %s\n", file, file)
+		os.Stdout.Write(unifiedDiff(file+".orig", file, src, res))
+	}
+	if write {
+		if bytes.Equal(src, res) {
+			return nil
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(file, res, info.Mode().Perm())
+	}
+	if !diff {
+		os.Stdout.Write(res)
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+// Command gjs runs a JavaScript file.
+//
+// Usage:
+//
+//	gjs [flags] file
+//	gjs fmt [flags] [file ...]
+//	gjs minify [flags] [file]
+//
+// The fmt command formats the files, or the standard input, and prints the
+// result. With -w the result is written back to the files, and with -d the
+// diffs of the files are printed instead.
+//
+// The minify command prints the file, or the standard input, minified to a
+// program that evaluates the same. With -o the result is written to a file,
+// and with -source-map its source map is written to a file and referred to by
+// a sourceMappingURL comment at the end of the result.
+//
+// The file is run as it is parsed unless an optimization level is given with
+// -O1 or -O2, at which the program is optimized by the passes of the optimize
+// package before it runs.
+//
+// The stack trace of an uncaught error is printed with the positions in the
+// original sources if the file refers to its source map in a sourceMappingURL
+// comment on its last line.
+//
+// A file may be made an executable script with a hashbang comment that runs
+// gjs, such as "#!/usr/bin/env gjs". The flags that follow gjs in the
+// hashbang, as in "#!/usr/bin/env -S gjs -module", are applied to the script
+// unless they are given on the command line.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/evaluator"
+	"github.com/ghosind/gjs/lexer"
+	"github.com/ghosind/gjs/optimize"
+	"github.com/ghosind/gjs/parser"
+	"github.com/ghosind/gjs/runtime"
+	"github.com/ghosind/gjs/token"
+	"github.com/ghosind/gjs/transform"
+	"github.com/ghosind/gjs/value"
+)
+
+// config is the configuration of a run, set by the flags.
+type config struct {
+	module      bool
+	ecmaVersion int
+	jsx         bool
+	jsxFactory  string
+	jsxFragment string
+	ts          bool
+	optLevel    int
+}
+
+func (c *config) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.BoolVar(&c.module, "module", c.module, "run the file as a module")
+	fs.IntVar(&c.ecmaVersion, "ecma", c.ecmaVersion, "the `edition` of ECMAScript to parse, such as 5 or 2020")
+	fs.BoolVar(&c.jsx, "jsx", c.jsx, "parse JSX, and rewrite it to calls of the JSX factory")
+	fs.StringVar(&c.jsxFactory, "jsx-factory", c.jsxFactory, "the `function` that creates JSX elements (default React.createElement)")
+	fs.StringVar(&c.jsxFragment, "jsx-fragment", c.jsxFragment, "the `type` of JSX fragments (default React.Fragment)")
+	fs.BoolVar(&c.ts, "ts", c.ts, "parse TypeScript, and erase its types")
+	for level := optimize.O0; level <= optimize.MaxLevel; level++ {
+		fs.BoolFunc(fmt.Sprintf("O%d", level), fmt.Sprintf("optimize the program at level %d before running it", level), func(string) error {
+			c.optLevel = int(level)
+			return nil
+		})
+	}
+	return fs
+}
+
+func (c *config) options(file string) parser.Options {
+	opts := parser.Options{
+		EcmaVersion: c.ecmaVersion,
+		FileName:    file,
+		JSX:         c.jsx,
+		TypeScript:  c.ts,
+	}
+	if c.module {
+		opts.SourceType = ast.SourceModule
+	}
+	return opts
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "fmt":
+			return runFmt(args[1:])
+		case "minify":
+			return runMinify(args[1:])
+		}
+	}
+
+	cfg := new(config)
+	fs := cfg.flagSet("gjs")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gjs [flags] file")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	} else if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	file := fs.Arg(0)
+	src, err := os.ReadFile(file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	program, err := parse(src, cfg.options(file))
+	hashBang := hashBangOf(program, src)
+	if hashBang != nil {
+		// Apply the flags of the hashbang, and then the command line flags
+		// again, which take precedence.
+		hashBangCfg := *cfg
+		ok, hashBangErr := applyHashBang(&hashBangCfg, hashBang.Literal)
+		if hashBangErr != nil {
+			fmt.Fprintf(os.Stderr, "%s: hashbang: %s\n", file, hashBangErr)
+			return 2
+		}
+		if ok {
+			hashBangCfg.flagSet("gjs").Parse(args)
+			if hashBangCfg != *cfg {
+				*cfg = hashBangCfg
+				program, err = parse(src, cfg.options(file))
+			}
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s:%s\n", file, err)
+		return 1
+	}
+	if cfg.jsx {
+		transform.JSX(program, transform.JSXOptions{Factory: cfg.jsxFactory, Fragment: cfg.jsxFragment})
+	}
+	optimize.Program(program, optimize.Level(cfg.optLevel))
+
+	return eval(program, src)
+}
+
+func parse(src []byte, opts parser.Options) (*ast.Program, error) {
+	// Scripts are allowed to start with a hashbang in every edition.
+	opts.AllowHashBang = true
+	return parser.NewWithOptions(lexer.New(src), opts).Parse()
+}
+
+// hashBangOf returns the hashbang of the program parsed from src. If the
+// program failed to parse, which it may without the flags of its hashbang, the
+// hashbang is read from a partial parse of the source.
+func hashBangOf(program *ast.Program, src []byte) *token.Token {
+	if program == nil {
+		program, _ = parser.NewWithOptions(lexer.New(src), parser.Options{AllowHashBang: true}).ParseProgramTolerant()
+	}
+	return program.HashBang
+}
+
+// applyHashBang parses the flags that follow gjs in the interpreter line of a
+// hashbang into cfg, and reports whether the interpreter is gjs. It returns an
+// error if the flags are invalid.
+func applyHashBang(cfg *config, line string) (bool, error) {
+	fields := strings.Fields(line)
+	for i, field := range fields {
+		if strings.TrimSuffix(filepath.Base(field), ".exe") != "gjs" {
+			continue
+		}
+		fs := cfg.flagSet("hashbang")
+		fs.SetOutput(io.Discard)
+		if err := fs.Parse(fields[i+1:]); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+func eval(program *ast.Program, src []byte) int {
+	env := runtime.New()
+	defer env.Close()
+
+	if m, err := loadSourceMap(program.FileName, src); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: source map: %s\n", program.FileName, err)
+	} else if m != nil {
+		env.SetSourceMap(program.FileName, m)
+	}
+
+	res := evaluator.New(env).Eval(program)
+	env.Jobs().Run()
+
+	if promise, ok := res.(*value.Promise); ok && program.SourceType == ast.SourceModule {
+		if promise.State == value.PromiseStateRejected {
+			res = &evaluator.Exception{Value: promise.Result}
+		}
+	}
+	if exc, ok := res.(*evaluator.Exception); ok {
+		fmt.Fprintln(os.Stderr, exc.StackTrace())
+		return 1
+	}
+	return 0
+}
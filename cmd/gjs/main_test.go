@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ghosind/go-assert"
+)
+
+func TestApplyHashBang(t *testing.T) {
+	a := assert.New(t)
+
+	cfg := new(config)
+	ok, err := applyHashBang(cfg, "#!/usr/bin/env -S gjs -module -O2")
+	a.NilNow(err)
+	a.TrueNow(ok)
+	a.TrueNow(cfg.module)
+	a.EqualNow(cfg.optLevel, 2)
+
+	cfg = new(config)
+	ok, err = applyHashBang(cfg, "#!/usr/bin/env node --harmony")
+	a.NilNow(err)
+	a.NotTrueNow(ok)
+
+	for _, line := range []string{"#!/usr/bin/env -S gjs -unknown", "#!/usr/bin/env -S gjs -ecma x"} {
+		_, err = applyHashBang(new(config), line)
+		a.NotNilNow(err, line)
+	}
+}
+
+func TestRunHashBang(t *testing.T) {
+	a := assert.New(t)
+
+	dir := t.TempDir()
+	write := func(name, src string) string {
+		file := filepath.Join(dir, name)
+		a.NilNow(os.WriteFile(file, []byte(src), 0o644))
+		return file
+	}
+
+	a.EqualNow(run([]string{write("ok.js", "#!/usr/bin/env -S gjs -ecma 2015\nlet a = 1;\n")}), 0)
+	a.EqualNow(run([]string{write("unknown.js", "#!/usr/bin/env -S gjs -unknown\nlet a = 1;\n")}), 2)
+	a.EqualNow(run([]string{write("invalid.js", "#!/usr/bin/env -S gjs -ecma x\nlet a = 1;\n")}), 2)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/ghosind/gjs/minify"
+	"github.com/ghosind/gjs/printer"
+	"github.com/ghosind/gjs/sourcemap"
+	"github.com/ghosind/gjs/transform"
+)
+
+// runMinify runs the minify command, which minifies a file, or the standard
+// input if no file is given, and prints the result to the standard output.
+func runMinify(args []string) int {
+	cfg := new(config)
+	fs := cfg.flagSet("gjs minify")
+	output := fs.String("o", "", "write the result to `file` instead of the standard output")
+	keepNames := fs.Bool("keep-names", false, "keep the names of the local bindings")
+	sourceMap := fs.String("source-map", "", "write the source map of the result to `file`")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gjs minify [flags] [file]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	} else if fs.NArg() > 1 {
+		fs.Usage()
+		return 2
+	}
+
+	file := fs.Arg(0)
+	var src []byte
+	var err error
+	if file == "" {
+		file = "<standard input>"
+		src, err = io.ReadAll(os.Stdin)
+	} else {
+		src, err = os.ReadFile(file)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	program, err := parse(src, cfg.options(file))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s:%s\n", file, err)
+		return 1
+	}
+	if cfg.jsx {
+		transform.JSX(program, transform.JSXOptions{Factory: cfg.jsxFactory, Fragment: cfg.jsxFragment})
+	}
+	minify.Program(program, minify.Options{KeepNames: *keepNames})
+
+	buf := new(bytes.Buffer)
+	pcfg := &printer.Config{Compact: true}
+	if *sourceMap != "" {
+		var name string
+		if *output != "" {
+			name = filepath.Base(*output)
+		}
+		pcfg.SourceMap = sourcemap.NewGenerator(name)
+		pcfg.SourceMap.SetSourceContent(file, string(src))
+	}
+	if err := pcfg.Fprint(buf, program); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", file, err)
+		return 1
+	}
+	if *sourceMap != "" {
+		comment, err := writeSourceMap(*sourceMap, *output, pcfg.SourceMap.Map())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Fprintf(buf, "\n%s\n", comment)
+	}
+	if *output != "" {
+		err = os.WriteFile(*output, buf.Bytes(), 0o644)
+	} else {
+		_, err = os.Stdout.Write(buf.Bytes())
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghosind/gjs/sourcemap"
+)
+
+// sourceMappingURL is the comment at the end of a generated source that
+// refers to its source map.
+const sourceMappingURL = "//# sourceMappingURL="
+
+// loadSourceMap reads the source map that the source of file refers to in a
+// sourceMappingURL comment on its last line, which is a path relative to the
+// file or a data URL in base64. It returns nil if there is no such comment.
+func loadSourceMap(file string, src []byte) (*sourcemap.Map, error) {
+	src = bytes.TrimRight(src, " \t\r\n")
+	last := src[bytes.LastIndexByte(src, '\n')+1:]
+	url, ok := strings.CutPrefix(strings.TrimSpace(string(last)), sourceMappingURL)
+	if !ok {
+		return nil, nil
+	}
+
+	var data []byte
+	var err error
+	if strings.HasPrefix(url, "data:") {
+		i := strings.Index(url, ";base64,")
+		if i < 0 {
+			return nil, fmt.Errorf("unsupported source map URL %q", url)
+		}
+		data, err = base64.StdEncoding.DecodeString(url[i+len(";base64,"):])
+	} else {
+		data, err = os.ReadFile(filepath.Join(filepath.Dir(file), filepath.FromSlash(url)))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sourcemap.Parse(data)
+}
+
+// writeSourceMap writes the source map of the output of the minify command
+// to file, and returns the sourceMappingURL comment that refers to it from
+// output.
+func writeSourceMap(file, output string, m *sourcemap.Map) (string, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(file, data, 0o644); err != nil {
+		return "", err
+	}
+
+	url := file
+	if output != "" {
+		if rel, err := filepath.Rel(filepath.Dir(output), file); err == nil {
+			url = rel
+		}
+	}
+	return sourceMappingURL + filepath.ToSlash(url), nil
+}
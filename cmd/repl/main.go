@@ -34,5 +34,6 @@ func main() {
 		if evaluated != nil {
 			fmt.Fprintln(os.Stdout, evaluated.Inspect())
 		}
+		env.Jobs().Run()
 	}
 }
@@ -0,0 +1,397 @@
+package evaluator
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/runtime"
+	"github.com/ghosind/gjs/token"
+	"github.com/ghosind/gjs/value"
+)
+
+type bindingKind int
+
+const (
+	bindAssign bindingKind = iota
+	bindVar
+	bindLet
+	bindConst
+)
+
+var compoundOperators = map[token.TokenType]token.TokenType{
+	token.TOKEN_PLUS_EQUAL:                    token.TOKEN_PLUS,
+	token.TOKEN_MINUS_EQUAL:                   token.TOKEN_MINUS,
+	token.TOKEN_STAR_EQUAL:                    token.TOKEN_STAR,
+	token.TOKEN_SLASH_EQUAL:                   token.TOKEN_SLASH,
+	token.TOKEN_PERCENT_EQUAL:                 token.TOKEN_PERCENT,
+	token.TOKEN_STAR_STAR_EQUAL:               token.TOKEN_STAR_STAR,
+	token.TOKEN_LESS_LESS_EQUAL:               token.TOKEN_LESS_LESS,
+	token.TOKEN_GREATER_GREATER_EQUAL:         token.TOKEN_GREATER_GREATER,
+	token.TOKEN_GREATER_GREATER_GREATER_EQUAL: token.TOKEN_GREATER_GREATER_GREATER,
+	token.TOKEN_AND_EQUAL:                     token.TOKEN_AND,
+	token.TOKEN_PIPE_EQUAL:                    token.TOKEN_PIPE,
+	token.TOKEN_HAT_EQUAL:                     token.TOKEN_HAT,
+}
+
+func (e *Evaluator) evalDeclarations(decls []ast.Declaration, kind bindingKind) value.Value {
+	for _, decl := range decls {
+		decl := decl.(*ast.VariableDeclaration)
+
+		var val value.Value = UNDEFINED
+		if decl.Value != nil {
+			val = e.Eval(decl.Value)
+			if isAbrupt(val) {
+				return val
+			}
+			setFunctionName(val, decl.Value, decl.Name)
+		} else if id, ok := decl.Name.(*ast.Identifier); ok && kind == bindVar && e.varEnv.Has(id.Value) {
+			// Redeclaring a var without an initializer keeps its value.
+			continue
+		}
+
+		if res := e.bindPattern(decl.Name, val, kind); isAbrupt(res) {
+			return res
+		}
+	}
+
+	return nil
+}
+
+// bindPattern binds val to an identifier, a property, or the identifiers of
+// a destructuring pattern.
+func (e *Evaluator) bindPattern(target ast.Expression, val value.Value, kind bindingKind) value.Value {
+	switch target := target.(type) {
+	case *ast.Identifier:
+		return e.bindIdentifier(target.Value, val, kind)
+	case *ast.MemberExpression:
+		obj := e.Eval(target.Object)
+		if isAbrupt(obj) {
+			return obj
+		}
+		key := e.evalPropertyKey(target)
+		if isAbrupt(key) {
+			return key
+		}
+		return e.setProperty(obj, key.(*value.String).Value, val)
+	case *ast.AssignmentPattern:
+		if val == UNDEFINED {
+			val = e.Eval(target.Right)
+			if isAbrupt(val) {
+				return val
+			}
+			setFunctionName(val, target.Right, target.Left)
+		}
+		return e.bindPattern(target.Left, val, kind)
+	case *ast.ArrayPattern:
+		return e.bindArrayPattern(target, val, kind)
+	case *ast.ObjectPattern:
+		return e.bindObjectPattern(target, val, kind)
+	}
+
+	return newError("invalid binding target: %s", target.String())
+}
+
+func (e *Evaluator) bindIdentifier(name string, val value.Value, kind bindingKind) value.Value {
+	switch kind {
+	case bindVar:
+		e.varEnv.Set(name, val)
+	case bindLet:
+		e.env.SetLexical(name, val)
+	case bindConst:
+		e.env.SetConst(name, val)
+	default:
+		err := e.env.Assign(name, val)
+		if errors.Is(err, runtime.ErrNotDefined) {
+			// Assigning to an undeclared name creates a global property in
+			// sloppy mode code.
+			if e.strict {
+				return newReferenceError("%s is not defined", name)
+			}
+			e.env.Global().Set(name, val)
+		} else if err != nil {
+			return newTypeError("%v", err)
+		}
+	}
+	return nil
+}
+
+func (e *Evaluator) bindArrayPattern(pattern *ast.ArrayPattern, val value.Value, kind bindingKind) value.Value {
+	iter, res := e.getIterator(val, val.Inspect(), false)
+	if res != nil {
+		return res
+	}
+
+	for _, elem := range pattern.Elements {
+		if rest, ok := elem.(*ast.RestElement); ok {
+			elements := make([]value.Value, 0)
+			for {
+				next := e.iteratorStep(iter)
+				if next == nil {
+					break
+				} else if isAbrupt(next) {
+					return next
+				}
+				elements = append(elements, next)
+			}
+			return e.bindPattern(rest.Argument, &value.Array{Elements: elements}, kind)
+		}
+
+		var next value.Value = UNDEFINED
+		if !iter.done {
+			next = e.iteratorStep(iter)
+			if next == nil {
+				next = UNDEFINED
+			} else if isAbrupt(next) {
+				return next
+			}
+		}
+
+		if _, ok := elem.(*ast.Elision); ok {
+			continue
+		}
+		if res := e.bindPattern(elem, next, kind); isAbrupt(res) {
+			e.iteratorClose(iter)
+			return res
+		}
+	}
+
+	if !iter.done {
+		return e.iteratorClose(iter)
+	}
+	return nil
+}
+
+func (e *Evaluator) bindObjectPattern(pattern *ast.ObjectPattern, val value.Value, kind bindingKind) value.Value {
+	switch val.(type) {
+	case *value.Undefined, *value.Null:
+		return newTypeError("Cannot destructure '%s' as it is %s.", val.Inspect(), val.Inspect())
+	}
+
+	used := make(map[string]bool)
+	for _, prop := range pattern.Properties {
+		if rest, ok := prop.(*ast.RestElement); ok {
+			obj := value.NewObject()
+			if src, ok := val.(*value.Object); ok {
+				for _, key := range src.Keys() {
+					if !used[key] {
+						obj.Set(key, e.getProperty(src, key))
+					}
+				}
+			}
+			return e.bindPattern(rest.Argument, obj, kind)
+		}
+
+		prop := prop.(*ast.Property)
+		key, ok := e.propertyName(prop.Key, prop.Computed)
+		if !ok {
+			return key
+		}
+		name := key.(*value.String).Value
+		used[name] = true
+
+		v := e.getProperty(val, name)
+		if isAbrupt(v) {
+			return v
+		}
+		if res := e.bindPattern(prop.Value, v, kind); isAbrupt(res) {
+			return res
+		}
+	}
+
+	return nil
+}
+
+// reference evaluates a simple assignment target, an identifier or a member
+// expression, to the functions getting and setting its value. It returns the
+// completion of an abrupt evaluation instead, and all nil if target is not a
+// simple assignment target.
+func (e *Evaluator) reference(target ast.Expression) (func() value.Value, func(value.Value) value.Value, value.Value) {
+	switch target := target.(type) {
+	case *ast.Identifier:
+		get := func() value.Value {
+			return e.evalIdentifier(target)
+		}
+		set := func(val value.Value) value.Value {
+			return e.bindIdentifier(target.Value, val, bindAssign)
+		}
+		return get, set, nil
+	case *ast.MemberExpression:
+		obj := e.Eval(target.Object)
+		if isAbrupt(obj) {
+			return nil, nil, obj
+		}
+		key := e.evalPropertyKey(target)
+		if isAbrupt(key) {
+			return nil, nil, key
+		}
+		name := key.(*value.String).Value
+
+		get := func() value.Value {
+			return e.getProperty(obj, name)
+		}
+		set := func(val value.Value) value.Value {
+			return e.setProperty(obj, name, val)
+		}
+		return get, set, nil
+	}
+
+	return nil, nil, nil
+}
+
+func (e *Evaluator) evalAssignmentExpression(node *ast.AssignmentExpression) value.Value {
+	get, set, res := e.reference(node.Left)
+	if res != nil {
+		return res
+	} else if get != nil {
+		return e.assign(node, get, set)
+	}
+
+	val := e.Eval(node.Right)
+	if isAbrupt(val) {
+		return val
+	}
+	if res := e.bindPattern(node.Left, val, bindAssign); isAbrupt(res) {
+		return res
+	}
+	return val
+}
+
+// assign evaluates a simple or compound assignment to the reference accessed
+// by get and set.
+func (e *Evaluator) assign(node *ast.AssignmentExpression, get func() value.Value, set func(value.Value) value.Value) value.Value {
+	op := node.Operator.TokenType
+
+	if op != token.TOKEN_EQUAL {
+		old := get()
+		if isAbrupt(old) {
+			return old
+		}
+
+		switch op {
+		case token.TOKEN_AND_AND_EQUAL:
+			if !isTruthy(old) {
+				return old
+			}
+		case token.TOKEN_PIPE_PIPE_EQUAL:
+			if isTruthy(old) {
+				return old
+			}
+		case token.TOKEN_QUESTION_QUESTION_EQUAL:
+			if old != UNDEFINED && old != NULL {
+				return old
+			}
+		}
+
+		val := e.Eval(node.Right)
+		if isAbrupt(val) {
+			return val
+		}
+		if binOp, ok := compoundOperators[op]; ok {
+			val = evalBinaryExpression(&token.Token{TokenType: binOp}, old, val)
+			if isAbrupt(val) {
+				return val
+			}
+		}
+
+		if res := set(val); isAbrupt(res) {
+			return res
+		}
+		return val
+	}
+
+	val := e.Eval(node.Right)
+	if isAbrupt(val) {
+		return val
+	}
+	setFunctionName(val, node.Right, node.Left)
+
+	if res := set(val); isAbrupt(res) {
+		return res
+	}
+	return val
+}
+
+// evalUpdateExpression evaluates an increment or a decrement of a reference.
+// A postfix update evaluates to the old value, and a prefix one to the new
+// value.
+func (e *Evaluator) evalUpdateExpression(node *ast.UpdateExpression) value.Value {
+	get, set, res := e.reference(node.Argument)
+	if res != nil {
+		return res
+	} else if get == nil {
+		return newReferenceError("Invalid left-hand side expression in update operation")
+	}
+
+	old := get()
+	if isAbrupt(old) {
+		return old
+	}
+	op := token.TOKEN_PLUS
+	if node.Operator.TokenType == token.TOKEN_MINUS_MINUS {
+		op = token.TOKEN_MINUS
+	}
+	val := evalBinaryExpression(&token.Token{TokenType: op}, old, &value.Number{Value: 1})
+	if isAbrupt(val) {
+		return val
+	}
+	if res := set(val); isAbrupt(res) {
+		return res
+	}
+
+	if !node.Prefix {
+		return old
+	}
+	return val
+}
+
+func (e *Evaluator) setProperty(obj value.Value, key string, val value.Value) value.Value {
+	switch obj := obj.(type) {
+	case *value.Undefined, *value.Null:
+		return newTypeError("Cannot set properties of %s (setting '%s')", obj.Inspect(), key)
+	case *value.Object:
+		if accessor, ok := obj.Properties[key].(*value.Accessor); ok {
+			if accessor.Set == nil {
+				return nil
+			}
+			if res := e.callFunction(accessor.Set, obj, []value.Value{val}); isAbrupt(res) {
+				return res
+			}
+			return nil
+		}
+		obj.Set(key, val)
+	case *value.Array:
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 {
+			return nil
+		}
+		for len(obj.Elements) <= i {
+			obj.Elements = append(obj.Elements, UNDEFINED)
+		}
+		obj.Elements[i] = val
+	}
+
+	return nil
+}
+
+// setFunctionName names the function created by an anonymous function
+// expression by the identifier it is bound to.
+func setFunctionName(val value.Value, expr, target ast.Expression) {
+	switch expr := expr.(type) {
+	case *ast.FunctionExpression:
+		if expr.Name != nil {
+			return
+		}
+	case *ast.ArrowFunctionExpression:
+	default:
+		return
+	}
+
+	fn, ok := val.(*value.Function)
+	if !ok {
+		return
+	}
+	if id, ok := target.(*ast.Identifier); ok {
+		fn.Name = id.Value
+	}
+}
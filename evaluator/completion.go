@@ -0,0 +1,149 @@
+package evaluator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/value"
+)
+
+type ReturnValue struct {
+	Value value.Value
+}
+
+func (r *ReturnValue) Type() value.DataType {
+	return r.Value.Type()
+}
+
+func (r *ReturnValue) Inspect() string {
+	return r.Value.Inspect()
+}
+
+// Exception is a thrown value unwinding the evaluation. An Exception returned
+// from Evaluator.Eval is an uncaught error.
+type Exception struct {
+	Value value.Value
+	// Stack holds the frames of the function calls the exception was thrown
+	// through, from the innermost one.
+	Stack []StackFrame
+	// located is set when the position of the innermost frame not left yet
+	// is recorded.
+	located bool
+}
+
+// StackFrame is the position in a function, or at the top level of a program,
+// where an exception was thrown or left a function call.
+type StackFrame struct {
+	// Function is the name of the function, which is empty for anonymous
+	// functions and the top level.
+	Function string
+	File     string
+	Line     int
+	Col      int
+}
+
+func (f StackFrame) String() string {
+	file := f.File
+	if file == "" {
+		file = "<anonymous>"
+	}
+	loc := fmt.Sprintf("%s:%d:%d", file, f.Line, f.Col)
+	if f.Function == "" {
+		return loc
+	}
+	return f.Function + " (" + loc + ")"
+}
+
+func (e *Exception) Type() value.DataType {
+	return e.Value.Type()
+}
+
+func (e *Exception) Inspect() string {
+	return "Uncaught " + inspectError(e.Value)
+}
+
+// StackTrace returns the description of the exception followed by the frames
+// of its stack.
+func (e *Exception) StackTrace() string {
+	var buf strings.Builder
+	buf.WriteString(e.Inspect())
+	for _, frame := range e.Stack {
+		buf.WriteString("\n    at ")
+		buf.WriteString(frame.String())
+	}
+	return buf.String()
+}
+
+// locate records the position of node as the position of the innermost frame
+// of exc that is not located yet, mapped to the original source if a source
+// map is registered for the file.
+func (e *Evaluator) locate(exc *Exception, node ast.Node) {
+	pos := node.Pos()
+	if exc.located || !pos.IsValid() {
+		return
+	}
+	file, line, col := e.env.OriginalPosition(e.file, pos.Line, pos.Col)
+	exc.Stack = append(exc.Stack, StackFrame{File: file, Line: line, Col: col})
+	exc.located = true
+}
+
+// leaveFrame names the innermost frame of e after the function it leaves, and
+// lets the position of the frame of the caller be recorded.
+func (e *Exception) leaveFrame(name string) {
+	if e.located {
+		e.Stack[len(e.Stack)-1].Function = name
+		e.located = false
+	}
+}
+
+func inspectError(val value.Value) string {
+	obj, ok := val.(*value.Object)
+	if !ok {
+		return val.Inspect()
+	}
+
+	name, hasName := obj.Get("name")
+	message, hasMessage := obj.Get("message")
+	if !hasName || !hasMessage {
+		return val.Inspect()
+	}
+	if message.Inspect() == "" {
+		return name.Inspect()
+	}
+	return name.Inspect() + ": " + message.Inspect()
+}
+
+// breakValue and continueValue are the completions of break and continue
+// statements, with the target label or an empty string.
+type breakValue struct {
+	label string
+}
+
+func (b *breakValue) Type() value.DataType {
+	return value.DataType_Undefined
+}
+
+func (b *breakValue) Inspect() string {
+	return "undefined"
+}
+
+type continueValue struct {
+	label string
+}
+
+func (c *continueValue) Type() value.DataType {
+	return value.DataType_Undefined
+}
+
+func (c *continueValue) Inspect() string {
+	return "undefined"
+}
+
+func isAbrupt(obj value.Value) bool {
+	switch obj.(type) {
+	case *ReturnValue, *Exception, *breakValue, *continueValue:
+		return true
+	}
+	return false
+}
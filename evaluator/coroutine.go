@@ -0,0 +1,153 @@
+package evaluator
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/ghosind/gjs/runtime"
+	"github.com/ghosind/gjs/value"
+)
+
+type resumeMode int
+
+const (
+	resumeNext resumeMode = iota
+	resumeThrow
+	resumeReturn
+)
+
+type resumption struct {
+	mode  resumeMode
+	value value.Value
+}
+
+type suspendKind int
+
+const (
+	suspendAwait suspendKind = iota
+	suspendYield
+	suspendDone
+)
+
+type suspension struct {
+	kind  suspendKind
+	value value.Value
+	// panic is the value of a panic raised by the body.
+	panic any
+}
+
+// errCoroutineClosed is raised in the goroutine of a closed coroutine to
+// unwind its suspended body.
+var errCoroutineClosed = errors.New("coroutine closed")
+
+// coroutine runs the body of an async or generator function on its own
+// goroutine, so the body can be suspended at await and yield expressions and
+// resumed later. Control is handed over through unbuffered channels, so only
+// one of the caller and the body runs at any time.
+//
+// The goroutine of a suspended coroutine is released when the coroutine is
+// closed, either by the runtime being closed or by an abandoned generator
+// being garbage collected.
+type coroutine struct {
+	body    func(co *coroutine) value.Value
+	env     *runtime.Runtime
+	async   bool
+	started bool
+	done    bool
+	in      chan resumption
+	out     chan suspension
+	closed  chan struct{}
+	once    sync.Once
+	// unregister removes close from the closers of the runtime.
+	unregister func()
+}
+
+func newCoroutine(env *runtime.Runtime, body func(co *coroutine) value.Value) *coroutine {
+	return &coroutine{
+		body:   body,
+		env:    env,
+		in:     make(chan resumption),
+		out:    make(chan suspension),
+		closed: make(chan struct{}),
+	}
+}
+
+// resume runs the body until it suspends or completes. The first resumption
+// starts the body and its value is ignored. A panic in the body is raised
+// again in the caller.
+func (co *coroutine) resume(r resumption) suspension {
+	select {
+	case <-co.closed:
+		co.done = true
+	default:
+	}
+	if co.done {
+		return suspension{kind: suspendDone, value: UNDEFINED}
+	}
+	if !co.started {
+		co.started = true
+		co.unregister = co.env.OnClose(co.close)
+		go co.run()
+	}
+
+	co.in <- r
+	s := <-co.out
+	if s.kind == suspendDone {
+		co.done = true
+		co.unregister()
+	}
+	if s.panic != nil {
+		panic(s.panic)
+	}
+	return s
+}
+
+func (co *coroutine) run() {
+	defer func() {
+		if r := recover(); r != nil && r != errCoroutineClosed {
+			co.out <- suspension{kind: suspendDone, value: UNDEFINED, panic: r}
+		}
+	}()
+
+	<-co.in
+	res := co.body(co)
+	co.out <- suspension{kind: suspendDone, value: res}
+}
+
+// close releases the goroutine of a suspended coroutine by unwinding its body.
+// It must not be called while the body is running.
+func (co *coroutine) close() {
+	co.once.Do(func() {
+		close(co.closed)
+	})
+}
+
+// suspend is called from the body to hand control back to the caller, and
+// returns the resumption once the caller resumes it.
+func (co *coroutine) suspend(s suspension) resumption {
+	co.out <- s
+	select {
+	case r := <-co.in:
+		return r
+	case <-co.closed:
+		panic(errCoroutineClosed)
+	}
+}
+
+func (co *coroutine) await(val value.Value) value.Value {
+	return completionOf(co.suspend(suspension{kind: suspendAwait, value: val}))
+}
+
+func (co *coroutine) yield(val value.Value) value.Value {
+	return completionOf(co.suspend(suspension{kind: suspendYield, value: val}))
+}
+
+func completionOf(r resumption) value.Value {
+	switch r.mode {
+	case resumeThrow:
+		return &Exception{Value: r.value}
+	case resumeReturn:
+		return &ReturnValue{Value: r.value}
+	}
+	return r.value
+}
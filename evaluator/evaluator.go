@@ -2,7 +2,9 @@ package evaluator
 
 import (
 	"fmt"
+	"math"
 	"strconv"
+	"strings"
 
 	"github.com/ghosind/gjs/ast"
 	"github.com/ghosind/gjs/runtime"
@@ -11,20 +13,46 @@ import (
 )
 
 var (
-	NULL  = &value.Null{}
-	TRUE  = &value.Boolean{Value: true}
-	FALSE = &value.Boolean{Value: false}
+	UNDEFINED = &value.Undefined{}
+	NULL      = &value.Null{}
+	TRUE      = &value.Boolean{Value: true}
+	FALSE     = &value.Boolean{Value: false}
 )
 
 type Evaluator struct {
 	env *runtime.Runtime
+	// varEnv is the environment of the enclosing function or program, where
+	// var declarations are bound.
+	varEnv *runtime.Runtime
+	// co is the coroutine running the current async or generator function
+	// body, or nil when evaluating synchronous code.
+	co *coroutine
+	// strict is set when evaluating strict mode code.
+	strict bool
+	// file is the name of the source file of the code evaluated.
+	file string
 }
 
 func New(env *runtime.Runtime) *Evaluator {
-	return &Evaluator{env: env}
+	e := &Evaluator{env: env, varEnv: env}
+	// The this binding of scripts is the global object.
+	e.env.SetLexical("this", env.GlobalObject())
+	e.env.Set("globalThis", env.GlobalObject())
+	e.env.Set("undefined", UNDEFINED)
+	e.env.Set("Promise", e.newPromiseConstructor())
+	e.env.Set("Symbol", newSymbolConstructor())
+	return e
 }
 
 func (e *Evaluator) Eval(node ast.Node) value.Value {
+	res := e.eval(node)
+	if exc, ok := res.(*Exception); ok {
+		e.locate(exc, node)
+	}
+	return res
+}
+
+func (e *Evaluator) eval(node ast.Node) value.Value {
 	switch node := node.(type) {
 	case *ast.Program:
 		return e.evalProgram(node)
@@ -32,21 +60,76 @@ func (e *Evaluator) Eval(node ast.Node) value.Value {
 	case *ast.BlockStatement:
 		return e.evalBlockStatement(node)
 	case *ast.VarStatement:
-		for _, decl := range node.Declarations {
-			e.Eval(decl)
+		return e.evalDeclarations(node.Declarations, bindVar)
+	case *ast.LexicalDeclaration:
+		kind := bindLet
+		if node.Const {
+			kind = bindConst
 		}
+		return e.evalDeclarations(node.Declarations, kind)
+	case *ast.EmptyStatement:
 	case *ast.ExpressionStatement:
 		return e.Eval(node.Expression)
 	case *ast.IfStatement:
 		return e.evalIfExpression(node)
 	case *ast.ReturnStatement:
-		return e.Eval(node.Result)
+		if node.Result == nil {
+			return &ReturnValue{Value: UNDEFINED}
+		}
+		val := e.Eval(node.Result)
+		if isAbrupt(val) {
+			return val
+		}
+		return &ReturnValue{Value: val}
+	case *ast.ThrowStatement:
+		val := e.Eval(node.Argument)
+		if isAbrupt(val) {
+			return val
+		}
+		return &Exception{Value: val}
+	case *ast.FunctionDeclaration:
+		// hoisted by hoistFunctions
+	case *ast.BreakStatement:
+		if node.Label != nil {
+			return &breakValue{label: node.Label.String()}
+		}
+		return &breakValue{}
+	case *ast.ContinueStatement:
+		if node.Label != nil {
+			return &continueValue{label: node.Label.String()}
+		}
+		return &continueValue{}
+	case *ast.LabeledStatement:
+		return e.evalLabeledStatement(node, nil)
+	case *ast.WhileStatement:
+		return e.evalWhileStatement(node, nil)
+	case *ast.WithStatement:
+		return e.evalWithStatement(node)
+	case *ast.DoWhileStatement:
+		return e.evalDoWhileStatement(node, nil)
+	case *ast.ForStatement:
+		return e.evalForStatement(node, nil)
+	case *ast.ForInStatement:
+		return e.evalForInStatement(node, nil)
+	case *ast.ForOfStatement:
+		return e.evalForOfStatement(node, nil)
+	case *ast.ImportDeclaration, *ast.ExportAllDeclaration:
+		// resolved by linkModule
+	case *ast.ExportNamedDeclaration:
+		if node.Declaration != nil {
+			return e.Eval(node.Declaration)
+		}
+	case *ast.ExportDefaultDeclaration:
+		return e.evalExportDefaultDeclaration(node)
 
 	// Expression
 	case *ast.Literal:
 		switch node.Kind {
 		case ast.LitNumber:
-			val, err := strconv.ParseFloat(node.Value, 64)
+			if node.Number != nil {
+				return &value.Number{Value: *node.Number}
+			}
+			val, err := parseNumber(node.Value)
 			if err != nil {
 				return newError("could not parse %q as number", node.Value)
 			}
@@ -58,46 +141,157 @@ func (e *Evaluator) Eval(node ast.Node) value.Value {
 				return TRUE
 			}
 			return FALSE
+		case ast.LitNull:
+			return NULL
 		default:
 			return newError("unknown literal kind: %d", node.Kind)
 		}
 	case *ast.Identifier:
 		return e.evalIdentifier(node)
+	case *ast.ThisExpression:
+		if this, ok := e.env.Get("this"); ok {
+			return this
+		}
+		return UNDEFINED
 	case *ast.UnaryExpression:
 		right := e.Eval(node.Value)
+		if isAbrupt(right) {
+			return right
+		}
 		return evalUnaryExpression(node.Operator, right)
+	case *ast.UpdateExpression:
+		return e.evalUpdateExpression(node)
+	case *ast.LogicalExpression:
+		return e.evalLogicalExpression(node)
 	case *ast.BinaryExpression:
 		left := e.Eval(node.Left)
+		if isAbrupt(left) {
+			return left
+		}
 		right := e.Eval(node.Right)
+		if isAbrupt(right) {
+			return right
+		}
 		return evalBinaryExpression(node.Operator, left, right)
-
-	// Declaration
-	case *ast.VariableDeclaration:
-		val := e.Eval(node.Value)
-		e.env.Set(node.Name.Value, val)
+	case *ast.SequenceExpression:
+		var val value.Value
+		for _, expr := range node.Expressions {
+			if val = e.Eval(expr); isAbrupt(val) {
+				return val
+			}
+		}
+		return val
+	case *ast.ArrayLiteral:
+		return e.evalArrayLiteral(node)
+	case *ast.ObjectLiteral:
+		return e.evalObjectLiteral(node)
+	case *ast.MemberExpression:
+		return e.evalMemberExpression(node)
+	case *ast.FunctionExpression:
+		return e.evalFunctionExpression(node)
+	case *ast.ArrowFunctionExpression:
+		return e.newFunction("", node.Params, node.Body, node.Async, false, true, node.Strict)
+	case *ast.CallExpression:
+		return e.evalCallExpression(node)
+	case *ast.NewExpression:
+		return e.evalNewExpression(node)
+	case *ast.AwaitExpression:
+		return e.evalAwaitExpression(node)
+	case *ast.YieldExpression:
+		return e.evalYieldExpression(node)
+	case *ast.AssignmentExpression:
+		return e.evalAssignmentExpression(node)
+	case *ast.ImportExpression:
+		return e.evalImportExpression(node)
+	case *ast.MetaProperty:
+		return e.evalMetaProperty(node)
+	case *ast.BadStatement, *ast.BadExpression:
+		// The placeholders of a program parsed in tolerant mode throw when
+		// they are reached.
+		return newErrorObject("SyntaxError", "Invalid or unexpected token")
+	case *ast.JSXElement, *ast.JSXFragment:
+		// JSX is lowered to function calls by the transform package before
+		// evaluation.
+		return newErrorObject("SyntaxError", "Unexpected JSX, which must be transformed before evaluation")
 	}
 
 	return nil
 }
 
 func (e *Evaluator) evalProgram(program *ast.Program) value.Value {
-	var res value.Value
-	for _, statement := range program.Statements {
-		res = e.Eval(statement)
+	if program.SourceType == ast.SourceModule {
+		_, promise := e.EvalModule(program)
+		return promise
+	}
+
+	e.strict = program.Strict
+	e.file = program.FileName
+	res := e.evalStatements(program.Statements)
+	if ret, ok := res.(*ReturnValue); ok {
+		return ret.Value
 	}
 	return res
 }
 
 func (e *Evaluator) evalBlockStatement(block *ast.BlockStatement) value.Value {
+	return e.enclosed().evalStatements(block.StatementList)
+}
+
+// enclosed returns an evaluator for a new block scope.
+func (e *Evaluator) enclosed() *Evaluator {
+	return &Evaluator{
+		env:    runtime.NewEnclosedEnvironment(e.env),
+		varEnv: e.varEnv,
+		co:     e.co,
+		strict: e.strict,
+		file:   e.file,
+	}
+}
+
+func (e *Evaluator) evalWithStatement(node *ast.WithStatement) value.Value {
+	val := e.Eval(node.Object)
+	if isAbrupt(val) {
+		return val
+	}
+
+	var obj *value.Object
+	switch val := val.(type) {
+	case *value.Undefined, *value.Null:
+		return newTypeError("Cannot convert undefined or null to object")
+	case *value.Object:
+		obj = val
+	default:
+		obj = value.NewObject()
+	}
+
+	inner := &Evaluator{
+		env:    runtime.NewObjectEnvironment(obj, e.env),
+		varEnv: e.varEnv,
+		co:     e.co,
+		file:   e.file,
+	}
+	return inner.Eval(node.Body)
+}
+
+func (e *Evaluator) evalStatements(statements []ast.Statement) value.Value {
 	var res value.Value
-	for _, statement := range block.StatementList {
-		res = e.Eval(statement)
+	e.hoistFunctions(statements)
+	for _, statement := range statements {
+		val := e.Eval(statement)
+		if isAbrupt(val) {
+			return val
+		} else if val != nil {
+			res = val
+		}
 	}
 	return res
 }
 
 func (e *Evaluator) evalIfExpression(ie *ast.IfStatement) value.Value {
 	condition := e.Eval(ie.Condition)
+	if isAbrupt(condition) {
+		return condition
+	}
 	if isTruthy(condition) {
 		return e.Eval(ie.TrueBranch)
 	} else if ie.FalseBranch != nil {
@@ -106,11 +300,46 @@ func (e *Evaluator) evalIfExpression(ie *ast.IfStatement) value.Value {
 	return nil
 }
 
+// parseNumber parses a numeric literal. A legacy octal literal such as 010,
+// only allowed in sloppy mode code, is parsed in base 8.
+func parseNumber(lit string) (float64, error) {
+	if len(lit) > 1 && lit[0] == '0' && strings.Trim(lit, "01234567") == "" {
+		val, err := strconv.ParseInt(lit[1:], 8, 64)
+		return float64(val), err
+	}
+	return strconv.ParseFloat(lit, 64)
+}
+
 func (e *Evaluator) evalIdentifier(node *ast.Identifier) value.Value {
 	if val, ok := e.env.Get(node.Value); ok {
 		return val
 	}
-	return newError("identifier not found: " + node.Value)
+	return newReferenceError("%s is not defined", node.Value)
+}
+
+// evalLogicalExpression evaluates the right operand of a logical expression
+// only if the value of the left one does not decide the result.
+func (e *Evaluator) evalLogicalExpression(node *ast.LogicalExpression) value.Value {
+	left := e.Eval(node.Left)
+	if isAbrupt(left) {
+		return left
+	}
+
+	switch node.Operator.TokenType {
+	case token.TOKEN_AND_AND:
+		if !isTruthy(left) {
+			return left
+		}
+	case token.TOKEN_PIPE_PIPE:
+		if isTruthy(left) {
+			return left
+		}
+	case token.TOKEN_QUESTION_QUESTION:
+		if left != UNDEFINED && left != NULL {
+			return left
+		}
+	}
+	return e.Eval(node.Right)
 }
 
 func evalUnaryExpression(operator *token.Token, right value.Value) value.Value {
@@ -159,9 +388,9 @@ func evalBinaryExpression(operator *token.Token, left, right value.Value) value.
 	switch {
 	case left.Type() == value.DataType_Number && right.Type() == value.DataType_Number:
 		return evalNumberBinaryExpression(operator, left, right)
-	case operator.TokenType == token.TOKEN_EQUAL_EQUAL:
+	case operator.TokenType == token.TOKEN_EQUAL_EQUAL, operator.TokenType == token.TOKEN_EQUAL_EQUAL_EQUAL:
 		return nativeBoolToBooleanObject(left == right)
-	case operator.TokenType == token.TOKEN_BANG_EQUAL:
+	case operator.TokenType == token.TOKEN_BANG_EQUAL, operator.TokenType == token.TOKEN_BANG_EQUAL_EQUAL:
 		return nativeBoolToBooleanObject(left != right)
 	case left.Type() != right.Type():
 		return newError("type mismatch: %s %s %s", left.Type(), operator.TokenType, right.Type())
@@ -183,30 +412,64 @@ func evalNumberBinaryExpression(operator *token.Token, left, right value.Value)
 		return &value.Number{Value: lv * rv}
 	case token.TOKEN_SLASH:
 		return &value.Number{Value: lv / rv}
+	case token.TOKEN_PERCENT:
+		return &value.Number{Value: math.Mod(lv, rv)}
+	case token.TOKEN_STAR_STAR:
+		return &value.Number{Value: math.Pow(lv, rv)}
 	case token.TOKEN_LESS:
 		return nativeBoolToBooleanObject(lv < rv)
 	case token.TOKEN_GREATER:
 		return nativeBoolToBooleanObject(lv > rv)
-	case token.TOKEN_EQUAL_EQUAL:
+	case token.TOKEN_LESS_EQUAL:
+		return nativeBoolToBooleanObject(lv <= rv)
+	case token.TOKEN_GREATER_EQUAL:
+		return nativeBoolToBooleanObject(lv >= rv)
+	case token.TOKEN_EQUAL_EQUAL, token.TOKEN_EQUAL_EQUAL_EQUAL:
 		return nativeBoolToBooleanObject(lv == rv)
-	case token.TOKEN_BANG_EQUAL:
+	case token.TOKEN_BANG_EQUAL, token.TOKEN_BANG_EQUAL_EQUAL:
 		return nativeBoolToBooleanObject(lv != rv)
+	case token.TOKEN_AND:
+		return &value.Number{Value: float64(toInt32(lv) & toInt32(rv))}
+	case token.TOKEN_PIPE:
+		return &value.Number{Value: float64(toInt32(lv) | toInt32(rv))}
+	case token.TOKEN_HAT:
+		return &value.Number{Value: float64(toInt32(lv) ^ toInt32(rv))}
+	case token.TOKEN_LESS_LESS:
+		return &value.Number{Value: float64(toInt32(lv) << (uint32(toInt32(rv)) & 31))}
+	case token.TOKEN_GREATER_GREATER:
+		return &value.Number{Value: float64(toInt32(lv) >> (uint32(toInt32(rv)) & 31))}
+	case token.TOKEN_GREATER_GREATER_GREATER:
+		return &value.Number{Value: float64(uint32(toInt32(lv)) >> (uint32(toInt32(rv)) & 31))}
 	default:
 		return newError("unknown operator: %s %s %s", left.Type(), operator.TokenType, right.Type())
 	}
 }
 
+// toInt32 converts a number to a 32-bit integer as the bitwise operators do.
+func toInt32(num float64) int32 {
+	if math.IsNaN(num) || math.IsInf(num, 0) {
+		return 0
+	}
+	return int32(uint32(int64(math.Mod(math.Trunc(num), 1<<32))))
+}
+
 func isTruthy(obj value.Value) bool {
 	switch obj {
-	case NULL:
+	case UNDEFINED, NULL:
 		return false
 	case TRUE:
 		return true
 	case FALSE:
 		return false
-	default:
-		return true
 	}
+
+	switch obj := obj.(type) {
+	case *value.Number:
+		return obj.Value != 0 && !math.IsNaN(obj.Value)
+	case *value.String:
+		return obj.Value != ""
+	}
+	return true
 }
 
 func nativeBoolToBooleanObject(input bool) *value.Boolean {
@@ -217,18 +480,25 @@ func nativeBoolToBooleanObject(input bool) *value.Boolean {
 }
 
 func newError(format string, a ...interface{}) value.Value {
-	// TODO: define an error object type
-	return &value.Object{
-		Properties: map[string]value.Value{
-			"message": &value.String{Value: fmt.Sprintf(format, a...)},
-		},
-	}
+	return newErrorObject("Error", fmt.Sprintf(format, a...))
+}
+
+func newTypeError(format string, a ...interface{}) value.Value {
+	return newErrorObject("TypeError", fmt.Sprintf(format, a...))
+}
+
+func newReferenceError(format string, a ...interface{}) value.Value {
+	return newErrorObject("ReferenceError", fmt.Sprintf(format, a...))
+}
+
+func newErrorObject(name, message string) *Exception {
+	obj := value.NewObject()
+	obj.Set("name", &value.String{Value: name})
+	obj.Set("message", &value.String{Value: message})
+	return &Exception{Value: obj}
 }
 
 func isError(obj value.Value) bool {
-	// TODO: define an error object type and check for it here
-	if obj != nil {
-		return obj.Type() == value.DataType_Object && obj.(*value.Object).Properties["message"] != nil
-	}
-	return false
+	_, ok := obj.(*Exception)
+	return ok
 }
@@ -0,0 +1,620 @@
+package evaluator
+
+import (
+	"bytes"
+	goruntime "runtime"
+	"testing"
+	"time"
+
+	"github.com/ghosind/go-assert"
+
+	"github.com/ghosind/gjs/lexer"
+	"github.com/ghosind/gjs/parser"
+	"github.com/ghosind/gjs/printer"
+	"github.com/ghosind/gjs/runtime"
+	"github.com/ghosind/gjs/sourcemap"
+	"github.com/ghosind/gjs/value"
+)
+
+func testEval(a *assert.Assertion, input string) (value.Value, *runtime.Runtime) {
+	p := parser.New(lexer.New([]byte(input)))
+	program, err := p.ParseProgram()
+	a.NilNow(err)
+
+	env := runtime.New()
+	return New(env).Eval(program), env
+}
+
+// testEvalAsync evaluates the input, drains the job queue and returns the
+// values passed to the host function report in order.
+func testEvalAsync(a *assert.Assertion, input string) []string {
+	p := parser.New(lexer.New([]byte(input)))
+	program, err := p.ParseProgram()
+	a.NilNow(err)
+
+	reports := make([]string, 0)
+	env := runtime.New()
+	env.Set("report", newNativeFunction("report", func(this value.Value, args []value.Value) value.Value {
+		reports = append(reports, argument(args, 0).Inspect())
+		return UNDEFINED
+	}))
+
+	res := New(env).Eval(program)
+	a.NotTrueNow(isError(res), res)
+	env.Jobs().Run()
+
+	return reports
+}
+
+func TestAsyncFunction(t *testing.T) {
+	a := assert.New(t)
+
+	res, env := testEval(a, `
+async function f(x) { var y = await x; return y; }
+f(Promise.resolve(5));
+`)
+	a.EqualNow(res.Inspect(), "Promise { <pending> }")
+	env.Jobs().Run()
+	a.EqualNow(res.Inspect(), "Promise { 5 }")
+}
+
+func TestAsyncFunctionRejection(t *testing.T) {
+	a := assert.New(t)
+
+	res, env := testEval(a, `
+async function f() { await Promise.reject(1); report("unreachable"); }
+f();
+`)
+	env.Jobs().Run()
+	a.EqualNow(res.Inspect(), "Promise { <rejected> 1 }")
+
+	reports := testEvalAsync(a, `
+var f = async () => { throw 2; };
+f().catch(e => report(e));
+`)
+	a.DeepEqualNow(reports, []string{"2"})
+}
+
+func TestAwaitOrdering(t *testing.T) {
+	a := assert.New(t)
+
+	reports := testEvalAsync(a, `
+async function f() {
+  report("f start");
+  await undefined;
+  report("f resumed");
+}
+f();
+Promise.resolve(1).then(v => report("then"));
+report("sync");
+`)
+	a.DeepEqualNow(reports, []string{"f start", "sync", "f resumed", "then"})
+}
+
+func TestAwaitThenable(t *testing.T) {
+	a := assert.New(t)
+
+	reports := testEvalAsync(a, `
+var thenable = { then(resolve) { resolve(42); } };
+(async () => report(await thenable))();
+`)
+	a.DeepEqualNow(reports, []string{"42"})
+}
+
+func TestAsyncMethod(t *testing.T) {
+	a := assert.New(t)
+
+	reports := testEvalAsync(a, `
+var o = { v: 7, async get() { return this.v; } };
+o.get().then(v => report(v));
+`)
+	a.DeepEqualNow(reports, []string{"7"})
+}
+
+func TestPromiseFinally(t *testing.T) {
+	a := assert.New(t)
+
+	reports := testEvalAsync(a, `
+Promise.reject(3).finally(() => report("finally")).catch(e => report(e));
+`)
+	a.DeepEqualNow(reports, []string{"finally", "3"})
+}
+
+func TestGenerator(t *testing.T) {
+	a := assert.New(t)
+
+	res, _ := testEval(a, `
+function* g() { var x = yield 1; yield x; return 3; }
+var it = g();
+[it.next(), it.next(2), it.next(), it.next()];
+`)
+	a.EqualNow(res.Inspect(),
+		"[{value: 1, done: false}, {value: 2, done: false}, {value: 3, done: true}, {value: undefined, done: true}]")
+}
+
+func TestAsyncGenerator(t *testing.T) {
+	a := assert.New(t)
+
+	reports := testEvalAsync(a, `
+async function* g() {
+  yield 1;
+  var x = await Promise.resolve(2);
+  yield x;
+}
+var it = g();
+it.next().then(r => report(r.value));
+it.next().then(r => report(r.value));
+it.next().then(r => report(r.done));
+`)
+	a.DeepEqualNow(reports, []string{"1", "2", "true"})
+}
+
+func TestAsyncGeneratorDelegate(t *testing.T) {
+	a := assert.New(t)
+
+	reports := testEvalAsync(a, `
+async function* inner() { yield 1; yield 2; }
+async function* outer() { yield* inner(); yield 3; }
+async function run(it) {
+  report((await it.next()).value);
+  report((await it.next()).value);
+  report((await it.next()).value);
+}
+run(outer());
+`)
+	a.DeepEqualNow(reports, []string{"1", "2", "3"})
+}
+
+func TestForInStatement(t *testing.T) {
+	a := assert.New(t)
+
+	reports := testEvalAsync(a, `
+var o = { a: 1, b: 2, c: 3 };
+for (var k in o) report(k);
+for (const i in ["x", "y"]) report(i);
+for (k in null) report(k);
+for (k in { b: 1, a: 2, 10: 3, 2: 4, "01": 5 }) report(k);
+`)
+	a.DeepEqualNow(reports, []string{"a", "b", "c", "0", "1", "2", "10", "b", "a", "01"})
+}
+
+func TestForOfStatement(t *testing.T) {
+	a := assert.New(t)
+
+	reports := testEvalAsync(a, `
+for (const x of [1, 2]) report(x);
+for (let c of "hi") report(c);
+function* g() { yield 3; yield 4; }
+for (var v of g()) report(v);
+var o = {};
+for ([o.a, o.b] of [[5, 6]]) report(o.a + o.b);
+for (const { x, y = 8 } of [{ x: 7 }]) report(y);
+`)
+	a.DeepEqualNow(reports, []string{"1", "2", "h", "i", "3", "4", "11", "8"})
+}
+
+func TestForOfIteratorProtocol(t *testing.T) {
+	a := assert.New(t)
+
+	reports := testEvalAsync(a, `
+var iterable = {
+  [Symbol.iterator]() {
+    var n = 0;
+    return {
+      next() { n += 1; return { value: n, done: n > 3 }; },
+      return() { report("closed"); return {}; },
+    };
+  },
+};
+for (const x of iterable) report(x);
+for (const x of iterable) { report(x); break; }
+report([...iterable]);
+`)
+	a.DeepEqualNow(reports, []string{"1", "2", "3", "1", "closed", "[1, 2, 3]"})
+
+	res, _ := testEval(a, `for (const x of 1) {}`)
+	a.EqualNow(res.Inspect(), "Uncaught TypeError: 1 is not iterable")
+}
+
+func TestForAwaitOfStatement(t *testing.T) {
+	a := assert.New(t)
+
+	reports := testEvalAsync(a, `
+async function* g() { yield 1; yield 2; }
+async function f() {
+  for await (const x of g()) report(x);
+  for await (const x of [Promise.resolve(3), 4]) report(x);
+}
+f();
+`)
+	a.DeepEqualNow(reports, []string{"1", "2", "3", "4"})
+}
+
+func TestLabeledStatement(t *testing.T) {
+	a := assert.New(t)
+
+	reports := testEvalAsync(a, `
+outer: for (const i of [1, 2, 3]) {
+  for (const j of [1, 2, 3]) {
+    if (j == 2) continue outer;
+    if (i == 3) break outer;
+    report(i * 10 + j);
+  }
+}
+block: {
+  report("in");
+  break block;
+}
+`)
+	a.DeepEqualNow(reports, []string{"11", "21", "in"})
+}
+
+func TestForStatementBindings(t *testing.T) {
+	a := assert.New(t)
+
+	reports := testEvalAsync(a, `
+var fns = [];
+for (let i = 0; i < 3; i += 1) fns = [...fns, () => i];
+for (const fn of fns) report(fn());
+var n = 0;
+while (n < 2) n += 1;
+report(n);
+`)
+	a.DeepEqualNow(reports, []string{"0", "1", "2", "2"})
+
+	res, _ := testEval(a, `const c = 1; c = 2;`)
+	a.EqualNow(res.Inspect(), "Uncaught TypeError: Assignment to constant variable.")
+}
+
+func TestModuleTopLevelAwait(t *testing.T) {
+	a := assert.New(t)
+
+	p := parser.New(lexer.New([]byte(`
+export const x = await Promise.resolve(1);
+export default async function () { return x; }
+for await (const y of [Promise.resolve(2)]) report(y);
+report(x);
+`)))
+	program, err := p.ParseModule()
+	a.NilNow(err)
+
+	reports := make([]string, 0)
+	env := runtime.New()
+	env.Set("report", newNativeFunction("report", func(this value.Value, args []value.Value) value.Value {
+		reports = append(reports, argument(args, 0).Inspect())
+		return UNDEFINED
+	}))
+
+	moduleEnv, res := New(env).EvalModule(program)
+	a.EqualNow(res.Inspect(), "Promise { <pending> }")
+	env.Jobs().Run()
+	a.EqualNow(res.Inspect(), "Promise { undefined }")
+	a.DeepEqualNow(reports, []string{"2", "1"})
+
+	a.EqualNow(moduleEnv.Module().Default.Inspect(), "[AsyncFunction: default]")
+	x, ok := moduleEnv.Get("x")
+	a.TrueNow(ok)
+	a.EqualNow(x.Inspect(), "1")
+	_, ok = env.Get("x")
+	a.NotTrueNow(ok)
+}
+
+func TestModuleEnvironment(t *testing.T) {
+	a := assert.New(t)
+
+	env := runtime.New()
+	e := New(env)
+	metas := make([]value.Value, 0)
+	env.Set("report", newNativeFunction("report", func(this value.Value, args []value.Value) value.Value {
+		metas = append(metas, argument(args, 0))
+		return UNDEFINED
+	}))
+
+	for i := 0; i < 2; i++ {
+		p := parser.New(lexer.New([]byte(`
+report(import.meta);
+report(this);
+function f() { return import.meta; }
+report(f() == import.meta);
+export default function g() {}
+`)))
+		program, err := p.ParseModule()
+		a.NilNow(err)
+
+		moduleEnv, _ := e.EvalModule(program)
+		env.Jobs().Run()
+		a.EqualNow(moduleEnv.Module().Default.Inspect(), "[Function: g]")
+	}
+
+	a.EqualNow(len(metas), 6)
+	a.EqualNow(metas[1].Inspect(), "undefined")
+	a.EqualNow(metas[2].Inspect(), "true")
+	a.TrueNow(metas[0] != metas[3])
+	_, ok := env.Get("g")
+	a.NotTrueNow(ok)
+}
+
+func TestModuleImport(t *testing.T) {
+	a := assert.New(t)
+
+	p := parser.New(lexer.New([]byte(`import a from "a";`)))
+	program, err := p.ParseModule()
+	a.NilNow(err)
+
+	env := runtime.New()
+	res := New(env).Eval(program)
+	a.EqualNow(res.Inspect(), "Promise { <rejected> {name: Error, message: Cannot find module 'a'} }")
+
+	reports := testEvalAsync(a, `import("b").catch(e => report(e.message));`)
+	a.DeepEqualNow(reports, []string{"Cannot find module 'b'"})
+}
+
+func TestStrictMode(t *testing.T) {
+	a := assert.New(t)
+
+	reports := testEvalAsync(a, `
+function sloppy() { return this; }
+function strict() { "use strict"; return this; }
+report(sloppy() == globalThis);
+report(strict());
+x = 1;
+report(x);
+var o = { y: 2 };
+with (o) { y = 3; report(y); }
+report(o.y);
+report(010);
+`)
+	a.DeepEqualNow(reports, []string{"true", "undefined", "1", "3", "3", "8"})
+
+	res, _ := testEval(a, `"use strict"; z = 1;`)
+	a.EqualNow(res.Inspect(), "Uncaught ReferenceError: z is not defined")
+
+	res, _ = testEval(a, `function f() { "use strict"; w = 1; } f();`)
+	a.EqualNow(res.Inspect(), "Uncaught ReferenceError: w is not defined")
+
+	res, _ = testEval(a, `with (undefined) ;`)
+	a.EqualNow(res.Inspect(), "Uncaught TypeError: Cannot convert undefined or null to object")
+}
+
+func TestGlobalObject(t *testing.T) {
+	a := assert.New(t)
+
+	reports := testEvalAsync(a, `
+"use strict";
+report(this == globalThis);
+function f() { return this; }
+report(f());
+var x = 3;
+let l = 4;
+report(globalThis.x);
+report(globalThis.l);
+globalThis.y = 5;
+report(y);
+`)
+	a.DeepEqualNow(reports, []string{"true", "undefined", "3", "undefined", "5"})
+
+	reports = testEvalAsync(a, `
+function f() { return this.x; }
+var x = 3;
+report(f());
+z = 5;
+report(globalThis.z);
+report(globalThis.globalThis == globalThis);
+`)
+	a.DeepEqualNow(reports, []string{"3", "5", "true"})
+
+	res, _ := testEval(a, `var o = { a: 1 }; o.self = o; o;`)
+	a.EqualNow(res.Inspect(), "{a: 1, self: [Circular]}")
+}
+
+func TestCoroutineClose(t *testing.T) {
+	a := assert.New(t)
+
+	p := parser.New(lexer.New([]byte(`
+function* g() { yield 1; yield 2; }
+var pending = new Promise(() => {});
+async function f() { await pending; }
+var gens = [];
+for (let i = 0; i < 100; i += 1) {
+  const it = g();
+  it.next();
+  gens = [...gens, it];
+  f();
+}
+`)))
+	program, err := p.ParseProgram()
+	a.NilNow(err)
+
+	before := goruntime.NumGoroutine()
+	env := runtime.New()
+	New(env).Eval(program)
+	env.Jobs().Run()
+	a.TrueNow(goruntime.NumGoroutine() >= before+200)
+
+	env.Close()
+	for i := 0; i < 100 && goruntime.NumGoroutine() > before; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	a.TrueNow(goruntime.NumGoroutine() <= before)
+}
+
+func TestCoroutinePanic(t *testing.T) {
+	a := assert.New(t)
+
+	p := parser.New(lexer.New([]byte(`function* g() { fail(); } g().next();`)))
+	program, err := p.ParseProgram()
+	a.NilNow(err)
+
+	env := runtime.New()
+	env.Set("fail", newNativeFunction("fail", func(this value.Value, args []value.Value) value.Value {
+		panic("host failure")
+	}))
+
+	recovered := func() (r any) {
+		defer func() {
+			r = recover()
+		}()
+		New(env).Eval(program)
+		return nil
+	}()
+	a.EqualNow(recovered, "host failure")
+}
+
+func TestBadStatement(t *testing.T) {
+	a := assert.New(t)
+
+	p := parser.New(lexer.New([]byte("var a = 1;\nfoo(;\na = 2;\n")))
+	program, errs := p.ParseProgramTolerant()
+	a.EqualNow(len(errs), 1)
+
+	env := runtime.New()
+	res := New(env).Eval(program)
+	exception, ok := res.(*Exception)
+	a.TrueNow(ok)
+	a.EqualNow(exception.Value.Inspect(), "{name: SyntaxError, message: Invalid or unexpected token}")
+	val, _ := env.Get("a")
+	a.EqualNow(val.Inspect(), "1")
+}
+
+func TestBinaryOperators(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1 || 2 || 3", "1"},
+		{"0 || 0 || 3", "3"},
+		{"1 && 2 && 0", "0"},
+		{"null ?? 2", "2"},
+		{"0 ?? 2", "0"},
+		{"2 ** 3 ** 2", "512"},
+		{"(2 ** 3) ** 2", "64"},
+		{"1 + 2 * 3 - 4 / 2", "5"},
+		{"7 % 4", "3"},
+		{"1 < 2 == 2 >= 3", "false"},
+		{"5 & 3 | 8 ^ 1", "9"},
+		{"1 << 4 >> 2", "4"},
+		{"-1 >>> 28", "15"},
+		{"1, 2, 3", "3"},
+	}
+
+	for _, test := range tests {
+		res, _ := testEval(a, test.input)
+		a.EqualNow(res.Inspect(), test.expected, test.input)
+	}
+}
+
+func TestShortCircuitEvaluation(t *testing.T) {
+	a := assert.New(t)
+
+	res, _ := testEval(a, `
+var count = 0;
+function f() { count = count + 1; return true; }
+false && f();
+true || f();
+1 ?? f();
+count;
+`)
+	a.EqualNow(res.Inspect(), "0")
+
+	res, _ = testEval(a, `
+var count = 0;
+function f() { count = count + 1; return count; }
+var a = [true && f(), false || f(), null ?? f(), undefined ?? f(), 0 || null, 0 && f(), count];
+a;
+`)
+	a.EqualNow(res.Inspect(), "[1, 2, 3, 4, null, 0, 4]")
+}
+
+func TestUpdateExpression(t *testing.T) {
+	a := assert.New(t)
+
+	res, _ := testEval(a, `
+var i = 0;
+var o = { n: 1 };
+var a = [i++, i, ++i, i--, --i, o.n++, o.n];
+a;
+`)
+	a.EqualNow(res.Inspect(), "[0, 1, 2, 2, 0, 1, 2]")
+
+	res, _ = testEval(a, `
+var calls = 0;
+var o = { n: 1 };
+function key() { calls = calls + 1; return "n"; }
+var a = [o[key()]++, ++o[key()], o[key()]--, --o[key()], o.n, calls];
+a;
+`)
+	a.EqualNow(res.Inspect(), "[1, 3, 3, 1, 1, 4]")
+
+	res, _ = testEval(a, `
+var sum = 0;
+for (var i = 0; i < 4; i++) sum = sum + i;
+sum;
+`)
+	a.EqualNow(res.Inspect(), "6")
+}
+
+func TestTypeScript(t *testing.T) {
+	a := assert.New(t)
+
+	program, err := parser.NewWithOptions(lexer.New([]byte(`
+enum Color { Red, Green = 4, Blue }
+enum Color { Purple = 10 }
+enum Dir { Up = "UP", Down = "DOWN" }
+interface Point { x: number; y?: number }
+type Key = number | string;
+function sum(xs: number[], init: number = 0): number {
+  let s: number = init;
+  for (let i = 0; i < xs.length; i++) s += xs[i]!;
+  return s;
+}
+const id = <T,>(x: T): T => x;
+const total = sum([1, 2, 3] as number[]) satisfies Key;
+[Color.Red, Color.Blue, Color[5], Color.Purple, Dir.Up, Dir["Down"], id<number>(total)];
+`)), parser.Options{TypeScript: true}).Parse()
+	a.NilNow(err)
+
+	res := New(runtime.New()).Eval(program)
+	a.EqualNow(res.Inspect(), "[0, 5, Blue, 10, UP, DOWN, 6]")
+}
+
+func TestStackTrace(t *testing.T) {
+	a := assert.New(t)
+
+	src := `function check(value) {
+  if (value > 1) {
+    throw value;
+  }
+}
+var run = (list) => {
+  for (const item of list) check(item);
+};
+run([1, 2]);
+`
+	expected := "Uncaught 2\n    at check (main.js:3:5)\n    at run (main.js:7:28)\n    at main.js:9:1"
+
+	program, err := parser.NewWithOptions(lexer.New([]byte(src)), parser.Options{FileName: "main.js"}).Parse()
+	a.NilNow(err)
+	exc, ok := New(runtime.New()).Eval(program).(*Exception)
+	a.TrueNow(ok)
+	a.EqualNow(exc.StackTrace(), expected)
+
+	// The positions in a program printed from the source are reported as
+	// the positions in the source.
+	g := sourcemap.NewGenerator("main.min.js")
+	buf := new(bytes.Buffer)
+	a.NilNow((&printer.Config{Compact: true, SourceMap: g}).Fprint(buf, program))
+	program, err = parser.NewWithOptions(lexer.New(buf.Bytes()), parser.Options{FileName: "main.min.js"}).Parse()
+	a.NilNow(err)
+
+	env := runtime.New()
+	env.SetSourceMap("main.min.js", g.Map())
+	exc, ok = New(env).Eval(program).(*Exception)
+	a.TrueNow(ok)
+	a.EqualNow(exc.StackTrace(), expected)
+
+	res, _ := testEval(a, "function f() { null.x; }\nf();")
+	a.EqualNow(res.(*Exception).StackTrace(),
+		"Uncaught TypeError: Cannot read properties of null (reading 'x')\n    at f (<anonymous>:1:16)\n    at <anonymous>:2:1")
+}
@@ -0,0 +1,250 @@
+package evaluator
+
+import (
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/runtime"
+	"github.com/ghosind/gjs/value"
+)
+
+func (e *Evaluator) newFunction(name string, params []ast.Expression, body ast.Node, async, generator, arrow, strict bool) *value.Function {
+	return &value.Function{
+		Name:      name,
+		Params:    params,
+		Body:      body,
+		Env:       e.env,
+		Async:     async,
+		Generator: generator,
+		Arrow:     arrow,
+		Strict:    strict,
+		File:      e.file,
+	}
+}
+
+func (e *Evaluator) hoistFunctions(statements []ast.Statement) {
+	for _, stmt := range statements {
+		var decl *ast.FunctionDeclaration
+		isDefault := false
+		switch stmt := stmt.(type) {
+		case *ast.FunctionDeclaration:
+			decl = stmt
+		case *ast.ExportNamedDeclaration:
+			decl, _ = stmt.Declaration.(*ast.FunctionDeclaration)
+		case *ast.ExportDefaultDeclaration:
+			decl, isDefault = stmt.Declaration.(*ast.FunctionDeclaration)
+		}
+		if decl == nil || decl.Name == nil {
+			continue
+		}
+		fn := e.newFunction(decl.Name.Value, decl.Params, decl.Body, decl.Async, decl.Generator, false, decl.Strict)
+		e.env.Set(decl.Name.Value, fn)
+		if isDefault {
+			e.env.Module().Default = fn
+		}
+	}
+}
+
+func (e *Evaluator) evalFunctionExpression(node *ast.FunctionExpression) value.Value {
+	if node.Name == nil {
+		return e.newFunction("", node.Params, node.Body, node.Async, node.Generator, false, node.Strict)
+	}
+
+	// The name of a function expression is only visible inside its body.
+	env := runtime.NewEnclosedEnvironment(e.env)
+	fn := e.newFunction(node.Name.Value, node.Params, node.Body, node.Async, node.Generator, false, node.Strict)
+	fn.Env = env
+	env.Set(node.Name.Value, fn)
+
+	return fn
+}
+
+func (e *Evaluator) evalCallExpression(node *ast.CallExpression) value.Value {
+	var this value.Value = UNDEFINED
+	var callee value.Value
+
+	if member, ok := node.Callee.(*ast.MemberExpression); ok {
+		this = e.Eval(member.Object)
+		if isAbrupt(this) {
+			return this
+		}
+		key := e.evalPropertyKey(member)
+		if isAbrupt(key) {
+			return key
+		}
+		callee = e.getProperty(this, key.(*value.String).Value)
+	} else {
+		callee = e.Eval(node.Callee)
+	}
+	if isAbrupt(callee) {
+		return callee
+	}
+
+	args := e.evalArguments(node.Arguments)
+	if len(args) == 1 && isAbrupt(args[0]) {
+		return args[0]
+	}
+
+	if !isCallable(callee) {
+		return newTypeError("%s is not a function", node.Callee.String())
+	}
+
+	return e.callFunction(callee, this, args)
+}
+
+func (e *Evaluator) evalNewExpression(node *ast.NewExpression) value.Value {
+	callee := e.Eval(node.Callee)
+	if isAbrupt(callee) {
+		return callee
+	}
+
+	args := e.evalArguments(node.Arguments)
+	if len(args) == 1 && isAbrupt(args[0]) {
+		return args[0]
+	}
+
+	switch fn := callee.(type) {
+	case *value.NativeFunction:
+		if fn.Construct != nil {
+			return fn.Construct(args)
+		}
+	case *value.Function:
+		if fn.Arrow || fn.Async || fn.Generator {
+			break
+		}
+		obj := value.NewObject()
+		res := e.invoke(fn, obj, args, nil)
+		if isAbrupt(res) {
+			return res
+		} else if res.Type() == value.DataType_Object {
+			return res
+		}
+		return obj
+	}
+
+	return newTypeError("%s is not a constructor", node.Callee.String())
+}
+
+// evalArguments evaluates the argument list of a call. If an argument throws,
+// the returned slice holds only the exception.
+func (e *Evaluator) evalArguments(exprs []ast.Expression) []value.Value {
+	args := make([]value.Value, 0, len(exprs))
+
+	for _, expr := range exprs {
+		if spread, ok := expr.(*ast.SpreadElement); ok {
+			val := e.Eval(spread.Value)
+			if isAbrupt(val) {
+				return []value.Value{val}
+			}
+			list, res := e.iterableToList(val, spread.Value.String())
+			if res != nil {
+				return []value.Value{res}
+			}
+			args = append(args, list...)
+			continue
+		}
+
+		val := e.Eval(expr)
+		if isAbrupt(val) {
+			return []value.Value{val}
+		}
+		args = append(args, val)
+	}
+
+	return args
+}
+
+func (e *Evaluator) callFunction(callee, this value.Value, args []value.Value) value.Value {
+	switch fn := callee.(type) {
+	case *value.NativeFunction:
+		return fn.Fn(this, args)
+	case *value.Function:
+		switch {
+		case fn.Async && fn.Generator:
+			return e.newAsyncGenerator(fn, this, args)
+		case fn.Async:
+			return e.callAsyncFunction(fn, this, args)
+		case fn.Generator:
+			return e.newGenerator(fn, this, args)
+		}
+		return e.invoke(fn, this, args, nil)
+	}
+
+	return newTypeError("%s is not a function", callee.Inspect())
+}
+
+// invoke evaluates the body of fn in a new environment. The body runs on the
+// coroutine co if it is an async or generator function.
+func (e *Evaluator) invoke(fn *value.Function, this value.Value, args []value.Value, co *coroutine) value.Value {
+	env := runtime.NewEnclosedEnvironment(fn.Env.(*runtime.Runtime))
+	if !fn.Arrow {
+		// A sloppy mode function called without a receiver gets the global
+		// object as this.
+		if !fn.Strict && (this == UNDEFINED || this == NULL) {
+			this = env.GlobalObject()
+		}
+		env.Set("this", this)
+	}
+	inner := &Evaluator{env: env, varEnv: env, co: co, strict: fn.Strict, file: fn.File}
+
+	res := inner.evalFunctionBody(fn, args)
+	if exc, ok := res.(*Exception); ok {
+		inner.locate(exc, fn.Body)
+		exc.leaveFrame(fn.Name)
+	}
+	return res
+}
+
+func (e *Evaluator) evalFunctionBody(fn *value.Function, args []value.Value) value.Value {
+	if res := e.bindParams(fn.Params, args); isAbrupt(res) {
+		return res
+	}
+
+	block, ok := fn.Body.(*ast.BlockStatement)
+	if !ok {
+		return e.Eval(fn.Body)
+	}
+
+	switch res := e.evalStatements(block.StatementList).(type) {
+	case *ReturnValue:
+		return res.Value
+	case *Exception:
+		return res
+	}
+	return UNDEFINED
+}
+
+func (e *Evaluator) bindParams(params []ast.Expression, args []value.Value) value.Value {
+	for i, param := range params {
+		if rest, ok := param.(*ast.RestElement); ok {
+			elements := make([]value.Value, 0)
+			if i < len(args) {
+				elements = append(elements, args[i:]...)
+			}
+			return e.bindPattern(rest.Argument, &value.Array{Elements: elements}, bindLet)
+		}
+
+		if res := e.bindPattern(param, argument(args, i), bindLet); isAbrupt(res) {
+			return res
+		}
+	}
+
+	return nil
+}
+
+func isCallable(val value.Value) bool {
+	switch val.(type) {
+	case *value.Function, *value.NativeFunction:
+		return true
+	}
+	return false
+}
+
+func newNativeFunction(name string, fn func(this value.Value, args []value.Value) value.Value) *value.NativeFunction {
+	return &value.NativeFunction{Name: name, Fn: fn}
+}
+
+func argument(args []value.Value, i int) value.Value {
+	if i < len(args) {
+		return args[i]
+	}
+	return UNDEFINED
+}
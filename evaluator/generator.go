@@ -0,0 +1,317 @@
+package evaluator
+
+import (
+	goruntime "runtime"
+
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/value"
+)
+
+type generatorState int
+
+const (
+	generatorSuspendedStart generatorState = iota
+	generatorSuspendedYield
+	generatorExecuting
+	generatorCompleted
+)
+
+type generator struct {
+	co    *coroutine
+	state generatorState
+}
+
+func (g *generator) Type() value.DataType {
+	return value.DataType_Object
+}
+
+func (g *generator) Inspect() string {
+	return "Object [Generator] {}"
+}
+
+type asyncGeneratorRequest struct {
+	resumption resumption
+	promise    *value.Promise
+}
+
+type asyncGenerator struct {
+	co    *coroutine
+	state generatorState
+	queue []asyncGeneratorRequest
+}
+
+func (g *asyncGenerator) Type() value.DataType {
+	return value.DataType_Object
+}
+
+func (g *asyncGenerator) Inspect() string {
+	return "Object [AsyncGenerator] {}"
+}
+
+func (e *Evaluator) newGenerator(fn *value.Function, this value.Value, args []value.Value) value.Value {
+	g := &generator{
+		co: newCoroutine(e.env, func(co *coroutine) value.Value {
+			return e.invoke(fn, this, args, co)
+		}),
+	}
+	// An abandoned generator releases the goroutine of its suspended body.
+	goruntime.SetFinalizer(g, func(g *generator) {
+		g.co.close()
+	})
+	return g
+}
+
+func (e *Evaluator) newAsyncGenerator(fn *value.Function, this value.Value, args []value.Value) value.Value {
+	co := newCoroutine(e.env, func(co *coroutine) value.Value {
+		return e.invoke(fn, this, args, co)
+	})
+	co.async = true
+
+	g := &asyncGenerator{co: co}
+	goruntime.SetFinalizer(g, func(g *asyncGenerator) {
+		g.co.close()
+	})
+	return g
+}
+
+func (e *Evaluator) generatorMethod(g *generator, key string) value.Value {
+	if key == value.SymbolIterator.Key() {
+		return newNativeFunction("[Symbol.iterator]", func(this value.Value, args []value.Value) value.Value {
+			return this
+		})
+	}
+
+	var mode resumeMode
+	switch key {
+	case "next":
+		mode = resumeNext
+	case "return":
+		mode = resumeReturn
+	case "throw":
+		mode = resumeThrow
+	default:
+		return UNDEFINED
+	}
+
+	return newNativeFunction(key, func(this value.Value, args []value.Value) value.Value {
+		return e.generatorResume(g, resumption{mode: mode, value: argument(args, 0)})
+	})
+}
+
+func (e *Evaluator) generatorResume(g *generator, r resumption) value.Value {
+	switch g.state {
+	case generatorExecuting:
+		return newTypeError("Generator is already running")
+	case generatorSuspendedStart:
+		if r.mode != resumeNext {
+			g.state = generatorCompleted
+		}
+	}
+
+	if g.state == generatorCompleted {
+		switch r.mode {
+		case resumeThrow:
+			return &Exception{Value: r.value}
+		case resumeReturn:
+			return newIterResult(r.value, true)
+		}
+		return newIterResult(UNDEFINED, true)
+	}
+
+	g.state = generatorExecuting
+	s := g.co.resume(r)
+	if s.kind == suspendYield {
+		g.state = generatorSuspendedYield
+		return newIterResult(s.value, false)
+	}
+
+	g.state = generatorCompleted
+	if isError(s.value) {
+		return s.value
+	}
+	return newIterResult(s.value, true)
+}
+
+func (e *Evaluator) asyncGeneratorMethod(g *asyncGenerator, key string) value.Value {
+	if key == value.SymbolAsyncIterator.Key() {
+		return newNativeFunction("[Symbol.asyncIterator]", func(this value.Value, args []value.Value) value.Value {
+			return this
+		})
+	}
+
+	var mode resumeMode
+	switch key {
+	case "next":
+		mode = resumeNext
+	case "return":
+		mode = resumeReturn
+	case "throw":
+		mode = resumeThrow
+	default:
+		return UNDEFINED
+	}
+
+	return newNativeFunction(key, func(this value.Value, args []value.Value) value.Value {
+		promise := new(value.Promise)
+		g.queue = append(g.queue, asyncGeneratorRequest{
+			resumption: resumption{mode: mode, value: argument(args, 0)},
+			promise:    promise,
+		})
+		if g.state != generatorExecuting {
+			e.asyncGeneratorDrain(g)
+		}
+		return promise
+	})
+}
+
+// asyncGeneratorDrain serves the queued requests of an async generator until
+// the queue is empty or the generator body is running.
+func (e *Evaluator) asyncGeneratorDrain(g *asyncGenerator) {
+	for len(g.queue) > 0 && g.state != generatorExecuting {
+		req := g.queue[0]
+
+		if g.state == generatorSuspendedStart && req.resumption.mode != resumeNext {
+			g.state = generatorCompleted
+		}
+
+		if g.state == generatorCompleted {
+			g.queue = g.queue[1:]
+			switch req.resumption.mode {
+			case resumeThrow:
+				e.rejectPromise(req.promise, req.resumption.value)
+			case resumeReturn:
+				e.resolvePromise(req.promise, newIterResult(req.resumption.value, true))
+			default:
+				e.resolvePromise(req.promise, newIterResult(UNDEFINED, true))
+			}
+			continue
+		}
+
+		e.asyncGeneratorResume(g, req.resumption)
+	}
+}
+
+func (e *Evaluator) asyncGeneratorResume(g *asyncGenerator, r resumption) {
+	g.state = generatorExecuting
+	s := g.co.resume(r)
+
+	switch s.kind {
+	case suspendAwait:
+		e.awaitValue(s.value, func(r resumption) {
+			e.asyncGeneratorResume(g, r)
+		})
+		return
+	case suspendYield:
+		g.state = generatorSuspendedYield
+		req := g.queue[0]
+		g.queue = g.queue[1:]
+		e.resolvePromise(req.promise, newIterResult(s.value, false))
+	default:
+		g.state = generatorCompleted
+		req := g.queue[0]
+		g.queue = g.queue[1:]
+		if exc, ok := s.value.(*Exception); ok {
+			e.rejectPromise(req.promise, exc.Value)
+		} else {
+			e.resolvePromise(req.promise, newIterResult(s.value, true))
+		}
+	}
+
+	e.asyncGeneratorDrain(g)
+}
+
+func (e *Evaluator) evalYieldExpression(node *ast.YieldExpression) value.Value {
+	var val value.Value = UNDEFINED
+	if node.Argument != nil {
+		val = e.Eval(node.Argument)
+		if isAbrupt(val) {
+			return val
+		}
+	}
+
+	if e.co == nil {
+		return newError("yield is only valid in generator functions")
+	}
+
+	if node.Delegate {
+		iter, res := e.getIterator(val, node.Argument.String(), e.co.async)
+		if res != nil {
+			return res
+		}
+		return e.evalYieldDelegate(iter)
+	}
+
+	if e.co.async {
+		val = e.co.await(val)
+		if isAbrupt(val) {
+			return val
+		}
+	}
+
+	return e.co.yield(val)
+}
+
+// evalYieldDelegate forwards the resumptions of the current generator to the
+// iterator and yields its results until it is done.
+func (e *Evaluator) evalYieldDelegate(iter *iteratorRecord) value.Value {
+	received := resumption{mode: resumeNext, value: UNDEFINED}
+
+	for {
+		var fn value.Value
+		switch received.mode {
+		case resumeThrow:
+			fn = e.getProperty(iter.iterator, "throw")
+		case resumeReturn:
+			fn = e.getProperty(iter.iterator, "return")
+		default:
+			fn = iter.next
+		}
+
+		if isAbrupt(fn) {
+			return fn
+		} else if !isCallable(fn) {
+			switch received.mode {
+			case resumeNext:
+				return newTypeError("%s is not a function", fn.Inspect())
+			case resumeReturn:
+				return &ReturnValue{Value: received.value}
+			default:
+				return newTypeError("The iterator does not provide a 'throw' method")
+			}
+		}
+
+		result := e.callFunction(fn, iter.iterator, []value.Value{received.value})
+		if iter.async && !isAbrupt(result) {
+			result = e.co.await(result)
+		}
+		if isAbrupt(result) {
+			return result
+		}
+
+		done := e.getProperty(result, "done")
+		if isAbrupt(done) {
+			return done
+		}
+		val := e.getProperty(result, "value")
+		if isAbrupt(val) {
+			return val
+		}
+
+		// An async generator delegating to a sync iterator awaits its values.
+		if e.co.async && !iter.async {
+			val = e.co.await(val)
+			if isAbrupt(val) {
+				return val
+			}
+		}
+
+		if isTruthy(done) {
+			if received.mode == resumeReturn {
+				return &ReturnValue{Value: val}
+			}
+			return val
+		}
+
+		received = e.co.suspend(suspension{kind: suspendYield, value: val})
+	}
+}
@@ -0,0 +1,177 @@
+package evaluator
+
+import (
+	"github.com/ghosind/gjs/value"
+)
+
+type iteratorRecord struct {
+	iterator value.Value
+	next     value.Value
+	// async is set for async iterators, whose results are awaited.
+	async bool
+	done  bool
+}
+
+// getIterator gets the iterator of val, falling back to the sync iterator if
+// an async iterator is requested but val has none. The name of the iterable is
+// used in error messages.
+func (e *Evaluator) getIterator(val value.Value, name string, async bool) (*iteratorRecord, value.Value) {
+	switch val.(type) {
+	case *value.Undefined, *value.Null:
+		return nil, newTypeError("%s is not iterable", name)
+	}
+
+	if async {
+		method := e.getProperty(val, value.SymbolAsyncIterator.Key())
+		if isAbrupt(method) {
+			return nil, method
+		} else if method != UNDEFINED && method != NULL {
+			return e.iteratorFromMethod(val, method, name, true)
+		}
+	}
+
+	method := e.getProperty(val, value.SymbolIterator.Key())
+	if isAbrupt(method) {
+		return nil, method
+	}
+	return e.iteratorFromMethod(val, method, name, false)
+}
+
+func (e *Evaluator) iteratorFromMethod(val, method value.Value, name string, async bool) (*iteratorRecord, value.Value) {
+	if !isCallable(method) {
+		return nil, newTypeError("%s is not iterable", name)
+	}
+
+	iterator := e.callFunction(method, val, nil)
+	if isAbrupt(iterator) {
+		return nil, iterator
+	} else if iterator.Type() != value.DataType_Object {
+		return nil, newTypeError("Result of the Symbol.iterator method is not an object")
+	}
+
+	next := e.getProperty(iterator, "next")
+	if isAbrupt(next) {
+		return nil, next
+	}
+
+	return &iteratorRecord{iterator: iterator, next: next, async: async}, nil
+}
+
+// iteratorStep returns the next value of the iterator, or nil if it is done.
+func (e *Evaluator) iteratorStep(iter *iteratorRecord) value.Value {
+	if !isCallable(iter.next) {
+		iter.done = true
+		return newTypeError("%s is not a function", iter.next.Inspect())
+	}
+
+	result := e.callFunction(iter.next, iter.iterator, nil)
+	if iter.async && !isAbrupt(result) {
+		result = e.co.await(result)
+	}
+	if isAbrupt(result) {
+		iter.done = true
+		return result
+	} else if result.Type() != value.DataType_Object {
+		iter.done = true
+		return newTypeError("Iterator result %s is not an object", result.Inspect())
+	}
+
+	done := e.getProperty(result, "done")
+	if isAbrupt(done) {
+		iter.done = true
+		return done
+	} else if isTruthy(done) {
+		iter.done = true
+		return nil
+	}
+
+	val := e.getProperty(result, "value")
+	if isAbrupt(val) {
+		iter.done = true
+	}
+	return val
+}
+
+// iteratorClose calls the return method of an iterator that is not exhausted.
+func (e *Evaluator) iteratorClose(iter *iteratorRecord) value.Value {
+	iter.done = true
+
+	ret := e.getProperty(iter.iterator, "return")
+	if isAbrupt(ret) {
+		return ret
+	} else if !isCallable(ret) {
+		return nil
+	}
+
+	res := e.callFunction(ret, iter.iterator, nil)
+	if iter.async && !isAbrupt(res) {
+		res = e.co.await(res)
+	}
+	if isAbrupt(res) {
+		return res
+	}
+	return nil
+}
+
+// iterableToList collects the values of an iterable, as done by spread
+// elements.
+func (e *Evaluator) iterableToList(val value.Value, name string) ([]value.Value, value.Value) {
+	if arr, ok := val.(*value.Array); ok {
+		return append([]value.Value(nil), arr.Elements...), nil
+	}
+
+	iter, res := e.getIterator(val, name, false)
+	if res != nil {
+		return nil, res
+	}
+
+	list := make([]value.Value, 0)
+	for {
+		next := e.iteratorStep(iter)
+		if next == nil {
+			return list, nil
+		} else if isAbrupt(next) {
+			return nil, next
+		}
+		list = append(list, next)
+	}
+}
+
+// newListIterator creates an iterator over the values returned by at, which
+// reports false after the last value.
+func newListIterator(at func(i int) (value.Value, bool)) *value.Object {
+	i := 0
+	done := false
+
+	iter := value.NewObject()
+	iter.Set("next", newNativeFunction("next", func(this value.Value, args []value.Value) value.Value {
+		if !done {
+			if val, ok := at(i); ok {
+				i++
+				return newIterResult(val, false)
+			}
+			done = true
+		}
+		return newIterResult(UNDEFINED, true)
+	}))
+	iter.Set(value.SymbolIterator.Key(), newNativeFunction("[Symbol.iterator]", func(this value.Value, args []value.Value) value.Value {
+		return this
+	}))
+
+	return iter
+}
+
+func newSymbolConstructor() *value.NativeFunction {
+	symbol := newNativeFunction("Symbol", func(this value.Value, args []value.Value) value.Value {
+		description := ""
+		if desc := argument(args, 0); desc != UNDEFINED {
+			description = desc.Inspect()
+		}
+		return value.NewSymbol(description)
+	})
+	symbol.Properties = map[string]value.Value{
+		"iterator":      value.SymbolIterator,
+		"asyncIterator": value.SymbolAsyncIterator,
+	}
+	return symbol
+}
@@ -0,0 +1,245 @@
+package evaluator
+
+import (
+	"strconv"
+
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/value"
+)
+
+// evalLabeledStatement evaluates a labeled statement. The labelSet holds the
+// labels directly enclosing it, which a continue in a loop may target.
+func (e *Evaluator) evalLabeledStatement(node *ast.LabeledStatement, labelSet []string) value.Value {
+	label := node.Label.String()
+	labelSet = append(labelSet, label)
+
+	var res value.Value
+	switch stmt := node.Statement.(type) {
+	case *ast.LabeledStatement:
+		res = e.evalLabeledStatement(stmt, labelSet)
+	case *ast.WhileStatement:
+		res = e.evalWhileStatement(stmt, labelSet)
+	case *ast.DoWhileStatement:
+		res = e.evalDoWhileStatement(stmt, labelSet)
+	case *ast.ForStatement:
+		res = e.evalForStatement(stmt, labelSet)
+	case *ast.ForInStatement:
+		res = e.evalForInStatement(stmt, labelSet)
+	case *ast.ForOfStatement:
+		res = e.evalForOfStatement(stmt, labelSet)
+	default:
+		res = e.Eval(stmt)
+	}
+
+	if brk, ok := res.(*breakValue); ok && brk.label == label {
+		return nil
+	}
+	return res
+}
+
+// loopContinues reports whether a loop goes on after its body completed with
+// res.
+func loopContinues(res value.Value, labelSet []string) bool {
+	cont, ok := res.(*continueValue)
+	if !ok {
+		return !isAbrupt(res)
+	} else if cont.label == "" {
+		return true
+	}
+
+	for _, label := range labelSet {
+		if label == cont.label {
+			return true
+		}
+	}
+	return false
+}
+
+// loopCompletion returns the completion of a loop ended by res.
+func loopCompletion(res value.Value) value.Value {
+	if brk, ok := res.(*breakValue); ok && brk.label == "" {
+		return nil
+	}
+	return res
+}
+
+func (e *Evaluator) evalWhileStatement(node *ast.WhileStatement, labelSet []string) value.Value {
+	for {
+		cond := e.Eval(node.Condition)
+		if isAbrupt(cond) {
+			return cond
+		} else if !isTruthy(cond) {
+			return nil
+		}
+
+		if res := e.Eval(node.Body); !loopContinues(res, labelSet) {
+			return loopCompletion(res)
+		}
+	}
+}
+
+func (e *Evaluator) evalDoWhileStatement(node *ast.DoWhileStatement, labelSet []string) value.Value {
+	for {
+		if res := e.Eval(node.Body); !loopContinues(res, labelSet) {
+			return loopCompletion(res)
+		}
+
+		cond := e.Eval(node.Condition)
+		if isAbrupt(cond) {
+			return cond
+		} else if !isTruthy(cond) {
+			return nil
+		}
+	}
+}
+
+func (e *Evaluator) evalForStatement(node *ast.ForStatement, labelSet []string) value.Value {
+	loop := e.enclosed()
+
+	// Each iteration gets its own copy of the let bindings, so closures
+	// created in the body capture the values of that iteration.
+	perIteration := false
+	if node.Init != nil {
+		if decl, ok := node.Init.(*ast.LexicalDeclaration); ok && !decl.Const {
+			perIteration = true
+		}
+		if res := loop.Eval(node.Init); isAbrupt(res) {
+			return res
+		}
+	}
+
+	for {
+		if perIteration {
+			loop.env = loop.env.Copy()
+		}
+
+		if node.Condition != nil {
+			cond := loop.Eval(node.Condition)
+			if isAbrupt(cond) {
+				return cond
+			} else if !isTruthy(cond) {
+				return nil
+			}
+		}
+
+		if res := loop.Eval(node.Body); !loopContinues(res, labelSet) {
+			return loopCompletion(res)
+		}
+
+		if perIteration {
+			loop.env = loop.env.Copy()
+		}
+
+		if node.Update != nil {
+			if res := loop.Eval(node.Update); isAbrupt(res) {
+				return res
+			}
+		}
+	}
+}
+
+func (e *Evaluator) evalForInStatement(node *ast.ForInStatement, labelSet []string) value.Value {
+	obj := e.Eval(node.Right)
+	if isAbrupt(obj) {
+		return obj
+	}
+
+	for _, key := range forInKeys(obj) {
+		res := e.evalForBody(node.Left, &value.String{Value: key}, node.Body)
+		if !loopContinues(res, labelSet) {
+			return loopCompletion(res)
+		}
+	}
+
+	return nil
+}
+
+// forInKeys returns the enumerable string keys of val.
+func forInKeys(val value.Value) []string {
+	keys := make([]string, 0)
+
+	switch val := val.(type) {
+	case *value.Object:
+		keys = append(keys, val.Keys()...)
+	case *value.Array:
+		for i := range val.Elements {
+			keys = append(keys, strconv.Itoa(i))
+		}
+	case *value.String:
+		for i := range []rune(val.Value) {
+			keys = append(keys, strconv.Itoa(i))
+		}
+	}
+
+	return keys
+}
+
+func (e *Evaluator) evalForOfStatement(node *ast.ForOfStatement, labelSet []string) value.Value {
+	obj := e.Eval(node.Right)
+	if isAbrupt(obj) {
+		return obj
+	}
+
+	iter, res := e.getIterator(obj, node.Right.String(), node.Await)
+	if res != nil {
+		return res
+	}
+
+	for {
+		next := e.iteratorStep(iter)
+		if next == nil {
+			return nil
+		} else if isAbrupt(next) {
+			return next
+		}
+
+		// for await over a sync iterator awaits the values instead.
+		if node.Await && !iter.async {
+			next = e.co.await(next)
+			if isAbrupt(next) {
+				e.iteratorClose(iter)
+				return next
+			}
+		}
+
+		res := e.evalForBody(node.Left, next, node.Body)
+		if loopContinues(res, labelSet) {
+			continue
+		}
+
+		if isError(res) {
+			e.iteratorClose(iter)
+			return res
+		} else if closed := e.iteratorClose(iter); isAbrupt(closed) {
+			return closed
+		}
+		return loopCompletion(res)
+	}
+}
+
+// evalForBody binds the value of an iteration to the head of a for-in or
+// for-of statement, and evaluates the body in a new scope.
+func (e *Evaluator) evalForBody(left ast.Node, val value.Value, body ast.Statement) value.Value {
+	iteration := e.enclosed()
+
+	var res value.Value
+	switch left := left.(type) {
+	case *ast.VarStatement:
+		decl := left.Declarations[0].(*ast.VariableDeclaration)
+		res = iteration.bindPattern(decl.Name, val, bindVar)
+	case *ast.LexicalDeclaration:
+		kind := bindLet
+		if left.Const {
+			kind = bindConst
+		}
+		decl := left.Declarations[0].(*ast.VariableDeclaration)
+		res = iteration.bindPattern(decl.Name, val, kind)
+	case ast.Expression:
+		res = iteration.bindPattern(left, val, bindAssign)
+	}
+	if isAbrupt(res) {
+		return res
+	}
+
+	return iteration.Eval(body)
+}
@@ -0,0 +1,109 @@
+package evaluator
+
+import (
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/runtime"
+	"github.com/ghosind/gjs/value"
+)
+
+// EvalModule evaluates the body of a module in a new module environment, and
+// returns the environment and a promise settled when the evaluation
+// completes. The module may await at the top level.
+func (e *Evaluator) EvalModule(program *ast.Program) (*runtime.Runtime, value.Value) {
+	env := runtime.NewModuleEnvironment(e.env)
+	// The this binding of modules is undefined.
+	env.SetLexical("this", UNDEFINED)
+
+	promise := new(value.Promise)
+	if exc := linkModule(program); exc != nil {
+		e.rejectPromise(promise, exc.Value)
+		return env, promise
+	}
+
+	co := newCoroutine(e.env, func(co *coroutine) value.Value {
+		inner := &Evaluator{env: env, varEnv: env, co: co, strict: true, file: program.FileName}
+		if res := inner.evalStatements(program.Statements); isError(res) {
+			return res
+		}
+		return UNDEFINED
+	})
+	co.async = true
+	e.runAsyncFunction(co, promise, resumption{})
+
+	return env, promise
+}
+
+// linkModule resolves the modules requested by the import and export
+// declarations of a module before it is evaluated. There is no module loader,
+// so linking fails if any module is requested.
+func linkModule(program *ast.Program) *Exception {
+	for _, stmt := range program.Statements {
+		switch stmt := stmt.(type) {
+		case *ast.ImportDeclaration:
+			return moduleNotFound(stmt.Source.Value)
+		case *ast.ExportNamedDeclaration:
+			if stmt.Source != nil {
+				return moduleNotFound(stmt.Source.Value)
+			}
+		case *ast.ExportAllDeclaration:
+			return moduleNotFound(stmt.Source.Value)
+		}
+	}
+	return nil
+}
+
+func (e *Evaluator) evalExportDefaultDeclaration(node *ast.ExportDefaultDeclaration) value.Value {
+	if decl, ok := node.Declaration.(*ast.FunctionDeclaration); ok && decl.Name != nil {
+		// hoisted by hoistFunctions
+		return nil
+	}
+
+	var val value.Value
+	if decl, ok := node.Declaration.(*ast.FunctionDeclaration); ok {
+		val = e.newFunction("default", decl.Params, decl.Body, decl.Async, decl.Generator, false, decl.Strict)
+	} else {
+		val = e.Eval(node.Declaration)
+		if isAbrupt(val) {
+			return val
+		}
+		setFunctionName(val, node.Declaration, &ast.Identifier{Value: "default"})
+	}
+
+	e.env.Module().Default = val
+	return nil
+}
+
+// evalImportExpression evaluates an import call. There is no module loader, so
+// the returned promise is rejected once the specifier is evaluated.
+func (e *Evaluator) evalImportExpression(node *ast.ImportExpression) value.Value {
+	promise := new(value.Promise)
+
+	specifier := e.Eval(node.Source)
+	if exc, ok := specifier.(*Exception); ok {
+		e.rejectPromise(promise, exc.Value)
+		return promise
+	} else if isAbrupt(specifier) {
+		return specifier
+	}
+
+	exc := moduleNotFound(specifier.Inspect())
+	e.rejectPromise(promise, exc.Value)
+	return promise
+}
+
+func (e *Evaluator) evalMetaProperty(node *ast.MetaProperty) value.Value {
+	module := e.env.Module()
+	if module == nil {
+		return newError("import.meta is only valid in modules")
+	}
+
+	// import.meta is created once per module.
+	if module.Meta == nil {
+		module.Meta = value.NewObject()
+	}
+	return module.Meta
+}
+
+func moduleNotFound(specifier string) *Exception {
+	return newErrorObject("Error", "Cannot find module '"+specifier+"'")
+}
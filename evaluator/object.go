@@ -0,0 +1,216 @@
+package evaluator
+
+import (
+	"strconv"
+
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/value"
+)
+
+func (e *Evaluator) evalArrayLiteral(node *ast.ArrayLiteral) value.Value {
+	elements := make([]value.Value, 0, len(node.ElementList))
+
+	for _, elem := range node.ElementList {
+		switch elem := elem.(type) {
+		case *ast.Elision:
+			elements = append(elements, UNDEFINED)
+		case *ast.SpreadElement:
+			val := e.Eval(elem.Value)
+			if isAbrupt(val) {
+				return val
+			}
+			list, res := e.iterableToList(val, elem.Value.String())
+			if res != nil {
+				return res
+			}
+			elements = append(elements, list...)
+		default:
+			val := e.Eval(elem)
+			if isAbrupt(val) {
+				return val
+			}
+			elements = append(elements, val)
+		}
+	}
+
+	return &value.Array{Elements: elements}
+}
+
+func (e *Evaluator) evalObjectLiteral(node *ast.ObjectLiteral) value.Value {
+	obj := value.NewObject()
+
+	for _, prop := range node.Properties {
+		if spread, ok := prop.(*ast.SpreadElement); ok {
+			val := e.Eval(spread.Value)
+			if isAbrupt(val) {
+				return val
+			}
+			if src, ok := val.(*value.Object); ok {
+				for _, key := range src.Keys() {
+					obj.Set(key, e.getProperty(src, key))
+				}
+			}
+			continue
+		}
+
+		prop := prop.(*ast.Property)
+		key, ok := e.propertyName(prop.Key, prop.Computed)
+		if !ok {
+			return key
+		}
+		name := key.(*value.String).Value
+
+		val := e.Eval(prop.Value)
+		if isAbrupt(val) {
+			return val
+		}
+		if fn, ok := val.(*value.Function); ok && fn.Name == "" {
+			fn.Name = name
+		}
+
+		switch prop.Kind {
+		case ast.PropertyGet, ast.PropertySet:
+			accessor, ok := obj.Properties[name].(*value.Accessor)
+			if !ok {
+				accessor = new(value.Accessor)
+			}
+			if prop.Kind == ast.PropertyGet {
+				accessor.Get = val
+			} else {
+				accessor.Set = val
+			}
+			obj.Set(name, accessor)
+		default:
+			obj.Set(name, val)
+		}
+	}
+
+	return obj
+}
+
+// propertyName evaluates a property key to a string, and reports false with
+// the abrupt completion if the evaluation of a computed key fails.
+func (e *Evaluator) propertyName(key ast.Expression, computed bool) (value.Value, bool) {
+	if !computed {
+		switch key := key.(type) {
+		case *ast.Identifier:
+			return &value.String{Value: key.Value}, true
+		case *ast.Literal:
+			val := e.Eval(key)
+			if isAbrupt(val) {
+				return val, false
+			}
+			return &value.String{Value: toPropertyKey(val)}, true
+		}
+	}
+
+	val := e.Eval(key)
+	if isAbrupt(val) {
+		return val, false
+	}
+	return &value.String{Value: toPropertyKey(val)}, true
+}
+
+func (e *Evaluator) evalMemberExpression(node *ast.MemberExpression) value.Value {
+	obj := e.Eval(node.Object)
+	if isAbrupt(obj) {
+		return obj
+	}
+
+	key := e.evalPropertyKey(node)
+	if isAbrupt(key) {
+		return key
+	}
+
+	return e.getProperty(obj, key.(*value.String).Value)
+}
+
+func (e *Evaluator) evalPropertyKey(node *ast.MemberExpression) value.Value {
+	if !node.Computed {
+		return &value.String{Value: node.Property.(*ast.Identifier).Value}
+	}
+	key, _ := e.propertyName(node.Property, true)
+	return key
+}
+
+func (e *Evaluator) getProperty(obj value.Value, key string) value.Value {
+	switch obj := obj.(type) {
+	case *value.Undefined, *value.Null:
+		return newTypeError("Cannot read properties of %s (reading '%s')", obj.Inspect(), key)
+	case *value.Object:
+		val, ok := obj.Get(key)
+		if !ok {
+			return UNDEFINED
+		}
+		if accessor, ok := val.(*value.Accessor); ok {
+			if accessor.Get == nil {
+				return UNDEFINED
+			}
+			return e.callFunction(accessor.Get, obj, nil)
+		}
+		return val
+	case *value.Array:
+		if key == "length" {
+			return &value.Number{Value: float64(len(obj.Elements))}
+		} else if key == value.SymbolIterator.Key() {
+			return newNativeFunction("[Symbol.iterator]", func(this value.Value, args []value.Value) value.Value {
+				return newListIterator(func(i int) (value.Value, bool) {
+					if i < len(obj.Elements) {
+						return obj.Elements[i], true
+					}
+					return nil, false
+				})
+			})
+		}
+		if i, err := strconv.Atoi(key); err == nil && i >= 0 && i < len(obj.Elements) {
+			return obj.Elements[i]
+		}
+	case *value.String:
+		if key == "length" {
+			return &value.Number{Value: float64(len(obj.Value))}
+		} else if key == value.SymbolIterator.Key() {
+			return newNativeFunction("[Symbol.iterator]", func(this value.Value, args []value.Value) value.Value {
+				chars := []rune(obj.Value)
+				return newListIterator(func(i int) (value.Value, bool) {
+					if i < len(chars) {
+						return &value.String{Value: string(chars[i])}, true
+					}
+					return nil, false
+				})
+			})
+		}
+	case *value.Function:
+		if key == "name" {
+			return &value.String{Value: obj.Name}
+		}
+	case *value.NativeFunction:
+		if key == "name" {
+			return &value.String{Value: obj.Name}
+		}
+		if val, ok := obj.Properties[key]; ok {
+			return val
+		}
+	case *value.Promise:
+		return e.promiseMethod(obj, key)
+	case *generator:
+		return e.generatorMethod(obj, key)
+	case *asyncGenerator:
+		return e.asyncGeneratorMethod(obj, key)
+	}
+
+	return UNDEFINED
+}
+
+func toPropertyKey(val value.Value) string {
+	if sym, ok := val.(*value.Symbol); ok {
+		return sym.Key()
+	}
+	return val.Inspect()
+}
+
+func newIterResult(val value.Value, done bool) *value.Object {
+	obj := value.NewObject()
+	obj.Set("value", val)
+	obj.Set("done", nativeBoolToBooleanObject(done))
+	return obj
+}
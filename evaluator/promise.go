@@ -0,0 +1,256 @@
+package evaluator
+
+import (
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/value"
+)
+
+func (e *Evaluator) newPromiseConstructor() *value.NativeFunction {
+	ctor := newNativeFunction("Promise", func(this value.Value, args []value.Value) value.Value {
+		return newTypeError("Promise constructor cannot be invoked without 'new'")
+	})
+	ctor.Construct = func(args []value.Value) value.Value {
+		executor := argument(args, 0)
+		if !isCallable(executor) {
+			return newTypeError("Promise resolver %s is not a function", executor.Inspect())
+		}
+
+		promise := new(value.Promise)
+		resolve, reject := e.newResolvingFunctions(promise)
+		if res := e.callFunction(executor, UNDEFINED, []value.Value{resolve, reject}); isError(res) {
+			reject.Fn(UNDEFINED, []value.Value{res.(*Exception).Value})
+		}
+		return promise
+	}
+	ctor.Properties = map[string]value.Value{
+		"resolve": newNativeFunction("resolve", func(this value.Value, args []value.Value) value.Value {
+			return e.promiseResolve(argument(args, 0))
+		}),
+		"reject": newNativeFunction("reject", func(this value.Value, args []value.Value) value.Value {
+			promise := new(value.Promise)
+			e.rejectPromise(promise, argument(args, 0))
+			return promise
+		}),
+	}
+
+	return ctor
+}
+
+func (e *Evaluator) promiseMethod(promise *value.Promise, key string) value.Value {
+	switch key {
+	case "then":
+		return newNativeFunction("then", func(this value.Value, args []value.Value) value.Value {
+			return e.promiseThen(promise, argument(args, 0), argument(args, 1))
+		})
+	case "catch":
+		return newNativeFunction("catch", func(this value.Value, args []value.Value) value.Value {
+			return e.promiseThen(promise, UNDEFINED, argument(args, 0))
+		})
+	case "finally":
+		return newNativeFunction("finally", func(this value.Value, args []value.Value) value.Value {
+			return e.promiseFinally(promise, argument(args, 0))
+		})
+	}
+	return UNDEFINED
+}
+
+// promiseResolve returns val if it is a promise, or a new promise resolved
+// with val otherwise.
+func (e *Evaluator) promiseResolve(val value.Value) *value.Promise {
+	if promise, ok := val.(*value.Promise); ok {
+		return promise
+	}
+	promise := new(value.Promise)
+	e.resolvePromise(promise, val)
+	return promise
+}
+
+func (e *Evaluator) newResolvingFunctions(promise *value.Promise) (*value.NativeFunction, *value.NativeFunction) {
+	alreadyResolved := false
+
+	resolve := newNativeFunction("", func(this value.Value, args []value.Value) value.Value {
+		if !alreadyResolved {
+			alreadyResolved = true
+			e.resolvePromise(promise, argument(args, 0))
+		}
+		return UNDEFINED
+	})
+	reject := newNativeFunction("", func(this value.Value, args []value.Value) value.Value {
+		if !alreadyResolved {
+			alreadyResolved = true
+			e.rejectPromise(promise, argument(args, 0))
+		}
+		return UNDEFINED
+	})
+
+	return resolve, reject
+}
+
+func (e *Evaluator) resolvePromise(promise *value.Promise, resolution value.Value) {
+	if resolution == promise {
+		e.rejectPromise(promise, newErrorObject("TypeError", "Chaining cycle detected for promise #<Promise>").Value)
+		return
+	} else if resolution.Type() != value.DataType_Object {
+		e.settlePromise(promise, value.PromiseStateFulfilled, resolution)
+		return
+	}
+
+	then := e.getProperty(resolution, "then")
+	if exc, ok := then.(*Exception); ok {
+		e.rejectPromise(promise, exc.Value)
+		return
+	} else if !isCallable(then) {
+		e.settlePromise(promise, value.PromiseStateFulfilled, resolution)
+		return
+	}
+
+	e.env.Jobs().Enqueue(func() {
+		resolve, reject := e.newResolvingFunctions(promise)
+		if res := e.callFunction(then, resolution, []value.Value{resolve, reject}); isError(res) {
+			reject.Fn(UNDEFINED, []value.Value{res.(*Exception).Value})
+		}
+	})
+}
+
+func (e *Evaluator) rejectPromise(promise *value.Promise, reason value.Value) {
+	e.settlePromise(promise, value.PromiseStateRejected, reason)
+}
+
+func (e *Evaluator) settlePromise(promise *value.Promise, state value.PromiseState, result value.Value) {
+	if promise.State != value.PromiseStatePending {
+		return
+	}
+
+	promise.State = state
+	promise.Result = result
+	reactions := promise.Reactions
+	promise.Reactions = nil
+
+	for _, reaction := range reactions {
+		e.enqueueReaction(reaction, state, result)
+	}
+}
+
+func (e *Evaluator) enqueueReaction(reaction value.PromiseReaction, state value.PromiseState, result value.Value) {
+	e.env.Jobs().Enqueue(func() {
+		reaction(state, result)
+	})
+}
+
+// performThen registers a reaction that runs as a job once the promise is
+// settled.
+func (e *Evaluator) performThen(promise *value.Promise, reaction value.PromiseReaction) {
+	if promise.State == value.PromiseStatePending {
+		promise.Reactions = append(promise.Reactions, reaction)
+		return
+	}
+	e.enqueueReaction(reaction, promise.State, promise.Result)
+}
+
+func (e *Evaluator) promiseThen(promise *value.Promise, onFulfilled, onRejected value.Value) *value.Promise {
+	derived := new(value.Promise)
+
+	e.performThen(promise, func(state value.PromiseState, result value.Value) {
+		handler := onFulfilled
+		if state == value.PromiseStateRejected {
+			handler = onRejected
+		}
+
+		if !isCallable(handler) {
+			if state == value.PromiseStateRejected {
+				e.rejectPromise(derived, result)
+			} else {
+				e.resolvePromise(derived, result)
+			}
+			return
+		}
+
+		res := e.callFunction(handler, UNDEFINED, []value.Value{result})
+		if exc, ok := res.(*Exception); ok {
+			e.rejectPromise(derived, exc.Value)
+		} else {
+			e.resolvePromise(derived, res)
+		}
+	})
+
+	return derived
+}
+
+func (e *Evaluator) promiseFinally(promise *value.Promise, onFinally value.Value) *value.Promise {
+	if !isCallable(onFinally) {
+		return e.promiseThen(promise, onFinally, onFinally)
+	}
+
+	derived := new(value.Promise)
+
+	e.performThen(promise, func(state value.PromiseState, result value.Value) {
+		res := e.callFunction(onFinally, UNDEFINED, nil)
+		if exc, ok := res.(*Exception); ok {
+			e.rejectPromise(derived, exc.Value)
+			return
+		}
+
+		e.performThen(e.promiseResolve(res), func(finallyState value.PromiseState, reason value.Value) {
+			switch {
+			case finallyState == value.PromiseStateRejected:
+				e.rejectPromise(derived, reason)
+			case state == value.PromiseStateRejected:
+				e.rejectPromise(derived, result)
+			default:
+				e.resolvePromise(derived, result)
+			}
+		})
+	})
+
+	return derived
+}
+
+// awaitValue resumes the suspended body with the outcome of val once it is
+// settled, as a job on the job queue.
+func (e *Evaluator) awaitValue(val value.Value, resume func(r resumption)) {
+	e.performThen(e.promiseResolve(val), func(state value.PromiseState, result value.Value) {
+		if state == value.PromiseStateRejected {
+			resume(resumption{mode: resumeThrow, value: result})
+		} else {
+			resume(resumption{mode: resumeNext, value: result})
+		}
+	})
+}
+
+func (e *Evaluator) callAsyncFunction(fn *value.Function, this value.Value, args []value.Value) value.Value {
+	promise := new(value.Promise)
+	co := newCoroutine(e.env, func(co *coroutine) value.Value {
+		return e.invoke(fn, this, args, co)
+	})
+	co.async = true
+	e.runAsyncFunction(co, promise, resumption{})
+
+	return promise
+}
+
+func (e *Evaluator) runAsyncFunction(co *coroutine, promise *value.Promise, r resumption) {
+	s := co.resume(r)
+	if s.kind == suspendAwait {
+		e.awaitValue(s.value, func(r resumption) {
+			e.runAsyncFunction(co, promise, r)
+		})
+		return
+	}
+
+	if exc, ok := s.value.(*Exception); ok {
+		e.rejectPromise(promise, exc.Value)
+	} else {
+		e.resolvePromise(promise, s.value)
+	}
+}
+
+func (e *Evaluator) evalAwaitExpression(node *ast.AwaitExpression) value.Value {
+	val := e.Eval(node.Argument)
+	if isAbrupt(val) {
+		return val
+	}
+	if e.co == nil {
+		return newError("await is only valid in async functions and the top level bodies of modules")
+	}
+	return e.co.await(val)
+}
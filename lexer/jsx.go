@@ -0,0 +1,90 @@
+package lexer
+
+import (
+	"unicode/utf8"
+
+	"github.com/ghosind/gjs/token"
+)
+
+// ScanJSXText scans the text of the children of a JSX element, which runs up
+// to the next '{', '<', '>' or '}' and may span lines. If there is no text, it
+// scans a token like ScanToken.
+func (l *Lexer) ScanJSXText() (*token.Token, error) {
+	l.start = l.cur
+	line, col := l.line, l.col
+	for !l.isEnd() && !isJSXTextEnd(l.peek()) {
+		l.skipRune()
+	}
+	if l.cur == l.start {
+		return l.ScanToken()
+	}
+
+	raw := string(l.source[l.start:l.cur])
+	return &token.Token{
+		TokenType: token.TOKEN_JSX_TEXT,
+		Line:      line,
+		Col:       col,
+		Literal:   raw,
+		Offset:    l.start,
+		Raw:       raw,
+	}, nil
+}
+
+// ScanJSXString scans the string value of a JSX attribute, after any white
+// space and line terminators. The string may span lines and has no escape
+// sequences. If there is no string, it scans a token like ScanToken.
+func (l *Lexer) ScanJSXString() (*token.Token, error) {
+	for !l.isEnd() && (l.isSpace(l.peek()) || isLineTerminator(l.peek())) {
+		l.skipRune()
+	}
+	quote := l.peek()
+	if quote != '"' && quote != '\'' {
+		return l.ScanToken()
+	}
+
+	l.start = l.cur
+	line, col := l.line, l.col
+	l.skipRune()
+	for !l.isEnd() && l.peek() != quote {
+		l.skipRune()
+	}
+	if l.isEnd() {
+		return nil, l.newSyntaxError()
+	}
+	l.skipRune()
+
+	raw := string(l.source[l.start:l.cur])
+	return &token.Token{
+		TokenType: token.TOKEN_STRING,
+		Line:      line,
+		Col:       col,
+		Literal:   raw[1 : len(raw)-1],
+		Offset:    l.start,
+		Raw:       raw,
+	}, nil
+}
+
+// skipRune moves past the next character, and counts the line terminators as
+// the positions of the tokens do.
+func (l *Lexer) skipRune() {
+	r, width := utf8.DecodeRune(l.source[l.cur:])
+	l.cur += width
+	if r == '\r' && l.peek() == '\n' {
+		l.cur++
+	}
+
+	if isLineTerminator(r) {
+		l.line++
+		l.col = 1
+	} else {
+		l.col++
+	}
+}
+
+func isJSXTextEnd(c rune) bool {
+	return c == '{' || c == '<' || c == '>' || c == '}'
+}
+
+func isLineTerminator(c rune) bool {
+	return c == '\n' || c == '\r' || c == 0x2028 || c == 0x2029
+}
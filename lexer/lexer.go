@@ -1,6 +1,7 @@
 package lexer
 
 import (
+	"strings"
 	"unicode/utf8"
 
 	"github.com/ghosind/gjs/errors"
@@ -27,6 +28,24 @@ func New(source []byte) *Lexer {
 	return l
 }
 
+// NewAt returns a lexer of source that starts scanning at pos, which must be
+// the position of the start of a token.
+func NewAt(source []byte, pos token.Position) *Lexer {
+	l := New(source)
+	l.Reset(pos)
+	return l
+}
+
+// Reset moves the lexer to pos, which must be the position of the start of a
+// token, to scan the source from there again.
+func (l *Lexer) Reset(pos token.Position) {
+	l.start = pos.Offset
+	l.cur = pos.Offset
+	l.line = pos.Line
+	l.col = pos.Col
+	l.width = 0
+}
+
 func (l *Lexer) ScanToken() (*token.Token, error) {
 	if !l.isEnd() {
 		l.start = l.cur
@@ -44,6 +63,7 @@ func (l *Lexer) ScanToken() (*token.Token, error) {
 		TokenType: token.TOKEN_EOF,
 		Line:      l.line,
 		Col:       l.col,
+		Offset:    len(l.source),
 	}, nil
 }
 
@@ -105,6 +125,8 @@ func (l *Lexer) scanToken() (*token.Token, error) {
 			} else {
 				tok = l.newToken(token.TOKEN_EQUAL_EQUAL)
 			}
+		} else if l.match('>') {
+			tok = l.newToken(token.TOKEN_EQUAL_GREATER)
 		} else {
 			tok = l.newToken(token.TOKEN_EQUAL)
 		}
@@ -298,6 +320,8 @@ func (l *Lexer) newTokenWithLiteral(tok token.TokenType, lit string) *token.Toke
 		Line:      l.line,
 		Col:       l.col,
 		Literal:   lit,
+		Offset:    l.start,
+		Raw:       string(l.source[l.start:l.cur]),
 	}
 }
 
@@ -449,3 +473,33 @@ func (l *Lexer) getCurrentLine() string {
 	}
 	return string(l.source[lineStart:lineEnd])
 }
+
+// SourceLine returns the text of the nth line of the source, counted from 1,
+// without the line terminator.
+func (l *Lexer) SourceLine(n int) string {
+	source := string(l.source)
+	line := 1
+	start := 0
+	for i, c := range source {
+		switch c {
+		case '\r':
+			if i+1 < len(source) && source[i+1] == '\n' {
+				continue
+			}
+		case '\n', 0x2028, 0x2029:
+		default:
+			continue
+		}
+
+		if line == n {
+			return strings.TrimSuffix(source[start:i], "\r")
+		}
+		line++
+		start = i + utf8.RuneLen(c)
+	}
+
+	if line == n {
+		return source[start:]
+	}
+	return ""
+}
@@ -0,0 +1,403 @@
+package minify
+
+import (
+	"bytes"
+	"math"
+
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/ast/astutil"
+	"github.com/ghosind/gjs/optimize"
+	"github.com/ghosind/gjs/printer"
+	"github.com/ghosind/gjs/runtime"
+	"github.com/ghosind/gjs/token"
+)
+
+// folder folds the constant expressions of a program, and removes its dead
+// code.
+type folder struct {
+	*optimize.Folder
+}
+
+// fold folds the constant expressions and removes the dead code of program.
+func fold(program *ast.Program, env *runtime.Runtime) {
+	f := &folder{Folder: optimize.NewFolder(env)}
+	astutil.Apply(program, nil, func(c *astutil.Cursor) bool {
+		f.node(c)
+		return true
+	})
+}
+
+func (f *folder) node(c *astutil.Cursor) {
+	switch n := c.Node().(type) {
+	case *ast.Literal:
+		if n.Kind == ast.LitNumber {
+			f.number(c, n)
+		}
+
+	case *ast.UnaryExpression:
+		switch n.Operator.TokenType {
+		case token.TOKEN_BANG:
+			if cmp, ok := n.Value.(*ast.BinaryExpression); ok && negate(cmp.Operator) != nil {
+				c.Replace(&ast.BinaryExpression{
+					Token:    cmp.Token,
+					Operator: negate(cmp.Operator),
+					Left:     cmp.Left,
+					Right:    cmp.Right,
+				})
+				return
+			}
+			f.constant(c, n)
+		case token.TOKEN_MINUS:
+			f.constant(c, n)
+		}
+
+	case *ast.BinaryExpression:
+		f.constant(c, n)
+
+	case *ast.LogicalExpression:
+		if f.constant(c, n) {
+			return
+		}
+		// The right operand is left out if the left one decides the result.
+		truthy, ok := f.Truthy(n.Left)
+		if !ok {
+			return
+		}
+		switch n.Operator.TokenType {
+		case token.TOKEN_AND_AND:
+			if truthy {
+				c.Replace(n.Right)
+			} else {
+				c.Replace(n.Left)
+			}
+		case token.TOKEN_PIPE_PIPE:
+			if truthy {
+				c.Replace(n.Left)
+			} else {
+				c.Replace(n.Right)
+			}
+		case token.TOKEN_QUESTION_QUESTION:
+			if lit, ok := n.Left.(*ast.Literal); ok && lit.Kind == ast.LitNull {
+				c.Replace(n.Right)
+			} else {
+				c.Replace(n.Left)
+			}
+		}
+
+	case *ast.MemberExpression:
+		if name, ok := identifierName(n.Property); ok && n.Computed {
+			n.Property = &ast.Identifier{Token: newToken(token.TOKEN_IDENTIFIER, name), Value: name}
+			n.Computed = false
+		}
+
+	case *ast.Property:
+		if name, ok := identifierName(n.Key); ok && !n.Computed {
+			n.Key = &ast.Identifier{Token: newToken(token.TOKEN_IDENTIFIER, name), Value: name}
+		}
+
+	case *ast.IfStatement:
+		f.ifStatement(c, n)
+
+	case *ast.WhileStatement:
+		n.Body = unwrap(n.Body)
+		if truthy, ok := f.Truthy(n.Condition); ok && !truthy && !hasDeclarations(n.Body) {
+			remove(c)
+		}
+
+	case *ast.DoWhileStatement:
+		n.Body = unwrap(n.Body)
+
+	case *ast.ForStatement:
+		n.Body = unwrap(n.Body)
+		if truthy, ok := f.Truthy(n.Condition); ok && truthy {
+			n.Condition = nil
+		}
+
+	case *ast.ForInStatement:
+		n.Body = unwrap(n.Body)
+
+	case *ast.ForOfStatement:
+		n.Body = unwrap(n.Body)
+
+	case *ast.Program:
+		n.Statements = statements(n.Statements)
+
+	case *ast.BlockStatement:
+		n.StatementList = statements(n.StatementList)
+
+	case *ast.SwitchCase:
+		n.Consequent = statements(n.Consequent)
+	}
+}
+
+// constant replaces the expression at c by its value, if it is a constant
+// expression, and the value is not longer to print.
+func (f *folder) constant(c *astutil.Cursor, expr ast.Expression) bool {
+	folded, ok := f.Fold(expr)
+	if !ok {
+		return false
+	}
+	folded = shorten(folded)
+	if size(folded) > size(expr) {
+		return false
+	}
+	c.Replace(folded)
+	return true
+}
+
+// number replaces a number literal by the shortest source of its value.
+func (f *folder) number(c *astutil.Cursor, lit *ast.Literal) {
+	if folded, ok := f.Fold(lit); ok && size(folded) < size(lit) {
+		c.Replace(folded)
+	}
+}
+
+func (f *folder) ifStatement(c *astutil.Cursor, n *ast.IfStatement) {
+	n.TrueBranch = unwrap(n.TrueBranch)
+	if n.FalseBranch != nil {
+		n.FalseBranch = unwrap(n.FalseBranch)
+		if _, ok := n.FalseBranch.(*ast.EmptyStatement); ok {
+			n.FalseBranch = nil
+		}
+	}
+
+	if truthy, ok := f.Truthy(n.Condition); ok {
+		taken, dead := n.TrueBranch, n.FalseBranch
+		if !truthy {
+			taken, dead = dead, taken
+		}
+		if dead == nil || !hasDeclarations(dead) {
+			if taken == nil {
+				remove(c)
+			} else {
+				notDirective(taken)
+				c.Replace(taken)
+			}
+			return
+		}
+	}
+
+	if n.FalseBranch != nil {
+		return
+	}
+	switch body := n.TrueBranch.(type) {
+	case *ast.ExpressionStatement:
+		// if (a) b(); is a && b();
+		c.Replace(&ast.ExpressionStatement{
+			Token:    n.Token,
+			EndToken: body.EndToken,
+			Expression: &ast.LogicalExpression{
+				Operator: newTokenPtr(token.TOKEN_AND_AND, "&&"),
+				Left:     n.Condition,
+				Right:    body.Expression,
+			},
+		})
+	case *ast.EmptyStatement:
+		c.Replace(&ast.ExpressionStatement{Token: n.Token, Expression: n.Condition})
+	}
+}
+
+// remove removes the statement at c, or replaces it by an empty statement
+// if it is not in a list.
+func remove(c *astutil.Cursor) {
+	if c.Index() >= 0 {
+		c.Delete()
+	} else {
+		c.Replace(&ast.EmptyStatement{})
+	}
+}
+
+// statements returns list without the empty statements and the statements
+// after a jump that are never reached.
+func statements(list []ast.Statement) []ast.Statement {
+	prologue := 0
+	for prologue < len(list) && isString(list[prologue]) {
+		prologue++
+	}
+
+	res := list[:0]
+	reached := true
+	for _, stmt := range list {
+		switch stmt.(type) {
+		case *ast.EmptyStatement:
+			continue
+		case *ast.FunctionDeclaration:
+			// The functions are declared before the statements run.
+		default:
+			if !reached && !hasDeclarations(stmt) {
+				continue
+			}
+		}
+		if len(res) >= prologue && isPrologue(res) {
+			// It was not a directive, as a statement was removed before it.
+			notDirective(stmt)
+		}
+		res = append(res, stmt)
+
+		switch stmt.(type) {
+		case *ast.ReturnStatement, *ast.ThrowStatement, *ast.BreakStatement, *ast.ContinueStatement:
+			reached = false
+		}
+	}
+	return res
+}
+
+// isPrologue reports whether list has only string expression statements.
+func isPrologue(list []ast.Statement) bool {
+	for _, stmt := range list {
+		if !isString(stmt) {
+			return false
+		}
+	}
+	return true
+}
+
+// isString reports whether stmt is a string expression statement, which is a
+// directive at the start of a program or a function body.
+func isString(stmt ast.Statement) bool {
+	s, ok := stmt.(*ast.ExpressionStatement)
+	if !ok || s.Token.TokenType != token.TOKEN_STRING {
+		return false
+	}
+	lit, ok := s.Expression.(*ast.Literal)
+	return ok && lit.Kind == ast.LitString
+}
+
+// notDirective keeps a string expression statement that was not a directive
+// from being taken as one, after the statements before it are removed.
+func notDirective(stmt ast.Statement) {
+	if isString(stmt) {
+		stmt.(*ast.ExpressionStatement).Token.TokenType = token.TOKEN_LEFT_PAREN
+	}
+}
+
+// unwrap returns the statement of a block with a single statement, which is
+// the body of a compound statement.
+func unwrap(stmt ast.Statement) ast.Statement {
+	block, ok := stmt.(*ast.BlockStatement)
+	if !ok {
+		return stmt
+	}
+	switch len(block.StatementList) {
+	case 0:
+		return &ast.EmptyStatement{}
+	case 1:
+		switch block.StatementList[0].(type) {
+		case *ast.LexicalDeclaration, *ast.FunctionDeclaration:
+			return stmt
+		}
+		return block.StatementList[0]
+	}
+	return stmt
+}
+
+// hasDeclarations reports whether stmt declares a var binding or a function
+// out of a nested function, which would be declared even if stmt is not run.
+func hasDeclarations(stmt ast.Statement) bool {
+	found := false
+	ast.Inspect(stmt, func(node ast.Node) bool {
+		switch node.(type) {
+		case *ast.VarStatement, *ast.FunctionDeclaration:
+			found = true
+		case *ast.FunctionExpression, *ast.ArrowFunctionExpression:
+			return false
+		}
+		return !found
+	})
+	return found
+}
+
+// negate returns the operator of the negation of an equality, or nil if op
+// is not an equality operator.
+func negate(op *token.Token) *token.Token {
+	switch op.TokenType {
+	case token.TOKEN_EQUAL_EQUAL:
+		return newTokenPtr(token.TOKEN_BANG_EQUAL, "!=")
+	case token.TOKEN_BANG_EQUAL:
+		return newTokenPtr(token.TOKEN_EQUAL_EQUAL, "==")
+	case token.TOKEN_EQUAL_EQUAL_EQUAL:
+		return newTokenPtr(token.TOKEN_BANG_EQUAL_EQUAL, "!==")
+	case token.TOKEN_BANG_EQUAL_EQUAL:
+		return newTokenPtr(token.TOKEN_EQUAL_EQUAL_EQUAL, "===")
+	}
+	return nil
+}
+
+// identifierName returns the value of a string literal that is an identifier
+// name other than a keyword, to be used as the name of a property.
+func identifierName(expr ast.Expression) (string, bool) {
+	lit, ok := expr.(*ast.Literal)
+	if !ok || lit.Kind != ast.LitString || lit.Value == "" {
+		return "", false
+	}
+	for i, c := range lit.Value {
+		switch {
+		case c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return "", false
+		}
+	}
+	return lit.Value, token.LookupIdent(lit.Value) == token.TOKEN_IDENTIFIER
+}
+
+// size returns the length of the compact source of expr.
+func size(expr ast.Expression) int {
+	buf := new(bytes.Buffer)
+	if err := (&printer.Config{Compact: true}).Fprint(buf, expr); err != nil {
+		return math.MaxInt
+	}
+	return buf.Len()
+}
+
+func numberLiteral(s string) *ast.Literal {
+	tok := newToken(token.TOKEN_NUMBER, s)
+	tok.Raw = s
+	return &ast.Literal{Token: tok, Value: s, Kind: ast.LitNumber}
+}
+
+func newToken(typ token.TokenType, lit string) token.Token {
+	return token.Token{TokenType: typ, Literal: lit}
+}
+
+func newTokenPtr(typ token.TokenType, lit string) *token.Token {
+	tok := newToken(typ, lit)
+	return &tok
+}
+
+// shorten returns the shortest expression of a folded value, which is !0 or
+// !1 for a boolean.
+func shorten(expr ast.Expression) ast.Expression {
+	if lit, ok := expr.(*ast.Literal); ok && lit.Kind == ast.LitBoolean {
+		return boolean(lit.Value == "true")
+	}
+	return expr
+}
+
+// boolean returns the shortest expression of a boolean, which is !0 or !1.
+func boolean(b bool) ast.Expression {
+	n := "1"
+	if b {
+		n = "0"
+	}
+	return &ast.UnaryExpression{Operator: newTokenPtr(token.TOKEN_BANG, "!"), Value: numberLiteral(n)}
+}
+
+// shortenBooleans replaces the boolean literals of program by !0 and !1.
+func shortenBooleans(program *ast.Program) {
+	astutil.Apply(program, nil, func(c *astutil.Cursor) bool {
+		lit, ok := c.Node().(*ast.Literal)
+		if !ok || lit.Kind != ast.LitBoolean {
+			return true
+		}
+		switch c.Parent().(type) {
+		case *ast.Property, *ast.MemberExpression:
+			if c.Name() == "Key" || c.Name() == "Property" {
+				// The name of a property, as in a.true.
+				return true
+			}
+		}
+		c.Replace(boolean(lit.Value == "true"))
+		return true
+	})
+}
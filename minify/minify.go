@@ -0,0 +1,35 @@
+// Package minify rewrites parsed programs to smaller ones that evaluate the
+// same, to be printed by the printer in compact mode.
+//
+// The constant expressions are folded, the dead branches and the statements
+// that are never reached are removed, the conditional statements are
+// simplified, and the local bindings are renamed to short names.
+package minify
+
+import (
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/runtime"
+)
+
+// Options configures the minification of a program.
+type Options struct {
+	// KeepNames leaves the names of the bindings as they are.
+	KeepNames bool
+}
+
+// Program minifies program in place.
+//
+// The bindings of the global scope of scripts, the exported bindings of
+// modules, and the names of functions, which are visible to other programs or
+// as the name of a function, are not renamed. No binding is renamed if the
+// program has a with statement, whose object may shadow any binding.
+func Program(program *ast.Program, opts Options) {
+	env := runtime.New()
+	defer env.Close()
+
+	fold(program, env)
+	if !opts.KeepNames {
+		rename(program)
+	}
+	shortenBooleans(program)
+}
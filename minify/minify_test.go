@@ -0,0 +1,115 @@
+package minify_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/evaluator"
+	"github.com/ghosind/gjs/lexer"
+	"github.com/ghosind/gjs/minify"
+	"github.com/ghosind/gjs/parser"
+	"github.com/ghosind/gjs/printer"
+	"github.com/ghosind/gjs/runtime"
+	"github.com/ghosind/go-assert"
+)
+
+func parse(a *assert.Assertion, src string, opts parser.Options) *ast.Program {
+	program, err := parser.NewWithOptions(lexer.New([]byte(src)), opts).Parse()
+	a.NilNow(err, src)
+	return program
+}
+
+func minified(a *assert.Assertion, src string, opts parser.Options) string {
+	program := parse(a, src, opts)
+	minify.Program(program, minify.Options{})
+	buf := new(bytes.Buffer)
+	a.NilNow((&printer.Config{Compact: true}).Fprint(buf, program), src)
+	return buf.String()
+}
+
+func eval(a *assert.Assertion, src string) string {
+	env := runtime.New()
+	defer env.Close()
+	res := evaluator.New(env).Eval(parse(a, src, parser.Options{}))
+	if exc, ok := res.(*evaluator.Exception); ok {
+		return exc.Inspect()
+	}
+	a.NotNilNow(res, src)
+	return res.Inspect()
+}
+
+func TestMinify(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x = 1 + 2 * 3; y = 1 / 3; z = (1 / 3) * 3; w = -(2 - 5); v = 1.50", "x=7;y=1/3;z=1;w=3;v=1.5;"},
+		{"x = 1 < 2; y = null === null; z = 'a' === 'a'; w = !0", "x=!0;y=!0;z='a'==='a';w=!0;"},
+		{"x = o['a']; y = o['if']; z = {'b': 1, 'c d': 2}", "x=o.a;y=o['if'];z={b:1,'c d':2};"},
+		{"x = 1 && a; y = 0 && a; z = '' || a; w = null ?? a; v = 0 ?? a", "x=a;y=0;z=a;w=a;v=0;"},
+		{"if (a) { b(); } if (a) b(); else { c(); } if (a) {}", "a&&b();if(a)b();else c();a;"},
+		{"if (1) a(); else b(); if (0) { a(); } else b(); if (0) a();", "a();b();"},
+		{"if (0) { var x; } while (0) a(); for (; 1;) { a(); }", "if(0)var x;for(;;)a();"},
+		{"x = !(a === b); y = !(a != b); z = !(a < b)", "x=a!==b;y=a==b;z=!(a<b);"},
+		{"function f() { return 1; a(); function g() {} }", "function f(){return 1;function g(){}}"},
+		{";;'a'; b;", "('a');b;"},
+		{"function f(first, second) { var third = first + second; return third * second; }",
+			"function f(b,a){var c=b+a;return c*a;}"},
+		{"function f(a) { let b = a; { let c = b; g(c); } return function (d) { return a + d; }; }",
+			"function f(a){let b=a;{let a=b;g(a);}return function(b){return a+b;};}"},
+		{"function f(x) { var a = x; return a + b; }", "function f(a){var c=a;return c+b;}"},
+		{"function f(x) { var g = function () {}; var h = () => 1; return x; }",
+			"function f(a){var g=function(){};var h=()=>1;return a;}"},
+		{"function f(x) { var o = {x, y: x}; var {x: z, w} = o; return [z, w]; }",
+			"function f(a){var b={x:a,y:a};var{x:c,w:d}=b;return[c,d];}"},
+		{"var {a, ...r} = o; r; function f({b, ...s}) { return [b, s]; }",
+			"var{a,...r}=o;r;function f({b,...c}){return[b,c];}"},
+		{"function f(x) { with (x) { return y; } }", "function f(x){with(x){return y;}}"},
+		{"var x = true; let y = false; o.true = 1", "var x=!0;let y=!1;o.true=1;"},
+	}
+	for _, test := range tests {
+		a.EqualNow(minified(a, test.input, parser.Options{}), test.expected, test.input)
+	}
+}
+
+func TestMinifyModule(t *testing.T) {
+	a := assert.New(t)
+
+	opts := parser.Options{SourceType: ast.SourceModule}
+	a.EqualNow(minified(a, "import {value} from 'm'; const local = value; export const exported = local;"+
+		"export {local};", opts), "import{value as b}from'm';const a=b;export const exported=a;export{a as local};")
+}
+
+func TestMinifyKeepNames(t *testing.T) {
+	a := assert.New(t)
+
+	program := parse(a, "function f(x) { return x; }", parser.Options{})
+	minify.Program(program, minify.Options{KeepNames: true})
+	buf := new(bytes.Buffer)
+	a.NilNow((&printer.Config{Compact: true}).Fprint(buf, program))
+	a.EqualNow(buf.String(), "function f(x){return x;}")
+}
+
+func TestMinifyEvaluation(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []string{
+		"function sum(list) { var total = 0; for (const item of list) { total = total + item; } return total; }" +
+			"var result = sum([1, 2, 3 * 4]); result;",
+		"function counter() { let count = 0; return function () { count = count + 1; return count; }; }" +
+			"var next = counter(); next(); next();",
+		"function f() { var fn = function () {}; var arrow = () => {}; return [fn.name, arrow.name]; } f();",
+		"function f(x) { if (x > 1) { return 'big'; } else { return 'small'; } } [f(2), f(0)];",
+		"function f(a) { var b = { a, c: a * 2 }; var { a: d, c } = b; return d + c; } f(1 + 1);",
+		"function f() { if (true) { return 1; } return 2; } f();",
+		"function f(x) { var y = x && 2; var z = x || 3; return y + z; } f(0) * f(1);",
+		"function outer(x) { function inner(y) { return x - y; } return inner(1); } outer(10);",
+		"function f(x) { throw x * 2; } f(21);",
+	}
+	for _, src := range tests {
+		a.EqualNow(eval(a, minified(a, src, parser.Options{})), eval(a, src), src)
+	}
+}
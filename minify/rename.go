@@ -0,0 +1,277 @@
+package minify
+
+import (
+	"sort"
+
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/scope"
+	"github.com/ghosind/gjs/token"
+)
+
+// renamer holds the new names of the variables of a program.
+type renamer struct {
+	// ids are the identifiers that declare or reference each variable.
+	ids   map[*scope.Variable][]*ast.Identifier
+	names map[*scope.Variable]string
+	keep  map[*scope.Variable]bool
+	// named are the identifiers that name the anonymous functions assigned to
+	// them, as in var f = function () {}.
+	named      map[*ast.Identifier]bool
+	shorthands []*ast.Property
+}
+
+// rename renames the local bindings of program to the shortest names that
+// do not change the bindings the identifiers refer to.
+func rename(program *ast.Program) {
+	r := &renamer{
+		ids:   make(map[*scope.Variable][]*ast.Identifier),
+		names: make(map[*scope.Variable]string),
+		keep:  make(map[*scope.Variable]bool),
+		named: make(map[*ast.Identifier]bool),
+	}
+	ast.Inspect(program, r.prepare)
+	info := scope.Analyze(program)
+	if hasWith(info.Global) {
+		return
+	}
+
+	for _, stmt := range program.Statements {
+		if export, ok := stmt.(*ast.ExportNamedDeclaration); ok && export.Declaration != nil {
+			for _, id := range declaredNames(export.Declaration) {
+				r.keep[info.Declarations[id]] = true
+			}
+		}
+	}
+	reserved := make(map[string]bool)
+	r.collect(info.Global, reserved)
+
+	r.assign(info.Global, reserved)
+	for v, ids := range r.ids {
+		for _, id := range ids {
+			id.Value = r.names[v]
+		}
+	}
+	for _, prop := range r.shorthands {
+		if value := propertyValue(prop); value.Value != prop.Key.(*ast.Identifier).Value {
+			prop.Shorthand = false
+		}
+	}
+}
+
+// prepare separates the identifiers shared by the nodes of the program, to
+// print them apart if they are renamed, and records the identifiers that name
+// anonymous functions.
+func (r *renamer) prepare(node ast.Node) bool {
+	switch n := node.(type) {
+	case *ast.Property:
+		r.shorthand(n)
+	case *ast.ImportSpecifier:
+		if n.Imported == ast.Expression(n.Local) {
+			n.Imported = copyIdentifier(n.Local)
+		}
+	case *ast.ExportNamedDeclaration:
+		if n.Source != nil {
+			return false
+		}
+		for _, spec := range n.Specifiers {
+			if local, ok := spec.Local.(*ast.Identifier); ok && spec.Exported == spec.Local {
+				spec.Exported = copyIdentifier(local)
+			}
+		}
+	case *ast.VariableDeclaration:
+		if id, ok := n.Name.(*ast.Identifier); ok && isAnonymousFunction(n.Value) {
+			r.named[id] = true
+		}
+	case *ast.AssignmentExpression:
+		if id, ok := n.Left.(*ast.Identifier); ok && isAnonymousFunction(n.Right) {
+			r.named[id] = true
+		}
+	case *ast.AssignmentPattern:
+		if id, ok := n.Left.(*ast.Identifier); ok && isAnonymousFunction(n.Right) {
+			r.named[id] = true
+		}
+	}
+	return true
+}
+
+// collect collects the identifiers of the variables of s and of its
+// children, and marks the variables whose names may not be changed, adding
+// their names to reserved. The names of the variables of the global scope,
+// of functions, and of the variables referenced by JSX elements, which are
+// visible out of the program or as the name of a function, are kept.
+func (r *renamer) collect(s *scope.Scope, reserved map[string]bool) {
+	for _, v := range s.Variables {
+		r.names[v] = v.Name
+		seen := make(map[*ast.Identifier]bool)
+		add := func(id *ast.Identifier) {
+			if !seen[id] {
+				seen[id] = true
+				r.ids[v] = append(r.ids[v], id)
+			}
+			if r.named[id] {
+				r.keep[v] = true
+			}
+		}
+		for _, id := range v.Identifiers {
+			add(id)
+		}
+		for _, ref := range v.References {
+			if id, ok := ref.Node.(*ast.Identifier); ok {
+				add(id)
+			} else {
+				r.keep[v] = true
+			}
+		}
+
+		if s.Kind == scope.Global || v.Kind == scope.DeclFunction || v.Kind == scope.DeclFunctionName {
+			r.keep[v] = true
+		}
+		if r.keep[v] {
+			reserved[v.Name] = true
+		}
+	}
+	for _, child := range s.Children {
+		r.collect(child, reserved)
+	}
+}
+
+// assign assigns the new names of the variables of s and of its children.
+// The variables that are referenced more often get the shorter names.
+func (r *renamer) assign(s *scope.Scope, reserved map[string]bool) {
+	taken := make(map[string]bool)
+	for _, v := range s.FreeVariables() {
+		taken[r.names[v]] = true
+	}
+	vars := append([]*scope.Variable(nil), s.Variables...)
+	sort.SliceStable(vars, func(i, j int) bool {
+		return len(r.ids[vars[i]]) > len(r.ids[vars[j]])
+	})
+	n := 0
+	for _, v := range vars {
+		if r.keep[v] {
+			continue
+		}
+		for {
+			name := shortName(n)
+			n++
+			if !reserved[name] && !taken[name] && token.LookupIdent(name) == token.TOKEN_IDENTIFIER {
+				r.names[v] = name
+				taken[name] = true
+				break
+			}
+		}
+	}
+	for _, child := range s.Children {
+		r.assign(child, reserved)
+	}
+}
+
+// hasWith reports whether s or any of its children is the scope of a with
+// statement, whose object may shadow any binding.
+func hasWith(s *scope.Scope) bool {
+	if s.Kind == scope.With {
+		return true
+	}
+	for _, child := range s.Children {
+		if hasWith(child) {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	nameStart = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ_$"
+	namePart  = nameStart + "0123456789"
+)
+
+// shortName returns the nth shortest identifier name.
+func shortName(n int) string {
+	name := []byte{nameStart[n%len(nameStart)]}
+	for n /= len(nameStart); n > 0; n /= len(namePart) {
+		n--
+		name = append(name, namePart[n%len(namePart)])
+	}
+	return string(name)
+}
+
+// shorthand separates the key of a shorthand property from the identifier
+// of its value, to print it as a property with a value if it is renamed.
+func (r *renamer) shorthand(prop *ast.Property) {
+	if !prop.Shorthand {
+		return
+	}
+	if key, ok := prop.Key.(*ast.Identifier); ok && key == propertyValue(prop) {
+		prop.Key = copyIdentifier(key)
+		r.shorthands = append(r.shorthands, prop)
+	}
+}
+
+// propertyValue returns the identifier of the value of a shorthand property.
+func propertyValue(prop *ast.Property) *ast.Identifier {
+	value := prop.Value
+	if pattern, ok := value.(*ast.AssignmentPattern); ok {
+		value = pattern.Left
+	}
+	id, _ := value.(*ast.Identifier)
+	return id
+}
+
+// declaredNames returns the identifiers of the bindings of a declaration.
+func declaredNames(decl ast.Node) []*ast.Identifier {
+	var ids []*ast.Identifier
+	var pattern func(node ast.Expression)
+	pattern = func(node ast.Expression) {
+		switch n := node.(type) {
+		case *ast.Identifier:
+			ids = append(ids, n)
+		case *ast.AssignmentPattern:
+			pattern(n.Left)
+		case *ast.ArrayPattern:
+			for _, elem := range n.Elements {
+				pattern(elem)
+			}
+		case *ast.ObjectPattern:
+			for _, prop := range n.Properties {
+				if p, ok := prop.(*ast.Property); ok {
+					pattern(p.Value)
+				} else {
+					pattern(prop)
+				}
+			}
+		case *ast.RestElement:
+			pattern(n.Argument)
+		}
+	}
+
+	var list []ast.Declaration
+	switch n := decl.(type) {
+	case *ast.VarStatement:
+		list = n.Declarations
+	case *ast.LexicalDeclaration:
+		list = n.Declarations
+	case *ast.FunctionDeclaration:
+		return []*ast.Identifier{n.Name}
+	}
+	for _, decl := range list {
+		pattern(decl.(*ast.VariableDeclaration).Name)
+	}
+	return ids
+}
+
+// isAnonymousFunction reports whether expr is a function without a name,
+// which is named by the binding it is assigned to.
+func isAnonymousFunction(expr ast.Expression) bool {
+	switch n := expr.(type) {
+	case *ast.FunctionExpression:
+		return n.Name == nil
+	case *ast.ArrowFunctionExpression:
+		return true
+	}
+	return false
+}
+
+func copyIdentifier(id *ast.Identifier) *ast.Identifier {
+	c := *id
+	return &c
+}
@@ -0,0 +1,94 @@
+package optimize
+
+import (
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/ast/astutil"
+	"github.com/ghosind/gjs/runtime"
+	"github.com/ghosind/gjs/token"
+)
+
+// branchPass removes the branches of the if statements and the conditional
+// expressions that are never taken, as their conditions are constants.
+type branchPass struct{}
+
+func (branchPass) Name() string { return "branches" }
+
+func (branchPass) Run(program *ast.Program, env *runtime.Runtime) {
+	f := NewFolder(env)
+	astutil.Apply(program, nil, func(c *astutil.Cursor) bool {
+		switch n := c.Node().(type) {
+		case *ast.IfStatement:
+			ifStatement(c, n, f)
+		case *ast.TernaryExpression:
+			if isReference(c) {
+				return true
+			}
+			if truthy, ok := f.Truthy(n.Condition); ok {
+				if truthy {
+					c.Replace(n.TrueBranch)
+				} else {
+					c.Replace(n.FalseBranch)
+				}
+			}
+		}
+		return true
+	})
+}
+
+func ifStatement(c *astutil.Cursor, n *ast.IfStatement, f *Folder) {
+	truthy, ok := f.Truthy(n.Condition)
+	if !ok {
+		return
+	}
+	taken, dead := n.TrueBranch, n.FalseBranch
+	if !truthy {
+		taken, dead = dead, taken
+	}
+	if dead != nil && hasDeclarations(dead) {
+		// The bindings of the dead branch are declared all the same.
+		return
+	}
+	switch taken.(type) {
+	case nil:
+		if c.Index() >= 0 {
+			c.Delete()
+		} else {
+			c.Replace(&ast.EmptyStatement{})
+		}
+	case *ast.FunctionDeclaration:
+		// A function declared as the body of an if statement is scoped to
+		// the statement.
+	default:
+		c.Replace(taken)
+	}
+}
+
+// isReference reports whether the node of c is used as a reference rather
+// than a value. The branches may not replace it, as a member expression
+// called as `o.f()` is called with o as this, and `delete o.f` deletes the
+// property.
+func isReference(c *astutil.Cursor) bool {
+	switch n := c.Parent().(type) {
+	case *ast.CallExpression, *ast.NewExpression:
+		return c.Name() == "Callee"
+	case *ast.UnaryExpression:
+		return n.Operator.TokenType == token.TOKEN_DELETE
+	}
+	return false
+}
+
+// hasDeclarations reports whether stmt declares a var binding or a function
+// out of a nested function, which would be declared even if stmt is not run.
+func hasDeclarations(stmt ast.Statement) bool {
+	found := false
+	ast.Inspect(stmt, func(node ast.Node) bool {
+		switch node.(type) {
+		case *ast.VarStatement, *ast.FunctionDeclaration:
+			found = true
+		case *ast.FunctionExpression, *ast.ArrowFunctionExpression:
+			return false
+		}
+		return !found
+	})
+	return found
+}
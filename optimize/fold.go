@@ -0,0 +1,163 @@
+package optimize
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/ast/astutil"
+	"github.com/ghosind/gjs/evaluator"
+	"github.com/ghosind/gjs/runtime"
+	"github.com/ghosind/gjs/token"
+	"github.com/ghosind/gjs/value"
+)
+
+// Folder folds the constant expressions of programs with an evaluator, so the
+// folded values are the ones the programs would evaluate to.
+type Folder struct {
+	eval *evaluator.Evaluator
+}
+
+// NewFolder returns a folder that evaluates the constant expressions in env.
+func NewFolder(env *runtime.Runtime) *Folder {
+	return &Folder{eval: evaluator.New(env)}
+}
+
+// Fold returns the literal of the value of expr, if expr is a constant
+// expression whose value has a literal.
+func (f *Folder) Fold(expr ast.Expression) (ast.Expression, bool) {
+	if !isConstant(expr) {
+		return nil, false
+	}
+	if bin, ok := expr.(*ast.BinaryExpression); ok {
+		left, right := f.eval.Eval(bin.Left), f.eval.Eval(bin.Right)
+		if !foldable(bin.Operator, left, right) {
+			return nil, false
+		}
+	}
+	return literalOf(f.eval.Eval(expr))
+}
+
+// Truthy reports whether expr converts to true, if it is a constant
+// expression.
+func (f *Folder) Truthy(expr ast.Expression) (truthy, ok bool) {
+	if expr == nil || !isConstant(expr) {
+		return false, false
+	}
+	// The ! operator of the evaluator converts constants as the conditions
+	// do.
+	not := f.eval.Eval(&ast.UnaryExpression{Operator: newTokenPtr(token.TOKEN_BANG, "!"), Value: expr})
+	b, ok := not.(*value.Boolean)
+	if !ok {
+		return false, false
+	}
+	return !b.Value, true
+}
+
+// foldPass folds the constant expressions of the operators.
+type foldPass struct{}
+
+func (foldPass) Name() string { return "fold" }
+
+func (foldPass) Run(program *ast.Program, env *runtime.Runtime) {
+	f := NewFolder(env)
+	astutil.Apply(program, nil, func(c *astutil.Cursor) bool {
+		switch n := c.Node().(type) {
+		case *ast.UnaryExpression:
+			if _, ok := n.Value.(*ast.Literal); ok && n.Operator.TokenType == token.TOKEN_MINUS {
+				// A negative number, which is already folded.
+				return true
+			}
+		case *ast.BinaryExpression, *ast.LogicalExpression:
+		default:
+			return true
+		}
+		if folded, ok := f.Fold(c.Node().(ast.Expression)); ok {
+			c.Replace(folded)
+		}
+		return true
+	})
+}
+
+// isConstant reports whether expr has no other operands than literals, and
+// its value is known without running the program.
+func isConstant(expr ast.Expression) bool {
+	switch n := expr.(type) {
+	case *ast.Literal:
+		return true
+	case *ast.UnaryExpression:
+		switch n.Operator.TokenType {
+		case token.TOKEN_BANG, token.TOKEN_MINUS:
+			return isConstant(n.Value)
+		}
+	case *ast.BinaryExpression:
+		switch n.Operator.TokenType {
+		case token.TOKEN_IN, token.TOKEN_INSTANCEOF:
+			return false
+		}
+		return isConstant(n.Left) && isConstant(n.Right)
+	case *ast.LogicalExpression:
+		return isConstant(n.Left) && isConstant(n.Right)
+	}
+	return false
+}
+
+// foldable reports whether a binary operator is folded for the values of its
+// operands. Only the numbers, and the equality of the values other than
+// strings, are compared as the language does.
+func foldable(op *token.Token, left, right value.Value) bool {
+	_, leftNumber := left.(*value.Number)
+	_, rightNumber := right.(*value.Number)
+	if leftNumber && rightNumber {
+		return true
+	}
+	switch op.TokenType {
+	case token.TOKEN_EQUAL_EQUAL_EQUAL, token.TOKEN_BANG_EQUAL_EQUAL:
+		_, leftString := left.(*value.String)
+		_, rightString := right.(*value.String)
+		return !leftString && !rightString
+	}
+	return false
+}
+
+// literalOf returns the expression of a primitive value. Numbers that are not
+// finite, and negative zero, have no literals and are not folded.
+func literalOf(val value.Value) (ast.Expression, bool) {
+	switch val := val.(type) {
+	case *value.Number:
+		n := val.Value
+		if math.IsNaN(n) || math.IsInf(n, 0) || n == 0 && math.Signbit(n) {
+			return nil, false
+		}
+		lit := numberLiteral(strconv.FormatFloat(math.Abs(n), 'f', -1, 64))
+		if n < 0 {
+			return &ast.UnaryExpression{Operator: newTokenPtr(token.TOKEN_MINUS, "-"), Value: lit}, true
+		}
+		return lit, true
+	case *value.String:
+		return &ast.Literal{Token: newToken(token.TOKEN_STRING, val.Value), Value: val.Value, Kind: ast.LitString}, true
+	case *value.Boolean:
+		if val.Value {
+			return &ast.Literal{Token: newToken(token.TOKEN_TRUE, "true"), Value: "true", Kind: ast.LitBoolean}, true
+		}
+		return &ast.Literal{Token: newToken(token.TOKEN_FALSE, "false"), Value: "false", Kind: ast.LitBoolean}, true
+	case *value.Null:
+		return &ast.Literal{Token: newToken(token.TOKEN_NULL, "null"), Value: "null", Kind: ast.LitNull}, true
+	}
+	return nil, false
+}
+
+func numberLiteral(s string) *ast.Literal {
+	tok := newToken(token.TOKEN_NUMBER, s)
+	tok.Raw = s
+	return &ast.Literal{Token: tok, Value: s, Kind: ast.LitNumber}
+}
+
+func newToken(typ token.TokenType, lit string) token.Token {
+	return token.Token{TokenType: typ, Literal: lit}
+}
+
+func newTokenPtr(typ token.TokenType, lit string) *token.Token {
+	tok := newToken(typ, lit)
+	return &tok
+}
@@ -0,0 +1,29 @@
+package optimize
+
+import (
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/evaluator"
+	"github.com/ghosind/gjs/runtime"
+	"github.com/ghosind/gjs/value"
+)
+
+// literalPass converts the number literals to their values, which the
+// evaluator would otherwise parse every time they are evaluated.
+type literalPass struct{}
+
+func (literalPass) Name() string { return "literals" }
+
+func (literalPass) Run(program *ast.Program, env *runtime.Runtime) {
+	eval := evaluator.New(env)
+	ast.Inspect(program, func(node ast.Node) bool {
+		lit, ok := node.(*ast.Literal)
+		if !ok || lit.Kind != ast.LitNumber || lit.Number != nil {
+			return true
+		}
+		if num, ok := eval.Eval(lit).(*value.Number); ok {
+			n := num.Value
+			lit.Number = &n
+		}
+		return true
+	})
+}
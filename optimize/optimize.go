@@ -0,0 +1,87 @@
+// Package optimize rewrites parsed programs to ones the evaluator runs
+// faster, with the same results.
+//
+// The optimizations are passes over a program, which run at an optimization
+// level or above. At O1 the number literals are converted ahead of the
+// evaluation. At O2 the constant expressions are folded as well, and the
+// branches of the if statements and the conditional expressions with
+// constant conditions that are never taken are removed. More passes may be
+// added with Register.
+package optimize
+
+import (
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/runtime"
+)
+
+// Level is an optimization level, from O0, at which no pass runs.
+type Level int
+
+const (
+	O0 Level = iota
+	O1
+	O2
+	// MaxLevel is the highest level of the passes of this package.
+	MaxLevel = O2
+)
+
+// Pass is an optimization pass.
+type Pass interface {
+	// Name returns the name of the pass, such as "fold".
+	Name() string
+	// Run rewrites program in place. The constant expressions are evaluated
+	// in env, if the pass evaluates them.
+	Run(program *ast.Program, env *runtime.Runtime)
+}
+
+type registered struct {
+	pass  Pass
+	level Level
+}
+
+// passes are the registered passes, in the order they run.
+var passes []registered
+
+func init() {
+	Register(O2, foldPass{})
+	Register(O2, branchPass{})
+	// The literals are converted last, so the literals of the folded values
+	// are converted as well.
+	Register(O1, literalPass{})
+}
+
+// Register adds a pass, which runs at level or above, after the passes
+// registered before it. It is meant to be called from the init functions of
+// the packages of the passes, and is not safe to call along with Program.
+func Register(level Level, pass Pass) {
+	passes = append(passes, registered{pass: pass, level: level})
+}
+
+// Passes returns the passes that run at level, in order.
+func Passes(level Level) []Pass {
+	list := make([]Pass, 0, len(passes))
+	for _, r := range passes {
+		if level >= r.level {
+			list = append(list, r.pass)
+		}
+	}
+	return list
+}
+
+// Program runs the passes of level on program in place.
+func Program(program *ast.Program, level Level) {
+	Run(program, Passes(level)...)
+}
+
+// Run runs the passes on program in place, in order.
+func Run(program *ast.Program, passes ...Pass) {
+	if len(passes) == 0 {
+		return
+	}
+	env := runtime.New()
+	defer env.Close()
+
+	for _, pass := range passes {
+		pass.Run(program, env)
+	}
+}
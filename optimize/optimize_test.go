@@ -0,0 +1,167 @@
+package optimize_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/evaluator"
+	"github.com/ghosind/gjs/lexer"
+	"github.com/ghosind/gjs/optimize"
+	"github.com/ghosind/gjs/parser"
+	"github.com/ghosind/gjs/printer"
+	"github.com/ghosind/gjs/runtime"
+	"github.com/ghosind/go-assert"
+)
+
+func parse(a *assert.Assertion, src string) *ast.Program {
+	program, err := parser.New(lexer.New([]byte(src))).Parse()
+	a.NilNow(err, src)
+	return program
+}
+
+func optimized(a *assert.Assertion, src string, level optimize.Level) string {
+	program := parse(a, src)
+	optimize.Program(program, level)
+	buf := new(bytes.Buffer)
+	a.NilNow((&printer.Config{Compact: true}).Fprint(buf, program), src)
+	return buf.String()
+}
+
+func eval(program *ast.Program) string {
+	env := runtime.New()
+	defer env.Close()
+	res := evaluator.New(env).Eval(program)
+	if exc, ok := res.(*evaluator.Exception); ok {
+		return exc.Inspect()
+	}
+	return res.Inspect()
+}
+
+func names(passes []optimize.Pass) []string {
+	list := make([]string, 0, len(passes))
+	for _, pass := range passes {
+		list = append(list, pass.Name())
+	}
+	return list
+}
+
+func TestPasses(t *testing.T) {
+	a := assert.New(t)
+
+	a.DeepEqualNow(names(optimize.Passes(optimize.O0)), []string{})
+	a.DeepEqualNow(names(optimize.Passes(optimize.O1)), []string{"literals"})
+	a.DeepEqualNow(names(optimize.Passes(optimize.O2)), []string{"fold", "branches", "literals"})
+}
+
+func TestFold(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x = 1 + 2 * 3; y = 1 / 3; z = -(2 - 5); w = 1 / 0; v = -(0)", "x=7;y=0.3333333333333333;z=3;w=1/0;v=-0;"},
+		{"x = 1 < 2; y = null === null; z = 'a' === 'a'; w = !0; v = 10 + 010", "x=true;y=true;z='a'==='a';w=true;v=18;"},
+		{"x = 1 && 2; y = 0 || null; z = a + (1 + 1); w = 1 in o", "x=2;y=null;z=a+2;w=1 in o;"},
+	}
+	for _, test := range tests {
+		a.EqualNow(optimized(a, test.input, optimize.O2), test.expected, test.input)
+		a.EqualNow(optimized(a, test.input, optimize.O1), optimized(a, test.input, optimize.O0), test.input)
+	}
+}
+
+func TestBranches(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"if (1) a(); else b(); if (0) { a(); } else { b(); } if (0) a();", "a();{b();}"},
+		{"if (1 > 2) a(); else if (a) b(); while (a) if ('') b();", "if(a)b();while(a);"},
+		{"if (0) { var x; } if (1) a(); else function f() {}", "if(0){var x;}if(1)a();else function f(){}"},
+		{"x = 1 ? a : b; y = 0 ? a : b; z = 2 - 2 ? a : b; w = a ? 1 : 2", "x=a;y=b;z=b;w=a?1:2;"},
+		{"(1 ? o.f : o.g)(); new (0 ? o.F : o.G)(); delete (1 ? o.f : o.g); x = (1 ? o.f : o.g)", "(1?o.f:o.g)();new(0?o.F:o.G)();delete(1?o.f:o.g);x=o.f;"},
+		{"1 ? o.f() : o.g(); (0 ? f : g)(1 ? a : b)", "o.f();(0?f:g)(a);"},
+	}
+	for _, test := range tests {
+		a.EqualNow(optimized(a, test.input, optimize.O2), test.expected, test.input)
+	}
+}
+
+func TestLiterals(t *testing.T) {
+	a := assert.New(t)
+
+	program := parse(a, "x = [1.50, 010, 100, 'a'];")
+	optimize.Program(program, optimize.O1)
+	var numbers []float64
+	ast.Inspect(program, func(node ast.Node) bool {
+		if lit, ok := node.(*ast.Literal); ok {
+			if lit.Kind == ast.LitNumber {
+				a.NotNilNow(lit.Number, lit.Value)
+				numbers = append(numbers, *lit.Number)
+			} else {
+				a.NilNow(lit.Number)
+			}
+		}
+		return true
+	})
+	a.DeepEqualNow(numbers, []float64{1.5, 8, 100})
+	a.EqualNow(eval(program), "[1.5, 8, 100, a]")
+
+	// The literals of the folded values are converted as well.
+	program = parse(a, "1 + 2")
+	optimize.Program(program, optimize.O2)
+	lit := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.Literal)
+	a.NotNilNow(lit.Number)
+	a.EqualNow(*lit.Number, 3.0)
+}
+
+func TestEval(t *testing.T) {
+	a := assert.New(t)
+
+	srcs := []string{
+		"var s = 0; for (var i = 0; i < 10; i++) { if (1 < 2) s += i * (2 + 3); else s = -1; } s",
+		"function f(x) { if (2 > 1) return x * 0.5; return x; } [f(3), f(010), 1 === 1 && 0]",
+		"if (0) { var v = 1; } typeof v",
+	}
+	for _, src := range srcs {
+		expected := eval(parse(a, src))
+		for level := optimize.O1; level <= optimize.MaxLevel; level++ {
+			program := parse(a, src)
+			optimize.Program(program, level)
+			a.EqualNow(eval(program), expected, src)
+		}
+	}
+}
+
+type countPass struct {
+	literals int
+}
+
+func (p *countPass) Name() string { return "count" }
+
+func (p *countPass) Run(program *ast.Program, env *runtime.Runtime) {
+	ast.Inspect(program, func(node ast.Node) bool {
+		if _, ok := node.(*ast.Literal); ok {
+			p.literals++
+		}
+		return true
+	})
+}
+
+func TestRegister(t *testing.T) {
+	a := assert.New(t)
+
+	pass := new(countPass)
+	optimize.Register(optimize.MaxLevel+1, pass)
+	list := names(optimize.Passes(optimize.MaxLevel + 1))
+	a.EqualNow(list[len(list)-1], "count")
+	a.DeepEqualNow(names(optimize.Passes(optimize.MaxLevel)), []string{"fold", "branches", "literals"})
+
+	optimize.Program(parse(a, "x = 1 + 2 + y"), optimize.MaxLevel+1)
+	a.EqualNow(pass.literals, 1)
+	optimize.Run(parse(a, "x = 1 + 2 + y"), pass)
+	a.EqualNow(pass.literals, 3)
+}
@@ -0,0 +1,802 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/token"
+)
+
+// Check reports the early errors of a program, the errors that the spec
+// requires to be detected before any code is evaluated. It returns an
+// ErrorList holding every violation, or nil if the program is valid.
+//
+// ParseProgram and ParseModule run Check on the programs they parse. It is
+// exported for programs built or transformed by other tools.
+func Check(program *ast.Program) error {
+	return check(program, false)
+}
+
+// check reports the early errors of a program, allowing return statements at
+// its top level if allowReturn is set.
+func check(program *ast.Program, allowReturn bool) error {
+	c := &checker{
+		module:      program.SourceType == ast.SourceModule,
+		strict:      program.Strict,
+		allowReturn: allowReturn,
+	}
+
+	kind := scopeScript
+	if c.module {
+		kind = scopeModule
+	}
+	c.declarations(program.Statements, kind, nil)
+	if c.module {
+		c.exports(program.Statements)
+	}
+	c.statements(program.Statements)
+
+	return c.errs.Err()
+}
+
+// checkExpression reports the early errors of an expression parsed on its
+// own.
+func checkExpression(expr ast.Expression, strict bool) error {
+	c := &checker{strict: strict}
+	c.expression(expr)
+	return c.errs.Err()
+}
+
+type scopeKind int
+
+const (
+	scopeScript scopeKind = iota
+	scopeModule
+	scopeFunction
+	scopeBlock
+)
+
+// checker walks a program and collects its early errors.
+type checker struct {
+	errs   ErrorList
+	module bool
+	strict bool
+	// allowReturn allows return statements outside of functions.
+	allowReturn bool
+
+	// noAwait and noYield are set while the parameters of an async function,
+	// or of a generator or an arrow function, are checked, which must not
+	// hold await or yield expressions.
+	noAwait bool
+	noYield bool
+
+	// The jump targets of the function being checked.
+	inFunction bool
+	labels     []*label
+	loops      int
+	switches   int
+}
+
+func (c *checker) errorf(tok *token.Token, format string, a ...any) {
+	c.errs = append(c.errs, &SyntaxError{tok: tok, msg: fmt.Sprintf(format, a...)})
+}
+
+func (c *checker) statements(list []ast.Statement) {
+	for _, stmt := range list {
+		c.statement(stmt, nil)
+	}
+}
+
+// statement checks a statement. labelSet holds the labels that directly label
+// the statement.
+func (c *checker) statement(stmt ast.Statement, labelSet []*label) {
+	switch stmt := stmt.(type) {
+	case *ast.BlockStatement:
+		c.declarations(stmt.StatementList, scopeBlock, nil)
+		c.statements(stmt.StatementList)
+	case *ast.VarStatement:
+		c.variableDeclarations(stmt.Declarations)
+	case *ast.LexicalDeclaration:
+		c.variableDeclarations(stmt.Declarations)
+	case *ast.ExpressionStatement:
+		c.expression(stmt.Expression)
+	case *ast.IfStatement:
+		c.expression(stmt.Condition)
+		c.statement(stmt.TrueBranch, nil)
+		if stmt.FalseBranch != nil {
+			c.statement(stmt.FalseBranch, nil)
+		}
+	case *ast.ForStatement:
+		c.forHead(stmt.Init, stmt.Body)
+		if init, ok := stmt.Init.(ast.Expression); ok {
+			c.expression(init)
+		} else if stmt.Init != nil {
+			c.statement(stmt.Init, nil)
+		}
+		if stmt.Condition != nil {
+			c.expression(stmt.Condition)
+		}
+		if stmt.Update != nil {
+			c.expression(stmt.Update)
+		}
+		c.loop(stmt.Body, labelSet)
+	case *ast.ForInStatement:
+		c.forInOf(stmt.Left, stmt.Right, stmt.Body, labelSet)
+	case *ast.ForOfStatement:
+		c.forInOf(stmt.Left, stmt.Right, stmt.Body, labelSet)
+	case *ast.WhileStatement:
+		c.expression(stmt.Condition)
+		c.loop(stmt.Body, labelSet)
+	case *ast.DoWhileStatement:
+		c.loop(stmt.Body, labelSet)
+		c.expression(stmt.Condition)
+	case *ast.WithStatement:
+		c.expression(stmt.Object)
+		c.statement(stmt.Body, nil)
+	case *ast.ContinueStatement:
+		c.continueStatement(stmt)
+	case *ast.BreakStatement:
+		c.breakStatement(stmt)
+	case *ast.ReturnStatement:
+		if !c.inFunction && !c.allowReturn {
+			c.errorf(&stmt.Token, "Illegal return statement")
+		}
+		if stmt.Result != nil {
+			c.expression(stmt.Result)
+		}
+	case *ast.SwitchStatement:
+		c.expression(stmt.Discriminant)
+		cases := stmt.Cases
+		if stmt.DefaultCase != nil {
+			cases = append(cases[:len(cases):len(cases)], *stmt.DefaultCase)
+		}
+		body := make([]ast.Statement, 0)
+		for _, switchCase := range cases {
+			body = append(body, switchCase.Consequent...)
+		}
+		c.declarations(body, scopeBlock, nil)
+
+		c.switches++
+		for _, switchCase := range cases {
+			if switchCase.Test != nil {
+				c.expression(switchCase.Test)
+			}
+			c.statements(switchCase.Consequent)
+		}
+		c.switches--
+	case *ast.LabeledStatement:
+		c.labeledStatement(stmt, labelSet)
+	case *ast.ThrowStatement:
+		c.expression(stmt.Argument)
+	case *ast.TryStatement:
+		c.statement(stmt.Block, nil)
+		if clause := stmt.CatchClause; clause != nil {
+			var params []*ast.Identifier
+			if clause.Param != nil {
+				params = append(params, clause.Param)
+			}
+			c.declarations(clause.Body.StatementList, scopeBlock, params)
+			c.statements(clause.Body.StatementList)
+		}
+		if stmt.Finally != nil {
+			c.statement(stmt.Finally, nil)
+		}
+	case *ast.FunctionDeclaration:
+		c.function(stmt.Params, stmt.Body, stmt.Strict, stmt.Async, stmt.Generator)
+	case *ast.ExportNamedDeclaration:
+		if stmt.Declaration != nil {
+			c.statement(stmt.Declaration, nil)
+		}
+	case *ast.ExportDefaultDeclaration:
+		switch decl := stmt.Declaration.(type) {
+		case *ast.FunctionDeclaration:
+			c.statement(decl, nil)
+		case ast.Expression:
+			c.expression(decl)
+		}
+	}
+}
+
+func (c *checker) variableDeclarations(decls []ast.Declaration) {
+	for _, decl := range decls {
+		decl := decl.(*ast.VariableDeclaration)
+		c.pattern(decl.Name)
+		if decl.Value != nil {
+			c.expression(decl.Value)
+		}
+	}
+}
+
+// loop checks the body of an iteration statement.
+func (c *checker) loop(body ast.Statement, labelSet []*label) {
+	markIterationLabels(labelSet)
+	c.loops++
+	c.statement(body, nil)
+	c.loops--
+}
+
+// forHead checks the bindings of a lexical declaration in the head of a for
+// statement, which must not be redeclared by a var declaration in the body.
+func (c *checker) forHead(head ast.Node, body ast.Statement) {
+	decl, ok := head.(*ast.LexicalDeclaration)
+	if !ok {
+		return
+	}
+
+	names := make(map[string]*ast.Identifier)
+	for _, id := range declarationNames(decl.Declarations) {
+		c.lexicalName(names, id)
+	}
+	for _, id := range varDeclaredNames(body) {
+		if prev, ok := names[id.Value]; ok {
+			c.redeclared(prev, id)
+		}
+	}
+}
+
+func (c *checker) forInOf(left ast.Node, right ast.Expression, body ast.Statement, labelSet []*label) {
+	c.forHead(left, body)
+	switch left := left.(type) {
+	case *ast.VarStatement:
+		c.variableDeclarations(left.Declarations)
+	case *ast.LexicalDeclaration:
+		c.variableDeclarations(left.Declarations)
+	case ast.Expression:
+		if !isAssignmentTarget(left, true) {
+			c.errorf(nodeToken(left), "Invalid left-hand side in for-loop")
+		}
+		c.pattern(left)
+	}
+	c.expression(right)
+	c.loop(body, labelSet)
+}
+
+func (c *checker) labeledStatement(stmt *ast.LabeledStatement, labelSet []*label) {
+	id, _ := stmt.Label.(*ast.Identifier)
+	if id == nil {
+		c.statement(stmt.Statement, labelSet)
+		return
+	}
+
+	if c.findLabel(id.Value) != nil {
+		c.errorf(&id.Token, "Label '%s' has already been declared", id.Value)
+	}
+
+	l := &label{name: id.Value}
+	c.labels = append(c.labels, l)
+	c.statement(stmt.Statement, append(labelSet, l))
+	c.labels = c.labels[:len(c.labels)-1]
+}
+
+func (c *checker) breakStatement(stmt *ast.BreakStatement) {
+	if id, ok := stmt.Label.(*ast.Identifier); ok {
+		if c.findLabel(id.Value) == nil {
+			c.errorf(&id.Token, "Undefined label '%s'", id.Value)
+		}
+	} else if c.loops == 0 && c.switches == 0 {
+		c.errorf(&stmt.Token, "Illegal break statement")
+	}
+}
+
+func (c *checker) continueStatement(stmt *ast.ContinueStatement) {
+	if id, ok := stmt.Label.(*ast.Identifier); ok {
+		if target := c.findLabel(id.Value); target == nil {
+			c.errorf(&id.Token, "Undefined label '%s'", id.Value)
+		} else if !target.loop {
+			c.errorf(&id.Token, "Illegal continue statement: '%s' does not denote an iteration statement", id.Value)
+		}
+	} else if c.loops == 0 {
+		c.errorf(&stmt.Token, "Illegal continue statement: no surrounding iteration statement")
+	}
+}
+
+func (c *checker) findLabel(name string) *label {
+	for i := len(c.labels) - 1; i >= 0; i-- {
+		if c.labels[i].name == name {
+			return c.labels[i]
+		}
+	}
+	return nil
+}
+
+// function checks the parameters and the body of a function. Jump targets do
+// not cross function boundaries. The parameters must not hold await
+// expressions if noAwait is set, nor yield expressions if noYield is set.
+func (c *checker) function(params []ast.Expression, body ast.Node, strict, noAwait, noYield bool) {
+	saved := *c
+	c.inFunction, c.labels, c.loops, c.switches = true, nil, 0, 0
+	c.strict = strict
+	defer func() {
+		c.inFunction, c.labels, c.loops, c.switches = saved.inFunction, saved.labels, saved.loops, saved.switches
+		c.strict = saved.strict
+		c.noAwait, c.noYield = saved.noAwait, saved.noYield
+	}()
+
+	c.noAwait, c.noYield = noAwait, noYield
+	for _, param := range params {
+		c.pattern(param)
+	}
+	c.noAwait, c.noYield = false, false
+
+	block, ok := body.(*ast.BlockStatement)
+	if !ok {
+		if expr, ok := body.(ast.Expression); ok {
+			c.expression(expr)
+		}
+		return
+	}
+
+	names := make([]*ast.Identifier, 0)
+	for _, param := range params {
+		names = append(names, boundNames(param)...)
+	}
+	c.declarations(block.StatementList, scopeFunction, names)
+	c.statements(block.StatementList)
+}
+
+// pattern checks the expressions in a binding or assignment pattern.
+func (c *checker) pattern(target ast.Expression) {
+	switch target := target.(type) {
+	case *ast.Identifier:
+	case *ast.AssignmentPattern:
+		c.pattern(target.Left)
+		c.expression(target.Right)
+	case *ast.RestElement:
+		c.pattern(target.Argument)
+	case *ast.Property:
+		if target.Computed {
+			c.expression(target.Key)
+		}
+		c.pattern(target.Value)
+	case *ast.ArrayPattern:
+		for _, elem := range target.Elements {
+			c.pattern(elem)
+		}
+	case *ast.ObjectPattern:
+		for _, prop := range target.Properties {
+			c.pattern(prop)
+		}
+	case nil, *ast.Elision:
+	default:
+		c.expression(target)
+	}
+}
+
+func (c *checker) expressions(list []ast.Expression) {
+	for _, expr := range list {
+		c.expression(expr)
+	}
+}
+
+func (c *checker) expression(expr ast.Expression) {
+	switch expr := expr.(type) {
+	case *ast.SpreadElement:
+		c.expression(expr.Value)
+	case *ast.ArrayLiteral:
+		c.expressions(expr.ElementList)
+	case *ast.ObjectLiteral:
+		c.objectLiteral(expr)
+	case *ast.Property:
+		if expr.Computed {
+			c.expression(expr.Key)
+		}
+		if init, ok := expr.Value.(*ast.AssignmentPattern); ok && expr.Shorthand {
+			// A CoverInitializedName, such as { a = 1 }, which is only valid
+			// in an assignment pattern.
+			c.errorf(nodeToken(init.Left), "Invalid shorthand property initializer")
+		}
+		c.expression(expr.Value)
+	case *ast.UnaryExpression:
+		c.expression(expr.Value)
+	case *ast.UpdateExpression:
+		if !isAssignmentTarget(expr.Argument, false) {
+			c.errorf(expr.Operator, "Invalid left-hand side expression in update operation")
+		}
+		c.expression(expr.Argument)
+	case *ast.BinaryExpression:
+		c.expression(expr.Left)
+		c.expression(expr.Right)
+	case *ast.LogicalExpression:
+		c.expression(expr.Left)
+		c.expression(expr.Right)
+	case *ast.SequenceExpression:
+		c.expressions(expr.Expressions)
+	case *ast.TernaryExpression:
+		c.expression(expr.Condition)
+		c.expression(expr.TrueBranch)
+		c.expression(expr.FalseBranch)
+	case *ast.AssignmentExpression:
+		if !isAssignmentTarget(expr.Left, expr.Operator.TokenType == token.TOKEN_EQUAL) {
+			c.errorf(expr.Operator, "Invalid left-hand side in assignment")
+		}
+		c.pattern(expr.Left)
+		c.expression(expr.Right)
+	case *ast.AssignmentPattern:
+		c.pattern(expr)
+	case *ast.ArrayPattern, *ast.ObjectPattern, *ast.RestElement:
+		c.pattern(expr)
+	case *ast.MemberExpression:
+		c.expression(expr.Object)
+		if expr.Computed {
+			c.expression(expr.Property)
+		}
+	case *ast.CallExpression:
+		c.expression(expr.Callee)
+		c.expressions(expr.Arguments)
+	case *ast.NewExpression:
+		c.expression(expr.Callee)
+		c.expressions(expr.Arguments)
+	case *ast.FunctionExpression:
+		// The name of a function expression is bound in its own scope.
+		if expr.Name != nil && expr.Async && expr.Name.Value == "await" {
+			c.errorf(&expr.Name.Token, "Unexpected reserved word")
+		} else if expr.Name != nil && expr.Generator && expr.Name.Value == "yield" {
+			c.errorf(&expr.Name.Token, "Unexpected identifier 'yield'")
+		}
+		c.function(expr.Params, expr.Body, expr.Strict, expr.Async, expr.Generator)
+	case *ast.ArrowFunctionExpression:
+		// The parameters of arrow functions hold no yield expressions, even
+		// in generators.
+		c.function(expr.Params, expr.Body, expr.Strict, expr.Async, true)
+	case *ast.AwaitExpression:
+		if c.noAwait {
+			c.errorf(&expr.Token, "Illegal await-expression in formal parameters of async function")
+		}
+		c.expression(expr.Argument)
+	case *ast.YieldExpression:
+		if c.noYield {
+			c.errorf(&expr.Token, "Yield expression not allowed in formal parameter")
+		}
+		if expr.Argument != nil {
+			c.expression(expr.Argument)
+		}
+	case *ast.ImportExpression:
+		c.expression(expr.Source)
+		if expr.Options != nil {
+			c.expression(expr.Options)
+		}
+	case *ast.JSXElement:
+		c.expressions(expr.OpeningElement.Attributes)
+		c.expressions(expr.Children)
+	case *ast.JSXFragment:
+		c.expressions(expr.Children)
+	case *ast.JSXAttribute:
+		if expr.Value != nil {
+			c.expression(expr.Value)
+		}
+	case *ast.JSXSpreadAttribute:
+		c.expression(expr.Argument)
+	case *ast.JSXExpressionContainer:
+		c.expression(expr.Expression)
+	case *ast.JSXSpreadChild:
+		c.expression(expr.Expression)
+	}
+}
+
+func (c *checker) objectLiteral(obj *ast.ObjectLiteral) {
+	hasProto := false
+	for _, prop := range obj.Properties {
+		if prop, ok := prop.(*ast.Property); ok && isProtoSetter(prop) {
+			if hasProto {
+				c.errorf(nodeToken(prop.Key), "Duplicate __proto__ fields are not allowed in object literals")
+			}
+			hasProto = true
+		}
+		c.expression(prop)
+	}
+}
+
+// isProtoSetter reports whether a property definition sets the prototype of
+// the object literal.
+func isProtoSetter(prop *ast.Property) bool {
+	if prop.Computed || prop.Shorthand || prop.Method || prop.Kind != ast.PropertyInit {
+		return false
+	}
+	switch key := prop.Key.(type) {
+	case *ast.Identifier:
+		return key.Value == "__proto__"
+	case *ast.Literal:
+		return key.Kind == ast.LitString && key.Value == "__proto__"
+	}
+	return false
+}
+
+// isAssignmentTarget reports whether expr can be assigned to. Destructuring
+// patterns are only allowed if pattern is set.
+func isAssignmentTarget(expr ast.Expression, pattern bool) bool {
+	switch expr.(type) {
+	case *ast.Identifier, *ast.MemberExpression:
+		return true
+	case *ast.ArrayPattern, *ast.ObjectPattern:
+		return pattern
+	}
+	return false
+}
+
+// declarations checks the declarations of a scope: the lexically declared
+// names must be unique and must not be declared by var declarations in the
+// same scope, nor by the parameters of the scope.
+func (c *checker) declarations(list []ast.Statement, kind scopeKind, params []*ast.Identifier) {
+	lexical := make(map[string]*ast.Identifier)
+	functions := make(map[string]bool)
+
+	for _, stmt := range list {
+		for _, decl := range lexicallyScopedDeclarations(stmt, kind) {
+			fn, isFunction := decl.node.(*ast.FunctionDeclaration)
+			// Sloppy mode code may redeclare plain functions in blocks.
+			plain := isFunction && !fn.Async && !fn.Generator
+			if prev, ok := lexical[decl.id.Value]; ok && !(plain && functions[decl.id.Value] && !c.strict && kind == scopeBlock) {
+				c.redeclared(prev, decl.id)
+				continue
+			}
+			if !isFunction && decl.id.Value == "let" {
+				c.errorf(&decl.id.Token, "let is disallowed as a lexically bound name")
+			}
+			lexical[decl.id.Value] = decl.id
+			functions[decl.id.Value] = plain
+		}
+	}
+
+	for _, param := range params {
+		if prev, ok := lexical[param.Value]; ok {
+			c.redeclared(param, prev)
+		}
+	}
+
+	for _, stmt := range list {
+		for _, id := range varDeclaredNames(stmt) {
+			if prev, ok := lexical[id.Value]; ok {
+				c.redeclared(prev, id)
+			}
+		}
+		if kind == scopeScript || kind == scopeFunction {
+			if fn, ok := unlabel(stmt).(*ast.FunctionDeclaration); ok && fn.Name != nil {
+				if prev, ok := lexical[fn.Name.Value]; ok {
+					c.redeclared(prev, fn.Name)
+				}
+			}
+		}
+	}
+}
+
+// redeclared reports the later of two declarations of the same name.
+func (c *checker) redeclared(a, b *ast.Identifier) {
+	later := b
+	if a.Token.Line > b.Token.Line || a.Token.Line == b.Token.Line && a.Token.Col > b.Token.Col {
+		later = a
+	}
+	c.errorf(&later.Token, "Identifier '%s' has already been declared", later.Value)
+}
+
+// lexicalName records a lexically declared name, and reports it if it is
+// already declared.
+func (c *checker) lexicalName(names map[string]*ast.Identifier, id *ast.Identifier) {
+	if prev, ok := names[id.Value]; ok {
+		c.redeclared(prev, id)
+		return
+	}
+	if id.Value == "let" {
+		c.errorf(&id.Token, "let is disallowed as a lexically bound name")
+	}
+	names[id.Value] = id
+}
+
+type scopedDeclaration struct {
+	id   *ast.Identifier
+	node ast.Node
+}
+
+// lexicallyScopedDeclarations returns the names declared by a statement that
+// are scoped to the enclosing block. Function declarations are lexically
+// scoped in blocks and modules only.
+func lexicallyScopedDeclarations(stmt ast.Statement, kind scopeKind) []scopedDeclaration {
+	decls := make([]scopedDeclaration, 0)
+
+	switch stmt := stmt.(type) {
+	case *ast.LexicalDeclaration:
+		for _, id := range declarationNames(stmt.Declarations) {
+			decls = append(decls, scopedDeclaration{id, stmt})
+		}
+	case *ast.FunctionDeclaration, *ast.LabeledStatement:
+		fn, ok := unlabel(stmt).(*ast.FunctionDeclaration)
+		if ok && fn.Name != nil && (kind == scopeBlock || kind == scopeModule) {
+			decls = append(decls, scopedDeclaration{fn.Name, fn})
+		}
+	case *ast.ImportDeclaration:
+		for _, spec := range stmt.Specifiers {
+			var local *ast.Identifier
+			switch spec := spec.(type) {
+			case *ast.ImportSpecifier:
+				local = spec.Local
+			case *ast.ImportDefaultSpecifier:
+				local = spec.Local
+			case *ast.ImportNamespaceSpecifier:
+				local = spec.Local
+			}
+			if local != nil {
+				decls = append(decls, scopedDeclaration{local, spec})
+			}
+		}
+	case *ast.ExportNamedDeclaration:
+		if stmt.Declaration != nil {
+			return lexicallyScopedDeclarations(stmt.Declaration, kind)
+		}
+	case *ast.ExportDefaultDeclaration:
+		if fn, ok := stmt.Declaration.(*ast.FunctionDeclaration); ok && fn.Name != nil {
+			decls = append(decls, scopedDeclaration{fn.Name, fn})
+		}
+	}
+
+	return decls
+}
+
+// unlabel returns the statement labeled by a chain of labels.
+func unlabel(stmt ast.Statement) ast.Statement {
+	for {
+		labeled, ok := stmt.(*ast.LabeledStatement)
+		if !ok {
+			return stmt
+		}
+		stmt = labeled.Statement
+	}
+}
+
+func declarationNames(decls []ast.Declaration) []*ast.Identifier {
+	names := make([]*ast.Identifier, 0)
+	for _, decl := range decls {
+		if decl, ok := decl.(*ast.VariableDeclaration); ok {
+			names = append(names, boundNames(decl.Name)...)
+		}
+	}
+	return names
+}
+
+// varDeclaredNames returns the names declared by the var declarations in a
+// statement and in its nested statements, excluding nested functions.
+func varDeclaredNames(stmt ast.Node) []*ast.Identifier {
+	switch stmt := stmt.(type) {
+	case *ast.VarStatement:
+		return declarationNames(stmt.Declarations)
+	case *ast.BlockStatement:
+		names := make([]*ast.Identifier, 0)
+		for _, s := range stmt.StatementList {
+			names = append(names, varDeclaredNames(s)...)
+		}
+		return names
+	case *ast.IfStatement:
+		names := varDeclaredNames(stmt.TrueBranch)
+		if stmt.FalseBranch != nil {
+			names = append(names, varDeclaredNames(stmt.FalseBranch)...)
+		}
+		return names
+	case *ast.ForStatement:
+		return append(varDeclaredNames(stmt.Init), varDeclaredNames(stmt.Body)...)
+	case *ast.ForInStatement:
+		return append(varDeclaredNames(stmt.Left), varDeclaredNames(stmt.Body)...)
+	case *ast.ForOfStatement:
+		return append(varDeclaredNames(stmt.Left), varDeclaredNames(stmt.Body)...)
+	case *ast.WhileStatement:
+		return varDeclaredNames(stmt.Body)
+	case *ast.DoWhileStatement:
+		return varDeclaredNames(stmt.Body)
+	case *ast.WithStatement:
+		return varDeclaredNames(stmt.Body)
+	case *ast.LabeledStatement:
+		return varDeclaredNames(stmt.Statement)
+	case *ast.SwitchStatement:
+		names := make([]*ast.Identifier, 0)
+		cases := stmt.Cases
+		if stmt.DefaultCase != nil {
+			cases = append(cases[:len(cases):len(cases)], *stmt.DefaultCase)
+		}
+		for _, switchCase := range cases {
+			for _, s := range switchCase.Consequent {
+				names = append(names, varDeclaredNames(s)...)
+			}
+		}
+		return names
+	case *ast.TryStatement:
+		names := varDeclaredNames(stmt.Block)
+		if stmt.CatchClause != nil {
+			names = append(names, varDeclaredNames(stmt.CatchClause.Body)...)
+		}
+		if stmt.Finally != nil {
+			names = append(names, varDeclaredNames(stmt.Finally)...)
+		}
+		return names
+	case *ast.ExportNamedDeclaration:
+		if stmt.Declaration != nil {
+			return varDeclaredNames(stmt.Declaration)
+		}
+	}
+	return nil
+}
+
+// exports checks the export declarations of a module: exported names must be
+// unique, and locally exported names must be declared in the module.
+func (c *checker) exports(list []ast.Statement) {
+	declared := make(map[string]bool)
+	for _, stmt := range list {
+		for _, decl := range lexicallyScopedDeclarations(stmt, scopeModule) {
+			declared[decl.id.Value] = true
+		}
+		for _, id := range varDeclaredNames(stmt) {
+			declared[id.Value] = true
+		}
+	}
+
+	exported := make(map[string]bool)
+	export := func(name string, tok *token.Token) {
+		if exported[name] {
+			c.errorf(tok, "Duplicate export of '%s'", name)
+		}
+		exported[name] = true
+	}
+
+	for _, stmt := range list {
+		switch stmt := stmt.(type) {
+		case *ast.ExportNamedDeclaration:
+			if stmt.Declaration != nil {
+				for _, decl := range lexicallyScopedDeclarations(stmt.Declaration, scopeModule) {
+					export(decl.id.Value, &decl.id.Token)
+				}
+				for _, id := range varDeclaredNames(stmt.Declaration) {
+					export(id.Value, &id.Token)
+				}
+				continue
+			}
+			for _, spec := range stmt.Specifiers {
+				export(exportName(spec.Exported), nodeToken(spec.Exported))
+				if local, ok := spec.Local.(*ast.Identifier); ok && stmt.Source == nil && !declared[local.Value] {
+					c.errorf(&local.Token, "Export '%s' is not defined in module", local.Value)
+				}
+			}
+		case *ast.ExportDefaultDeclaration:
+			export("default", nil)
+		case *ast.ExportAllDeclaration:
+			if stmt.Exported != nil {
+				export(exportName(stmt.Exported), nodeToken(stmt.Exported))
+			}
+		}
+	}
+}
+
+func exportName(expr ast.Expression) string {
+	switch expr := expr.(type) {
+	case *ast.Identifier:
+		return expr.Value
+	case *ast.Literal:
+		return expr.Value
+	}
+	return ""
+}
+
+// nodeToken returns the token of a node that records one, or nil.
+func nodeToken(node ast.Node) *token.Token {
+	switch node := node.(type) {
+	case *ast.Identifier:
+		return &node.Token
+	case *ast.Literal:
+		return &node.Token
+	case *ast.ThisExpression:
+		return &node.Token
+	case *ast.UnaryExpression:
+		return node.Operator
+	case *ast.UpdateExpression:
+		if node.Prefix {
+			return node.Operator
+		}
+		return nodeToken(node.Argument)
+	case *ast.BinaryExpression:
+		return nodeToken(node.Left)
+	case *ast.LogicalExpression:
+		return nodeToken(node.Left)
+	case *ast.AssignmentExpression:
+		return nodeToken(node.Left)
+	case *ast.MemberExpression:
+		return nodeToken(node.Object)
+	case *ast.CallExpression:
+		return nodeToken(node.Callee)
+	}
+	return nil
+}
@@ -1,15 +1,191 @@
 package parser
 
-import "github.com/ghosind/gjs/token"
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
 
+	"github.com/ghosind/gjs/token"
+)
+
+// SyntaxError is an error in the source, reported at a token. Its message
+// follows the wording of V8, and it is printed with a code frame of the line
+// it is reported at.
 type SyntaxError struct {
 	tok *token.Token
+	msg string
+	// source is the text of the line the error is reported at, if located is
+	// set.
+	source  string
+	located bool
 }
 
 func (e *SyntaxError) Error() string {
-	return "SyntaxError: unexpected token " + e.tok.Literal
+	if e.tok == nil {
+		return e.Message()
+	} else if !e.located {
+		return fmt.Sprintf("%s (%d:%d)", e.Message(), e.Line(), e.Column())
+	}
+
+	buf := new(bytes.Buffer)
+	buf.WriteString(strconv.Itoa(e.Line()))
+	buf.WriteString(":")
+	buf.WriteString(strconv.Itoa(e.Column()))
+	buf.WriteString("\n")
+	buf.WriteString(e.source)
+	buf.WriteString("\n")
+	for i, c := range []rune(e.source) {
+		if i >= e.Column()-1 {
+			break
+		} else if c == '\t' {
+			buf.WriteString("\t")
+		} else {
+			buf.WriteString(" ")
+		}
+	}
+	buf.WriteString("^\n")
+	buf.WriteString(e.Message())
+	return buf.String()
+}
+
+// Message returns the message of the error without the code frame.
+func (e *SyntaxError) Message() string {
+	return "SyntaxError: " + e.msg
 }
 
 func (p *Parser) newSyntaxError(tok *token.Token) error {
-	return &SyntaxError{tok: tok}
+	return &SyntaxError{tok: tok, msg: p.unexpected(tok)}
+}
+
+func (p *Parser) newSyntaxErrorf(tok *token.Token, format string, a ...any) error {
+	return &SyntaxError{tok: tok, msg: fmt.Sprintf(format, a...)}
+}
+
+// newExpectedError returns the error of the current token, where a token of
+// type expected is required.
+func (p *Parser) newExpectedError(expected token.TokenType) error {
+	tok := p.current()
+	msg := p.unexpected(tok)
+	if tok != nil && tok.TokenType != token.TOKEN_EOF {
+		msg += ", expected '" + expected.Text() + "'"
+	}
+	return &SyntaxError{tok: tok, msg: msg}
+}
+
+// reservedWords are the keywords that cannot be used as identifiers.
+var reservedWords = map[token.TokenType]bool{
+	token.TOKEN_BREAK: true, token.TOKEN_CASE: true, token.TOKEN_CATCH: true,
+	token.TOKEN_CLASS: true, token.TOKEN_CONST: true, token.TOKEN_CONTINUE: true,
+	token.TOKEN_DEBUGGER: true, token.TOKEN_DEFAULT: true, token.TOKEN_DELETE: true,
+	token.TOKEN_DO: true, token.TOKEN_ELSE: true, token.TOKEN_ENUM: true,
+	token.TOKEN_EXPORT: true, token.TOKEN_EXTENDS: true, token.TOKEN_FALSE: true,
+	token.TOKEN_FINALLY: true, token.TOKEN_FOR: true, token.TOKEN_FUNCTION: true,
+	token.TOKEN_IF: true, token.TOKEN_IMPORT: true, token.TOKEN_IN: true,
+	token.TOKEN_INSTANCEOF: true, token.TOKEN_NEW: true, token.TOKEN_NULL: true,
+	token.TOKEN_RETURN: true, token.TOKEN_SUPER: true, token.TOKEN_SWITCH: true,
+	token.TOKEN_THIS: true, token.TOKEN_THROW: true, token.TOKEN_TRUE: true,
+	token.TOKEN_TRY: true, token.TOKEN_TYPEOF: true, token.TOKEN_VAR: true,
+	token.TOKEN_VOID: true, token.TOKEN_WHILE: true, token.TOKEN_WITH: true,
+}
+
+// strictReservedWords are the keywords that cannot be used as identifiers in
+// strict mode code.
+var strictReservedWords = map[token.TokenType]bool{
+	token.TOKEN_IMPLEMENTS: true, token.TOKEN_INTERFACE: true, token.TOKEN_LET: true,
+	token.TOKEN_PACKAGE: true, token.TOKEN_PRIVATE: true, token.TOKEN_PROTECTED: true,
+	token.TOKEN_PUBLIC: true, token.TOKEN_STATIC: true, token.TOKEN_YIELD: true,
+}
+
+// unexpected returns the message of an unexpected token.
+func (p *Parser) unexpected(tok *token.Token) string {
+	if tok == nil {
+		return "Unexpected token"
+	}
+
+	switch ty := tok.TokenType; {
+	case ty == token.TOKEN_EOF:
+		return "Unexpected end of input"
+	case ty == token.TOKEN_NEW_LINE:
+		return "Unexpected end of line"
+	case ty == token.TOKEN_NUMBER:
+		return "Unexpected number"
+	case ty == token.TOKEN_STRING:
+		return "Unexpected string"
+	case ty == token.TOKEN_AWAIT && (p.module || p.inAsync):
+		return "Unexpected reserved word"
+	case ty == token.TOKEN_IDENTIFIER:
+		return "Unexpected identifier '" + tok.Literal + "'"
+	case reservedWords[ty]:
+		return "Unexpected token '" + tok.Literal + "'"
+	case strictReservedWords[ty] && p.strict:
+		return "Unexpected strict mode reserved word"
+	case ty.IsKeyword():
+		return "Unexpected identifier '" + tok.Literal + "'"
+	}
+	return "Unexpected token '" + tok.TokenType.Text() + "'"
+}
+
+// locate records the source lines of the syntax errors in err, which are
+// shown in their code frames.
+func (p *Parser) locate(err error) {
+	var list ErrorList
+	var syntaxErr *SyntaxError
+	if errors.As(err, &list) {
+		for _, e := range list {
+			p.locate(e)
+		}
+	} else if errors.As(err, &syntaxErr) && syntaxErr.tok != nil && !syntaxErr.located {
+		syntaxErr.source = p.l.SourceLine(syntaxErr.tok.Line)
+		syntaxErr.located = true
+	}
+}
+
+// Line returns the line of the token the error is reported at.
+func (e *SyntaxError) Line() int {
+	if e.tok == nil {
+		return 0
+	}
+	return e.tok.Line
+}
+
+// Column returns the column of the token the error is reported at.
+func (e *SyntaxError) Column() int {
+	if e.tok == nil {
+		return 0
+	}
+	return e.tok.Col
+}
+
+// ErrorList is a list of syntax errors, such as the early errors of a program
+// reported by Check.
+type ErrorList []*SyntaxError
+
+func (l ErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	}
+	return fmt.Sprintf("%s\n(and %d more errors)", l[0], len(l)-1)
+}
+
+// Sort sorts the list by the positions of the errors.
+func (l ErrorList) Sort() {
+	sort.SliceStable(l, func(i, j int) bool {
+		if l[i].Line() != l[j].Line() {
+			return l[i].Line() < l[j].Line()
+		}
+		return l[i].Column() < l[j].Column()
+	})
+}
+
+// Err returns the list as an error, or nil if the list is empty.
+func (l ErrorList) Err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return l
 }
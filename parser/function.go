@@ -0,0 +1,870 @@
+package parser
+
+import (
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/token"
+)
+
+// functionDeclaration parses a function declaration. The name may only be
+// omitted in export default declarations.
+// The declaration starts at the token start, which is the async keyword of an
+// async function.
+func (p *Parser) functionDeclaration(start *token.Token, async, optionalName bool) (ast.Statement, error) {
+	if _, err := p.consume(token.TOKEN_FUNCTION); err != nil {
+		return nil, err
+	}
+	generator := p.skipAndMatch(token.TOKEN_STAR)
+	if err := p.requireFunctionKind(start, async, generator); err != nil {
+		return nil, err
+	}
+
+	var name *ast.Identifier
+	p.skip()
+	if !optionalName || p.current().TokenType != token.TOKEN_LEFT_PAREN {
+		id, err := p.bindingIdentifier()
+		if err != nil {
+			return nil, err
+		}
+		name = id
+	}
+
+	fn, err := p.functionRest(name, async, generator, false)
+	if err != nil {
+		return nil, err
+	} else if fn.Body == nil {
+		// An overload signature or an ambient declaration of TypeScript,
+		// which is erased.
+		return nil, nil
+	}
+
+	return &ast.FunctionDeclaration{
+		Token:     *start,
+		Name:      name,
+		Params:    fn.Params,
+		Body:      fn.Body,
+		Async:     async,
+		Generator: generator,
+		Strict:    fn.Strict,
+	}, nil
+}
+
+// isAsyncFunction reports whether the current async token starts an async
+// function.
+func (p *Parser) isAsyncFunction() (bool, error) {
+	next, newLine, err := p.peekSignificant()
+	if err != nil {
+		return false, err
+	}
+	return next.TokenType == token.TOKEN_FUNCTION && !newLine, nil
+}
+
+func (p *Parser) functionExpr(start *token.Token, async bool) (ast.Expression, error) {
+	generator := p.skipAndMatch(token.TOKEN_STAR)
+	if err := p.requireFunctionKind(start, async, generator); err != nil {
+		return nil, err
+	}
+
+	var name *ast.Identifier
+	p.skip()
+	if p.isIdentifierReference(p.current()) {
+		id, err := p.bindingIdentifier()
+		if err != nil {
+			return nil, err
+		}
+		name = id
+	}
+
+	fn, err := p.functionRest(name, async, generator, false)
+	if err != nil {
+		return nil, err
+	} else if fn.Body == nil {
+		return nil, p.tsMissingBody()
+	}
+	fn.Token = *start
+	fn.Name = name
+
+	return fn, nil
+}
+
+// functionRest parses the parameters and the body of a function. The name of
+// the function, if any, is checked with the strictness of its body. Methods
+// must not have duplicate parameters.
+//
+// In TypeScript, the function may have type parameters and a return type, and
+// the body is nil if the function is declared without one.
+func (p *Parser) functionRest(name *ast.Identifier, async, generator, method bool) (*ast.FunctionExpression, error) {
+	inAsync, inGenerator, noIn, labels, strict := p.inAsync, p.inGenerator, p.noIn, p.labels, p.strict
+	p.inAsync, p.inGenerator, p.noIn, p.labels = async, generator, false, nil
+	defer func() {
+		p.inAsync, p.inGenerator, p.noIn, p.labels, p.strict = inAsync, inGenerator, noIn, labels, strict
+	}()
+
+	if err := p.tsTypeParameters(); err != nil {
+		return nil, err
+	}
+	if _, err := p.skipAndConsume(token.TOKEN_LEFT_PAREN); err != nil {
+		return nil, err
+	}
+	params, err := p.formalParams()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.tsReturnType(); err != nil {
+		return nil, err
+	}
+	if p.skip(); p.opts.TypeScript && p.current().TokenType != token.TOKEN_LEFT_BRACE {
+		if err := p.semicolon(); err != nil {
+			return nil, err
+		}
+		return &ast.FunctionExpression{Params: params, Async: async, Generator: generator}, nil
+	}
+
+	body, useStrict, err := p.functionBody()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.checkParams(params, useStrict, method); err != nil {
+		return nil, err
+	}
+	if name != nil {
+		if err := p.checkStrictBinding(name); err != nil {
+			return nil, err
+		}
+	}
+
+	return &ast.FunctionExpression{
+		Params:    params,
+		Body:      body,
+		Async:     async,
+		Generator: generator,
+		Strict:    p.strict,
+	}, nil
+}
+
+// checkParams validates the parameters of a function after its body is
+// parsed, when the strictness of the function is known.
+func (p *Parser) checkParams(params []ast.Expression, useStrict, unique bool) error {
+	simple := true
+	for _, param := range params {
+		if _, ok := param.(*ast.Identifier); !ok {
+			simple = false
+		}
+	}
+
+	if useStrict && !simple {
+		return p.newSyntaxErrorf(p.previous(), "Illegal 'use strict' directive in function with non-simple parameter list")
+	}
+
+	unique = unique || p.strict || !simple
+	names := make(map[string]bool)
+	for _, param := range params {
+		for _, id := range boundNames(param) {
+			if unique && names[id.Value] {
+				return p.newSyntaxErrorf(&id.Token, "Duplicate parameter name not allowed in this context")
+			}
+			names[id.Value] = true
+
+			if err := p.checkStrictBinding(id); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkStrictBinding checks that an identifier may be bound in strict mode
+// code, if the current code is strict.
+func (p *Parser) checkStrictBinding(id *ast.Identifier) error {
+	if !p.strict {
+		return nil
+	}
+
+	switch id.Token.TokenType {
+	case token.TOKEN_EVAL, token.TOKEN_ARGUMENTS:
+		return p.newSyntaxErrorf(&id.Token, "Unexpected eval or arguments in strict mode")
+	}
+	if isStrictReservedWord(id.Token.TokenType) {
+		return p.newSyntaxErrorf(&id.Token, "Unexpected strict mode reserved word")
+	}
+	return nil
+}
+
+func isStrictReservedWord(ty token.TokenType) bool {
+	switch ty {
+	case token.TOKEN_IMPLEMENTS,
+		token.TOKEN_INTERFACE,
+		token.TOKEN_LET,
+		token.TOKEN_PACKAGE,
+		token.TOKEN_PRIVATE,
+		token.TOKEN_PROTECTED,
+		token.TOKEN_PUBLIC,
+		token.TOKEN_STATIC,
+		token.TOKEN_YIELD:
+		return true
+	}
+	return false
+}
+
+// boundNames returns the identifiers bound by a binding target.
+func boundNames(target ast.Expression) []*ast.Identifier {
+	switch target := target.(type) {
+	case *ast.Identifier:
+		return []*ast.Identifier{target}
+	case *ast.AssignmentPattern:
+		return boundNames(target.Left)
+	case *ast.RestElement:
+		return boundNames(target.Argument)
+	case *ast.Property:
+		return boundNames(target.Value)
+	case *ast.ArrayPattern:
+		names := make([]*ast.Identifier, 0)
+		for _, elem := range target.Elements {
+			names = append(names, boundNames(elem)...)
+		}
+		return names
+	case *ast.ObjectPattern:
+		names := make([]*ast.Identifier, 0)
+		for _, prop := range target.Properties {
+			names = append(names, boundNames(prop)...)
+		}
+		return names
+	}
+	return nil
+}
+
+func (p *Parser) formalParams() ([]ast.Expression, error) {
+	params := make([]ast.Expression, 0)
+
+	for !p.skipAndMatch(token.TOKEN_RIGHT_PAREN) {
+		if p.opts.TypeScript && p.skipAndMatch(token.TOKEN_THIS) {
+			// The type of this in TypeScript, which is not a parameter.
+			if err := p.tsTypeAnnotation(); err != nil {
+				return nil, err
+			}
+			if !p.skipAndMatch(token.TOKEN_COMMA) {
+				if _, err := p.skipAndConsume(token.TOKEN_RIGHT_PAREN); err != nil {
+					return nil, err
+				}
+				break
+			}
+			continue
+		}
+
+		if p.skipAndMatch(token.TOKEN_DOT_DOT_DOT) {
+			tok := p.previous()
+			if err := p.require(tok, 2015, "Rest parameter"); err != nil {
+				return nil, err
+			}
+			arg, err := p.bindingTarget()
+			if err != nil {
+				return nil, err
+			} else if err := p.tsTypeAnnotation(); err != nil {
+				return nil, err
+			}
+			params = append(params, &ast.RestElement{Token: *tok, Argument: arg})
+			if _, err := p.skipAndConsume(token.TOKEN_RIGHT_PAREN); err != nil {
+				return nil, err
+			}
+			break
+		}
+
+		param, err := p.formalParam()
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, param)
+
+		if !p.skipAndMatch(token.TOKEN_COMMA) {
+			if _, err := p.skipAndConsume(token.TOKEN_RIGHT_PAREN); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	return params, nil
+}
+
+// formalParam parses a parameter of a function, which may be optional and
+// have a type annotation in TypeScript, as in x?: number.
+func (p *Parser) formalParam() (ast.Expression, error) {
+	name, err := p.bindingTarget()
+	if err != nil {
+		return nil, err
+	}
+	if p.opts.TypeScript {
+		p.skipAndMatch(token.TOKEN_QUESTION)
+		if err := p.tsTypeAnnotation(); err != nil {
+			return nil, err
+		}
+	}
+	return p.bindingInitializer(name)
+}
+
+func (p *Parser) bindingElement() (ast.Expression, error) {
+	name, err := p.bindingTarget()
+	if err != nil {
+		return nil, err
+	}
+	return p.bindingInitializer(name)
+}
+
+// bindingInitializer parses the default value of a binding target, if there
+// is one.
+func (p *Parser) bindingInitializer(name ast.Expression) (ast.Expression, error) {
+	p.skip()
+	start := p.current()
+	initializer, err := p.initializer()
+	if err != nil {
+		return nil, err
+	} else if initializer != nil {
+		if err := p.require(start, 2015, "Default value"); err != nil {
+			return nil, err
+		}
+		return &ast.AssignmentPattern{Left: name, Right: initializer}, nil
+	}
+
+	return name, nil
+}
+
+func (p *Parser) bindingIdentifier() (*ast.Identifier, error) {
+	p.skip()
+	tok := p.current()
+	if !p.isIdentifierReference(tok) {
+		return nil, p.newSyntaxError(tok)
+	}
+	p.advance()
+
+	id := &ast.Identifier{Token: *tok, Value: tok.Literal}
+	if err := p.checkStrictBinding(id); err != nil {
+		return nil, err
+	}
+	return id, p.err
+}
+
+// bindingTarget parses a binding identifier or a destructuring pattern.
+func (p *Parser) bindingTarget() (ast.Expression, error) {
+	p.skip()
+	if tok := p.current(); isOneOf(tok, token.TOKEN_LEFT_BRACKET, token.TOKEN_LEFT_BRACE) {
+		if err := p.require(tok, 2015, "Destructuring pattern"); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case p.match(token.TOKEN_LEFT_BRACKET):
+		return p.arrayBindingPattern()
+	case p.match(token.TOKEN_LEFT_BRACE):
+		return p.objectBindingPattern()
+	}
+
+	id, err := p.bindingIdentifier()
+	if err != nil {
+		return nil, err
+	}
+	return id, nil
+}
+
+func (p *Parser) arrayBindingPattern() (ast.Expression, error) {
+	lbracket := p.previous()
+	elems := make([]ast.Expression, 0)
+
+	for !p.skipAndMatch(token.TOKEN_RIGHT_BRACKET) {
+		if p.skipAndMatch(token.TOKEN_COMMA) {
+			elems = append(elems, &ast.Elision{Token: *p.previous()})
+			continue
+		}
+
+		if p.skipAndMatch(token.TOKEN_DOT_DOT_DOT) {
+			tok := p.previous()
+			arg, err := p.bindingTarget()
+			if err != nil {
+				return nil, err
+			}
+			elems = append(elems, &ast.RestElement{Token: *tok, Argument: arg})
+			if _, err := p.skipAndConsume(token.TOKEN_RIGHT_BRACKET); err != nil {
+				return nil, err
+			}
+			break
+		}
+
+		elem, err := p.bindingElement()
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, elem)
+
+		if !p.skipAndMatch(token.TOKEN_COMMA) {
+			if _, err := p.skipAndConsume(token.TOKEN_RIGHT_BRACKET); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	return &ast.ArrayPattern{
+		LBracket: *lbracket,
+		RBracket: *p.previous(),
+		Elements: elems,
+	}, nil
+}
+
+func (p *Parser) objectBindingPattern() (ast.Expression, error) {
+	lbrace := p.previous()
+	props := make([]ast.Expression, 0)
+
+	for !p.skipAndMatch(token.TOKEN_RIGHT_BRACE) {
+		if p.skipAndMatch(token.TOKEN_DOT_DOT_DOT) {
+			tok := p.previous()
+			if err := p.require(tok, 2018, "Object rest property"); err != nil {
+				return nil, err
+			}
+			arg, err := p.bindingIdentifier()
+			if err != nil {
+				return nil, err
+			}
+			props = append(props, &ast.RestElement{Token: *tok, Argument: arg})
+			if _, err := p.skipAndConsume(token.TOKEN_RIGHT_BRACE); err != nil {
+				return nil, err
+			}
+			break
+		}
+
+		p.skip()
+		tok := p.current()
+		key, computed, err := p.propertyName()
+		if err != nil {
+			return nil, err
+		}
+
+		prop := &ast.Property{Token: *tok, Key: key, Computed: computed}
+		if p.skipAndMatch(token.TOKEN_COLON) {
+			prop.Value, err = p.bindingElement()
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			ident, ok := key.(*ast.Identifier)
+			if !ok || computed || !p.isIdentifierReference(tok) {
+				return nil, p.newSyntaxError(tok)
+			}
+
+			prop.Value = ident
+			prop.Shorthand = true
+			initializer, err := p.initializer()
+			if err != nil {
+				return nil, err
+			} else if initializer != nil {
+				prop.Value = &ast.AssignmentPattern{Left: ident, Right: initializer}
+			}
+		}
+		props = append(props, prop)
+
+		if !p.skipAndMatch(token.TOKEN_COMMA) {
+			if _, err := p.skipAndConsume(token.TOKEN_RIGHT_BRACE); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	return &ast.ObjectPattern{
+		LBrace:     *lbrace,
+		RBrace:     *p.previous(),
+		Properties: props,
+	}, nil
+}
+
+// functionBody parses the body of a function, and reports whether its
+// directive prologue contains a use strict directive.
+func (p *Parser) functionBody() (*ast.BlockStatement, bool, error) {
+	p.skip()
+	lbrace := p.current()
+	if lbrace.TokenType != token.TOKEN_LEFT_BRACE {
+		return nil, false, p.newSyntaxError(lbrace)
+	}
+	p.advance()
+
+	list := make([]ast.Statement, 0)
+	pro := new(prologue)
+	for !p.skipAndMatch(token.TOKEN_RIGHT_BRACE) {
+		start := p.current()
+		stmt, err := p.statement()
+		if err == nil && p.current() == start {
+			err = p.newSyntaxError(start)
+		}
+		if err != nil {
+			if stmt, err = p.recoverStatement(start, err); err != nil {
+				return nil, false, err
+			}
+		}
+		if stmt != nil {
+			list = append(list, stmt)
+		}
+
+		if err := p.directive(pro, start, stmt); err != nil {
+			return nil, false, err
+		}
+	}
+
+	return &ast.BlockStatement{
+		LBrace:        *lbrace,
+		RBrace:        *p.previous(),
+		StatementList: list,
+	}, pro.useStrict, nil
+}
+
+// prologue tracks the directive prologue of a program or a function body.
+type prologue struct {
+	done      bool
+	useStrict bool
+	// tokens are the string literals of the directives seen so far.
+	tokens []*token.Token
+}
+
+// directives returns the directives of the prologue as written between their
+// quotes.
+func (pro *prologue) directives() []string {
+	list := make([]string, 0, len(pro.tokens))
+	for _, tok := range pro.tokens {
+		list = append(list, tok.Raw[1:len(tok.Raw)-1])
+	}
+	return list
+}
+
+// directive checks whether a statement starting with the token start is a
+// directive of the prologue, and enables strict mode if it is a use strict
+// directive. The directives before a use strict directive are checked again
+// as strict mode code.
+func (p *Parser) directive(pro *prologue, start *token.Token, stmt ast.Statement) error {
+	if pro.done {
+		return nil
+	}
+
+	pro.done = true
+	if start.TokenType != token.TOKEN_STRING {
+		return nil
+	}
+	expr, ok := stmt.(*ast.ExpressionStatement)
+	if !ok {
+		return nil
+	}
+	lit, ok := expr.Expression.(*ast.Literal)
+	if !ok || lit.Kind != ast.LitString {
+		return nil
+	}
+
+	pro.done = false
+	pro.tokens = append(pro.tokens, start)
+	if lit.Value != "use strict" {
+		return nil
+	}
+
+	pro.useStrict = true
+	p.strict = true
+	for _, tok := range pro.tokens {
+		if err := p.checkStrictLiteral(tok); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// arrowFunction parses the body of an arrow function, whose head starts at the
+// token start.
+func (p *Parser) arrowFunction(start *token.Token, params []ast.Expression, async bool) (ast.Expression, error) {
+	if err := p.require(start, 2015, "Arrow function"); err != nil {
+		return nil, err
+	} else if err := p.requireFunctionKind(start, async, false); err != nil {
+		return nil, err
+	}
+
+	inAsync, inGenerator, labels, strict := p.inAsync, p.inGenerator, p.labels, p.strict
+	p.inAsync, p.inGenerator, p.labels = async, false, nil
+	defer func() {
+		p.inAsync, p.inGenerator, p.labels, p.strict = inAsync, inGenerator, labels, strict
+	}()
+
+	var body ast.Node
+	p.skip()
+	if p.current().TokenType == token.TOKEN_LEFT_BRACE {
+		block, useStrict, err := p.functionBody()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.checkParams(params, useStrict, true); err != nil {
+			return nil, err
+		}
+		body = block
+	} else {
+		if err := p.checkParams(params, false, true); err != nil {
+			return nil, err
+		}
+		expr, err := p.assignmentExpr()
+		if err != nil {
+			return nil, err
+		} else if expr == nil {
+			return nil, p.newSyntaxError(p.current())
+		}
+		body = expr
+	}
+
+	return &ast.ArrowFunctionExpression{
+		Token:  *start,
+		Params: params,
+		Body:   body,
+		Async:  async,
+		Strict: p.strict,
+	}, nil
+}
+
+// arrowParams converts a parenthesized expression list that turned out to be
+// the head of an arrow function into its formal parameters.
+func (p *Parser) arrowParams(exprs []ast.Expression) ([]ast.Expression, error) {
+	params := make([]ast.Expression, 0, len(exprs))
+
+	for i, expr := range exprs {
+		var param ast.Expression
+		ok := false
+
+		if spread, isSpread := expr.(*ast.SpreadElement); isSpread {
+			if i == len(exprs)-1 {
+				param, ok = p.toAssignmentTarget(spread.Value)
+				param = &ast.RestElement{Token: spread.Token, Argument: param}
+			}
+		} else {
+			param, ok = p.toAssignmentElement(expr)
+		}
+		if !ok || !isBindingPattern(param) {
+			return nil, p.newSyntaxError(p.previous())
+		}
+
+		params = append(params, param)
+	}
+
+	return params, nil
+}
+
+// isBindingPattern reports whether an assignment target only binds
+// identifiers, as required for parameters.
+func isBindingPattern(expr ast.Expression) bool {
+	switch expr := expr.(type) {
+	case *ast.Identifier, *ast.Elision:
+		return true
+	case *ast.RestElement:
+		return isBindingPattern(expr.Argument)
+	case *ast.AssignmentPattern:
+		return isBindingPattern(expr.Left)
+	case *ast.Property:
+		return isBindingPattern(expr.Value)
+	case *ast.ArrayPattern:
+		for _, elem := range expr.Elements {
+			if !isBindingPattern(elem) {
+				return false
+			}
+		}
+		return true
+	case *ast.ObjectPattern:
+		for _, prop := range expr.Properties {
+			if !isBindingPattern(prop) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+func (p *Parser) parenthesizedExpr() (ast.Expression, error) {
+	lparen := p.previous()
+	if params, ok, err := p.tsArrowHead(); err != nil {
+		return nil, err
+	} else if ok {
+		return p.arrowFunction(lparen, params, false)
+	}
+
+	exprs, err := p.arguments()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skip(token.TOKEN_SPACE, token.TOKEN_MULTI_LINE_COMMENT)
+	if p.match(token.TOKEN_EQUAL_GREATER) {
+		params, err := p.arrowParams(exprs)
+		if err != nil {
+			return nil, err
+		}
+		return p.arrowFunction(lparen, params, false)
+	}
+
+	if len(exprs) == 0 {
+		return nil, p.newSyntaxError(p.previous())
+	}
+	for _, expr := range exprs {
+		if _, ok := expr.(*ast.SpreadElement); ok {
+			return nil, p.newSyntaxError(p.previous())
+		}
+	}
+
+	if len(exprs) > 1 {
+		return &ast.SequenceExpression{Expressions: exprs}, nil
+	}
+	return exprs[0], nil
+}
+
+func (p *Parser) asyncExpr() (ast.Expression, error) {
+	tok := p.current()
+	next, newLine, err := p.peekSignificant()
+	if err != nil {
+		return nil, err
+	}
+
+	if newLine || next.TokenType == token.TOKEN_EQUAL_GREATER {
+		return p.identifierReference()
+	}
+
+	switch {
+	case next.TokenType == token.TOKEN_FUNCTION:
+		p.advance()
+		if _, err := p.skipAndConsume(token.TOKEN_FUNCTION); err != nil {
+			return nil, err
+		}
+		return p.functionExpr(tok, true)
+	case next.TokenType == token.TOKEN_LEFT_PAREN:
+		p.advance()
+		if _, err := p.skipAndConsume(token.TOKEN_LEFT_PAREN); err != nil {
+			return nil, err
+		}
+		if params, ok, err := p.tsArrowHead(); err != nil {
+			return nil, err
+		} else if ok {
+			return p.arrowFunction(tok, params, true)
+		}
+		args, err := p.arguments()
+		if err != nil {
+			return nil, err
+		}
+		rparen := p.previous()
+
+		p.skip(token.TOKEN_SPACE, token.TOKEN_MULTI_LINE_COMMENT)
+		if p.match(token.TOKEN_EQUAL_GREATER) {
+			params, err := p.arrowParams(args)
+			if err != nil {
+				return nil, err
+			}
+			return p.arrowFunction(tok, params, true)
+		}
+
+		return &ast.CallExpression{
+			Callee:    &ast.Identifier{Token: *tok, Value: tok.Literal},
+			Arguments: args,
+			RParen:    *rparen,
+		}, nil
+	case p.isIdentifierReference(next):
+		p.advance()
+		param, err := p.bindingIdentifier()
+		if err != nil {
+			return nil, err
+		}
+		p.skip(token.TOKEN_SPACE, token.TOKEN_MULTI_LINE_COMMENT)
+		if _, err := p.consume(token.TOKEN_EQUAL_GREATER); err != nil {
+			return nil, err
+		}
+		return p.arrowFunction(tok, []ast.Expression{param}, true)
+	}
+
+	return p.identifierReference()
+}
+
+func (p *Parser) awaitExpr() (ast.Expression, error) {
+	tok := p.previous()
+	arg, err := p.unaryExpr()
+	if err != nil {
+		return nil, err
+	} else if arg == nil {
+		return nil, p.newSyntaxError(p.current())
+	}
+
+	return &ast.AwaitExpression{Token: *tok, Argument: arg}, nil
+}
+
+func (p *Parser) yieldExpr() (ast.Expression, error) {
+	tok := p.previous()
+	p.skip(token.TOKEN_SPACE, token.TOKEN_MULTI_LINE_COMMENT)
+	delegate := p.match(token.TOKEN_STAR)
+
+	p.skip(token.TOKEN_SPACE, token.TOKEN_MULTI_LINE_COMMENT)
+	if !delegate {
+		switch p.current().TokenType {
+		case token.TOKEN_NEW_LINE, token.TOKEN_SINGLE_LINE_COMMENT, token.TOKEN_EOF,
+			token.TOKEN_SEMICOLON, token.TOKEN_COMMA, token.TOKEN_COLON,
+			token.TOKEN_RIGHT_PAREN, token.TOKEN_RIGHT_BRACKET, token.TOKEN_RIGHT_BRACE:
+			return &ast.YieldExpression{Token: *tok}, nil
+		}
+	}
+
+	arg, err := p.assignmentExpr()
+	if err != nil {
+		return nil, err
+	} else if arg == nil {
+		return nil, p.newSyntaxError(p.current())
+	}
+
+	return &ast.YieldExpression{
+		Token:    *tok,
+		Argument: arg,
+		Delegate: delegate,
+	}, nil
+}
+
+func (p *Parser) identifierReference() (ast.Expression, error) {
+	tok := p.current()
+	p.advance()
+	if p.isSyntaxError() {
+		return nil, p.err
+	}
+	ident := &ast.Identifier{Token: *tok, Value: tok.Literal}
+	if p.strict && isStrictReservedWord(tok.TokenType) {
+		return nil, p.newSyntaxErrorf(tok, "Unexpected strict mode reserved word")
+	}
+
+	p.skip(token.TOKEN_SPACE, token.TOKEN_MULTI_LINE_COMMENT)
+	if p.match(token.TOKEN_EQUAL_GREATER) {
+		return p.arrowFunction(tok, []ast.Expression{ident}, false)
+	}
+
+	return ident, nil
+}
+
+func (p *Parser) isIdentifierReference(tok *token.Token) bool {
+	switch tok.TokenType {
+	case token.TOKEN_IDENTIFIER,
+		token.TOKEN_ARGUMENTS,
+		token.TOKEN_AS,
+		token.TOKEN_ASYNC,
+		token.TOKEN_EVAL,
+		token.TOKEN_FROM,
+		token.TOKEN_GET,
+		token.TOKEN_IMPLEMENTS,
+		token.TOKEN_INTERFACE,
+		token.TOKEN_LET,
+		token.TOKEN_META,
+		token.TOKEN_OF,
+		token.TOKEN_PACKAGE,
+		token.TOKEN_PRIVATE,
+		token.TOKEN_PROTECTED,
+		token.TOKEN_PUBLIC,
+		token.TOKEN_SET,
+		token.TOKEN_STATIC,
+		token.TOKEN_TARGET,
+		token.TOKEN_UNDEFINED:
+		return true
+	case token.TOKEN_AWAIT:
+		return !p.inAsync && !p.module
+	case token.TOKEN_YIELD:
+		return !p.inGenerator
+	}
+	return false
+}
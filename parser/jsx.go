@@ -0,0 +1,361 @@
+package parser
+
+import (
+	"html"
+	"regexp"
+
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/token"
+)
+
+// jsxElement parses a JSX element or fragment, whose < is the current token.
+// If child is set, the element is a child of another one, and the source
+// after it is scanned as JSX text.
+func (p *Parser) jsxElement(child bool) (ast.Expression, error) {
+	lt := p.current()
+	p.advance()
+
+	if p.skip(); isGreater(p.current()) {
+		gt, err := p.jsxTagEnd(true)
+		if err != nil {
+			return nil, err
+		}
+		children, err := p.jsxChildren()
+		if err != nil {
+			return nil, err
+		}
+		closing, err := p.jsxClosingTag(nil, child)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.JSXFragment{
+			OpeningFragment: &ast.JSXOpeningFragment{LessThan: *lt, GreaterThan: *gt},
+			Children:        children,
+			ClosingFragment: &ast.JSXClosingFragment{LessThan: closing.LessThan, GreaterThan: closing.GreaterThan},
+		}, nil
+	}
+
+	name, err := p.jsxElementName()
+	if err != nil {
+		return nil, err
+	}
+	opening := &ast.JSXOpeningElement{LessThan: *lt, Name: name}
+	opening.Attributes, err = p.jsxAttributes()
+	if err != nil {
+		return nil, err
+	}
+
+	elem := &ast.JSXElement{OpeningElement: opening}
+	if p.skipAndMatch(token.TOKEN_SLASH) {
+		opening.SelfClosing = true
+		gt, err := p.jsxTagEnd(child)
+		if err != nil {
+			return nil, err
+		}
+		opening.GreaterThan = *gt
+		return elem, nil
+	}
+
+	gt, err := p.jsxTagEnd(true)
+	if err != nil {
+		return nil, err
+	}
+	opening.GreaterThan = *gt
+	elem.Children, err = p.jsxChildren()
+	if err != nil {
+		return nil, err
+	}
+	elem.ClosingElement, err = p.jsxClosingTag(name, child)
+	if err != nil {
+		return nil, err
+	}
+	return elem, nil
+}
+
+// jsxClosingTag parses the closing tag of the element named name, or of a
+// fragment if name is nil, whose < is the current token.
+func (p *Parser) jsxClosingTag(name ast.Expression, child bool) (*ast.JSXClosingElement, error) {
+	lt := p.current()
+	p.advance()
+	if _, err := p.skipAndConsume(token.TOKEN_SLASH); err != nil {
+		return nil, err
+	}
+
+	closing := &ast.JSXClosingElement{LessThan: *lt}
+	if p.skip(); name == nil {
+		if !isGreater(p.current()) {
+			return nil, p.newSyntaxErrorf(p.current(), "Expected corresponding closing tag for JSX fragment")
+		}
+	} else {
+		var err error
+		closing.Name, err = p.jsxElementName()
+		if err != nil {
+			return nil, err
+		} else if closing.Name.String() != name.String() {
+			return nil, p.newSyntaxErrorf(&closing.LessThan, "Expected corresponding JSX closing tag for <%s>", name)
+		}
+	}
+
+	gt, err := p.jsxTagEnd(child)
+	if err != nil {
+		return nil, err
+	}
+	closing.GreaterThan = *gt
+	return closing, nil
+}
+
+// jsxChildren parses the children of an element up to its closing tag.
+func (p *Parser) jsxChildren() ([]ast.Expression, error) {
+	children := make([]ast.Expression, 0)
+	for {
+		tok := p.current()
+		if p.isSyntaxError() {
+			return nil, p.err
+		}
+
+		switch tok.TokenType {
+		case token.TOKEN_JSX_TEXT:
+			p.advance()
+			children = append(children, &ast.JSXText{Token: *tok, Value: decodeJSXEntities(tok.Literal)})
+		case token.TOKEN_LEFT_BRACE:
+			child, err := p.jsxExpressionContainer(true)
+			if err != nil {
+				return nil, err
+			}
+			children = append(children, child)
+		case token.TOKEN_LESS:
+			next, _, err := p.peekSignificant()
+			if err != nil {
+				return nil, err
+			} else if next.TokenType == token.TOKEN_SLASH {
+				return children, nil
+			}
+			child, err := p.jsxElement(true)
+			if err != nil {
+				return nil, err
+			}
+			children = append(children, child)
+		default:
+			return nil, p.newSyntaxError(tok)
+		}
+	}
+}
+
+// jsxExpressionContainer parses an expression in braces, whose { is the
+// current token. A child container may be empty or spread its expression.
+func (p *Parser) jsxExpressionContainer(child bool) (ast.Expression, error) {
+	lbrace := p.current()
+	p.advance()
+
+	p.skip()
+	if tok := p.current(); tok.TokenType == token.TOKEN_RIGHT_BRACE {
+		if !child {
+			return nil, p.newSyntaxErrorf(lbrace, "JSX attributes must only be assigned a non-empty expression")
+		}
+		rbrace, err := p.jsxClosingBrace(child)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.JSXExpressionContainer{
+			LBrace:     *lbrace,
+			RBrace:     *rbrace,
+			Expression: &ast.JSXEmptyExpression{RBrace: *rbrace},
+		}, nil
+	}
+
+	spread := child && p.match(token.TOKEN_DOT_DOT_DOT)
+	expr, err := p.assignmentExpr()
+	if err != nil {
+		return nil, err
+	} else if expr == nil {
+		return nil, p.newSyntaxError(p.current())
+	}
+	rbrace, err := p.jsxClosingBrace(child)
+	if err != nil {
+		return nil, err
+	}
+
+	if spread {
+		return &ast.JSXSpreadChild{LBrace: *lbrace, RBrace: *rbrace, Expression: expr}, nil
+	}
+	return &ast.JSXExpressionContainer{LBrace: *lbrace, RBrace: *rbrace, Expression: expr}, nil
+}
+
+// jsxClosingBrace consumes the } that closes an expression container, and
+// scans the source after it as JSX text if text is set.
+func (p *Parser) jsxClosingBrace(text bool) (*token.Token, error) {
+	p.skip()
+	rbrace := p.current()
+	if rbrace.TokenType != token.TOKEN_RIGHT_BRACE {
+		return nil, p.newExpectedError(token.TOKEN_RIGHT_BRACE)
+	}
+	if text {
+		if err := p.rescan(rbrace.End(), p.l.ScanJSXText); err != nil {
+			return nil, err
+		}
+	}
+	p.advance()
+	return rbrace, p.err
+}
+
+// jsxTagEnd consumes the > that ends a tag, and scans the source after it as
+// JSX text if text is set.
+func (p *Parser) jsxTagEnd(text bool) (*token.Token, error) {
+	p.skip()
+	if !isGreater(p.current()) {
+		return nil, p.newExpectedError(token.TOKEN_GREATER)
+	}
+	if text {
+		return p.splitGreater(p.l.ScanJSXText)
+	}
+	return p.splitGreater(nil)
+}
+
+// jsxElementName parses the name of an element, which may be a namespaced
+// name or a member expression.
+func (p *Parser) jsxElementName() (ast.Expression, error) {
+	id, err := p.jsxIdentifier()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case p.skipAndMatch(token.TOKEN_COLON):
+		name, err := p.jsxIdentifier()
+		if err != nil {
+			return nil, err
+		}
+		return &ast.JSXNamespacedName{Namespace: id, Name: name}, nil
+	case p.skipAndMatch(token.TOKEN_DOT):
+		var expr ast.Expression = id
+		for {
+			prop, err := p.jsxIdentifier()
+			if err != nil {
+				return nil, err
+			}
+			expr = &ast.JSXMemberExpression{Object: expr, Property: prop}
+			if !p.skipAndMatch(token.TOKEN_DOT) {
+				return expr, nil
+			}
+		}
+	}
+	return id, nil
+}
+
+// jsxAttributes parses the attributes of an opening tag.
+func (p *Parser) jsxAttributes() ([]ast.Expression, error) {
+	attrs := make([]ast.Expression, 0)
+	for {
+		p.skip()
+		tok := p.current()
+		if tok.TokenType == token.TOKEN_SLASH || isGreater(tok) || p.isSyntaxError() {
+			return attrs, p.err
+		}
+
+		if tok.TokenType == token.TOKEN_LEFT_BRACE {
+			p.advance()
+			if _, err := p.skipAndConsume(token.TOKEN_DOT_DOT_DOT); err != nil {
+				return nil, err
+			}
+			arg, err := p.assignmentExpr()
+			if err != nil {
+				return nil, err
+			} else if arg == nil {
+				return nil, p.newSyntaxError(p.current())
+			}
+			rbrace, err := p.skipAndConsume(token.TOKEN_RIGHT_BRACE)
+			if err != nil {
+				return nil, err
+			}
+			attrs = append(attrs, &ast.JSXSpreadAttribute{LBrace: *tok, RBrace: *rbrace, Argument: arg})
+			continue
+		}
+
+		attr, err := p.jsxAttribute()
+		if err != nil {
+			return nil, err
+		}
+		attrs = append(attrs, attr)
+	}
+}
+
+func (p *Parser) jsxAttribute() (*ast.JSXAttribute, error) {
+	id, err := p.jsxIdentifier()
+	if err != nil {
+		return nil, err
+	}
+	attr := &ast.JSXAttribute{Name: id}
+	if p.skipAndMatch(token.TOKEN_COLON) {
+		name, err := p.jsxIdentifier()
+		if err != nil {
+			return nil, err
+		}
+		attr.Name = &ast.JSXNamespacedName{Namespace: id, Name: name}
+	}
+
+	p.skip()
+	if tok := p.current(); tok.TokenType != token.TOKEN_EQUAL {
+		return attr, nil
+	} else if err := p.rescan(tok.End(), p.l.ScanJSXString); err != nil {
+		return nil, err
+	}
+	p.advance()
+
+	tok := p.current()
+	switch tok.TokenType {
+	case token.TOKEN_STRING:
+		p.advance()
+		attr.Value = &ast.Literal{Token: *tok, Value: decodeJSXEntities(tok.Literal), Kind: ast.LitString}
+	case token.TOKEN_LEFT_BRACE:
+		attr.Value, err = p.jsxExpressionContainer(false)
+	case token.TOKEN_LESS:
+		attr.Value, err = p.jsxElement(false)
+	default:
+		return nil, p.newSyntaxErrorf(tok, "JSX value should be either an expression or a quoted JSX text")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return attr, p.err
+}
+
+// jsxIdentifier parses the name of an element or an attribute. The name is an
+// identifier name that may contain dashes, which is scanned as the adjacent
+// tokens of an identifier name followed by dashes, identifier names and
+// numbers.
+func (p *Parser) jsxIdentifier() (*ast.JSXIdentifier, error) {
+	p.skip()
+	tok := p.current()
+	if tok.TokenType != token.TOKEN_IDENTIFIER && !tok.TokenType.IsKeyword() {
+		return nil, p.newSyntaxError(tok)
+	}
+	p.advance()
+
+	id := &ast.JSXIdentifier{Token: *tok, EndToken: *tok, Name: tok.Literal}
+	for {
+		next := p.current()
+		if next.Offset != id.EndToken.End().Offset {
+			break
+		}
+		switch {
+		case next.TokenType == token.TOKEN_MINUS, next.TokenType == token.TOKEN_MINUS_MINUS,
+			next.TokenType == token.TOKEN_NUMBER, next.TokenType == token.TOKEN_IDENTIFIER,
+			next.TokenType.IsKeyword():
+		default:
+			return id, p.err
+		}
+		p.advance()
+		id.EndToken = *next
+		id.Name += next.Raw
+	}
+	return id, p.err
+}
+
+// jsxEntity matches a character reference of JSX text or strings.
+var jsxEntity = regexp.MustCompile(`&(?:#[0-9]+|#x[0-9a-fA-F]+|[A-Za-z][A-Za-z0-9]*);`)
+
+// decodeJSXEntities decodes the character references of JSX text or strings,
+// such as &amp; and &#123;. Unknown references are kept as they are.
+func decodeJSXEntities(s string) string {
+	return jsxEntity.ReplaceAllStringFunc(s, html.UnescapeString)
+}
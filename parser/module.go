@@ -0,0 +1,496 @@
+package parser
+
+import (
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/token"
+)
+
+func (p *Parser) moduleItem() (ast.Statement, error) {
+	p.skip()
+
+	switch p.current().TokenType {
+	case token.TOKEN_IMPORT:
+		isDecl, err := p.isImportDeclaration()
+		if err != nil {
+			return nil, err
+		} else if isDecl {
+			return p.importDeclaration()
+		}
+	case token.TOKEN_EXPORT:
+		return p.exportDeclaration()
+	}
+
+	return p.statement()
+}
+
+// isImportDeclaration reports whether the current import token starts an
+// import declaration rather than an import call or import.meta.
+func (p *Parser) isImportDeclaration() (bool, error) {
+	next, _, err := p.peekSignificant()
+	if err != nil {
+		return false, err
+	}
+
+	switch next.TokenType {
+	case token.TOKEN_LEFT_PAREN, token.TOKEN_DOT:
+		return false, nil
+	}
+	return true, nil
+}
+
+func (p *Parser) importDeclaration() (ast.Statement, error) {
+	keyword := p.advance()
+
+	decl := &ast.ImportDeclaration{
+		Token:      *keyword,
+		Specifiers: make([]ast.Expression, 0),
+	}
+
+	p.skip()
+	// A type-only import, as in import type { T } from "m", which is erased.
+	typeOnly, err := p.tsTypeOnly()
+	if err != nil {
+		return nil, err
+	}
+	if p.current().TokenType != token.TOKEN_STRING {
+		if err := p.importClause(decl); err != nil {
+			return nil, err
+		}
+		if _, err := p.skipAndConsume(token.TOKEN_FROM); err != nil {
+			return nil, err
+		}
+	}
+
+	source, err := p.moduleSpecifier()
+	if err != nil {
+		return nil, err
+	}
+	decl.Source = source
+
+	decl.Attributes, err = p.importAttributes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.semicolon(); err != nil {
+		return nil, err
+	}
+	decl.EndToken = *p.last
+
+	if typeOnly {
+		return nil, nil
+	}
+	return decl, nil
+}
+
+func (p *Parser) importClause(decl *ast.ImportDeclaration) error {
+	if p.isIdentifierReference(p.current()) {
+		local, err := p.bindingIdentifier()
+		if err != nil {
+			return err
+		}
+		decl.Specifiers = append(decl.Specifiers, &ast.ImportDefaultSpecifier{Local: local})
+
+		if !p.skipAndMatch(token.TOKEN_COMMA) {
+			return nil
+		}
+	}
+
+	switch {
+	case p.skipAndMatch(token.TOKEN_STAR):
+		star := p.previous()
+		if _, err := p.skipAndConsume(token.TOKEN_AS); err != nil {
+			return err
+		}
+		local, err := p.bindingIdentifier()
+		if err != nil {
+			return err
+		}
+		decl.Specifiers = append(decl.Specifiers, &ast.ImportNamespaceSpecifier{Token: *star, Local: local})
+	case p.skipAndMatch(token.TOKEN_LEFT_BRACE):
+		for !p.skipAndMatch(token.TOKEN_RIGHT_BRACE) {
+			p.skip()
+			typeOnly, err := p.tsTypeOnly()
+			if err != nil {
+				return err
+			}
+			tok := p.current()
+			imported, err := p.moduleExportName()
+			if err != nil {
+				return err
+			}
+
+			var local *ast.Identifier
+			if p.skipAndMatch(token.TOKEN_AS) {
+				local, err = p.bindingIdentifier()
+				if err != nil {
+					return err
+				}
+			} else if id, ok := imported.(*ast.Identifier); ok && p.isIdentifierReference(tok) {
+				local = id
+			} else {
+				return p.newSyntaxError(tok)
+			}
+			if !typeOnly {
+				decl.Specifiers = append(decl.Specifiers, &ast.ImportSpecifier{
+					Imported: imported,
+					Local:    local,
+				})
+			}
+
+			if !p.skipAndMatch(token.TOKEN_COMMA) {
+				if _, err := p.skipAndConsume(token.TOKEN_RIGHT_BRACE); err != nil {
+					return err
+				}
+				break
+			}
+		}
+	default:
+		return p.newSyntaxError(p.current())
+	}
+
+	return nil
+}
+
+// moduleExportName parses an identifier name or a string literal naming an
+// import or export.
+func (p *Parser) moduleExportName() (ast.Expression, error) {
+	p.skip()
+	tok := p.current()
+
+	switch {
+	case tok.TokenType == token.TOKEN_STRING:
+		p.advance()
+		return &ast.Literal{Token: *tok, Value: tok.Literal, Kind: ast.LitString}, p.err
+	case tok.TokenType == token.TOKEN_IDENTIFIER || tok.TokenType.IsKeyword():
+		p.advance()
+		return &ast.Identifier{Token: *tok, Value: tok.Literal}, p.err
+	}
+
+	return nil, p.newSyntaxError(tok)
+}
+
+func (p *Parser) moduleSpecifier() (*ast.Literal, error) {
+	p.skip()
+	tok := p.current()
+	if tok.TokenType != token.TOKEN_STRING {
+		return nil, p.newSyntaxError(tok)
+	}
+	p.advance()
+
+	return &ast.Literal{Token: *tok, Value: tok.Literal, Kind: ast.LitString}, p.err
+}
+
+// importAttributes parses the optional with clause of an import or export
+// declaration.
+func (p *Parser) importAttributes() ([]*ast.ImportAttribute, error) {
+	if !p.skipAndMatch(token.TOKEN_WITH) {
+		return nil, nil
+	}
+	if err := p.require(p.last, 2025, "Import attributes"); err != nil {
+		return nil, err
+	}
+	if _, err := p.skipAndConsume(token.TOKEN_LEFT_BRACE); err != nil {
+		return nil, err
+	}
+
+	attrs := make([]*ast.ImportAttribute, 0)
+	keys := make(map[string]bool)
+	for !p.skipAndMatch(token.TOKEN_RIGHT_BRACE) {
+		p.skip()
+		tok := p.current()
+		key, err := p.moduleExportName()
+		if err != nil {
+			return nil, err
+		}
+		if keys[tok.Literal] {
+			return nil, p.newSyntaxErrorf(tok, "Import attribute has duplicate key '%s'", tok.Literal)
+		}
+		keys[tok.Literal] = true
+
+		if _, err := p.skipAndConsume(token.TOKEN_COLON); err != nil {
+			return nil, err
+		}
+		val, err := p.moduleSpecifier()
+		if err != nil {
+			return nil, err
+		}
+		attrs = append(attrs, &ast.ImportAttribute{Key: key, Value: val})
+
+		if !p.skipAndMatch(token.TOKEN_COMMA) {
+			if _, err := p.skipAndConsume(token.TOKEN_RIGHT_BRACE); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	return attrs, nil
+}
+
+func (p *Parser) exportDeclaration() (ast.Statement, error) {
+	keyword := p.advance()
+	p.skip()
+
+	tok := p.current()
+	if stmt, ok, err := p.tsStatement(); err != nil {
+		return nil, err
+	} else if ok && stmt == nil {
+		return nil, nil
+	} else if ok {
+		return &ast.ExportNamedDeclaration{Token: *keyword, EndToken: *p.last, Declaration: stmt}, nil
+	}
+
+	switch tok.TokenType {
+	case token.TOKEN_STAR:
+		p.advance()
+		return p.exportAllDeclaration(keyword)
+	case token.TOKEN_DEFAULT:
+		p.advance()
+		return p.exportDefaultDeclaration(keyword)
+	case token.TOKEN_LEFT_BRACE:
+		p.advance()
+		return p.exportList(keyword)
+	case token.TOKEN_VAR:
+		decl, err := p.variableStatement()
+		if err != nil {
+			return nil, err
+		}
+		return &ast.ExportNamedDeclaration{Token: *keyword, EndToken: *p.last, Declaration: decl}, nil
+	case token.TOKEN_LET, token.TOKEN_CONST:
+		decl, err := p.lexicalDeclaration()
+		if err != nil {
+			return nil, err
+		}
+		return &ast.ExportNamedDeclaration{Token: *keyword, EndToken: *p.last, Declaration: decl}, nil
+	case token.TOKEN_FUNCTION, token.TOKEN_ASYNC:
+		decl, err := p.exportedFunction(false)
+		if err != nil {
+			return nil, err
+		} else if decl != nil {
+			return &ast.ExportNamedDeclaration{Token: *keyword, EndToken: *p.last, Declaration: decl}, nil
+		} else if p.current() != tok {
+			// An overload signature of TypeScript, which is erased.
+			return nil, nil
+		}
+	}
+	if isContextual(tok, "type") {
+		// A type-only export list, as in export type { T }, which is erased.
+		if typeOnly, err := p.tsTypeOnly(); err != nil {
+			return nil, err
+		} else if typeOnly && p.skipAndMatch(token.TOKEN_LEFT_BRACE) {
+			_, err := p.exportList(keyword)
+			return nil, err
+		}
+	}
+
+	return nil, p.newSyntaxError(tok)
+}
+
+// exportedFunction parses an exported function declaration, and returns nil if
+// the current token does not start one.
+func (p *Parser) exportedFunction(optionalName bool) (ast.Statement, error) {
+	start := p.current()
+	async := false
+	if p.current().TokenType == token.TOKEN_ASYNC {
+		isAsync, err := p.isAsyncFunction()
+		if err != nil || !isAsync {
+			return nil, err
+		}
+		async = true
+		p.advance()
+		p.skip(token.TOKEN_SPACE, token.TOKEN_MULTI_LINE_COMMENT)
+	}
+
+	return p.functionDeclaration(start, async, optionalName)
+}
+
+func (p *Parser) exportAllDeclaration(keyword *token.Token) (ast.Statement, error) {
+	decl := &ast.ExportAllDeclaration{Token: *keyword}
+
+	if p.skipAndMatch(token.TOKEN_AS) {
+		if err := p.require(p.last, 2020, "Namespace export"); err != nil {
+			return nil, err
+		}
+		exported, err := p.moduleExportName()
+		if err != nil {
+			return nil, err
+		}
+		decl.Exported = exported
+	}
+
+	if _, err := p.skipAndConsume(token.TOKEN_FROM); err != nil {
+		return nil, err
+	}
+
+	source, err := p.moduleSpecifier()
+	if err != nil {
+		return nil, err
+	}
+	decl.Source = source
+
+	decl.Attributes, err = p.importAttributes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.semicolon(); err != nil {
+		return nil, err
+	}
+	decl.EndToken = *p.last
+
+	return decl, nil
+}
+
+func (p *Parser) exportDefaultDeclaration(keyword *token.Token) (ast.Statement, error) {
+	p.skip()
+
+	switch tok := p.current(); tok.TokenType {
+	case token.TOKEN_FUNCTION, token.TOKEN_ASYNC:
+		decl, err := p.exportedFunction(true)
+		if err != nil {
+			return nil, err
+		} else if decl != nil {
+			return &ast.ExportDefaultDeclaration{Token: *keyword, EndToken: *p.last, Declaration: decl}, nil
+		} else if p.current() != tok {
+			return nil, nil
+		}
+	case token.TOKEN_INTERFACE:
+		if stmt, ok, err := p.tsStatement(); ok || err != nil {
+			return stmt, err
+		}
+	}
+
+	expr, err := p.assignmentExpr()
+	if err != nil {
+		return nil, err
+	} else if expr == nil {
+		return nil, p.newSyntaxError(p.current())
+	}
+
+	if err := p.semicolon(); err != nil {
+		return nil, err
+	}
+
+	return &ast.ExportDefaultDeclaration{Token: *keyword, EndToken: *p.last, Declaration: expr}, nil
+}
+
+func (p *Parser) exportList(keyword *token.Token) (ast.Statement, error) {
+	decl := &ast.ExportNamedDeclaration{
+		Token:      *keyword,
+		Specifiers: make([]*ast.ExportSpecifier, 0),
+	}
+	// The first local name that is not a valid reference, which is only
+	// allowed when re-exporting from another module.
+	var invalidLocal *token.Token
+
+	for !p.skipAndMatch(token.TOKEN_RIGHT_BRACE) {
+		p.skip()
+		typeOnly, err := p.tsTypeOnly()
+		if err != nil {
+			return nil, err
+		}
+		tok := p.current()
+		local, err := p.moduleExportName()
+		if err != nil {
+			return nil, err
+		}
+		if invalidLocal == nil && !p.isIdentifierReference(tok) {
+			invalidLocal = tok
+		}
+
+		exported := local
+		if p.skipAndMatch(token.TOKEN_AS) {
+			exported, err = p.moduleExportName()
+			if err != nil {
+				return nil, err
+			}
+		}
+		if !typeOnly {
+			decl.Specifiers = append(decl.Specifiers, &ast.ExportSpecifier{
+				Local:    local,
+				Exported: exported,
+			})
+		}
+
+		if !p.skipAndMatch(token.TOKEN_COMMA) {
+			if _, err := p.skipAndConsume(token.TOKEN_RIGHT_BRACE); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	if p.skipAndMatch(token.TOKEN_FROM) {
+		source, err := p.moduleSpecifier()
+		if err != nil {
+			return nil, err
+		}
+		decl.Source = source
+
+		decl.Attributes, err = p.importAttributes()
+		if err != nil {
+			return nil, err
+		}
+	} else if invalidLocal != nil {
+		return nil, p.newSyntaxError(invalidLocal)
+	}
+
+	if err := p.semicolon(); err != nil {
+		return nil, err
+	}
+	decl.EndToken = *p.last
+
+	return decl, nil
+}
+
+// importExpr parses an import call or import.meta after the import keyword.
+func (p *Parser) importExpr(tok *token.Token) (ast.Expression, error) {
+	if p.skipAndMatch(token.TOKEN_DOT) {
+		p.skip()
+		prop := p.current()
+		if prop.TokenType != token.TOKEN_META {
+			return nil, p.newSyntaxError(prop)
+		} else if !p.module {
+			return nil, p.newSyntaxErrorf(prop, "Cannot use 'import.meta' outside a module")
+		} else if err := p.require(tok, 2020, "import.meta"); err != nil {
+			return nil, err
+		}
+		p.advance()
+
+		return &ast.MetaProperty{
+			Meta:     &ast.Identifier{Token: *tok, Value: tok.Literal},
+			Property: &ast.Identifier{Token: *prop, Value: prop.Literal},
+		}, p.err
+	}
+
+	if err := p.require(tok, 2020, "Dynamic import"); err != nil {
+		return nil, err
+	}
+	if _, err := p.skipAndConsume(token.TOKEN_LEFT_PAREN); err != nil {
+		return nil, err
+	}
+
+	args, err := p.arguments()
+	if err != nil {
+		return nil, err
+	}
+	rparen := p.previous()
+	switch {
+	case len(args) == 0:
+		return nil, p.newSyntaxErrorf(tok, "import() requires a specifier")
+	case len(args) > 2:
+		return nil, p.newSyntaxErrorf(tok, "import() accepts at most two arguments")
+	}
+	for _, arg := range args {
+		if _, ok := arg.(*ast.SpreadElement); ok {
+			return nil, p.newSyntaxErrorf(tok, "... is not allowed in import()")
+		}
+	}
+
+	expr := &ast.ImportExpression{Token: *tok, RParen: *rparen, Source: args[0]}
+	if len(args) == 2 {
+		expr.Options = args[1]
+	}
+	return expr, nil
+}
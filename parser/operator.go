@@ -0,0 +1,181 @@
+package parser
+
+import (
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/token"
+)
+
+// precedence is the binding power of a binary operator. An operator of a
+// higher precedence binds tighter than the operators of lower ones.
+type precedence int
+
+const (
+	precNone precedence = iota
+	// precLogicalOr is the precedence of || and ??, which may not be mixed
+	// without parentheses.
+	precLogicalOr
+	precLogicalAnd
+	precBitwiseOr
+	precBitwiseXor
+	precBitwiseAnd
+	precEquality
+	precRelational
+	precShift
+	precAdditive
+	precMultiplicative
+	precExponentiation
+)
+
+var binaryPrecedences = map[token.TokenType]precedence{
+	token.TOKEN_PIPE_PIPE:               precLogicalOr,
+	token.TOKEN_QUESTION_QUESTION:       precLogicalOr,
+	token.TOKEN_AND_AND:                 precLogicalAnd,
+	token.TOKEN_PIPE:                    precBitwiseOr,
+	token.TOKEN_HAT:                     precBitwiseXor,
+	token.TOKEN_AND:                     precBitwiseAnd,
+	token.TOKEN_EQUAL_EQUAL:             precEquality,
+	token.TOKEN_BANG_EQUAL:              precEquality,
+	token.TOKEN_EQUAL_EQUAL_EQUAL:       precEquality,
+	token.TOKEN_BANG_EQUAL_EQUAL:        precEquality,
+	token.TOKEN_LESS:                    precRelational,
+	token.TOKEN_GREATER:                 precRelational,
+	token.TOKEN_LESS_EQUAL:              precRelational,
+	token.TOKEN_GREATER_EQUAL:           precRelational,
+	token.TOKEN_INSTANCEOF:              precRelational,
+	token.TOKEN_IN:                      precRelational,
+	token.TOKEN_LESS_LESS:               precShift,
+	token.TOKEN_GREATER_GREATER:         precShift,
+	token.TOKEN_GREATER_GREATER_GREATER: precShift,
+	token.TOKEN_PLUS:                    precAdditive,
+	token.TOKEN_MINUS:                   precAdditive,
+	token.TOKEN_STAR:                    precMultiplicative,
+	token.TOKEN_SLASH:                   precMultiplicative,
+	token.TOKEN_PERCENT:                 precMultiplicative,
+	token.TOKEN_STAR_STAR:               precExponentiation,
+}
+
+// binaryPrecedence returns the precedence of tok as a binary operator, or
+// precNone if it is not one here.
+func (p *Parser) binaryPrecedence(tok *token.Token) precedence {
+	if tok.TokenType == token.TOKEN_IN && p.noIn {
+		return precNone
+	}
+	return binaryPrecedences[tok.TokenType]
+}
+
+// binaryExpr parses a binary expression whose operators have a precedence of
+// at least minPrec. The operands are parsed in a loop, and only the right
+// operand of an operator that binds tighter recurses, so the depth does not
+// grow with the length of the expression.
+//
+// It also returns the operator of the outermost binary expression it parsed,
+// or nil if there is none, to reject mixing ?? with && and || without
+// parentheses.
+func (p *Parser) binaryExpr(minPrec precedence) (ast.Expression, *token.Token, error) {
+	p.depth++
+	p.maxDepth = max(p.maxDepth, p.depth)
+	defer func() { p.depth-- }()
+
+	p.skip()
+	unary := p.isUnaryOperator(p.current())
+	left, err := p.unaryExpr()
+	if err != nil || left == nil {
+		return left, nil, err
+	}
+
+	var leftOp *token.Token
+	for {
+		p.skip()
+		op := p.current()
+		if p.isTypeAssertion(op) && precRelational >= minPrec {
+			// x as T and x satisfies T, whose types are erased.
+			p.advance()
+			if err := p.tsType(); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+		prec := p.binaryPrecedence(op)
+		if prec == precNone || prec < minPrec {
+			return left, leftOp, nil
+		}
+
+		if err := p.requireOperator(op); err != nil {
+			return nil, nil, err
+		}
+
+		next := prec + 1
+		if op.TokenType == token.TOKEN_STAR_STAR {
+			if unary {
+				return nil, nil, p.newSyntaxErrorf(op, "Unary operator used immediately before exponentiation expression. Parenthesis must be used to disambiguate operator precedence")
+			}
+			// The exponentiation operator is right-associative.
+			next = prec
+		}
+		p.advance()
+
+		right, rightOp, err := p.binaryExpr(next)
+		if err != nil {
+			return nil, nil, err
+		} else if right == nil {
+			return nil, nil, p.newSyntaxError(p.current())
+		}
+		if isMixedCoalesce(op, leftOp) || isMixedCoalesce(op, rightOp) {
+			return nil, nil, p.newSyntaxError(op)
+		}
+
+		if isLogicalOperator(op) {
+			left = &ast.LogicalExpression{Operator: op, Left: left, Right: right}
+		} else {
+			left = &ast.BinaryExpression{Operator: op, Left: left, Right: right}
+		}
+		leftOp = op
+		unary = false
+	}
+}
+
+// isTypeAssertion reports whether tok is the operator of a TypeScript as or
+// satisfies expression, which must be on the same line as its operand.
+func (p *Parser) isTypeAssertion(tok *token.Token) bool {
+	if !p.opts.TypeScript || p.newLine {
+		return false
+	}
+	return tok.TokenType == token.TOKEN_AS || isContextual(tok, "satisfies")
+}
+
+// isMixedCoalesce reports whether the operator op is applied to an operand of
+// the operator inner, where one of them is ?? and the other is && or ||.
+func isMixedCoalesce(op, inner *token.Token) bool {
+	if inner == nil {
+		return false
+	}
+
+	isCoalesce := func(tok *token.Token) bool {
+		return tok.TokenType == token.TOKEN_QUESTION_QUESTION
+	}
+	isLogical := func(tok *token.Token) bool {
+		return tok.TokenType == token.TOKEN_AND_AND || tok.TokenType == token.TOKEN_PIPE_PIPE
+	}
+	return isCoalesce(op) && isLogical(inner) || isLogical(op) && isCoalesce(inner)
+}
+
+// isLogicalOperator reports whether tok is a short-circuiting operator.
+func isLogicalOperator(tok *token.Token) bool {
+	switch tok.TokenType {
+	case token.TOKEN_AND_AND, token.TOKEN_PIPE_PIPE, token.TOKEN_QUESTION_QUESTION:
+		return true
+	}
+	return false
+}
+
+// isUnaryOperator reports whether tok is the operator of a unary expression.
+func (p *Parser) isUnaryOperator(tok *token.Token) bool {
+	switch tok.TokenType {
+	case token.TOKEN_DELETE, token.TOKEN_VOID, token.TOKEN_TYPEOF, token.TOKEN_PLUS,
+		token.TOKEN_MINUS, token.TOKEN_TILDE, token.TOKEN_BANG:
+		return true
+	case token.TOKEN_AWAIT:
+		return p.inAsync
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package parser
+
+import (
+	"strconv"
+
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/lexer"
+	"github.com/ghosind/gjs/token"
+)
+
+// LatestEcmaVersion is the latest edition of ECMAScript the parser supports.
+const LatestEcmaVersion = 2025
+
+// Options configures a parser. The zero value parses the latest edition of
+// ECMAScript as a script.
+type Options struct {
+	// EcmaVersion is the edition of ECMAScript to parse, either 5 or a year
+	// from 2015 on. The editions from 6 on may also be given by their number,
+	// such as 6 for 2015. Syntax introduced in a later edition is rejected.
+	// It is LatestEcmaVersion if zero. Other values before 2015 select ES5,
+	// and the years after LatestEcmaVersion select LatestEcmaVersion.
+	EcmaVersion int
+	// SourceType selects whether Parse parses the source as a script or as a
+	// module.
+	SourceType ast.SourceType
+	// AllowReturnOutsideFunction allows return statements at the top level.
+	AllowReturnOutsideFunction bool
+	// AllowHashBang allows a hashbang comment at the start of the source in
+	// editions before ES2023, which introduced it.
+	AllowHashBang bool
+	// FileName is the name of the source file, which is recorded in the
+	// parsed program.
+	FileName string
+	// DisableAnnexB rejects the web compatibility syntax of Annex B, which is
+	// otherwise allowed in non-strict code: function declarations as the body
+	// of an if statement or of a labeled statement.
+	DisableAnnexB bool
+	// JSX enables the JSX extension, which parses elements such as
+	// <div className="a">{text}</div> as expressions. It is disabled by
+	// default, as JSX is not a part of ECMAScript.
+	JSX bool
+	// TypeScript enables parsing TypeScript. The type annotations, the type
+	// declarations and the other syntax of types are erased from the program,
+	// and enums are lowered to objects, so the program can be evaluated as
+	// JavaScript.
+	TypeScript bool
+	// Comments keeps the comments of the source in the Comments of the
+	// parsed program, such as for printing it again.
+	Comments bool
+}
+
+// NewWithOptions returns a parser of the source scanned by l, configured by
+// opts.
+func NewWithOptions(l *lexer.Lexer, opts Options) *Parser {
+	p := new(Parser)
+	p.l = l
+
+	opts.EcmaVersion = ecmaVersion(opts.EcmaVersion)
+	p.opts = opts
+	return p
+}
+
+// ecmaVersion returns the year of the edition selected by version, as
+// described by Options.EcmaVersion.
+func ecmaVersion(version int) int {
+	if version >= 6 && version <= LatestEcmaVersion-2009 {
+		// The number of an edition, such as 6 for 2015.
+		version += 2009
+	}
+	switch {
+	case version == 0 || version > LatestEcmaVersion:
+		return LatestEcmaVersion
+	case version < 2015:
+		return 5
+	}
+	return version
+}
+
+// Parse parses the source as a script or as a module, as selected by the
+// options of the parser.
+func (p *Parser) Parse() (*ast.Program, error) {
+	if p.opts.SourceType == ast.SourceModule {
+		return p.ParseModule()
+	}
+	return p.ParseProgram()
+}
+
+// require rejects the syntax starting at tok, named by feature, if it was
+// introduced after the edition being parsed.
+func (p *Parser) require(tok *token.Token, version int, feature string) error {
+	if p.opts.EcmaVersion >= version {
+		return nil
+	}
+	return p.newSyntaxErrorf(tok, "%s requires %s or later, but %s is selected",
+		feature, editionName(version), editionName(p.opts.EcmaVersion))
+}
+
+func editionName(version int) string {
+	return "ES" + strconv.Itoa(version)
+}
+
+// annexB reports whether the web compatibility syntax of Annex B is allowed
+// in the current code.
+func (p *Parser) annexB() bool {
+	return !p.opts.DisableAnnexB && !p.strict
+}
+
+// operatorVersions are the editions that introduced the operators added after
+// ES5.
+var operatorVersions = map[token.TokenType]int{
+	token.TOKEN_STAR_STAR:               2016,
+	token.TOKEN_STAR_STAR_EQUAL:         2016,
+	token.TOKEN_QUESTION_QUESTION:       2020,
+	token.TOKEN_AND_AND_EQUAL:           2021,
+	token.TOKEN_PIPE_PIPE_EQUAL:         2021,
+	token.TOKEN_QUESTION_QUESTION_EQUAL: 2021,
+}
+
+// requireOperator rejects the operator op if it was introduced after the
+// edition being parsed.
+func (p *Parser) requireOperator(op *token.Token) error {
+	if version, ok := operatorVersions[op.TokenType]; ok {
+		return p.require(op, version, "Operator '"+op.TokenType.Text()+"'")
+	}
+	return nil
+}
+
+// requireDestructuring rejects an assignment target at tok if it is a
+// destructuring pattern, which was introduced in ES2015.
+func (p *Parser) requireDestructuring(tok *token.Token, target ast.Expression) error {
+	switch target.(type) {
+	case *ast.ArrayPattern, *ast.ObjectPattern:
+		return p.require(tok, 2015, "Destructuring assignment")
+	}
+	return nil
+}
+
+// requireFunctionKind rejects an async or a generator function starting at
+// tok if it was introduced after the edition being parsed.
+func (p *Parser) requireFunctionKind(tok *token.Token, async, generator bool) error {
+	switch {
+	case async && generator:
+		return p.require(tok, 2018, "Async generator function")
+	case async:
+		return p.require(tok, 2017, "Async function")
+	case generator:
+		return p.require(tok, 2015, "Generator function")
+	}
+	return nil
+}
@@ -1,8 +1,7 @@
 package parser
 
 import (
-	"errors"
-	"fmt"
+	"strings"
 
 	"github.com/ghosind/gjs/ast"
 	"github.com/ghosind/gjs/lexer"
@@ -10,52 +9,275 @@ import (
 )
 
 type Parser struct {
-	l   *lexer.Lexer
-	err error
+	l    *lexer.Lexer
+	err  error
+	opts Options
+
+	// tolerant is set to recover from syntax errors, which are collected in
+	// errs.
+	tolerant bool
+	errs     ErrorList
 
 	prevToken *token.Token
 	curToken  *token.Token
 	peekToken *token.Token
+	buffer    []*token.Token
+	// scanErr is the error of scanning the token after the current one. It
+	// is returned when the parser moves past the current token, as the source
+	// after it may be scanned again in another way, such as JSX text.
+	scanErr error
+
+	// newLine reports whether a line terminator precedes the current token.
+	newLine bool
+	// last is the last token consumed that is not a white space, a line
+	// terminator or a comment.
+	last *token.Token
+
+	// module is set when parsing a module, where await is reserved.
+	module bool
+	// strict is set when parsing strict mode code.
+	strict      bool
+	inAsync     bool
+	inGenerator bool
+	// noIn disallows the in operator while parsing the head of a for loop.
+	noIn bool
+
+	// labels are the labels enclosing the current statement in the current
+	// function, and labelSet the ones that directly precede it.
+	labels   []*label
+	labelSet []*label
+
+	// comments are the comments consumed so far, if they are kept.
+	comments []token.Token
+
+	// enums are the names of the TypeScript enums declared so far, whose
+	// members are added to the same object if they are declared again.
+	enums map[string]bool
+
+	// depth is the number of nested calls of binaryExpr, and maxDepth the
+	// largest depth reached, which tells how deep the parsing of binary
+	// expressions recurses.
+	depth, maxDepth int
 }
 
-func New(l *lexer.Lexer) *Parser {
-	p := new(Parser)
-	p.l = l
+type label struct {
+	name string
+	loop bool
+}
 
-	return p
+// New returns a parser of the source scanned by l, with the default options.
+func New(l *lexer.Lexer) *Parser {
+	return NewWithOptions(l, Options{})
 }
 
 func (p *Parser) ParseProgram() (*ast.Program, error) {
+	return p.parse(ast.SourceScript)
+}
+
+// ParseModule parses the source as a module, which may contain import and
+// export declarations and await expressions at the top level.
+func (p *Parser) ParseModule() (*ast.Program, error) {
+	p.module = true
+	p.strict = true
+	// Top-level await was introduced in ES2022.
+	p.inAsync = p.opts.EcmaVersion >= 2022
+	return p.parse(ast.SourceModule)
+}
+
+func (p *Parser) parse(sourceType ast.SourceType) (*ast.Program, error) {
+	program := new(ast.Program)
+	program.Statements = make([]ast.Statement, 0)
+	program.SourceType = sourceType
+	program.FileName = p.opts.FileName
+
+	if err := p.parseStatements(program); err != nil {
+		if !p.tolerant {
+			p.locate(err)
+			return nil, err
+		} else if err != errAborted {
+			p.report(err)
+		}
+	}
+
+	program.Strict = p.strict
+	program.Comments = p.comments
+	if err := check(program, p.opts.AllowReturnOutsideFunction); err != nil {
+		if !p.tolerant {
+			p.locate(err)
+			return nil, err
+		}
+		p.report(err)
+	}
+
+	p.locate(p.errs)
+	return program, p.errs.Err()
+}
+
+// ParseExpression parses src as a single expression, such as the condition of
+// a rule or an expression in a template. It is an error if src is empty or if
+// anything but white space and comments follows the expression.
+func ParseExpression(src string) (ast.Expression, error) {
+	return New(lexer.New([]byte(src))).ParseExpression()
+}
+
+// ParseExpression parses the source as a single expression, which must be
+// followed by the end of the source.
+func (p *Parser) ParseExpression() (ast.Expression, error) {
+	if p.opts.SourceType == ast.SourceModule {
+		p.module = true
+		p.strict = true
+	}
+
+	expr, err := p.parseExpression()
+	if err == nil {
+		err = checkExpression(expr, p.strict)
+	}
+	if err != nil {
+		p.locate(err)
+		return nil, err
+	}
+	return expr, nil
+}
+
+func (p *Parser) parseExpression() (ast.Expression, error) {
+	if err := p.readFirstToken(); err != nil {
+		return nil, err
+	}
+
+	expr, err := p.expression()
+	if err != nil {
+		return nil, err
+	} else if p.isSyntaxError() {
+		return nil, p.err
+	}
+	if p.skip(); expr == nil || p.current().TokenType != token.TOKEN_EOF {
+		return nil, p.newSyntaxError(p.current())
+	}
+	return expr, nil
+}
+
+// readFirstToken reads the first token of the source if it has not been read.
+func (p *Parser) readFirstToken() error {
 	for p.current() == nil {
 		err := p.nextToken()
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
+	return nil
+}
 
-	program := new(ast.Program)
-	program.Statements = make([]ast.Statement, 0)
+func (p *Parser) parseStatements(program *ast.Program) error {
+	if err := p.readFirstToken(); err != nil {
+		return err
+	}
+
+	if tok := p.current(); tok.TokenType == token.TOKEN_HASH_BANG && tok.Offset == 0 {
+		if !p.opts.AllowHashBang {
+			if err := p.require(tok, 2023, "Hashbang comment"); err != nil {
+				return err
+			}
+		}
+		hashBang := *tok
+		program.HashBang = &hashBang
+		p.advance()
+	}
+	if p.module {
+		p.skip()
+		if err := p.require(p.current(), 2015, "Module"); err != nil {
+			return err
+		}
+	}
 
-	for p.current().TokenType != token.TOKEN_EOF {
-		stmt, err := p.statement()
+	pro := new(prologue)
+	for p.skip(); p.current().TokenType != token.TOKEN_EOF; p.skip() {
+		start := p.current()
+		stmt, err := p.programItem()
 		if err != nil {
-			return nil, err
+			return err
 		} else if stmt != nil {
 			program.Statements = append(program.Statements, stmt)
 		}
-		err = p.nextToken()
-		if err != nil {
-			return nil, err
+
+		if err := p.directive(pro, start, stmt); err != nil {
+			return err
 		}
 	}
+	if p.isSyntaxError() {
+		return p.err
+	}
+	program.EndToken = *p.current()
+	program.Directives = pro.directives()
 
-	return program, nil
+	return nil
+}
+
+// programItem parses a statement at the top level of a script, or a module
+// item at the top level of a module.
+func (p *Parser) programItem() (ast.Statement, error) {
+	start := p.current()
+	var stmt ast.Statement
+	var err error
+	if p.module {
+		stmt, err = p.moduleItem()
+	} else {
+		stmt, err = p.statement()
+	}
+	if err == nil && p.current() == start {
+		err = p.newSyntaxError(start)
+	}
+	if err != nil {
+		return p.recoverStatement(start, err)
+	}
+	return stmt, nil
 }
 
 func (p *Parser) nextToken() error {
+	if err := p.scanErr; err != nil {
+		p.scanErr = nil
+		p.prevToken = p.curToken
+		return err
+	}
+	if p.curToken != nil {
+		switch p.curToken.TokenType {
+		case token.TOKEN_NEW_LINE:
+			p.newLine = true
+		case token.TOKEN_SPACE:
+		case token.TOKEN_SINGLE_LINE_COMMENT, token.TOKEN_MULTI_LINE_COMMENT:
+			if p.opts.Comments {
+				p.comments = append(p.comments, *p.curToken)
+			}
+		default:
+			p.newLine = false
+			p.last = p.curToken
+		}
+	}
 	p.prevToken = p.curToken
 	p.curToken = p.peekToken
+	if len(p.buffer) > 0 {
+		p.peekToken = p.buffer[0]
+		p.buffer = p.buffer[1:]
+		return nil
+	}
 	tok, err := p.l.ScanToken()
+	if err != nil {
+		p.scanErr = err
+		p.peekToken = p.curToken
+		return nil
+	}
+	p.peekToken = tok
+	return nil
+}
+
+// rescan discards the tokens scanned after the current one, and scans the
+// source from pos again with scan, which returns the token after the current
+// one.
+func (p *Parser) rescan(pos token.Position, scan func() (*token.Token, error)) error {
+	p.l.Reset(pos)
+	p.buffer = nil
+	p.scanErr = nil
+
+	tok, err := scan()
 	if err != nil {
 		return err
 	}
@@ -63,24 +285,172 @@ func (p *Parser) nextToken() error {
 	return nil
 }
 
+// splitGreater consumes the > at the start of the current token, which may
+// have been scanned together with the characters after it, as in >= or >>.
+// The source after the > is scanned again with scan, or as tokens if scan is
+// nil.
+func (p *Parser) splitGreater(scan func() (*token.Token, error)) (*token.Token, error) {
+	tok := p.current()
+	gt := *tok
+	gt.TokenType, gt.Literal, gt.Raw = token.TOKEN_GREATER, ">", ">"
+	if scan == nil && tok.TokenType != token.TOKEN_GREATER {
+		scan = p.l.ScanToken
+	}
+	if scan != nil {
+		if err := p.rescan(gt.End(), scan); err != nil {
+			return nil, err
+		}
+	}
+	p.curToken = &gt
+	p.advance()
+	return &gt, p.err
+}
+
+// isGreater reports whether tok starts with a >, as the token closing a list
+// of type arguments or a JSX tag may have been scanned together with the
+// characters after it.
+func isGreater(tok *token.Token) bool {
+	switch tok.TokenType {
+	case token.TOKEN_GREATER, token.TOKEN_GREATER_EQUAL, token.TOKEN_GREATER_GREATER,
+		token.TOKEN_GREATER_GREATER_EQUAL, token.TOKEN_GREATER_GREATER_GREATER,
+		token.TOKEN_GREATER_GREATER_GREATER_EQUAL:
+		return true
+	}
+	return false
+}
+
+// state is the position of the parser, saved to parse the source after it
+// in one way and to go back if that fails.
+type state struct {
+	prevToken, curToken, last *token.Token
+	newLine                   bool
+	errs, comments            int
+}
+
+func (p *Parser) save() state {
+	return state{
+		prevToken: p.prevToken,
+		curToken:  p.curToken,
+		last:      p.last,
+		newLine:   p.newLine,
+		errs:      len(p.errs),
+		comments:  len(p.comments),
+	}
+}
+
+// restore goes back to the saved state s, and scans the source after its
+// current token again.
+func (p *Parser) restore(s state) error {
+	p.prevToken, p.curToken, p.last, p.newLine = s.prevToken, s.curToken, s.last, s.newLine
+	p.err = nil
+	p.errs = p.errs[:s.errs]
+	p.comments = p.comments[:s.comments]
+	return p.rescan(s.curToken.End(), p.l.ScanToken)
+}
+
+// try parses the source from the current token with parse, and goes back to
+// the current token if it fails. It reports whether parse succeeded.
+func (p *Parser) try(parse func() error) (bool, error) {
+	s := p.save()
+	if err := parse(); err == nil && !p.isSyntaxError() {
+		return true, nil
+	}
+	return false, p.restore(s)
+}
+
+// peekSignificant returns the first token after the current one that is not a
+// white space or a comment, and whether a line terminator precedes it.
+func (p *Parser) peekSignificant() (*token.Token, bool, error) {
+	newLine := false
+	for i := 0; ; i++ {
+		tok := p.peekToken
+		if i > 0 {
+			for len(p.buffer) < i {
+				scanned, err := p.l.ScanToken()
+				if err != nil {
+					return nil, false, err
+				}
+				p.buffer = append(p.buffer, scanned)
+			}
+			tok = p.buffer[i-1]
+		}
+
+		switch tok.TokenType {
+		case token.TOKEN_SPACE, token.TOKEN_SINGLE_LINE_COMMENT, token.TOKEN_MULTI_LINE_COMMENT:
+		case token.TOKEN_NEW_LINE:
+			newLine = true
+		default:
+			return tok, newLine, nil
+		}
+	}
+}
+
 func (p *Parser) statement() (ast.Statement, error) {
 	p.skip()
 	tok := p.current()
 
+	labelSet := p.labelSet
+	p.labelSet = nil
+
+	if p.isIdentifierReference(tok) {
+		next, _, err := p.peekSignificant()
+		if err != nil {
+			return nil, err
+		} else if next.TokenType == token.TOKEN_COLON {
+			return p.labeledStmt(labelSet)
+		}
+	}
+	if stmt, ok, err := p.tsStatement(); ok || err != nil {
+		return stmt, err
+	}
+
 	switch tok.TokenType {
 	case token.TOKEN_BREAK:
 		return p.breakStmt()
 	case token.TOKEN_CONTINUE:
-		return p.continueStmt(), nil
+		return p.continueStmt()
+	case token.TOKEN_CONST:
+		return p.lexicalDeclaration()
+	case token.TOKEN_LET:
+		isDecl, err := p.isLetDeclaration()
+		if err != nil {
+			return nil, err
+		} else if isDecl {
+			return p.lexicalDeclaration()
+		}
+		return p.exprStmt()
 	case token.TOKEN_DEBUGGER:
-		if _, err := p.consume(token.TOKEN_SEMICOLON); err != nil {
+		p.advance()
+		if err := p.semicolon(); err != nil {
 			return nil, err
 		}
-		return new(ast.DebuggerStatement), nil
+		return &ast.DebuggerStatement{Token: *tok, EndToken: *p.last}, nil
 	case token.TOKEN_DO:
+		markIterationLabels(labelSet)
 		return p.doWhileStmt()
 	case token.TOKEN_FOR:
+		markIterationLabels(labelSet)
 		return p.forStmt()
+	case token.TOKEN_FUNCTION:
+		return p.functionDeclaration(tok, false, false)
+	case token.TOKEN_ASYNC:
+		isAsync, err := p.isAsyncFunction()
+		if err != nil {
+			return nil, err
+		} else if isAsync {
+			p.advance()
+			p.skip(token.TOKEN_SPACE, token.TOKEN_MULTI_LINE_COMMENT)
+			return p.functionDeclaration(tok, true, false)
+		}
+		return p.exprStmt()
+	case token.TOKEN_IMPORT:
+		isDecl, err := p.isImportDeclaration()
+		if err != nil {
+			return nil, err
+		} else if isDecl {
+			return nil, p.newSyntaxErrorf(tok, "Cannot use import statement outside a module")
+		}
+		return p.exprStmt()
 	case token.TOKEN_IF:
 		return p.ifStat()
 	case token.TOKEN_LEFT_BRACE:
@@ -91,7 +461,7 @@ func (p *Parser) statement() (ast.Statement, error) {
 		if _, err := p.consume(token.TOKEN_SEMICOLON); err != nil {
 			return nil, err
 		}
-		return new(ast.EmptyStatement), nil
+		return &ast.EmptyStatement{Token: *tok}, nil
 	case token.TOKEN_SWITCH:
 		return p.switchStmt()
 	case token.TOKEN_THROW:
@@ -101,24 +471,26 @@ func (p *Parser) statement() (ast.Statement, error) {
 	case token.TOKEN_VAR:
 		return p.variableStatement()
 	case token.TOKEN_WHILE:
+		markIterationLabels(labelSet)
 		return p.whileStmt()
+	case token.TOKEN_WITH:
+		if p.strict {
+			return nil, p.newSyntaxErrorf(tok, "Strict mode code may not include a with statement")
+		}
+		return p.withStmt()
 	default:
 		return p.exprStmt()
 	}
 }
 
-func (p *Parser) declaration() (ast.Statement, error) {
-	// TODO
-	return nil, nil
-}
-
 func (p *Parser) tryStmt() (ast.Statement, error) {
 	// TODO
-	return nil, nil
+	return nil, p.newSyntaxErrorf(p.current(), "try statement is not supported")
 }
 
 func (p *Parser) throwStmt() (ast.Statement, error) {
-	if _, err := p.consume(token.TOKEN_THROW); err != nil {
+	keyword, err := p.consume(token.TOKEN_THROW)
+	if err != nil {
 		return nil, err
 	}
 
@@ -129,15 +501,20 @@ func (p *Parser) throwStmt() (ast.Statement, error) {
 		return nil, err
 	}
 
-	p.skipAndConsume(token.TOKEN_SEMICOLON)
+	if err := p.semicolon(); err != nil {
+		return nil, err
+	}
 
 	return &ast.ThrowStatement{
+		Token:    *keyword,
+		EndToken: *p.last,
 		Argument: result,
 	}, nil
 }
 
 func (p *Parser) switchStmt() (ast.Statement, error) {
 	// TODO
+	return nil, p.newSyntaxErrorf(p.current(), "switch statement is not supported")
 
 	// p.consume(TOKEN_SWITCH)
 
@@ -164,104 +541,232 @@ func (p *Parser) switchStmt() (ast.Statement, error) {
 	// 	Tag:  tag,
 	// 	Body: body,
 	// }, nil
-
-	return nil, nil
 }
 
 func (p *Parser) returnStmt() (ast.Statement, error) {
+	tok := p.current()
 	p.consume(token.TOKEN_RETURN)
 
 	p.skip(token.TOKEN_SPACE, token.TOKEN_MULTI_LINE_COMMENT)
 
-	// TODO
-	cur := p.curToken
-	result, err := p.expression()
-	if err != nil {
-		return nil, err
-	} else if result == nil {
-		p.curToken = cur
+	var result ast.Expression
+	switch p.current().TokenType {
+	case token.TOKEN_NEW_LINE, token.TOKEN_SINGLE_LINE_COMMENT, token.TOKEN_SEMICOLON,
+		token.TOKEN_RIGHT_BRACE, token.TOKEN_EOF:
+	default:
+		expr, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		result = expr
 	}
 
-	p.skipAndConsume(token.TOKEN_SEMICOLON)
+	if err := p.semicolon(); err != nil {
+		return nil, err
+	}
 
 	return &ast.ReturnStatement{
-		Result: result,
+		Token:    *tok,
+		EndToken: *p.last,
+		Result:   result,
 	}, nil
 }
 
 func (p *Parser) breakStmt() (ast.Statement, error) {
 	var label ast.Expression
 
-	if _, err := p.consume(token.TOKEN_BREAK); err != nil {
+	keyword, err := p.consume(token.TOKEN_BREAK)
+	if err != nil {
 		return nil, err
 	}
 
 	p.skip(token.TOKEN_SPACE, token.TOKEN_MULTI_LINE_COMMENT)
-	if p.match(token.TOKEN_IDENTIFIER) {
-		tok := p.previous()
-		label = &ast.Identifier{Value: tok.Literal}
+	if tok := p.current(); p.isIdentifierReference(tok) {
+		p.advance()
+		if p.findLabel(tok.Literal) == nil {
+			return nil, p.newSyntaxErrorf(tok, "Undefined label '%s'", tok.Literal)
+		}
+		label = &ast.Identifier{Token: *tok, Value: tok.Literal}
 	}
 
-	p.skipAndConsume(token.TOKEN_SEMICOLON)
-
-	if p.isSyntaxError() {
-		return nil, p.err
+	if err := p.semicolon(); err != nil {
+		return nil, err
 	}
 
 	return &ast.BreakStatement{
-		Label: label,
+		Token:    *keyword,
+		EndToken: *p.last,
+		Label:    label,
 	}, nil
 }
 
-func (p *Parser) continueStmt() ast.Statement {
+func (p *Parser) continueStmt() (ast.Statement, error) {
 	var label ast.Expression
 
+	keyword := p.current()
 	p.consume(token.TOKEN_CONTINUE)
 
 	p.skip(token.TOKEN_SPACE, token.TOKEN_MULTI_LINE_COMMENT)
-	if p.match(token.TOKEN_IDENTIFIER) {
-		tok := p.previous()
-		label = &ast.Identifier{Value: tok.Literal}
+	if tok := p.current(); p.isIdentifierReference(tok) {
+		p.advance()
+		target := p.findLabel(tok.Literal)
+		if target == nil {
+			return nil, p.newSyntaxErrorf(tok, "Undefined label '%s'", tok.Literal)
+		} else if !target.loop {
+			return nil, p.newSyntaxErrorf(tok,
+				"Illegal continue statement: '%s' does not denote an iteration statement", tok.Literal)
+		}
+		label = &ast.Identifier{Token: *tok, Value: tok.Literal}
 	}
 
-	p.skipAndConsume(token.TOKEN_SEMICOLON)
+	if err := p.semicolon(); err != nil {
+		return nil, err
+	}
 
 	return &ast.ContinueStatement{
-		Label: label,
-	}
+		Token:    *keyword,
+		EndToken: *p.last,
+		Label:    label,
+	}, nil
 }
 
-func (p *Parser) forStmt() (ast.Statement, error) {
-	p.consume(token.TOKEN_FOR)
+func (p *Parser) labeledStmt(labelSet []*label) (ast.Statement, error) {
+	tok := p.current()
+	p.advance()
+	if _, err := p.skipAndConsume(token.TOKEN_COLON); err != nil {
+		return nil, err
+	}
 
-	if _, err := p.skipAndConsume(token.TOKEN_LEFT_PAREN); err != nil {
+	if p.findLabel(tok.Literal) != nil {
+		return nil, p.newSyntaxErrorf(tok, "Label '%s' has already been declared", tok.Literal)
+	}
+
+	l := &label{name: tok.Literal}
+	p.labels = append(p.labels, l)
+	p.labelSet = append(labelSet, l)
+	defer func() {
+		p.labels = p.labels[:len(p.labels)-1]
+	}()
+
+	stmt, err := p.subStatement(true)
+	if err != nil {
 		return nil, err
 	}
 
-	var init ast.Expression
-	var post ast.Expression
-	var err error
+	return &ast.LabeledStatement{
+		Label:     &ast.Identifier{Token: *tok, Value: tok.Literal},
+		Statement: stmt,
+	}, nil
+}
 
-	if !p.skipAndMatch(token.TOKEN_SEMICOLON) {
-		init, err = p.expression()
-		if err != nil {
-			return nil, err
+// subStatement parses the body of an if, a loop, a with or a labeled
+// statement, which cannot be a declaration. A plain function declaration is
+// allowed as the body of an if or a labeled statement by Annex B if annexB is
+// set, but not in a labeled statement that is the body of a loop.
+func (p *Parser) subStatement(annexB bool) (ast.Statement, error) {
+	p.skip()
+	start := p.current()
+	stmt, err := p.statement()
+	if err != nil {
+		return nil, err
+	} else if stmt == nil {
+		// A TypeScript declaration that is erased.
+		return nil, p.newSyntaxError(start)
+	}
+
+	switch s := stmt.(type) {
+	case *ast.LexicalDeclaration:
+		return nil, p.newSyntaxErrorf(&s.Token, "Lexical declaration cannot appear in a single-statement context")
+	case *ast.FunctionDeclaration:
+		if annexB && p.annexB() && !s.Async && !s.Generator {
+			return stmt, nil
+		}
+		return nil, p.functionPlacementError(&s.Token)
+	case *ast.LabeledStatement:
+		if annexB {
+			break
+		}
+		for body := ast.Statement(s); ; {
+			labeled, ok := body.(*ast.LabeledStatement)
+			if !ok {
+				break
+			}
+			body = labeled.Statement
+			if fn, ok := body.(*ast.FunctionDeclaration); ok {
+				return nil, p.functionPlacementError(&fn.Token)
+			}
 		}
+	}
+	return stmt, nil
+}
 
-		if _, err := p.skipAndConsume(token.TOKEN_SEMICOLON); err != nil {
-			return nil, err
+func (p *Parser) functionPlacementError(tok *token.Token) error {
+	switch {
+	case p.strict:
+		return p.newSyntaxErrorf(tok, "In strict mode code, functions can only be declared at top level or inside a block.")
+	case p.opts.DisableAnnexB:
+		return p.newSyntaxErrorf(tok, "Functions can only be declared at top level or inside a block.")
+	}
+	return p.newSyntaxErrorf(tok, "In non-strict mode code, functions can only be declared at top level, inside a block, or as the body of an if statement.")
+}
+
+func (p *Parser) findLabel(name string) *label {
+	for i := len(p.labels) - 1; i >= 0; i-- {
+		if p.labels[i].name == name {
+			return p.labels[i]
 		}
 	}
+	return nil
+}
+
+func markIterationLabels(labelSet []*label) {
+	for _, l := range labelSet {
+		l.loop = true
+	}
+}
+
+func (p *Parser) forStmt() (ast.Statement, error) {
+	keyword := p.advance()
+
+	await := p.inAsync && p.skipAndMatch(token.TOKEN_AWAIT)
+
+	if _, err := p.skipAndConsume(token.TOKEN_LEFT_PAREN); err != nil {
+		return nil, err
+	}
 
-	cond, err := p.expression()
+	init, err := p.forInit()
 	if err != nil {
 		return nil, err
 	}
 
+	if p.skipAndMatch(token.TOKEN_IN, token.TOKEN_OF) {
+		return p.forInOfStmt(keyword, init, p.previous(), await)
+	} else if await {
+		return nil, p.newSyntaxError(p.current())
+	}
+
+	if init != nil {
+		if err := p.checkInitializers(init); err != nil {
+			return nil, err
+		}
+	}
 	if _, err := p.skipAndConsume(token.TOKEN_SEMICOLON); err != nil {
 		return nil, err
 	}
 
+	var cond ast.Expression
+	if !p.skipAndMatch(token.TOKEN_SEMICOLON) {
+		cond, err = p.expression()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := p.skipAndConsume(token.TOKEN_SEMICOLON); err != nil {
+			return nil, err
+		}
+	}
+
+	var post ast.Expression
 	if !p.skipAndMatch(token.TOKEN_RIGHT_PAREN) {
 		post, err = p.expression()
 		if err != nil {
@@ -273,12 +778,13 @@ func (p *Parser) forStmt() (ast.Statement, error) {
 		}
 	}
 
-	body, err := p.statement()
+	body, err := p.subStatement(false)
 	if err != nil {
 		return nil, err
 	}
 
 	return &ast.ForStatement{
+		Token:     *keyword,
 		Init:      init,
 		Condition: cond,
 		Update:    post,
@@ -286,436 +792,573 @@ func (p *Parser) forStmt() (ast.Statement, error) {
 	}, nil
 }
 
-func (p *Parser) whileStmt() (ast.Statement, error) {
-	p.consume(token.TOKEN_WHILE)
-
-	if _, err := p.skipAndConsume(token.TOKEN_LEFT_PAREN); err != nil {
-		return nil, err
-	}
-
-	expr, err := p.expression()
-	if err != nil {
-		return nil, err
-	}
-
-	if _, err = p.skipAndConsume(token.TOKEN_RIGHT_PAREN); err != nil {
-		return nil, err
-	}
+// forInit parses the part of a for statement before the first semicolon or
+// the in/of keyword, where the in operator is not allowed.
+func (p *Parser) forInit() (ast.Statement, error) {
+	noIn := p.noIn
+	p.noIn = true
+	defer func() {
+		p.noIn = noIn
+	}()
 
-	body, err := p.statement()
-	if err != nil {
-		return nil, err
+	p.skip()
+	switch tok := p.current(); tok.TokenType {
+	case token.TOKEN_SEMICOLON:
+		return nil, nil
+	case token.TOKEN_VAR:
+		p.advance()
+		decls, err := p.bindingList(false, false)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.VarStatement{Token: *tok, EndToken: *p.last, Declarations: decls}, nil
+	case token.TOKEN_LET, token.TOKEN_CONST:
+		if tok.TokenType == token.TOKEN_LET {
+			if isDecl, err := p.isLetDeclaration(); err != nil {
+				return nil, err
+			} else if !isDecl {
+				break
+			}
+		}
+		if err := p.require(tok, 2015, "Lexical declaration"); err != nil {
+			return nil, err
+		}
+		p.advance()
+		isConst := tok.TokenType == token.TOKEN_CONST
+		decls, err := p.bindingList(false, isConst)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.LexicalDeclaration{Token: *tok, EndToken: *p.last, Const: isConst, Declarations: decls}, nil
 	}
 
-	return &ast.WhileStatement{
-		Condition: expr,
-		Body:      body,
-	}, nil
-}
-
-func (p *Parser) doWhileStmt() (ast.Statement, error) {
-	p.consume(token.TOKEN_DO)
-
-	body, err := p.statement()
+	expr, err := p.expression()
 	if err != nil {
 		return nil, err
+	} else if expr == nil {
+		return nil, p.newSyntaxError(p.current())
 	}
+	return expr, nil
+}
 
-	if _, err := p.skipAndConsume(token.TOKEN_WHILE); err != nil {
-		return nil, err
+func (p *Parser) forInOfStmt(keyword *token.Token, init ast.Statement, op *token.Token, await bool) (ast.Statement, error) {
+	isOf := op.TokenType == token.TOKEN_OF
+	if await && !isOf {
+		return nil, p.newSyntaxError(op)
+	} else if await {
+		if err := p.require(keyword, 2018, "for-await-of loop"); err != nil {
+			return nil, err
+		}
+	} else if isOf {
+		if err := p.require(op, 2015, "for-of loop"); err != nil {
+			return nil, err
+		}
 	}
-	if _, err = p.skipAndConsume(token.TOKEN_LEFT_PAREN); err != nil {
-		return nil, err
+
+	kind := "in"
+	if isOf {
+		kind = "of"
 	}
 
-	expr, err := p.expression()
+	var left ast.Node
+	switch init := init.(type) {
+	case *ast.VarStatement, *ast.LexicalDeclaration:
+		var decls []ast.Declaration
+		if stmt, ok := init.(*ast.VarStatement); ok {
+			decls = stmt.Declarations
+		} else {
+			decls = init.(*ast.LexicalDeclaration).Declarations
+		}
+		if len(decls) != 1 {
+			return nil, p.newSyntaxErrorf(op, "Invalid left-hand side in for-%s loop: Must have a single binding.", kind)
+		} else if decls[0].(*ast.VariableDeclaration).Value != nil {
+			return nil, p.newSyntaxErrorf(op, "for-%s loop variable declaration may not have an initializer.", kind)
+		}
+		left = init
+	case nil:
+		return nil, p.newSyntaxError(op)
+	default:
+		target, ok := p.toAssignmentTarget(init)
+		if !ok {
+			return nil, p.newSyntaxErrorf(op, "Invalid left-hand side in for-%s loop", kind)
+		} else if err := p.checkStrictTarget(target); err != nil {
+			return nil, err
+		} else if err := p.requireDestructuring(op, target); err != nil {
+			return nil, err
+		}
+		left = target
+	}
+
+	var right ast.Expression
+	var err error
+	if isOf {
+		right, err = p.assignmentExpr()
+	} else {
+		right, err = p.expression()
+	}
 	if err != nil {
 		return nil, err
+	} else if right == nil {
+		return nil, p.newSyntaxError(p.current())
 	}
 
-	if _, err = p.skipAndConsume(token.TOKEN_RIGHT_PAREN); err != nil {
+	if _, err := p.skipAndConsume(token.TOKEN_RIGHT_PAREN); err != nil {
 		return nil, err
 	}
 
-	p.skipAndConsume(token.TOKEN_SEMICOLON)
+	body, err := p.subStatement(false)
+	if err != nil {
+		return nil, err
+	}
 
-	return &ast.DoWhileStatement{
-		Body:      body,
-		Condition: expr,
+	if isOf {
+		return &ast.ForOfStatement{
+			Token: *keyword,
+			Left:  left,
+			Right: right,
+			Body:  body,
+			Await: await,
+		}, nil
+	}
+	return &ast.ForInStatement{
+		Token: *keyword,
+		Left:  left,
+		Right: right,
+		Body:  body,
 	}, nil
 }
 
-func (p *Parser) ifStat() (ast.Statement, error) {
-	p.consume(token.TOKEN_IF)
+func (p *Parser) withStmt() (ast.Statement, error) {
+	keyword := p.advance()
 
 	if _, err := p.skipAndConsume(token.TOKEN_LEFT_PAREN); err != nil {
 		return nil, err
 	}
-	expr, err := p.expression()
+
+	obj, err := p.expression()
 	if err != nil {
 		return nil, err
+	} else if obj == nil {
+		return nil, p.newSyntaxError(p.current())
 	}
+
 	if _, err := p.skipAndConsume(token.TOKEN_RIGHT_PAREN); err != nil {
 		return nil, err
 	}
 
-	thenStmt, err := p.statement()
+	body, err := p.subStatement(false)
 	if err != nil {
 		return nil, err
 	}
 
-	var elseStmt ast.Statement
-	if p.skipAndMatch(token.TOKEN_ELSE) {
-		elseStmt, err = p.statement()
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	return &ast.IfStatement{
-		Condition:   expr,
-		TrueBranch:  thenStmt,
-		FalseBranch: elseStmt,
+	return &ast.WithStatement{
+		Token:  *keyword,
+		Object: obj,
+		Body:   body,
 	}, nil
 }
 
-func (p *Parser) initializer() (ast.Expression, error) {
-	if p.skipAndMatch(token.TOKEN_EQUAL) {
-		return p.assignmentExpr()
-	}
-
-	return nil, nil
-}
+func (p *Parser) whileStmt() (ast.Statement, error) {
+	keyword := p.advance()
 
-func (p *Parser) variableDeclaration() (ast.Declaration, error) {
-	name := p.previous()
-	decl := &ast.VariableDeclaration{
-		Name: &ast.Identifier{
-			Value: name.Literal,
-		},
+	expr, err := p.condition()
+	if err != nil {
+		return nil, err
 	}
-	initializer, err := p.initializer()
+
+	body, err := p.subStatement(false)
 	if err != nil {
 		return nil, err
 	}
-	decl.Value = initializer
-	return decl, nil
+
+	return &ast.WhileStatement{
+		Token:     *keyword,
+		Condition: expr,
+		Body:      body,
+	}, nil
 }
 
-func (p *Parser) variableStatement() (ast.Statement, error) {
-	p.consume(token.TOKEN_VAR)
-	decls := make([]ast.Declaration, 0)
+func (p *Parser) doWhileStmt() (ast.Statement, error) {
+	keyword := p.advance()
 
-	for p.skipAndMatch(token.TOKEN_IDENTIFIER) {
-		decl, err := p.variableDeclaration()
-		if err != nil {
-			return nil, err
-		}
+	body, err := p.subStatement(false)
+	if err != nil {
+		return nil, err
+	}
 
-		decls = append(decls, decl)
-		if !p.skipAndMatch(token.TOKEN_COMMA) {
-			break
-		}
+	if _, err := p.skipAndConsume(token.TOKEN_WHILE); err != nil {
+		return nil, err
 	}
 
-	if len(decls) == 0 {
-		return nil, p.newSyntaxError(p.curToken)
+	expr, err := p.condition()
+	if err != nil {
+		return nil, err
 	}
 
-	p.skipAndConsume(token.TOKEN_SEMICOLON)
+	// The semicolon after a do-while statement may always be omitted.
+	p.skip(token.TOKEN_SPACE, token.TOKEN_MULTI_LINE_COMMENT)
+	p.match(token.TOKEN_SEMICOLON)
 
-	return &ast.VarStatement{
-		Declarations: decls,
+	return &ast.DoWhileStatement{
+		Token:     *keyword,
+		EndToken:  *p.last,
+		Body:      body,
+		Condition: expr,
 	}, nil
 }
 
-func (p *Parser) blockStmt() (ast.Statement, error) {
-	var stmt ast.Statement
-	var err error
+func (p *Parser) ifStat() (ast.Statement, error) {
+	keyword := p.advance()
 
-	p.consume(token.TOKEN_LEFT_BRACE)
-	list := make([]ast.Statement, 0)
+	expr, err := p.condition()
+	if err != nil {
+		return nil, err
+	}
 
-	for !p.skipAndMatch(token.TOKEN_RIGHT_BRACE) {
-		stmt, err = p.statement()
+	thenStmt, err := p.subStatement(true)
+	if err != nil {
+		return nil, err
+	}
+
+	var elseStmt ast.Statement
+	if p.skipAndMatch(token.TOKEN_ELSE) {
+		elseStmt, err = p.subStatement(true)
 		if err != nil {
 			return nil, err
 		}
-		list = append(list, stmt)
 	}
 
-	return &ast.BlockStatement{StatementList: list}, nil
+	return &ast.IfStatement{
+		Token:       *keyword,
+		Condition:   expr,
+		TrueBranch:  thenStmt,
+		FalseBranch: elseStmt,
+	}, nil
 }
 
-func (p *Parser) exprStmt() (ast.Statement, error) {
+// condition parses the parenthesized condition of an if, while or do-while
+// statement.
+func (p *Parser) condition() (ast.Expression, error) {
+	if _, err := p.skipAndConsume(token.TOKEN_LEFT_PAREN); err != nil {
+		return nil, err
+	}
+
+	p.skip()
+	start := p.current()
 	expr, err := p.expression()
+	if err == nil && expr == nil {
+		err = p.newSyntaxError(start)
+	} else if err == nil && p.tolerant {
+		if p.skip(); p.current().TokenType != token.TOKEN_RIGHT_PAREN {
+			err = p.newSyntaxError(p.current())
+		}
+	}
 	if err != nil {
-		return nil, err
+		if expr, err = p.recoverExpression(start, err, token.TOKEN_RIGHT_PAREN); err != nil {
+			return nil, err
+		}
 	}
 
-	p.skipAndConsume(token.TOKEN_SEMICOLON)
+	if _, err := p.skipAndConsume(token.TOKEN_RIGHT_PAREN); err != nil {
+		return nil, err
+	}
 
-	return &ast.ExpressionStatement{Expression: expr}, nil
+	return expr, nil
 }
 
-func (p *Parser) expression() (ast.Expression, error) {
-	// TODO: comma operation
-	return p.assignmentExpr()
-}
+func (p *Parser) initializer() (ast.Expression, error) {
+	if !p.skipAndMatch(token.TOKEN_EQUAL) {
+		return nil, nil
+	}
 
-func (p *Parser) assignmentExpr() (ast.Expression, error) {
-	expr, err := p.conditionalExpr()
+	p.skip()
+	start := p.current()
+	expr, err := p.assignmentExpr()
+	if err == nil && expr == nil {
+		err = p.newSyntaxError(start)
+	}
 	if err != nil {
-		return nil, err
+		return p.recoverExpression(start, err, token.TOKEN_COMMA, token.TOKEN_SEMICOLON, token.TOKEN_NEW_LINE)
 	}
-
 	return expr, nil
 }
 
-func (p *Parser) conditionalExpr() (ast.Expression, error) {
-	expr, err := p.shortCircuitExpr()
+func (p *Parser) variableDeclaration() (ast.Declaration, error) {
+	name, err := p.bindingTarget()
 	if err != nil {
 		return nil, err
 	}
-
-	if p.skipAndMatch(token.TOKEN_QUESTION) {
-		trueExpr, err := p.assignmentExpr()
-		if err != nil {
+	if p.opts.TypeScript {
+		// A definite assignment assertion, as in let x!: number.
+		p.skip(token.TOKEN_SPACE, token.TOKEN_MULTI_LINE_COMMENT)
+		p.match(token.TOKEN_BANG)
+		if err := p.tsTypeAnnotation(); err != nil {
 			return nil, err
 		}
+	}
 
-		_, err = p.skipAndConsume(token.TOKEN_COLON)
+	initializer, err := p.initializer()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.VariableDeclaration{
+		Name:  name,
+		Value: initializer,
+	}, nil
+}
+
+// bindingList parses the comma separated declarations of a variable statement
+// or a lexical declaration. The initializers are checked by the caller unless
+// checkInit is set.
+func (p *Parser) bindingList(checkInit, isConst bool) ([]ast.Declaration, error) {
+	decls := make([]ast.Declaration, 0)
+
+	for {
+		decl, err := p.variableDeclaration()
 		if err != nil {
 			return nil, err
 		}
+		decls = append(decls, decl)
 
-		falseExpr, err := p.assignmentExpr()
-		if err != nil {
-			return nil, err
+		if !p.skipAndMatch(token.TOKEN_COMMA) {
+			break
 		}
+	}
 
-		return &ast.TernaryExpression{
-			Condition:   expr,
-			TrueBranch:  trueExpr,
-			FalseBranch: falseExpr,
-		}, nil
+	if checkInit {
+		var stmt ast.Statement = &ast.VarStatement{Declarations: decls}
+		if isConst {
+			stmt = &ast.LexicalDeclaration{Const: true, Declarations: decls}
+		}
+		if err := p.checkInitializers(stmt); err != nil {
+			return nil, err
+		}
 	}
 
-	return expr, nil
+	return decls, nil
 }
 
-func (p *Parser) shortCircuitExpr() (ast.Expression, error) {
-	// TODO: CoalesceExpression
-	return p.logicalOrExpr()
-}
+func (p *Parser) checkInitializers(stmt ast.Statement) error {
+	var decls []ast.Declaration
+	isConst := false
 
-func (p *Parser) logicalOrExpr() (ast.Expression, error) {
-	expr, err := p.logicalAndExpr()
-	if err != nil {
-		return nil, err
+	switch stmt := stmt.(type) {
+	case *ast.VarStatement:
+		decls = stmt.Declarations
+	case *ast.LexicalDeclaration:
+		decls = stmt.Declarations
+		isConst = stmt.Const
+	default:
+		return nil
 	}
 
-	if p.skipAndMatch(token.TOKEN_PIPE_PIPE) {
-		op := p.previous()
-		right, err := p.logicalAndExpr()
-		if err != nil {
-			return nil, err
+	for _, decl := range decls {
+		decl := decl.(*ast.VariableDeclaration)
+		if decl.Value != nil {
+			continue
+		}
+		if isConst {
+			return p.newSyntaxErrorf(p.current(), "Missing initializer in const declaration")
+		} else if _, ok := decl.Name.(*ast.Identifier); !ok {
+			return p.newSyntaxErrorf(p.current(), "Missing initializer in destructuring declaration")
 		}
-		return &ast.BinaryExpression{
-			Operator: op,
-			Left:     expr,
-			Right:    right,
-		}, nil
 	}
 
-	return expr, nil
+	return nil
 }
 
-func (p *Parser) logicalAndExpr() (ast.Expression, error) {
-	expr, err := p.bitwiseOrExpr()
+func (p *Parser) variableStatement() (ast.Statement, error) {
+	keyword := p.advance()
+
+	decls, err := p.bindingList(true, false)
 	if err != nil {
 		return nil, err
 	}
 
-	if p.skipAndMatch(token.TOKEN_AND_AND) {
-		op := p.previous()
-		right, err := p.bitwiseOrExpr()
-		if err != nil {
-			return nil, err
-		}
-		return &ast.BinaryExpression{
-			Operator: op,
-			Left:     expr,
-			Right:    right,
-		}, nil
+	if err := p.semicolon(); err != nil {
+		return nil, err
 	}
 
-	return expr, nil
+	return &ast.VarStatement{
+		Token:        *keyword,
+		EndToken:     *p.last,
+		Declarations: decls,
+	}, nil
 }
 
-func (p *Parser) bitwiseOrExpr() (ast.Expression, error) {
-	expr, err := p.bitwiseXorExpr()
+func (p *Parser) lexicalDeclaration() (ast.Statement, error) {
+	keyword := p.advance()
+	isConst := keyword.TokenType == token.TOKEN_CONST
+	if err := p.require(keyword, 2015, "Lexical declaration"); err != nil {
+		return nil, err
+	}
+
+	decls, err := p.bindingList(true, isConst)
 	if err != nil {
 		return nil, err
 	}
 
-	if p.skipAndMatch(token.TOKEN_PIPE) {
-		op := p.previous()
-		right, err := p.bitwiseXorExpr()
-		if err != nil {
-			return nil, err
-		}
-		return &ast.BinaryExpression{
-			Operator: op,
-			Left:     expr,
-			Right:    right,
-		}, nil
+	if err := p.semicolon(); err != nil {
+		return nil, err
 	}
 
-	return expr, nil
+	return &ast.LexicalDeclaration{
+		Token:        *keyword,
+		EndToken:     *p.last,
+		Const:        isConst,
+		Declarations: decls,
+	}, nil
 }
 
-func (p *Parser) bitwiseXorExpr() (ast.Expression, error) {
-	expr, err := p.bitwiseAndExpr()
+// isLetDeclaration reports whether the current let token starts a lexical
+// declaration rather than being an identifier.
+func (p *Parser) isLetDeclaration() (bool, error) {
+	next, _, err := p.peekSignificant()
 	if err != nil {
-		return nil, err
+		return false, err
 	}
 
-	if p.skipAndMatch(token.TOKEN_HAT) {
-		op := p.previous()
-		right, err := p.bitwiseAndExpr()
-		if err != nil {
-			return nil, err
-		}
-		return &ast.BinaryExpression{
-			Operator: op,
-			Left:     expr,
-			Right:    right,
-		}, nil
+	switch next.TokenType {
+	case token.TOKEN_LEFT_BRACKET, token.TOKEN_LEFT_BRACE:
+		return true, nil
 	}
-
-	return expr, nil
+	return p.isIdentifierReference(next), nil
 }
 
-func (p *Parser) bitwiseAndExpr() (ast.Expression, error) {
-	expr, err := p.equalityExpr()
+func (p *Parser) blockStmt() (ast.Statement, error) {
+	var stmt ast.Statement
+	var err error
+
+	lbrace, err := p.consume(token.TOKEN_LEFT_BRACE)
 	if err != nil {
 		return nil, err
 	}
+	list := make([]ast.Statement, 0)
 
-	if p.skipAndMatch(token.TOKEN_AND) {
-		op := p.previous()
-		right, err := p.equalityExpr()
+	for !p.skipAndMatch(token.TOKEN_RIGHT_BRACE) {
+		start := p.current()
+		stmt, err = p.statement()
+		if err == nil && p.current() == start {
+			err = p.newSyntaxError(start)
+		}
 		if err != nil {
-			return nil, err
+			if stmt, err = p.recoverStatement(start, err); err != nil {
+				return nil, err
+			}
+		}
+		if stmt != nil {
+			list = append(list, stmt)
 		}
-		return &ast.BinaryExpression{
-			Operator: op,
-			Left:     expr,
-			Right:    right,
-		}, nil
 	}
 
-	return expr, nil
+	return &ast.BlockStatement{
+		LBrace:        *lbrace,
+		RBrace:        *p.previous(),
+		StatementList: list,
+	}, nil
 }
 
-func (p *Parser) equalityExpr() (ast.Expression, error) {
-	expr, err := p.relationalExpr()
+func (p *Parser) exprStmt() (ast.Statement, error) {
+	p.skip()
+	start := p.current()
+	expr, err := p.expression()
 	if err != nil {
 		return nil, err
+	} else if expr == nil {
+		return nil, p.newSyntaxError(p.current())
 	}
 
-	if p.skipAndMatch(
-		token.TOKEN_EQUAL_EQUAL,
-		token.TOKEN_BANG_EQUAL,
-		token.TOKEN_EQUAL_EQUAL_EQUAL,
-		token.TOKEN_BANG_EQUAL_EQUAL,
-	) {
-		op := p.previous()
-		right, err := p.relationalExpr()
-		if err != nil {
-			return nil, err
-		}
-		return &ast.BinaryExpression{
-			Operator: op,
-			Left:     expr,
-			Right:    right,
-		}, nil
+	if err := p.semicolon(); err != nil {
+		return nil, err
 	}
 
-	return expr, nil
+	return &ast.ExpressionStatement{Token: *start, EndToken: *p.last, Expression: expr}, nil
 }
 
-func (p *Parser) relationalExpr() (ast.Expression, error) {
-	expr, err := p.shiftExpr()
-	if err != nil {
-		return nil, err
+func (p *Parser) expression() (ast.Expression, error) {
+	expr, err := p.assignmentExpr()
+	if err != nil || expr == nil || !p.skipAndMatch(token.TOKEN_COMMA) {
+		return expr, err
 	}
 
-	if p.skipAndMatch(
-		token.TOKEN_LESS,
-		token.TOKEN_GREATER,
-		token.TOKEN_LESS_EQUAL,
-		token.TOKEN_GREATER_EQUAL,
-		token.TOKEN_INSTANCEOF,
-		token.TOKEN_IN,
-	) {
-		op := p.previous()
-		right, err := p.shiftExpr()
+	seq := &ast.SequenceExpression{Expressions: []ast.Expression{expr}}
+	for {
+		expr, err := p.assignmentExpr()
 		if err != nil {
 			return nil, err
+		} else if expr == nil {
+			return nil, p.newSyntaxError(p.current())
 		}
-		return &ast.BinaryExpression{
-			Operator: op,
-			Left:     expr,
-			Right:    right,
-		}, nil
-	}
+		seq.Expressions = append(seq.Expressions, expr)
 
-	return expr, nil
+		if !p.skipAndMatch(token.TOKEN_COMMA) {
+			return seq, nil
+		}
+	}
 }
 
-func (p *Parser) shiftExpr() (ast.Expression, error) {
-	expr, err := p.additiveExpr()
-	if err != nil {
-		return nil, err
+func (p *Parser) assignmentExpr() (ast.Expression, error) {
+	if p.inGenerator && p.skipAndMatch(token.TOKEN_YIELD) {
+		return p.yieldExpr()
+	}
+
+	expr, err := p.conditionalExpr()
+	if err != nil || expr == nil {
+		return expr, err
 	}
 
 	if p.skipAndMatch(
-		token.TOKEN_LESS_LESS,
-		token.TOKEN_GREATER_GREATER,
-		token.TOKEN_GREATER_GREATER_GREATER,
+		token.TOKEN_EQUAL,
+		token.TOKEN_PLUS_EQUAL,
+		token.TOKEN_MINUS_EQUAL,
+		token.TOKEN_STAR_EQUAL,
+		token.TOKEN_SLASH_EQUAL,
+		token.TOKEN_PERCENT_EQUAL,
+		token.TOKEN_STAR_STAR_EQUAL,
+		token.TOKEN_LESS_LESS_EQUAL,
+		token.TOKEN_GREATER_GREATER_EQUAL,
+		token.TOKEN_GREATER_GREATER_GREATER_EQUAL,
+		token.TOKEN_AND_EQUAL,
+		token.TOKEN_PIPE_EQUAL,
+		token.TOKEN_HAT_EQUAL,
+		token.TOKEN_AND_AND_EQUAL,
+		token.TOKEN_PIPE_PIPE_EQUAL,
+		token.TOKEN_QUESTION_QUESTION_EQUAL,
 	) {
 		op := p.previous()
-		right, err := p.additiveExpr()
-		if err != nil {
+		if err := p.requireOperator(op); err != nil {
 			return nil, err
 		}
-		return &ast.BinaryExpression{
-			Operator: op,
-			Left:     expr,
-			Right:    right,
-		}, nil
-	}
 
-	return expr, nil
-}
-
-func (p *Parser) additiveExpr() (ast.Expression, error) {
-	expr, err := p.multiplicativeExpr()
-	if err != nil {
-		return nil, err
-	}
+		target, ok := expr, false
+		if op.TokenType == token.TOKEN_EQUAL {
+			target, ok = p.toAssignmentTarget(expr)
+			if err := p.requireDestructuring(op, target); err != nil {
+				return nil, err
+			}
+		} else {
+			switch expr.(type) {
+			case *ast.Identifier, *ast.MemberExpression:
+				ok = true
+			}
+		}
+		if !ok {
+			return nil, p.newSyntaxErrorf(op, "Invalid left-hand side in assignment")
+		} else if err := p.checkStrictTarget(target); err != nil {
+			return nil, err
+		}
 
-	if p.skipAndMatch(token.TOKEN_PLUS, token.TOKEN_MINUS) {
-		op := p.previous()
-		right, err := p.multiplicativeExpr()
+		right, err := p.assignmentExpr()
 		if err != nil {
 			return nil, err
+		} else if right == nil {
+			return nil, p.newSyntaxError(p.current())
 		}
-		return &ast.BinaryExpression{
+
+		return &ast.AssignmentExpression{
 			Operator: op,
-			Left:     expr,
+			Left:     target,
 			Right:    right,
 		}, nil
 	}
@@ -723,51 +1366,148 @@ func (p *Parser) additiveExpr() (ast.Expression, error) {
 	return expr, nil
 }
 
-func (p *Parser) multiplicativeExpr() (ast.Expression, error) {
-	expr, err := p.exponentiationExpr()
-	if err != nil {
-		return nil, err
+// toAssignmentTarget converts the left-hand side of an assignment to a target,
+// turning array and object literals into destructuring patterns.
+func (p *Parser) toAssignmentTarget(expr ast.Expression) (ast.Expression, bool) {
+	switch expr := expr.(type) {
+	case *ast.Identifier, *ast.MemberExpression, *ast.ArrayPattern, *ast.ObjectPattern:
+		return expr, true
+	case *ast.ArrayLiteral:
+		elems := make([]ast.Expression, 0, len(expr.ElementList))
+		for i, elem := range expr.ElementList {
+			switch elem := elem.(type) {
+			case *ast.Elision:
+				elems = append(elems, elem)
+			case *ast.SpreadElement:
+				target, ok := p.toAssignmentTarget(elem.Value)
+				if !ok || i != len(expr.ElementList)-1 {
+					return nil, false
+				}
+				elems = append(elems, &ast.RestElement{Token: elem.Token, Argument: target})
+			default:
+				target, ok := p.toAssignmentElement(elem)
+				if !ok {
+					return nil, false
+				}
+				elems = append(elems, target)
+			}
+		}
+		return &ast.ArrayPattern{
+			LBracket: expr.LBracket,
+			RBracket: expr.RBracket,
+			Elements: elems,
+		}, true
+	case *ast.ObjectLiteral:
+		props := make([]ast.Expression, 0, len(expr.Properties))
+		for i, prop := range expr.Properties {
+			switch prop := prop.(type) {
+			case *ast.SpreadElement:
+				target, ok := p.toAssignmentTarget(prop.Value)
+				if !ok || i != len(expr.Properties)-1 {
+					return nil, false
+				}
+				props = append(props, &ast.RestElement{Token: prop.Token, Argument: target})
+			case *ast.Property:
+				if prop.Method || prop.Kind != ast.PropertyInit {
+					return nil, false
+				}
+				target, ok := p.toAssignmentElement(prop.Value)
+				if !ok {
+					return nil, false
+				}
+				props = append(props, &ast.Property{
+					Token:     prop.Token,
+					Key:       prop.Key,
+					Value:     target,
+					Computed:  prop.Computed,
+					Shorthand: prop.Shorthand,
+				})
+			}
+		}
+		return &ast.ObjectPattern{
+			LBrace:     expr.LBrace,
+			RBrace:     expr.RBrace,
+			Properties: props,
+		}, true
 	}
 
-	if p.skipAndMatch(token.TOKEN_STAR, token.TOKEN_SLASH, token.TOKEN_PERCENT) {
-		op := p.previous()
-		right, err := p.exponentiationExpr()
-		if err != nil {
-			return nil, err
+	return nil, false
+}
+
+// checkStrictTarget checks the identifiers assigned by an assignment target in
+// strict mode code.
+func (p *Parser) checkStrictTarget(target ast.Expression) error {
+	for _, id := range boundNames(target) {
+		if err := p.checkStrictBinding(id); err != nil {
+			return err
 		}
-		return &ast.BinaryExpression{
-			Operator: op,
-			Left:     expr,
-			Right:    right,
-		}, nil
 	}
+	return nil
+}
 
-	return expr, nil
+func (p *Parser) toAssignmentElement(expr ast.Expression) (ast.Expression, bool) {
+	switch expr := expr.(type) {
+	case *ast.AssignmentExpression:
+		if expr.Operator.TokenType != token.TOKEN_EQUAL {
+			return nil, false
+		}
+		return &ast.AssignmentPattern{Left: expr.Left, Right: expr.Right}, true
+	case *ast.AssignmentPattern:
+		return expr, true
+	}
+
+	return p.toAssignmentTarget(expr)
 }
 
-func (p *Parser) exponentiationExpr() (ast.Expression, error) {
-	expr, err := p.updateExpr()
-	if err != nil {
-		return nil, err
+func (p *Parser) conditionalExpr() (ast.Expression, error) {
+	expr, err := p.shortCircuitExpr()
+	if err != nil || expr == nil {
+		return expr, err
 	}
 
-	if p.skipAndMatch(token.TOKEN_STAR_STAR) {
-		op := p.previous()
-		right, err := p.unaryExpr()
+	if p.skipAndMatch(token.TOKEN_QUESTION) {
+		noIn := p.noIn
+		p.noIn = false
+		trueExpr, err := p.assignmentExpr()
+		p.noIn = noIn
 		if err != nil {
 			return nil, err
+		} else if trueExpr == nil {
+			return nil, p.newSyntaxError(p.current())
 		}
-		return &ast.BinaryExpression{
-			Operator: op,
-			Left:     expr,
-			Right:    right,
+
+		_, err = p.skipAndConsume(token.TOKEN_COLON)
+		if err != nil {
+			return nil, err
+		}
+
+		falseExpr, err := p.assignmentExpr()
+		if err != nil {
+			return nil, err
+		} else if falseExpr == nil {
+			return nil, p.newSyntaxError(p.current())
+		}
+
+		return &ast.TernaryExpression{
+			Condition:   expr,
+			TrueBranch:  trueExpr,
+			FalseBranch: falseExpr,
 		}, nil
 	}
 
 	return expr, nil
 }
 
+func (p *Parser) shortCircuitExpr() (ast.Expression, error) {
+	expr, _, err := p.binaryExpr(precLogicalOr)
+	return expr, err
+}
+
 func (p *Parser) unaryExpr() (ast.Expression, error) {
+	if p.inAsync && p.skipAndMatch(token.TOKEN_AWAIT) {
+		return p.awaitExpr()
+	}
+
 	if p.skipAndMatch(token.TOKEN_DELETE,
 		token.TOKEN_VOID,
 		token.TOKEN_TYPEOF,
@@ -780,6 +1520,11 @@ func (p *Parser) unaryExpr() (ast.Expression, error) {
 		expr, err := p.unaryExpr()
 		if err != nil {
 			return nil, err
+		} else if expr == nil {
+			return nil, p.newSyntaxError(p.current())
+		}
+		if _, ok := expr.(*ast.Identifier); ok && p.strict && op.TokenType == token.TOKEN_DELETE {
+			return nil, p.newSyntaxErrorf(op, "Delete of an unqualified identifier in strict mode.")
 		}
 		return &ast.UnaryExpression{
 			Operator: op,
@@ -791,40 +1536,88 @@ func (p *Parser) unaryExpr() (ast.Expression, error) {
 }
 
 func (p *Parser) updateExpr() (ast.Expression, error) {
-	if p.skipAndMatch(token.TOKEN_PLUS_PLUS, token.TOKEN_MINUS) {
+	if p.skipAndMatch(token.TOKEN_PLUS_PLUS, token.TOKEN_MINUS_MINUS) {
 		op := p.previous()
 		expr, err := p.unaryExpr()
 		if err != nil {
 			return nil, err
+		} else if expr == nil {
+			return nil, p.newSyntaxError(p.current())
+		}
+		if err := p.checkStrictTarget(expr); err != nil {
+			return nil, err
 		}
-		return &ast.UnaryExpression{Operator: op, Value: expr}, nil
+		return &ast.UpdateExpression{Operator: op, Argument: expr, Prefix: true}, nil
 	}
 
 	expr, err := p.leftHandSideExpr()
-	if err != nil {
-		return nil, err
+	if err != nil || expr == nil {
+		return expr, err
 	}
+	// No line terminator is allowed before the operator of a postfix update.
 	p.skip(token.TOKEN_SPACE, token.TOKEN_MULTI_LINE_COMMENT)
-	if p.match(token.TOKEN_PLUS_PLUS, token.TOKEN_MINUS) {
+	if !p.newLine && p.match(token.TOKEN_PLUS_PLUS, token.TOKEN_MINUS_MINUS) {
 		op := p.previous()
-		return &ast.UnaryExpression{Operator: op, Value: expr}, nil
+		if err := p.checkStrictTarget(expr); err != nil {
+			return nil, err
+		}
+		return &ast.UpdateExpression{Operator: op, Argument: expr}, nil
 	}
 	return expr, nil
 }
 
 func (p *Parser) leftHandSideExpr() (ast.Expression, error) {
-	// TODO: call and optional expression
-	return p.newExpr()
+	// TODO: optional expression
+	expr, err := p.newExpr()
+	if err != nil || expr == nil {
+		return expr, err
+	}
+
+	for {
+		if _, err := p.tsCallTypeArguments(); err != nil {
+			return nil, err
+		}
+		if p.skipAndMatch(token.TOKEN_LEFT_PAREN) {
+			args, err := p.arguments()
+			if err != nil {
+				return nil, err
+			}
+			expr = &ast.CallExpression{Callee: expr, Arguments: args, RParen: *p.previous()}
+			continue
+		}
+
+		member, err := p.memberAccess(expr)
+		if err != nil {
+			return nil, err
+		} else if member == nil {
+			return expr, nil
+		}
+		expr = member
+	}
 }
 
 func (p *Parser) newExpr() (ast.Expression, error) {
 	if p.skipAndMatch(token.TOKEN_NEW) {
-		op := p.previous()
-		expr, err := p.newExpr()
+		tok := p.previous()
+		callee, err := p.newExpr()
 		if err != nil {
 			return nil, err
+		} else if callee == nil {
+			return nil, p.newSyntaxError(p.current())
+		} else if _, ok := callee.(*ast.ImportExpression); ok {
+			return nil, p.newSyntaxErrorf(tok, "Cannot use new with import")
+		}
+
+		var args []ast.Expression
+		var rparen *token.Token
+		if p.skipAndMatch(token.TOKEN_LEFT_PAREN) {
+			args, err = p.arguments()
+			if err != nil {
+				return nil, err
+			}
+			rparen = p.previous()
 		}
-		return &ast.UnaryExpression{Operator: op, Value: expr}, nil
+		return &ast.NewExpression{Token: *tok, RParen: rparen, Callee: callee, Arguments: args}, nil
 	}
 
 	return p.memberExpr()
@@ -832,14 +1625,108 @@ func (p *Parser) newExpr() (ast.Expression, error) {
 
 func (p *Parser) memberExpr() (ast.Expression, error) {
 	expr, err := p.primaryExpr()
-	if err != nil {
-		return nil, err
+	if err != nil || expr == nil {
+		return expr, err
 	}
 
-	return expr, nil
+	for {
+		if _, err := p.tsCallTypeArguments(); err != nil {
+			return nil, err
+		}
+		member, err := p.memberAccess(expr)
+		if err != nil {
+			return nil, err
+		} else if member == nil {
+			return expr, nil
+		}
+		expr = member
+	}
+}
+
+func (p *Parser) memberAccess(object ast.Expression) (ast.Expression, error) {
+	if p.opts.TypeScript {
+		// A non-null assertion, as in x!.y, which must be on the same line.
+		p.skip(token.TOKEN_SPACE, token.TOKEN_MULTI_LINE_COMMENT)
+		if p.match(token.TOKEN_BANG) {
+			return object, nil
+		}
+	}
+
+	switch {
+	case p.skipAndMatch(token.TOKEN_DOT):
+		p.skip()
+		tok := p.current()
+		if tok.TokenType != token.TOKEN_IDENTIFIER && !tok.TokenType.IsKeyword() {
+			return nil, p.newSyntaxError(tok)
+		}
+		p.advance()
+		return &ast.MemberExpression{
+			Object:   object,
+			Property: &ast.Identifier{Token: *tok, Value: tok.Literal},
+		}, p.err
+	case p.skipAndMatch(token.TOKEN_LEFT_BRACKET):
+		defer p.allowIn()()
+		property, err := p.expression()
+		if err != nil {
+			return nil, err
+		} else if property == nil {
+			return nil, p.newSyntaxError(p.current())
+		}
+		rbracket, err := p.skipAndConsume(token.TOKEN_RIGHT_BRACKET)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.MemberExpression{
+			Object:   object,
+			Property: property,
+			Computed: true,
+			RBracket: *rbracket,
+		}, nil
+	}
+
+	return nil, nil
+}
+
+func (p *Parser) arguments() ([]ast.Expression, error) {
+	defer p.allowIn()()
+	args := make([]ast.Expression, 0)
+
+	for !p.skipAndMatch(token.TOKEN_RIGHT_PAREN) {
+		var arg ast.Expression
+		var err error
+
+		if p.skipAndMatch(token.TOKEN_DOT_DOT_DOT) {
+			spread := p.previous()
+			if err := p.require(spread, 2015, "Spread element"); err != nil {
+				return nil, err
+			}
+			arg, err = p.assignmentExpr()
+			if arg != nil {
+				arg = &ast.SpreadElement{Token: *spread, Value: arg}
+			}
+		} else {
+			arg, err = p.assignmentExpr()
+		}
+		if err != nil {
+			return nil, err
+		} else if arg == nil {
+			return nil, p.newSyntaxError(p.current())
+		}
+		args = append(args, arg)
+
+		if !p.skipAndMatch(token.TOKEN_COMMA) {
+			if _, err := p.skipAndConsume(token.TOKEN_RIGHT_PAREN); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	return args, nil
 }
 
-func (p *Parser) arrayLiteral() (ast.Expression, error) {
+func (p *Parser) arrayLiteral(lbracket *token.Token) (ast.Expression, error) {
+	defer p.allowIn()()
 	list := make([]ast.Expression, 0)
 
 	for !p.skipAndMatch(token.TOKEN_RIGHT_BRACKET) {
@@ -847,15 +1734,18 @@ func (p *Parser) arrayLiteral() (ast.Expression, error) {
 			return nil, p.err
 		}
 
-		tok := p.peek()
+		tok := p.current()
 		switch tok.TokenType {
 		case token.TOKEN_COMMA:
-			list = append(list, &ast.Elision{})
+			list = append(list, &ast.Elision{Token: *tok})
 			p.advance()
 			if p.isSyntaxError() {
 				return nil, p.err
 			}
 		case token.TOKEN_DOT_DOT_DOT:
+			if err := p.require(tok, 2015, "Spread element"); err != nil {
+				return nil, err
+			}
 			p.advance()
 			if p.isSyntaxError() {
 				return nil, p.err
@@ -865,9 +1755,10 @@ func (p *Parser) arrayLiteral() (ast.Expression, error) {
 			if err != nil {
 				return nil, err
 			} else if expr == nil {
-				return nil, fmt.Errorf("unexpected token %v", p.peek())
+				return nil, p.newSyntaxError(p.current())
 			}
 			list = append(list, &ast.SpreadElement{
+				Token: *tok,
 				Value: expr,
 			})
 		default:
@@ -875,7 +1766,7 @@ func (p *Parser) arrayLiteral() (ast.Expression, error) {
 			if err != nil {
 				return nil, err
 			} else if expr == nil {
-				return nil, fmt.Errorf("unexpected token %v", tok)
+				return nil, p.newSyntaxError(tok)
 			}
 			list = append(list, expr)
 		}
@@ -884,10 +1775,201 @@ func (p *Parser) arrayLiteral() (ast.Expression, error) {
 	}
 
 	return &ast.ArrayLiteral{
+		LBracket:    *lbracket,
+		RBracket:    *p.previous(),
 		ElementList: list,
 	}, nil
 }
 
+func (p *Parser) objectLiteral(lbrace *token.Token) (ast.Expression, error) {
+	defer p.allowIn()()
+	props := make([]ast.Expression, 0)
+
+	for !p.skipAndMatch(token.TOKEN_RIGHT_BRACE) {
+		prop, err := p.propertyDefinition()
+		if err != nil {
+			return nil, err
+		}
+		props = append(props, prop)
+
+		if !p.skipAndMatch(token.TOKEN_COMMA) {
+			if _, err := p.skipAndConsume(token.TOKEN_RIGHT_BRACE); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	return &ast.ObjectLiteral{
+		LBrace:     *lbrace,
+		RBrace:     *p.previous(),
+		Properties: props,
+	}, nil
+}
+
+func (p *Parser) propertyDefinition() (ast.Expression, error) {
+	p.skip()
+	first := p.current()
+	if p.match(token.TOKEN_DOT_DOT_DOT) {
+		if err := p.require(first, 2018, "Object spread property"); err != nil {
+			return nil, err
+		}
+		expr, err := p.assignmentExpr()
+		if err != nil {
+			return nil, err
+		} else if expr == nil {
+			return nil, p.newSyntaxError(p.current())
+		}
+		return &ast.SpreadElement{Token: *first, Value: expr}, nil
+	}
+
+	async := false
+	kind := ast.PropertyInit
+	switch tok := p.current(); tok.TokenType {
+	case token.TOKEN_ASYNC, token.TOKEN_GET, token.TOKEN_SET:
+		// async, get and set are modifiers only when a property name follows.
+		next, newLine, err := p.peekSignificant()
+		if err != nil {
+			return nil, err
+		}
+		isModifier := p.isPropertyNameStart(next)
+		if tok.TokenType == token.TOKEN_ASYNC {
+			isModifier = (isModifier || next.TokenType == token.TOKEN_STAR) && !newLine
+		}
+		if isModifier {
+			p.advance()
+			switch tok.TokenType {
+			case token.TOKEN_ASYNC:
+				async = true
+			case token.TOKEN_GET:
+				kind = ast.PropertyGet
+			case token.TOKEN_SET:
+				kind = ast.PropertySet
+			}
+		}
+	}
+	generator := p.skipAndMatch(token.TOKEN_STAR)
+
+	key, computed, err := p.propertyName()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skip()
+	switch tok := p.current(); {
+	case tok.TokenType == token.TOKEN_LEFT_PAREN || p.opts.TypeScript && tok.TokenType == token.TOKEN_LESS:
+		if kind == ast.PropertyInit {
+			if err := p.require(first, 2015, "Method definition"); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.requireFunctionKind(first, async, generator); err != nil {
+			return nil, err
+		}
+		fn, err := p.functionRest(nil, async, generator, true)
+		if err != nil {
+			return nil, err
+		} else if fn.Body == nil {
+			return nil, p.tsMissingBody()
+		}
+		return &ast.Property{
+			Token:    *first,
+			Key:      key,
+			Value:    fn,
+			Kind:     kind,
+			Computed: computed,
+			Method:   kind == ast.PropertyInit,
+		}, nil
+	case async || generator || kind != ast.PropertyInit:
+		return nil, p.newSyntaxError(p.current())
+	case p.match(token.TOKEN_COLON):
+		value, err := p.assignmentExpr()
+		if err != nil {
+			return nil, err
+		} else if value == nil {
+			return nil, p.newSyntaxError(p.current())
+		}
+		return &ast.Property{
+			Token:    *first,
+			Key:      key,
+			Value:    value,
+			Computed: computed,
+		}, nil
+	}
+
+	if ident, ok := key.(*ast.Identifier); ok && !computed && p.isIdentifierReference(&ident.Token) {
+		if err := p.require(first, 2015, "Shorthand property"); err != nil {
+			return nil, err
+		}
+		var value ast.Expression = ident
+		// An initializer is only valid if the literal turns out to be an
+		// assignment pattern.
+		initializer, err := p.initializer()
+		if err != nil {
+			return nil, err
+		} else if initializer != nil {
+			value = &ast.AssignmentPattern{Left: ident, Right: initializer}
+		}
+
+		return &ast.Property{
+			Token:     *first,
+			Key:       key,
+			Value:     value,
+			Shorthand: true,
+		}, nil
+	}
+
+	return nil, p.newSyntaxError(p.current())
+}
+
+func (p *Parser) propertyName() (ast.Expression, bool, error) {
+	p.skip()
+	tok := p.current()
+
+	switch {
+	case tok.TokenType == token.TOKEN_LEFT_BRACKET:
+		if err := p.require(tok, 2015, "Computed property name"); err != nil {
+			return nil, false, err
+		}
+		p.advance()
+		key, err := p.assignmentExpr()
+		if err != nil {
+			return nil, false, err
+		} else if key == nil {
+			return nil, false, p.newSyntaxError(p.current())
+		}
+		if _, err := p.skipAndConsume(token.TOKEN_RIGHT_BRACKET); err != nil {
+			return nil, false, err
+		}
+		return key, true, nil
+	case tok.TokenType == token.TOKEN_STRING:
+		if err := p.checkStrictLiteral(tok); err != nil {
+			return nil, false, err
+		}
+		p.advance()
+		return &ast.Literal{Token: *tok, Value: tok.Literal, Kind: ast.LitString}, false, p.err
+	case tok.TokenType == token.TOKEN_NUMBER:
+		if err := p.checkStrictLiteral(tok); err != nil {
+			return nil, false, err
+		}
+		p.advance()
+		return &ast.Literal{Token: *tok, Value: tok.Literal, Kind: ast.LitNumber}, false, p.err
+	case tok.TokenType == token.TOKEN_IDENTIFIER || tok.TokenType.IsKeyword():
+		p.advance()
+		return &ast.Identifier{Token: *tok, Value: tok.Literal}, false, p.err
+	}
+
+	return nil, false, p.newSyntaxError(tok)
+}
+
+func (p *Parser) isPropertyNameStart(tok *token.Token) bool {
+	switch tok.TokenType {
+	case token.TOKEN_IDENTIFIER, token.TOKEN_STRING, token.TOKEN_NUMBER, token.TOKEN_LEFT_BRACKET:
+		return true
+	}
+	return tok.TokenType.IsKeyword()
+}
+
 func (p *Parser) primaryExpr() (expr ast.Expression, err error) {
 	p.skip()
 	tok := p.current()
@@ -895,24 +1977,66 @@ func (p *Parser) primaryExpr() (expr ast.Expression, err error) {
 		return nil, p.err
 	}
 
+	if tok.TokenType != token.TOKEN_ASYNC && p.isIdentifierReference(tok) {
+		return p.identifierReference()
+	}
+
 	switch tok.TokenType {
-	case token.TOKEN_IDENTIFIER:
-		expr = &ast.Identifier{Value: tok.Literal}
+	case token.TOKEN_ASYNC:
+		return p.asyncExpr()
+	case token.TOKEN_IMPORT:
+		p.advance()
+		return p.importExpr(tok)
+	case token.TOKEN_FUNCTION:
+		p.advance()
+		return p.functionExpr(tok, false)
+	case token.TOKEN_THIS:
+		expr = &ast.ThisExpression{Token: *tok}
+	case token.TOKEN_LEFT_PAREN:
+		p.advance()
+		if p.isSyntaxError() {
+			return nil, p.err
+		}
+		return p.parenthesizedExpr()
+	case token.TOKEN_LEFT_BRACE:
+		p.advance()
+		if p.isSyntaxError() {
+			return nil, p.err
+		}
+		return p.objectLiteral(tok)
 	case token.TOKEN_NULL:
-		expr = &ast.Literal{Value: tok.Literal, Kind: ast.LitNull}
+		expr = &ast.Literal{Token: *tok, Value: tok.Literal, Kind: ast.LitNull}
 	case token.TOKEN_TRUE, token.TOKEN_FALSE:
-		expr = &ast.Literal{Value: tok.Literal, Kind: ast.LitBoolean}
-	case token.TOKEN_NUMBER:
-		expr = &ast.Literal{Value: tok.Literal, Kind: ast.LitNumber}
-	case token.TOKEN_STRING:
-		expr = &ast.Literal{Value: tok.Literal, Kind: ast.LitString}
+		expr = &ast.Literal{Token: *tok, Value: tok.Literal, Kind: ast.LitBoolean}
+	case token.TOKEN_NUMBER, token.TOKEN_STRING:
+		if err := p.checkStrictLiteral(tok); err != nil {
+			return nil, err
+		}
+		kind := ast.LitNumber
+		if tok.TokenType == token.TOKEN_STRING {
+			kind = ast.LitString
+			if strings.HasPrefix(tok.Raw, "`") {
+				if err := p.require(tok, 2015, "Template literal"); err != nil {
+					return nil, err
+				}
+			}
+		}
+		expr = &ast.Literal{Token: *tok, Value: tok.Literal, Kind: kind}
 	case token.TOKEN_LEFT_BRACKET:
 		p.advance()
 		if p.isSyntaxError() {
 			return nil, p.err
 		}
-		expr, err = p.arrayLiteral()
+		expr, err = p.arrayLiteral(tok)
 		return
+	case token.TOKEN_LESS:
+		switch {
+		case p.opts.JSX:
+			return p.jsxElement(false)
+		case p.opts.TypeScript:
+			return p.tsAngleExpr(tok)
+		}
+		return nil, nil
 	default:
 		return nil, nil
 	}
@@ -926,14 +2050,71 @@ func (p *Parser) primaryExpr() (expr ast.Expression, err error) {
 }
 
 func (p *Parser) consume(tokType token.TokenType) (*token.Token, error) {
-	if p.isEnd() {
-		return nil, errors.New("unexpected termination")
-	}
-	if p.current().TokenType == tokType {
+	if !p.isEnd() && p.current().TokenType == tokType {
 		tok := p.advance()
 		return tok, p.err
 	}
-	return nil, fmt.Errorf("unexpected token %s", tokType)
+	return nil, p.newExpectedError(tokType)
+}
+
+// semicolon consumes the semicolon that terminates a statement. It may be
+// omitted before a line terminator, a closing brace or the end of input.
+func (p *Parser) semicolon() error {
+	p.skip(token.TOKEN_SPACE, token.TOKEN_MULTI_LINE_COMMENT)
+
+	switch tok := p.current(); tok.TokenType {
+	case token.TOKEN_SEMICOLON:
+		p.advance()
+		return p.err
+	case token.TOKEN_NEW_LINE, token.TOKEN_SINGLE_LINE_COMMENT, token.TOKEN_RIGHT_BRACE, token.TOKEN_EOF:
+		return nil
+	default:
+		if p.newLine {
+			return nil
+		}
+		return p.newSyntaxError(tok)
+	}
+}
+
+// checkStrictLiteral rejects legacy octal number literals and octal escape
+// sequences in strict mode code.
+func (p *Parser) checkStrictLiteral(tok *token.Token) error {
+	if !p.strict {
+		return nil
+	}
+
+	lit := tok.Literal
+	if tok.TokenType == token.TOKEN_NUMBER {
+		if len(lit) > 1 && lit[0] == '0' && lit[1] >= '0' && lit[1] <= '9' {
+			if strings.ContainsAny(lit, "89") {
+				return p.newSyntaxErrorf(tok, "Decimals with leading zeros are not allowed in strict mode.")
+			}
+			return p.newSyntaxErrorf(tok, "Octal literals are not allowed in strict mode.")
+		}
+		return nil
+	}
+
+	for i := 0; i < len(lit)-1; i++ {
+		if lit[i] != '\\' {
+			continue
+		}
+		c := lit[i+1]
+		if c >= '1' && c <= '7' || c == '0' && i+2 < len(lit) && lit[i+2] >= '0' && lit[i+2] <= '9' {
+			return p.newSyntaxErrorf(tok, "Octal escape sequences are not allowed in strict mode.")
+		}
+		i++
+	}
+	return nil
+}
+
+// allowIn allows the in operator in a nested expression, and returns the
+// function that restores the previous state.
+func (p *Parser) allowIn() func() {
+	noIn := p.noIn
+	p.noIn = false
+	return func() {
+		p.noIn = noIn
+	}
 }
 
 func (p *Parser) skip(skipTypes ...token.TokenType) {
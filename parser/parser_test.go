@@ -0,0 +1,1587 @@
+package parser
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ghosind/go-assert"
+
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/lexer"
+	"github.com/ghosind/gjs/token"
+)
+
+func parseProgram(a *assert.Assertion, input string) *ast.Program {
+	p := New(lexer.New([]byte(input)))
+	program, err := p.ParseProgram()
+	a.NilNow(err)
+	return program
+}
+
+func TestParseAsyncFunction(t *testing.T) {
+	a := assert.New(t)
+
+	program := parseProgram(a, "async function f(a, b = 1, ...c) { return await a; }")
+	a.EqualNow(len(program.Statements), 1)
+
+	decl, ok := program.Statements[0].(*ast.FunctionDeclaration)
+	a.TrueNow(ok)
+	a.TrueNow(decl.Async)
+	a.NotTrueNow(decl.Generator)
+	a.EqualNow(decl.Name.Value, "f")
+	a.EqualNow(len(decl.Params), 3)
+
+	ret := decl.Body.StatementList[0].(*ast.ReturnStatement)
+	_, ok = ret.Result.(*ast.AwaitExpression)
+	a.TrueNow(ok)
+}
+
+func TestParseAsyncArrowFunction(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []struct {
+		input  string
+		params int
+		async  bool
+	}{
+		{"x => x;", 1, false},
+		{"(a, b) => a;", 2, false},
+		{"() => {};", 0, false},
+		{"async x => await x;", 1, true},
+		{"async (a, ...b) => { await a; };", 2, true},
+		{"async () => 1;", 0, true},
+	}
+
+	for _, test := range tests {
+		program := parseProgram(a, test.input)
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		arrow, ok := stmt.Expression.(*ast.ArrowFunctionExpression)
+		a.TrueNow(ok, test.input)
+		a.EqualNow(len(arrow.Params), test.params, test.input)
+		a.EqualNow(arrow.Async, test.async, test.input)
+	}
+}
+
+func TestParseAsyncAsIdentifier(t *testing.T) {
+	a := assert.New(t)
+
+	program := parseProgram(a, "async(1, 2); async;")
+	call, ok := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.CallExpression)
+	a.TrueNow(ok)
+	a.EqualNow(call.Callee.String(), "async")
+	a.EqualNow(len(call.Arguments), 2)
+
+	ident, ok := program.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.Identifier)
+	a.TrueNow(ok)
+	a.EqualNow(ident.Value, "async")
+}
+
+func TestParseAsyncMethods(t *testing.T) {
+	a := assert.New(t)
+
+	program := parseProgram(a, "var o = { async m() {}, async *g() { yield 1; }, *h() {}, async: 1 };")
+	decl := program.Statements[0].(*ast.VarStatement).Declarations[0].(*ast.VariableDeclaration)
+	obj := decl.Value.(*ast.ObjectLiteral)
+	a.EqualNow(len(obj.Properties), 4)
+
+	expected := []struct {
+		async     bool
+		generator bool
+	}{{true, false}, {true, true}, {false, true}}
+	for i, e := range expected {
+		prop := obj.Properties[i].(*ast.Property)
+		fn := prop.Value.(*ast.FunctionExpression)
+		a.TrueNow(prop.Method)
+		a.EqualNow(fn.Async, e.async)
+		a.EqualNow(fn.Generator, e.generator)
+	}
+
+	prop := obj.Properties[3].(*ast.Property)
+	a.EqualNow(prop.Key.String(), "async")
+	a.NotTrueNow(prop.Method)
+}
+
+func TestParseAwaitOutsideAsyncFunction(t *testing.T) {
+	a := assert.New(t)
+
+	p := New(lexer.New([]byte("function f() { await x; }")))
+	_, err := p.ParseProgram()
+	a.NotNilNow(err)
+}
+
+func TestParseForInOfStatement(t *testing.T) {
+	a := assert.New(t)
+
+	program := parseProgram(a, `
+for (var k in o) ;
+for (const [a, b] of c) ;
+for (x.y of z) ;
+for (let i = ("a" in o) ? 1 : 0; i < 1; i += 1) ;
+`)
+	a.EqualNow(len(program.Statements), 4)
+
+	forIn, ok := program.Statements[0].(*ast.ForInStatement)
+	a.TrueNow(ok)
+	_, ok = forIn.Left.(*ast.VarStatement)
+	a.TrueNow(ok)
+
+	forOf, ok := program.Statements[1].(*ast.ForOfStatement)
+	a.TrueNow(ok)
+	decl := forOf.Left.(*ast.LexicalDeclaration)
+	a.TrueNow(decl.Const)
+	_, ok = decl.Declarations[0].(*ast.VariableDeclaration).Name.(*ast.ArrayPattern)
+	a.TrueNow(ok)
+
+	forOf, ok = program.Statements[2].(*ast.ForOfStatement)
+	a.TrueNow(ok)
+	_, ok = forOf.Left.(*ast.MemberExpression)
+	a.TrueNow(ok)
+
+	_, ok = program.Statements[3].(*ast.ForStatement)
+	a.TrueNow(ok)
+}
+
+func TestParseLabeledStatement(t *testing.T) {
+	a := assert.New(t)
+
+	program := parseProgram(a, "outer: while (1) { inner: for (;;) { continue outer; } }")
+	stmt, ok := program.Statements[0].(*ast.LabeledStatement)
+	a.TrueNow(ok)
+	a.EqualNow(stmt.Label.String(), "outer")
+}
+
+func TestParseLoopErrors(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{"for (var a, b of c) ;", "SyntaxError: Invalid left-hand side in for-of loop: Must have a single binding."},
+		{"for (let a = 1 of c) ;", "SyntaxError: for-of loop variable declaration may not have an initializer."},
+		{"for (f() in o) ;", "SyntaxError: Invalid left-hand side in for-in loop"},
+		{"a: { continue a; }", "SyntaxError: Illegal continue statement: 'a' does not denote an iteration statement"},
+		{"while (1) break b;", "SyntaxError: Undefined label 'b'"},
+		{"a: a: ;", "SyntaxError: Label 'a' has already been declared"},
+		{"const c;", "SyntaxError: Missing initializer in const declaration"},
+		{"for (let i = 0 in o) ;", "SyntaxError: for-in loop variable declaration may not have an initializer."},
+		{"async function f() { for await (x in y) ; }", "SyntaxError: Unexpected token 'in'"},
+	}
+
+	for _, test := range tests {
+		p := New(lexer.New([]byte(test.input)))
+		_, err := p.ParseProgram()
+		a.NotNilNow(err)
+		a.EqualNow(errorMessage(err), test.err)
+	}
+}
+
+func TestParseImportDeclaration(t *testing.T) {
+	a := assert.New(t)
+
+	p := New(lexer.New([]byte(`
+import a, { b as c, "d e" as f, default as g } from "x" with { type: "json" };
+import * as ns from "y";
+import "z";
+`)))
+	program, err := p.ParseModule()
+	a.NilNow(err)
+	a.EqualNow(program.SourceType, ast.SourceModule)
+	a.EqualNow(len(program.Statements), 3)
+
+	decl := program.Statements[0].(*ast.ImportDeclaration)
+	a.EqualNow(len(decl.Specifiers), 4)
+	a.EqualNow(decl.Source.Value, "x")
+	a.EqualNow(len(decl.Attributes), 1)
+	a.EqualNow(decl.String(), `import a, { b as c, "d e" as f, default as g } from "x" with { type: "json" };`)
+
+	decl = program.Statements[1].(*ast.ImportDeclaration)
+	_, ok := decl.Specifiers[0].(*ast.ImportNamespaceSpecifier)
+	a.TrueNow(ok)
+
+	decl = program.Statements[2].(*ast.ImportDeclaration)
+	a.EqualNow(len(decl.Specifiers), 0)
+}
+
+func TestParseExportDeclaration(t *testing.T) {
+	a := assert.New(t)
+
+	p := New(lexer.New([]byte(`
+export { a as b, c };
+export { default as d } from "m";
+export * from "m";
+export * as n from "m";
+export const x = 1;
+export async function f() {}
+export default function () {}
+var a, c;
+`)))
+	program, err := p.ParseModule()
+	a.NilNow(err)
+	a.EqualNow(len(program.Statements), 8)
+
+	named := program.Statements[0].(*ast.ExportNamedDeclaration)
+	a.EqualNow(len(named.Specifiers), 2)
+	a.NilNow(named.Source)
+
+	named = program.Statements[1].(*ast.ExportNamedDeclaration)
+	a.EqualNow(named.Source.Value, "m")
+
+	all := program.Statements[3].(*ast.ExportAllDeclaration)
+	a.EqualNow(all.Exported.String(), "n")
+
+	named = program.Statements[5].(*ast.ExportNamedDeclaration)
+	a.TrueNow(named.Declaration.(*ast.FunctionDeclaration).Async)
+
+	def := program.Statements[6].(*ast.ExportDefaultDeclaration)
+	a.NilNow(def.Declaration.(*ast.FunctionDeclaration).Name)
+}
+
+func TestParseImportExpression(t *testing.T) {
+	a := assert.New(t)
+
+	program := parseProgram(a, `import("x").then(m => m);`)
+	call := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.CallExpression)
+	_, ok := call.Callee.(*ast.MemberExpression).Object.(*ast.ImportExpression)
+	a.TrueNow(ok)
+
+	p := New(lexer.New([]byte("const m = await import(import.meta.url);")))
+	program, err := p.ParseModule()
+	a.NilNow(err)
+	decl := program.Statements[0].(*ast.LexicalDeclaration).Declarations[0].(*ast.VariableDeclaration)
+	expr := decl.Value.(*ast.AwaitExpression).Argument.(*ast.ImportExpression)
+	_, ok = expr.Source.(*ast.MemberExpression).Object.(*ast.MetaProperty)
+	a.TrueNow(ok)
+}
+
+func TestParseModuleErrors(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []struct {
+		input  string
+		module bool
+		err    string
+	}{
+		{`import a from "x";`, false, "SyntaxError: Cannot use import statement outside a module"},
+		{`export const a = 1;`, false, "SyntaxError: Unexpected token 'export'"},
+		{`import.meta;`, false, "SyntaxError: Cannot use 'import.meta' outside a module"},
+		{`export { default };`, true, "SyntaxError: Unexpected token 'default'"},
+		{`import a, from "x";`, true, "SyntaxError: Unexpected identifier 'from'"},
+		{`var await;`, true, "SyntaxError: Unexpected reserved word"},
+		{`new import("a");`, false, "SyntaxError: Cannot use new with import"},
+		{`import();`, false, "SyntaxError: import() requires a specifier"},
+		{`import(...a);`, false, "SyntaxError: ... is not allowed in import()"},
+		{`import(a, b, c);`, false, "SyntaxError: import() accepts at most two arguments"},
+	}
+
+	for _, test := range tests {
+		p := New(lexer.New([]byte(test.input)))
+		var err error
+		if test.module {
+			_, err = p.ParseModule()
+		} else {
+			_, err = p.ParseProgram()
+		}
+		a.NotNilNow(err)
+		a.EqualNow(errorMessage(err), test.err)
+	}
+}
+
+func TestParseStrictMode(t *testing.T) {
+	a := assert.New(t)
+
+	program := parseProgram(a, `"use strict"; function f() {}`)
+	a.TrueNow(program.Strict)
+	a.TrueNow(program.Statements[1].(*ast.FunctionDeclaration).Strict)
+
+	program = parseProgram(a, `f(); "use strict"; function f() { "a"; "use strict"; } function g() {}`)
+	a.NotTrueNow(program.Strict)
+	a.TrueNow(program.Statements[2].(*ast.FunctionDeclaration).Strict)
+	a.NotTrueNow(program.Statements[3].(*ast.FunctionDeclaration).Strict)
+
+	p := New(lexer.New([]byte("function f() { return this; }")))
+	program, err := p.ParseModule()
+	a.NilNow(err)
+	a.TrueNow(program.Strict)
+	a.TrueNow(program.Statements[0].(*ast.FunctionDeclaration).Strict)
+}
+
+func TestParseStrictModeErrors(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{`"use strict"; with (o) ;`, "SyntaxError: Strict mode code may not include a with statement"},
+		{`"use strict"; 010;`, "SyntaxError: Octal literals are not allowed in strict mode."},
+		{`"use strict"; 09;`, "SyntaxError: Decimals with leading zeros are not allowed in strict mode."},
+		{`"use strict"; "\07";`, "SyntaxError: Octal escape sequences are not allowed in strict mode."},
+		{`function f() { "use strict"; eval = 1; }`, "SyntaxError: Unexpected eval or arguments in strict mode"},
+		{`"use strict"; [arguments] = [];`, "SyntaxError: Unexpected eval or arguments in strict mode"},
+		{`"use strict"; eval++;`, "SyntaxError: Unexpected eval or arguments in strict mode"},
+		{`function f(a, a) { "use strict"; }`, "SyntaxError: Duplicate parameter name not allowed in this context"},
+		{`(a, a) => a;`, "SyntaxError: Duplicate parameter name not allowed in this context"},
+		{`function f(a = 1) { "use strict"; }`, "SyntaxError: Illegal 'use strict' directive in function with non-simple parameter list"},
+		{`"use strict"; delete x;`, "SyntaxError: Delete of an unqualified identifier in strict mode."},
+		{`"use strict"; var let;`, "SyntaxError: Unexpected strict mode reserved word"},
+		{`function eval() { "use strict"; }`, "SyntaxError: Unexpected eval or arguments in strict mode"},
+		{`var f = function arguments() { "use strict"; };`, "SyntaxError: Unexpected eval or arguments in strict mode"},
+		{`"\010"; "use strict";`, "SyntaxError: Octal escape sequences are not allowed in strict mode."},
+		{`function f() { "\01"; "use strict"; }`, "SyntaxError: Octal escape sequences are not allowed in strict mode."},
+	}
+
+	for _, test := range tests {
+		p := New(lexer.New([]byte(test.input)))
+		_, err := p.ParseProgram()
+		a.NotNilNow(err, test.input)
+		a.EqualNow(errorMessage(err), test.err, test.input)
+	}
+
+	parseProgram(a, `function f(a, a) {} with (o) x; 010; "\07";`)
+}
+
+func TestParseUnsupportedStatements(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{"try {} catch (e) {}", "SyntaxError: try statement is not supported"},
+		{"switch (1) {}", "SyntaxError: switch statement is not supported"},
+		{"{ try {} finally {} }", "SyntaxError: try statement is not supported"},
+		{"function f() { switch (1) {} }", "SyntaxError: switch statement is not supported"},
+	}
+
+	for _, test := range tests {
+		p := New(lexer.New([]byte(test.input)))
+		_, err := p.ParseProgram()
+		a.NotNilNow(err, test.input)
+		a.EqualNow(errorMessage(err), test.err, test.input)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{`break;`, "SyntaxError: Illegal break statement"},
+		{`continue;`, "SyntaxError: Illegal continue statement: no surrounding iteration statement"},
+		{`return 1;`, "SyntaxError: Illegal return statement"},
+		{`while (true) { function f() { break; } }`, "SyntaxError: Illegal break statement"},
+		{`a: while (true) { (() => { continue a; }); }`, "SyntaxError: Undefined label 'a'"},
+		{`a: { continue a; }`, "SyntaxError: Illegal continue statement: 'a' does not denote an iteration statement"},
+		{`let a; let a;`, "SyntaxError: Identifier 'a' has already been declared"},
+		{`let a; var a;`, "SyntaxError: Identifier 'a' has already been declared"},
+		{`const a = 1; function a() {}`, "SyntaxError: Identifier 'a' has already been declared"},
+		{`function f(a) { let a; }`, "SyntaxError: Identifier 'a' has already been declared"},
+		{`for (let i = 0; ; ) { var i; }`, "SyntaxError: Identifier 'i' has already been declared"},
+		{`"use strict"; { function f() {} function f() {} }`, "SyntaxError: Identifier 'f' has already been declared"},
+		{`let [let] = [];`, "SyntaxError: let is disallowed as a lexically bound name"},
+		{`++1;`, "SyntaxError: Invalid left-hand side expression in update operation"},
+		{`1 = 2;`, "SyntaxError: Invalid left-hand side in assignment"},
+		{`({ __proto__: 1, "__proto__": 2 });`, "SyntaxError: Duplicate __proto__ fields are not allowed in object literals"},
+		{`({ a = 1 });`, "SyntaxError: Invalid shorthand property initializer"},
+		{`f({ a: { b = 1 } });`, "SyntaxError: Invalid shorthand property initializer"},
+		{`async function f(a = await 1) {}`, "SyntaxError: Illegal await-expression in formal parameters of async function"},
+		{`function* g(a = yield) {}`, "SyntaxError: Yield expression not allowed in formal parameter"},
+		{`function* g() { (a = yield) => a; }`, "SyntaxError: Yield expression not allowed in formal parameter"},
+		{`(async function await() {});`, "SyntaxError: Unexpected reserved word"},
+		{`(function* yield() {});`, "SyntaxError: Unexpected identifier 'yield'"},
+	}
+
+	for _, test := range tests {
+		p := New(lexer.New([]byte(test.input)))
+		_, err := p.ParseProgram()
+		a.NotNilNow(err, test.input)
+		a.EqualNow(errorMessage(err), test.err, test.input)
+	}
+
+	for _, input := range []string{
+		`while (true) { break; }`,
+		`a: { break a; }`,
+		`a: for (;;) { b: { continue a; } }`,
+		`function f() { return 1; }`,
+		`{ function f() {} function f() {} }`,
+		`var a; var a; function a() {}`,
+		`function f(a) { var a; }`,
+		`({ a = 1 } = o); [{ a = 1 }] = o; for ({ a = 1 } of o); f = ({ a = 1 }) => a;`,
+		`async function f(a = async () => await 1) {}`,
+		`function* g(a = function* () { yield; }) {}`,
+		// The name of a declaration is bound in the enclosing scope.
+		`async function await() {} function* yield() {}`,
+	} {
+		p := New(lexer.New([]byte(input)))
+		_, err := p.ParseProgram()
+		a.NilNow(err, input)
+	}
+}
+
+func TestCheckModule(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{`export { a };`, "SyntaxError: Export 'a' is not defined in module"},
+		{`var a; export { a, a };`, "SyntaxError: Duplicate export of 'a'"},
+		{`export default 1; export default 2;`, "SyntaxError: Duplicate export of 'default'"},
+		{`import a from "m"; let a;`, "SyntaxError: Identifier 'a' has already been declared"},
+		{`function f() {} var f;`, "SyntaxError: Identifier 'f' has already been declared"},
+	}
+
+	for _, test := range tests {
+		p := New(lexer.New([]byte(test.input)))
+		_, err := p.ParseModule()
+		a.NotNilNow(err, test.input)
+		a.EqualNow(errorMessage(err), test.err, test.input)
+	}
+}
+
+func TestCheckErrorList(t *testing.T) {
+	a := assert.New(t)
+
+	p := New(lexer.New([]byte("break;\nif (x) {\n  continue;\n}\n")))
+	_, err := p.ParseProgram()
+	a.NotNilNow(err)
+	a.EqualNow(err.Error(), "1:1\nbreak;\n^\nSyntaxError: Illegal break statement\n(and 1 more errors)")
+
+	list, ok := err.(ErrorList)
+	a.TrueNow(ok)
+	a.EqualNow(len(list), 2)
+	a.EqualNow(list[0].Line(), 1)
+	a.EqualNow(list[0].Column(), 1)
+	a.EqualNow(list[1].Line(), 3)
+	a.EqualNow(list[1].Column(), 3)
+}
+
+func TestParseProgramTolerant(t *testing.T) {
+	a := assert.New(t)
+
+	p := New(lexer.New([]byte(`let a = 1;
+let b = );
+if (a b) {
+  foo(;
+  a = 2;
+}
+let c = [1, 2;
+c = 3;
+`)))
+	program, errs := p.ParseProgramTolerant()
+	a.NotNilNow(program)
+	a.EqualNow(len(errs), 4)
+	a.EqualNow(errs[0].Line(), 2)
+	a.EqualNow(errs[1].Line(), 3)
+	a.EqualNow(errs[1].Column(), 7)
+	a.EqualNow(errs[2].Line(), 4)
+	a.EqualNow(errs[3].Line(), 7)
+
+	a.EqualNow(len(program.Statements), 5)
+
+	_, ok := program.Statements[1].(*ast.BadStatement)
+	a.TrueNow(ok)
+
+	ifStmt := program.Statements[2].(*ast.IfStatement)
+	_, ok = ifStmt.Condition.(*ast.BadExpression)
+	a.TrueNow(ok)
+	block := ifStmt.TrueBranch.(*ast.BlockStatement)
+	a.EqualNow(len(block.StatementList), 2)
+	bad, ok := block.StatementList[0].(*ast.BadStatement)
+	a.TrueNow(ok)
+	a.EqualNow(bad.From.Literal, "foo")
+	a.EqualNow(bad.To.Literal, ";")
+	a.EqualNow(block.StatementList[1].String(), "a = 2;")
+
+	decl := program.Statements[3].(*ast.LexicalDeclaration)
+	_, ok = decl.Declarations[0].(*ast.VariableDeclaration).Value.(*ast.BadExpression)
+	a.TrueNow(ok)
+
+	a.EqualNow(program.Statements[4].String(), "c = 3;")
+}
+
+func TestParseProgramTolerantErrors(t *testing.T) {
+	a := assert.New(t)
+
+	// The early errors are reported with the syntax errors.
+	p := New(lexer.New([]byte("let a; let a;\nbreak;\n)\n")))
+	program, errs := p.ParseProgramTolerant()
+	a.NotNilNow(program)
+	a.EqualNow(len(errs), 3)
+	a.EqualNow(errs[0].Message(), "SyntaxError: Identifier 'a' has already been declared")
+	a.EqualNow(errs[2].Line(), 3)
+	a.EqualNow(len(program.Statements), 4)
+
+	// An unexpected end of input is reported once.
+	p = New(lexer.New([]byte("function f() {\n  if (a) {\n    b(\n")))
+	_, errs = p.ParseProgramTolerant()
+	a.EqualNow(len(errs), 1)
+
+	// The error of an invalid token stops the parser.
+	p = New(lexer.New([]byte("let a = 1;\nlet b = @;\nlet c;\n")))
+	program, errs = p.ParseProgramTolerant()
+	a.EqualNow(len(errs), 1)
+	a.EqualNow(len(program.Statements), 1)
+
+	p = New(lexer.New([]byte("let a = 1;")))
+	program, errs = p.ParseProgramTolerant()
+	a.NilNow(errs)
+	a.EqualNow(len(program.Statements), 1)
+}
+
+// errorMessage returns the message of the first syntax error in err, without
+// its code frame.
+func errorMessage(err error) string {
+	var list ErrorList
+	var syntaxErr *SyntaxError
+	if errors.As(err, &list) {
+		return list[0].Message()
+	} else if errors.As(err, &syntaxErr) {
+		return syntaxErr.Message()
+	}
+	return err.Error()
+}
+
+func TestSyntaxErrorMessages(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []struct {
+		input  string
+		err    string
+		line   int
+		column int
+	}{
+		{"let b = );", "SyntaxError: Unexpected token ')'", 1, 9},
+		{"foo(", "SyntaxError: Unexpected end of input", 1, 5},
+		{"const a;", "SyntaxError: Missing initializer in const declaration", 1, 8},
+		{"if (a b) {}", "SyntaxError: Unexpected identifier 'b', expected ')'", 1, 7},
+		{"var a;\nvar 1;", "SyntaxError: Unexpected number", 2, 5},
+		{`a "b";`, "SyntaxError: Unexpected string", 1, 3},
+		{"x = if;", "SyntaxError: Unexpected token 'if'", 1, 5},
+		{`"use strict"; var static;`, "SyntaxError: Unexpected strict mode reserved word", 1, 19},
+	}
+
+	for _, test := range tests {
+		p := New(lexer.New([]byte(test.input)))
+		_, err := p.ParseProgram()
+		a.NotNilNow(err, test.input)
+
+		syntaxErr, ok := err.(*SyntaxError)
+		a.TrueNow(ok, test.input)
+		a.EqualNow(syntaxErr.Message(), test.err, test.input)
+		a.EqualNow(syntaxErr.Line(), test.line, test.input)
+		a.EqualNow(syntaxErr.Column(), test.column, test.input)
+	}
+}
+
+func TestSyntaxErrorCodeFrame(t *testing.T) {
+	a := assert.New(t)
+
+	p := New(lexer.New([]byte("let a = 1;\n\tlet b = );\n")))
+	_, err := p.ParseProgram()
+	a.NotNilNow(err)
+	a.EqualNow(err.Error(), "2:10\n\tlet b = );\n\t        ^\nSyntaxError: Unexpected token ')'")
+
+	// The errors reported without the source have no code frames.
+	err = Check(&ast.Program{Statements: []ast.Statement{
+		&ast.BreakStatement{Token: token.Token{TokenType: token.TOKEN_BREAK, Line: 3, Col: 5}},
+	}})
+	a.EqualNow(err.Error(), "SyntaxError: Illegal break statement (3:5)")
+}
+
+func TestNodePositions(t *testing.T) {
+	a := assert.New(t)
+
+	input := `var a = [1, , ...b], { c, d: [e] = f } = g
+let h = { i: 1, "j"() {} };
+a.b[c](d, ...e)
+new F;
+function k(l) {
+  return l
+}
+if (a) b(); else { c }
+for (const x of y) {}
+do x++; while (x < 10)
+label: debugger;
+(async (x) => await x)`
+	program := parseProgram(a, input)
+	a.EqualNow(len(program.Statements), 10)
+
+	text := func(node ast.Node) string {
+		return input[node.Pos().Offset:node.End().Offset]
+	}
+
+	a.EqualNow(text(program.Statements[0]), "var a = [1, , ...b], { c, d: [e] = f } = g")
+	a.EqualNow(text(program.Statements[1]), `let h = { i: 1, "j"() {} };`)
+	a.EqualNow(text(program.Statements[2]), "a.b[c](d, ...e)")
+	a.EqualNow(text(program.Statements[3]), "new F;")
+	a.EqualNow(text(program.Statements[4]), "function k(l) {\n  return l\n}")
+	a.EqualNow(text(program.Statements[5]), "if (a) b(); else { c }")
+	a.EqualNow(text(program.Statements[6]), "for (const x of y) {}")
+	a.EqualNow(text(program.Statements[7]), "do x++; while (x < 10)")
+	a.EqualNow(text(program.Statements[8]), "label: debugger;")
+	a.EqualNow(text(program.Statements[9]), "(async (x) => await x)")
+
+	decls := program.Statements[0].(*ast.VarStatement).Declarations
+	arr := decls[0].(*ast.VariableDeclaration).Value.(*ast.ArrayLiteral)
+	a.EqualNow(text(arr), "[1, , ...b]")
+	a.EqualNow(text(arr.ElementList[2]), "...b")
+	pattern := decls[1].(*ast.VariableDeclaration).Name.(*ast.ObjectPattern)
+	a.EqualNow(text(pattern), "{ c, d: [e] = f }")
+	a.EqualNow(text(pattern.Properties[1]), "d: [e] = f")
+
+	obj := program.Statements[1].(*ast.LexicalDeclaration).Declarations[0].(*ast.VariableDeclaration).Value
+	a.EqualNow(text(obj.(*ast.ObjectLiteral).Properties[1]), `"j"() {}`)
+
+	ret := program.Statements[4].(*ast.FunctionDeclaration).Body.StatementList[0]
+	a.EqualNow(text(ret), "return l")
+	a.EqualNow(ret.Pos().String(), "6:3")
+	a.EqualNow(ret.End().String(), "6:11")
+
+	update := program.Statements[7].(*ast.DoWhileStatement).Body.(*ast.ExpressionStatement).Expression
+	a.EqualNow(text(update), "x++")
+
+	a.EqualNow(program.End().Offset, len(input))
+}
+
+func TestNodePositionsOfModule(t *testing.T) {
+	a := assert.New(t)
+
+	input := "import * as ns from 'a'\nexport default function () {}\nexport { ns as b };\nimport('c')"
+	program, err := New(lexer.New([]byte(input))).ParseModule()
+	a.NilNow(err)
+
+	text := func(node ast.Node) string {
+		return input[node.Pos().Offset:node.End().Offset]
+	}
+
+	a.EqualNow(text(program.Statements[0]), "import * as ns from 'a'")
+	a.EqualNow(text(program.Statements[0].(*ast.ImportDeclaration).Specifiers[0]), "* as ns")
+	a.EqualNow(text(program.Statements[1]), "export default function () {}")
+	a.EqualNow(text(program.Statements[2]), "export { ns as b };")
+	a.EqualNow(text(program.Statements[3]), "import('c')")
+}
+
+func TestParseBinaryExpression(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a || b || c", "((a || b) || c)"},
+		{"a ?? b ?? c", "((a ?? b) ?? c)"},
+		{"a || b && c", "(a || (b && c))"},
+		{"a - b - c", "((a - b) - c)"},
+		{"a + b * c", "(a + (b * c))"},
+		{"a * b + c % d", "((a * b) + (c % d))"},
+		{"a ** b ** c", "(a ** (b ** c))"},
+		{"a * b ** c", "(a * (b ** c))"},
+		{"(-a) ** b", "(-a ** b)"},
+		{"a ** -b", "(a ** -b)"},
+		{"a | b ^ c & d", "(a | (b ^ (c & d)))"},
+		{"a == b < c << d", "(a == (b < (c << d)))"},
+		{"a in b instanceof c", "((a in b) instanceof c)"},
+		{"(a || b) ?? c", "((a || b) ?? c)"},
+		{"a ?? (b && c)", "(a ?? (b && c))"},
+		{"a ? b : c || d", "a ? b : (c || d)"},
+	}
+
+	for _, test := range tests {
+		program := parseProgram(a, test.input)
+		expr := program.Statements[0].(*ast.ExpressionStatement).Expression
+		a.EqualNow(groupBinary(expr), test.expected, test.input)
+	}
+}
+
+// groupBinary prints an expression with its binary expressions grouped in
+// parentheses.
+func groupBinary(expr ast.Expression) string {
+	switch expr := expr.(type) {
+	case *ast.BinaryExpression:
+		return "(" + groupBinary(expr.Left) + " " + expr.Operator.Literal + " " + groupBinary(expr.Right) + ")"
+	case *ast.LogicalExpression:
+		return "(" + groupBinary(expr.Left) + " " + expr.Operator.Literal + " " + groupBinary(expr.Right) + ")"
+	case *ast.TernaryExpression:
+		return groupBinary(expr.Condition) + " ? " + groupBinary(expr.TrueBranch) + " : " + groupBinary(expr.FalseBranch)
+	}
+	return expr.String()
+}
+
+func TestParseUpdateAndSequenceExpressions(t *testing.T) {
+	a := assert.New(t)
+
+	program := parseProgram(a, "i++\n--j\nk\n++l\nfor (i = 0, j = 1; i < j; i++, j--);")
+	a.EqualNow(len(program.Statements), 5)
+
+	tokens := make([]token.TokenType, 0)
+	prefix := make([]bool, 0)
+	for _, stmt := range program.Statements[:4] {
+		expr := stmt.(*ast.ExpressionStatement).Expression
+		if update, ok := expr.(*ast.UpdateExpression); ok {
+			tokens = append(tokens, update.Operator.TokenType)
+			prefix = append(prefix, update.Prefix)
+		}
+	}
+	a.DeepEqualNow(tokens, []token.TokenType{token.TOKEN_PLUS_PLUS, token.TOKEN_MINUS_MINUS, token.TOKEN_PLUS_PLUS})
+	a.DeepEqualNow(prefix, []bool{false, true, true})
+	a.EqualNow(program.Statements[0].(*ast.ExpressionStatement).Expression.String(), "i++")
+	a.EqualNow(program.Statements[1].(*ast.ExpressionStatement).Expression.String(), "--j")
+
+	loop := program.Statements[4].(*ast.ForStatement)
+	a.EqualNow(len(loop.Init.(*ast.SequenceExpression).Expressions), 2)
+	a.EqualNow(len(loop.Update.(*ast.SequenceExpression).Expressions), 2)
+
+	program = parseProgram(a, "x = (a, b, c);")
+	assign := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.AssignmentExpression)
+	a.EqualNow(assign.Right.String(), "a, b, c")
+}
+
+func TestParseLogicalExpression(t *testing.T) {
+	a := assert.New(t)
+
+	program := parseProgram(a, "a && b || c; a | b & c;")
+	expr := program.Statements[0].(*ast.ExpressionStatement).Expression
+	or, ok := expr.(*ast.LogicalExpression)
+	a.TrueNow(ok)
+	a.EqualNow(or.Operator.TokenType, token.TOKEN_PIPE_PIPE)
+	_, ok = or.Left.(*ast.LogicalExpression)
+	a.TrueNow(ok)
+
+	expr = program.Statements[1].(*ast.ExpressionStatement).Expression
+	_, ok = expr.(*ast.BinaryExpression)
+	a.TrueNow(ok)
+}
+
+func TestParseOperatorErrors(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x = 1 +;", "SyntaxError: Unexpected token ';'"},
+		{"a * ;", "SyntaxError: Unexpected token ';'"},
+		{"a ? : b", "SyntaxError: Unexpected token ':'"},
+		{"a, ;", "SyntaxError: Unexpected token ';'"},
+		{"-a ** b", "SyntaxError: Unary operator used immediately before exponentiation expression. Parenthesis must be used to disambiguate operator precedence"},
+		{"typeof a ** b", "SyntaxError: Unary operator used immediately before exponentiation expression. Parenthesis must be used to disambiguate operator precedence"},
+		{"a ?? b || c", "SyntaxError: Unexpected token '||'"},
+		{"a || b ?? c", "SyntaxError: Unexpected token '??'"},
+		{"a ?? b && c", "SyntaxError: Unexpected token '??'"},
+		{"++;", "SyntaxError: Unexpected token ';'"},
+		{"1++;", "SyntaxError: Invalid left-hand side expression in update operation"},
+	}
+
+	for _, test := range tests {
+		_, err := New(lexer.New([]byte(test.input))).ParseProgram()
+		a.NotNilNow(err, test.input)
+		a.EqualNow(errorMessage(err), test.expected, test.input)
+	}
+}
+
+func TestParseLongBinaryChain(t *testing.T) {
+	a := assert.New(t)
+
+	program := parseProgram(a, longExpression(10000, " + "))
+	expr := program.Statements[0].(*ast.ExpressionStatement).Expression
+	depth := 0
+	for {
+		binary, ok := expr.(*ast.BinaryExpression)
+		if !ok {
+			break
+		}
+		_, ok = binary.Right.(*ast.Identifier)
+		a.TrueNow(ok)
+		expr = binary.Left
+		depth++
+	}
+	a.EqualNow(depth, 9999)
+}
+
+// longExpression returns an expression of n operands joined by op.
+func longExpression(n int, op string) string {
+	operands := make([]string, n)
+	for i := range operands {
+		operands[i] = "a" + strconv.Itoa(i)
+	}
+	return strings.Join(operands, op)
+}
+
+// benchmarkParse parses input, and reports the depth the parsing of binary
+// expressions recursed to as the depth metric.
+func benchmarkParse(b *testing.B, input string) {
+	src := []byte(input)
+	b.SetBytes(int64(len(src)))
+	b.ReportAllocs()
+	depth := 0
+	for i := 0; i < b.N; i++ {
+		p := New(lexer.New(src))
+		if _, err := p.ParseProgram(); err != nil {
+			b.Fatal(err)
+		}
+		depth = p.maxDepth
+	}
+	b.ReportMetric(float64(depth), "depth")
+}
+
+func TestParseBinaryDepth(t *testing.T) {
+	a := assert.New(t)
+
+	depth := func(input string) int {
+		p := New(lexer.New([]byte(input)))
+		_, err := p.ParseProgram()
+		a.NilNow(err)
+		return p.maxDepth
+	}
+
+	// The depth of left-associative chains does not grow with their length,
+	// and is at most one call per precedence level.
+	for _, op := range []string{" + ", " || ", " * ", " == "} {
+		a.EqualNow(depth(longExpression(10000, op)), depth(longExpression(2, op)), op)
+	}
+	a.TrueNow(depth(longExpression(10000, " + ")) <= 2)
+	mixed := depth("a || b && c | d ^ e & f == g < h << i + j * k ** l")
+	a.EqualNow(depth(strings.Repeat("a || b && c | d ^ e & f == g < h << i + j * k ** l || ", 1000)+"a"), mixed)
+
+	// The exponentiation operator is right-associative, so its chains
+	// recurse once per operator.
+	a.EqualNow(depth(longExpression(100, " ** ")), 100)
+}
+
+func BenchmarkParseAdditiveChain(b *testing.B) {
+	benchmarkParse(b, longExpression(1000, " + "))
+}
+
+func BenchmarkParseLogicalChain(b *testing.B) {
+	benchmarkParse(b, longExpression(1000, " || "))
+}
+
+func BenchmarkParseMixedOperators(b *testing.B) {
+	ops := []string{" + ", " * ", " < ", " && ", " | ", " == ", " - ", " << "}
+	buf := new(strings.Builder)
+	for i := 0; i < 1000; i++ {
+		if i > 0 {
+			buf.WriteString(ops[i%len(ops)])
+		}
+		buf.WriteString("a" + strconv.Itoa(i))
+	}
+	benchmarkParse(b, buf.String())
+}
+
+func BenchmarkParseExponentiationChain(b *testing.B) {
+	benchmarkParse(b, longExpression(1000, " ** "))
+}
+
+func TestParseEcmaVersion(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []struct {
+		input   string
+		version int
+		err     string
+	}{
+		{`var f = (a) => a;`, 5, "SyntaxError: Arrow function requires ES2015 or later, but ES5 is selected"},
+		{`let a = 1;`, 5, "SyntaxError: Lexical declaration requires ES2015 or later, but ES5 is selected"},
+		{`a = "" + ` + "`x`;", 5, "SyntaxError: Template literal requires ES2015 or later, but ES5 is selected"},
+		{`f(...a);`, 5, "SyntaxError: Spread element requires ES2015 or later, but ES5 is selected"},
+		{`function f(a = 1) {}`, 5, "SyntaxError: Default value requires ES2015 or later, but ES5 is selected"},
+		{`function f(...a) {}`, 5, "SyntaxError: Rest parameter requires ES2015 or later, but ES5 is selected"},
+		{`[a, b] = c;`, 5, "SyntaxError: Destructuring assignment requires ES2015 or later, but ES5 is selected"},
+		{`var o = { a };`, 5, "SyntaxError: Shorthand property requires ES2015 or later, but ES5 is selected"},
+		{`var o = { [a]: 1 };`, 5, "SyntaxError: Computed property name requires ES2015 or later, but ES5 is selected"},
+		{`function* g() {}`, 5, "SyntaxError: Generator function requires ES2015 or later, but ES5 is selected"},
+		{`for (a of b);`, 5, "SyntaxError: for-of loop requires ES2015 or later, but ES5 is selected"},
+		{`a ** 2;`, 2015, "SyntaxError: Operator '**' requires ES2016 or later, but ES2015 is selected"},
+		{`async function f() {}`, 2016, "SyntaxError: Async function requires ES2017 or later, but ES2016 is selected"},
+		{`async function* f() {}`, 2017, "SyntaxError: Async generator function requires ES2018 or later, but ES2017 is selected"},
+		{`var o = { ...a };`, 2017, "SyntaxError: Object spread property requires ES2018 or later, but ES2017 is selected"},
+		{`a ?? b;`, 2019, "SyntaxError: Operator '??' requires ES2020 or later, but ES2019 is selected"},
+		{`import("a");`, 2019, "SyntaxError: Dynamic import requires ES2020 or later, but ES2019 is selected"},
+		{`a ||= b;`, 2020, "SyntaxError: Operator '||=' requires ES2021 or later, but ES2020 is selected"},
+		{`a ??= b;`, 11, "SyntaxError: Operator '??=' requires ES2021 or later, but ES2020 is selected"},
+	}
+
+	for _, test := range tests {
+		p := NewWithOptions(lexer.New([]byte(test.input)), Options{EcmaVersion: test.version})
+		_, err := p.ParseProgram()
+		a.NotNilNow(err)
+		a.EqualNow(errorMessage(err), test.err)
+	}
+
+	for _, input := range []string{
+		`var a = [1, 2]; function f(b) { return a.length + b; }`,
+		`var o = { get a() { return 1; }, set a(v) {} };`,
+	} {
+		p := NewWithOptions(lexer.New([]byte(input)), Options{EcmaVersion: 5})
+		_, err := p.ParseProgram()
+		a.NilNow(err)
+	}
+
+	p := NewWithOptions(lexer.New([]byte(`a ?? b; a ||= b;`)), Options{EcmaVersion: 12})
+	_, err := p.ParseProgram()
+	a.NilNow(err)
+}
+
+func TestParseEcmaVersionNumber(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []struct {
+		version int
+		err     string
+	}{
+		{5, "SyntaxError: Lexical declaration requires ES2015 or later, but ES5 is selected"},
+		{6, ""},
+		{16, ""},
+		{17, "SyntaxError: Lexical declaration requires ES2015 or later, but ES5 is selected"},
+		{2011, "SyntaxError: Lexical declaration requires ES2015 or later, but ES5 is selected"},
+		{2015, ""},
+		{3000, ""},
+	}
+
+	for _, test := range tests {
+		p := NewWithOptions(lexer.New([]byte(`let a;`)), Options{EcmaVersion: test.version})
+		_, err := p.ParseProgram()
+		if test.err == "" {
+			a.NilNow(err, test.version)
+		} else {
+			a.NotNilNow(err, test.version)
+			a.EqualNow(errorMessage(err), test.err, test.version)
+		}
+	}
+	a.EqualNow(NewWithOptions(lexer.New(nil), Options{EcmaVersion: 16}).opts.EcmaVersion, 2025)
+	a.EqualNow(NewWithOptions(lexer.New(nil), Options{EcmaVersion: 3000}).opts.EcmaVersion, LatestEcmaVersion)
+}
+
+func TestParseModuleEcmaVersion(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []struct {
+		input   string
+		version int
+		err     string
+	}{
+		{`import a from "a";`, 5, "SyntaxError: Module requires ES2015 or later, but ES5 is selected"},
+		{`export * as a from "a";`, 2019, "SyntaxError: Namespace export requires ES2020 or later, but ES2019 is selected"},
+		{`a = import.meta;`, 2019, "SyntaxError: import.meta requires ES2020 or later, but ES2019 is selected"},
+		{`await a;`, 2021, "SyntaxError: Unexpected reserved word"},
+		{`import a from "a" with { type: "json" };`, 2024, "SyntaxError: Import attributes requires ES2025 or later, but ES2024 is selected"},
+	}
+
+	for _, test := range tests {
+		p := NewWithOptions(lexer.New([]byte(test.input)), Options{
+			EcmaVersion: test.version,
+			SourceType:  ast.SourceModule,
+		})
+		_, err := p.Parse()
+		a.NotNilNow(err)
+		a.EqualNow(errorMessage(err), test.err)
+	}
+
+	p := NewWithOptions(lexer.New([]byte(`await a;`)), Options{EcmaVersion: 2022, SourceType: ast.SourceModule})
+	program, err := p.Parse()
+	a.NilNow(err)
+	a.EqualNow(program.SourceType, ast.SourceModule)
+}
+
+func TestParseAllowReturnOutsideFunction(t *testing.T) {
+	a := assert.New(t)
+
+	p := New(lexer.New([]byte(`return 1;`)))
+	_, err := p.ParseProgram()
+	a.NotNilNow(err)
+	a.EqualNow(errorMessage(err), "SyntaxError: Illegal return statement")
+
+	p = NewWithOptions(lexer.New([]byte(`if (a) return 1;`)), Options{AllowReturnOutsideFunction: true})
+	program, err := p.ParseProgram()
+	a.NilNow(err)
+	_, ok := program.Statements[0].(*ast.IfStatement).TrueBranch.(*ast.ReturnStatement)
+	a.TrueNow(ok)
+}
+
+func TestParseHashBang(t *testing.T) {
+	a := assert.New(t)
+
+	input := "#!/usr/bin/env gjs\nvar a = 1;"
+
+	p := New(lexer.New([]byte(input)))
+	program, err := p.ParseProgram()
+	a.NilNow(err)
+	a.EqualNow(len(program.Statements), 1)
+	a.NotNilNow(program.HashBang)
+	a.EqualNow(program.HashBang.Literal, "/usr/bin/env gjs")
+	a.EqualNow(program.HashBang.Offset, 0)
+
+	program = parseProgram(a, "var a = 1;")
+	a.TrueNow(program.HashBang == nil)
+
+	p = NewWithOptions(lexer.New([]byte(input)), Options{EcmaVersion: 2022})
+	_, err = p.ParseProgram()
+	a.NotNilNow(err)
+	a.EqualNow(errorMessage(err), "SyntaxError: Hashbang comment requires ES2023 or later, but ES2022 is selected")
+
+	p = NewWithOptions(lexer.New([]byte(input)), Options{EcmaVersion: 2022, AllowHashBang: true})
+	_, err = p.ParseProgram()
+	a.NilNow(err)
+
+	p = New(lexer.New([]byte("var a = 1;\n#!/usr/bin/env gjs")))
+	_, err = p.ParseProgram()
+	a.NotNilNow(err)
+	a.EqualNow(errorMessage(err), "SyntaxError: Unexpected token '#!'")
+
+	p = New(lexer.New([]byte(" #!/usr/bin/env gjs")))
+	_, err = p.ParseProgram()
+	a.NotNilNow(err)
+}
+
+func TestParseProgramMetadata(t *testing.T) {
+	a := assert.New(t)
+
+	input := "#!/usr/bin/env gjs\n'use strict';\n\"a\\x62\"; f();\n\"c\";"
+	p := NewWithOptions(lexer.New([]byte(input)), Options{FileName: "main.js"})
+	program, err := p.ParseProgram()
+	a.NilNow(err)
+	a.EqualNow(program.FileName, "main.js")
+	a.EqualNow(program.SourceType, ast.SourceScript)
+	a.TrueNow(program.Strict)
+	a.DeepEqualNow(program.Directives, []string{"use strict", "a\\x62"})
+
+	p = NewWithOptions(lexer.New([]byte("export {};")), Options{SourceType: ast.SourceModule})
+	program, err = p.Parse()
+	a.NilNow(err)
+	a.EqualNow(program.SourceType, ast.SourceModule)
+	a.EqualNow(program.FileName, "")
+	a.DeepEqualNow(program.Directives, []string{})
+}
+
+func TestParseAnnexB(t *testing.T) {
+	a := assert.New(t)
+
+	for _, input := range []string{
+		`if (a) function f() {}`,
+		`if (a) b(); else function f() {}`,
+		`l: function f() {}`,
+	} {
+		p := New(lexer.New([]byte(input)))
+		_, err := p.ParseProgram()
+		a.NilNow(err)
+	}
+
+	tests := []struct {
+		input string
+		opts  Options
+		err   string
+	}{
+		{`if (a) function f() {}`, Options{DisableAnnexB: true}, "SyntaxError: Functions can only be declared at top level or inside a block."},
+		{`l: function f() {}`, Options{DisableAnnexB: true}, "SyntaxError: Functions can only be declared at top level or inside a block."},
+		{`"use strict"; if (a) function f() {}`, Options{}, "SyntaxError: In strict mode code, functions can only be declared at top level or inside a block."},
+		{`while (a) function f() {}`, Options{}, "SyntaxError: In non-strict mode code, functions can only be declared at top level, inside a block, or as the body of an if statement."},
+		{`while (a) l: function f() {}`, Options{}, "SyntaxError: In non-strict mode code, functions can only be declared at top level, inside a block, or as the body of an if statement."},
+		{`if (a) async function f() {}`, Options{}, "SyntaxError: In non-strict mode code, functions can only be declared at top level, inside a block, or as the body of an if statement."},
+		{`for (;;) let a = 1;`, Options{}, "SyntaxError: Lexical declaration cannot appear in a single-statement context"},
+	}
+
+	for _, test := range tests {
+		p := NewWithOptions(lexer.New([]byte(test.input)), test.opts)
+		_, err := p.ParseProgram()
+		a.NotNilNow(err)
+		a.EqualNow(errorMessage(err), test.err)
+	}
+}
+
+func TestParseExpression(t *testing.T) {
+	a := assert.New(t)
+
+	expr, err := ParseExpression(" price * qty > 100 /* total */ ")
+	a.NilNow(err)
+	binary, ok := expr.(*ast.BinaryExpression)
+	a.TrueNow(ok)
+	a.EqualNow(binary.Operator.TokenType, token.TOKEN_GREATER)
+	a.EqualNow(groupBinary(expr), "((price * qty) > 100)")
+
+	expr, err = ParseExpression("a = 1, b")
+	a.NilNow(err)
+	_, ok = expr.(*ast.SequenceExpression)
+	a.TrueNow(ok)
+
+	expr, err = ParseExpression("{ a: 1 }")
+	a.NilNow(err)
+	_, ok = expr.(*ast.ObjectLiteral)
+	a.TrueNow(ok)
+
+	p := NewWithOptions(lexer.New([]byte("a ?? b")), Options{EcmaVersion: 2019})
+	_, err = p.ParseExpression()
+	a.NotNilNow(err)
+	a.EqualNow(errorMessage(err), "SyntaxError: Operator '??' requires ES2020 or later, but ES2019 is selected")
+}
+
+func TestParseExpressionErrors(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{"", "SyntaxError: Unexpected end of input"},
+		{"  // comment", "SyntaxError: Unexpected end of input"},
+		{"a b", "SyntaxError: Unexpected identifier 'b'"},
+		{"a;", "SyntaxError: Unexpected token ';'"},
+		{"a; b", "SyntaxError: Unexpected token ';'"},
+		{"a +", "SyntaxError: Unexpected end of input"},
+		{"var a", "SyntaxError: Unexpected token 'var'"},
+		{"1 = a", "SyntaxError: Invalid left-hand side in assignment"},
+	}
+
+	for _, test := range tests {
+		_, err := ParseExpression(test.input)
+		a.NotNilNow(err)
+		a.EqualNow(errorMessage(err), test.err)
+	}
+}
+
+// reparse applies an edit replacing the bytes from start to end of src with
+// text, and returns the new source with the program reparsed from program.
+func parseJSX(a *assert.Assertion, input string) ast.Expression {
+	program, err := NewWithOptions(lexer.New([]byte(input)), Options{JSX: true}).Parse()
+	a.NilNow(err, input)
+	return program.Statements[0].(*ast.ExpressionStatement).Expression
+}
+
+func TestParseJSX(t *testing.T) {
+	a := assert.New(t)
+
+	elem, ok := parseJSX(a, `<div className="a &amp; b" data-id={id} {...rest} hidden>
+  Hello &lt;{name}&gt;{/* comment */}<br />
+</div>;`).(*ast.JSXElement)
+	a.TrueNow(ok)
+	a.EqualNow(elem.OpeningElement.Name.String(), "div")
+	a.EqualNow(elem.ClosingElement.Name.String(), "div")
+	a.NotTrueNow(elem.OpeningElement.SelfClosing)
+
+	attrs := elem.OpeningElement.Attributes
+	a.EqualNow(len(attrs), 4)
+	a.EqualNow(attrs[0].(*ast.JSXAttribute).Value.(*ast.Literal).Value, "a & b")
+	a.EqualNow(attrs[1].(*ast.JSXAttribute).Name.String(), "data-id")
+	a.EqualNow(attrs[1].(*ast.JSXAttribute).Value.(*ast.JSXExpressionContainer).Expression.String(), "id")
+	a.EqualNow(attrs[2].(*ast.JSXSpreadAttribute).Argument.String(), "rest")
+	a.NilNow(attrs[3].(*ast.JSXAttribute).Value)
+
+	children := elem.Children
+	a.EqualNow(len(children), 6)
+	a.EqualNow(children[0].(*ast.JSXText).Value, "\n  Hello <")
+	a.EqualNow(children[1].(*ast.JSXExpressionContainer).Expression.String(), "name")
+	a.EqualNow(children[2].(*ast.JSXText).Value, ">")
+	_, ok = children[3].(*ast.JSXExpressionContainer).Expression.(*ast.JSXEmptyExpression)
+	a.TrueNow(ok)
+	a.TrueNow(children[4].(*ast.JSXElement).OpeningElement.SelfClosing)
+	a.NilNow(children[4].(*ast.JSXElement).ClosingElement)
+	a.EqualNow(children[5].(*ast.JSXText).Value, "\n")
+
+	a.EqualNow(elem.Pos(), token.Position{Offset: 0, Line: 1, Col: 1})
+	a.EqualNow(elem.End(), token.Position{Offset: 108, Line: 3, Col: 7})
+}
+
+func TestParseJSXNames(t *testing.T) {
+	a := assert.New(t)
+
+	expr := parseJSX(a, `<Foo.Bar.Baz></Foo.Bar.Baz>;`)
+	name := expr.(*ast.JSXElement).OpeningElement.Name.(*ast.JSXMemberExpression)
+	a.EqualNow(name.Property.Name, "Baz")
+	a.EqualNow(name.Object.String(), "Foo.Bar")
+
+	expr = parseJSX(a, `<svg:rect xlink:href="#a" aria-hidden />;`)
+	opening := expr.(*ast.JSXElement).OpeningElement
+	a.EqualNow(opening.Name.(*ast.JSXNamespacedName).Namespace.Name, "svg")
+	a.EqualNow(opening.Attributes[0].(*ast.JSXAttribute).Name.String(), "xlink:href")
+	a.EqualNow(opening.Attributes[1].(*ast.JSXAttribute).Name.String(), "aria-hidden")
+
+	expr = parseJSX(a, `<my-element class="a"></my-element>;`)
+	a.EqualNow(expr.(*ast.JSXElement).OpeningElement.Name.String(), "my-element")
+}
+
+func TestParseJSXFragment(t *testing.T) {
+	a := assert.New(t)
+
+	frag, ok := parseJSX(a, `<><a>x</a>{...items}</>;`).(*ast.JSXFragment)
+	a.TrueNow(ok)
+	a.EqualNow(len(frag.Children), 2)
+	_, ok = frag.Children[0].(*ast.JSXElement)
+	a.TrueNow(ok)
+	a.EqualNow(frag.Children[1].(*ast.JSXSpreadChild).Expression.String(), "items")
+}
+
+func TestParseJSXText(t *testing.T) {
+	a := assert.New(t)
+
+	// The text and the strings of JSX are not scanned as tokens.
+	expr := parseJSX(a, `<p title='it"s
+two lines'>don't // stop</p>;`)
+	elem := expr.(*ast.JSXElement)
+	a.EqualNow(elem.OpeningElement.Attributes[0].(*ast.JSXAttribute).Value.(*ast.Literal).Value, "it\"s\ntwo lines")
+	a.EqualNow(elem.Children[0].(*ast.JSXText).Value, "don't // stop")
+
+	// The > that ends a tag may be scanned with the following characters.
+	expr = parseJSX(a, `x = <a>{1}</a>>=2;`)
+	cmp, ok := expr.(*ast.AssignmentExpression).Right.(*ast.BinaryExpression)
+	a.TrueNow(ok)
+	a.EqualNow(cmp.Operator.TokenType, token.TOKEN_GREATER_EQUAL)
+
+	expr = parseJSX(a, `f(<a />, () => <b>{c ? <d /> : null}</b>);`)
+	a.EqualNow(len(expr.(*ast.CallExpression).Arguments), 2)
+}
+
+func TestParseJSXErrors(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"<a></b>;", "SyntaxError: Expected corresponding JSX closing tag for <a>"},
+		{"<a.b></a>;", "SyntaxError: Expected corresponding JSX closing tag for <a.b>"},
+		{"<></a>;", "SyntaxError: Expected corresponding closing tag for JSX fragment"},
+		{"<a>", "SyntaxError: Unexpected end of input"},
+		{"<a>x > y</a>;", "SyntaxError: Unexpected token '>'"},
+		{"<a b={} />;", "SyntaxError: JSX attributes must only be assigned a non-empty expression"},
+		{"<a b=1 />;", "SyntaxError: JSX value should be either an expression or a quoted JSX text"},
+		{"<a>{a, b}</a>;", "SyntaxError: Unexpected token ',', expected '}'"},
+		{"<a b={() => { break; }} />;", "SyntaxError: Illegal break statement"},
+		{"<a>{a++ = 1}</a>;", "SyntaxError: Invalid left-hand side in assignment"},
+	}
+
+	for _, test := range tests {
+		_, err := NewWithOptions(lexer.New([]byte(test.input)), Options{JSX: true}).Parse()
+		a.NotNilNow(err, test.input)
+		a.EqualNow(errorMessage(err), test.expected, test.input)
+	}
+
+	// JSX is not parsed unless it is enabled.
+	_, err := New(lexer.New([]byte("<a />;"))).ParseProgram()
+	a.EqualNow(errorMessage(err), "SyntaxError: Unexpected token '<'")
+}
+
+func parseTypeScript(a *assert.Assertion, input string, sourceType ast.SourceType) string {
+	program, err := NewWithOptions(lexer.New([]byte(input)), Options{TypeScript: true, SourceType: sourceType}).Parse()
+	a.NilNow(err, input)
+	stmts := make([]string, 0, len(program.Statements))
+	for _, stmt := range program.Statements {
+		stmts = append(stmts, stmt.String())
+	}
+	return strings.Join(stmts, "\n")
+}
+
+func TestParseTypeScript(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let a: number = 1, b!: string;", "let a = 1, b;"},
+		{"let f: (x: number) => void, u: typeof a | null;", "let f, u;"},
+		{"let v: Array<Map<string, number[]>> = [];", "let v = [];"},
+		{"function f<T extends object = {}>(this: Window, x?: T, y: T = x, ...z: T[]): x is T {}", "function f(x, y = x, ...z) {\n}"},
+		{"function g(x): asserts x is string {}", "function g(x) {\n}"},
+		{"function h(a: string): string;\nfunction h(a: any) { return a; }", "function h(a) {\nreturn a;\n}"},
+		{"let i = (x: number): Promise<void> => x;", "let i = (x) => x;"},
+		{"let j = async (x: number): Promise<void> => x;", "let j = async (x) => x;"},
+		{"let k = <T,>(x: T): T => x;", "let k = (x) => x;"},
+		{"let l = <number>a;", "let l = a;"},
+		{"let m = (a as any)!.b! satisfies T;", "let m = a.b;"},
+		{"let n = f<string, number>(1);", "let n = f(1);"},
+		{"let o = { m<T>(x: T): T { return x; } };", "let o = {m(x) {\nreturn x;\n}};"},
+		{"let p = a < b, q = a > (b), r = c ? (d) : e;", "let p = a < b, q = a > b, r = c ? d : e;"},
+		{"interface I extends J<number> { a: string; b?(x: number): void }", ""},
+		{"type T<K extends string = \"a\"> = { [P in K]: number } | null;", ""},
+		{"type F = new (...args: any[]) => unknown;", ""},
+		{"type C<T> = T extends (infer U)[] ? U : never;", ""},
+		{"declare const x: number;\ndeclare function f(): void;\ndeclare module \"m\" { export const y: string }", ""},
+	}
+
+	for _, test := range tests {
+		a.EqualNow(parseTypeScript(a, test.input, ast.SourceScript), test.expected, test.input)
+	}
+}
+
+func TestParseTypeScriptEnum(t *testing.T) {
+	a := assert.New(t)
+
+	a.EqualNow(parseTypeScript(a, `enum E { A, B = 5, C, "D" = "d" }`, ast.SourceScript), `var E = function(E) {
+var A = 0;
+E[E[A] = A] = A;
+var B = 5;
+E[E[B] = B] = B;
+var C = 6;
+E[E[C] = C] = C;
+E[D] = d;
+return E;
+}({});`)
+
+	// A member after a computed one is incremented from it, and a merged enum
+	// extends the object of the first declaration.
+	a.EqualNow(parseTypeScript(a, "const enum F { X = 1 << 2, Y }\nenum F { Z = 9 }", ast.SourceScript), `var F = function(F) {
+var X = 1 << 2;
+F[F[X] = X] = X;
+var Y = F[X] + 1;
+F[F[Y] = Y] = Y;
+return F;
+}({});
+var F = function(F) {
+var Z = 9;
+F[F[Z] = Z] = Z;
+return F;
+}(F);`)
+}
+
+func TestParseTypeScriptModule(t *testing.T) {
+	a := assert.New(t)
+
+	a.EqualNow(parseTypeScript(a, `import type { A } from "a";
+import type B from "b";
+import type * as C from "c";
+import { type D, e, type as f } from "d";
+import type from "t";
+export type { A };
+export { type D, e as g };
+export type X = number;
+export interface Y { y: number }
+export declare const v: number;
+export function o(a: string): string;
+export function o(a: any) { return a; }
+export default interface I {}`, ast.SourceModule), `import { e, type as f } from "d";
+import type from "t";
+export { e as g };
+export function o(a) {
+return a;
+}`)
+
+	a.EqualNow(parseTypeScript(a, "export enum Z { Q }", ast.SourceModule), `export var Z = function(Z) {
+var Q = 0;
+Z[Z[Q] = Q] = Q;
+return Z;
+}({});`)
+}
+
+func TestParseTypeScriptErrors(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`enum E { A = "a", B }`, "SyntaxError: Enum member must have initializer"},
+		{"enum E { 1 }", "SyntaxError: An enum member name must be an identifier or a string literal"},
+		{"let f = function (): void;", "SyntaxError: Function implementation is missing or not immediately following the declaration."},
+		{"let o = { m(): void; };", "SyntaxError: Function implementation is missing or not immediately following the declaration."},
+		{"if (x) interface I {}", "SyntaxError: Unexpected identifier 'interface'"},
+		{"let x: = 1;", "SyntaxError: Unexpected token '='"},
+		{"type T = { a: number;", "SyntaxError: Unexpected end of input"},
+	}
+
+	for _, test := range tests {
+		_, err := NewWithOptions(lexer.New([]byte(test.input)), Options{TypeScript: true}).Parse()
+		a.NotNilNow(err, test.input)
+		a.EqualNow(errorMessage(err), test.expected, test.input)
+	}
+
+	// Types are not parsed unless TypeScript is enabled.
+	_, err := New(lexer.New([]byte("let a: number;"))).ParseProgram()
+	a.EqualNow(errorMessage(err), "SyntaxError: Unexpected token ':'")
+}
+
+func reparse(program *ast.Program, src string, start, end int, text string, opts Options) (string, *ast.Program, error) {
+	newSrc := src[:start] + text + src[end:]
+	edit := Edit{Start: start, OldEnd: end, NewEnd: start + len(text)}
+	reparsed, err := Reparse(program, []byte(newSrc), edit, opts)
+	return newSrc, reparsed, err
+}
+
+func TestReparse(t *testing.T) {
+	a := assert.New(t)
+
+	src := "var a = 1;\nlet b = a + 2\nfunction f(x) {\n  return x * 2;\n}\n/* é */ c = f(b); d = [1, 2]\nif (c) {\n  d = c;\n}\n"
+	tests := []struct {
+		at   string
+		del  int
+		text string
+	}{
+		{"1;", 1, "10"},
+		{"2\n", 0, " * 3"},
+		{"\nfunction", 0, "\n(b)"},
+		{"\nfunction", 0, "\n+ c"},
+		{"x * 2", 5, "x ** 2"},
+		{"c = f(b)", 0, "\n\n"},
+		{"é", 2, "ü and more\nlines"},
+		{" d = [1, 2]", 0, " /*"},
+		{"d = c;", 6, ""},
+		{"}\n/*", 2, ""},
+		{"if (c)", 0, "e; "},
+		{"", 0, "g;\n"},
+		{"var a", 3, "const"},
+	}
+
+	for _, test := range tests {
+		program, err := New(lexer.New([]byte(src))).ParseProgram()
+		a.NilNow(err)
+
+		start := strings.Index(src, test.at)
+		a.TrueNow(start >= 0)
+		newSrc, reparsed, err := reparse(program, src, start, start+test.del, test.text, Options{})
+
+		expected, expectedErr := New(lexer.New([]byte(newSrc))).ParseProgram()
+		if expectedErr != nil {
+			a.NotNilNow(err)
+			a.EqualNow(err.Error(), expectedErr.Error())
+			continue
+		}
+		a.NilNow(err)
+		a.DeepEqualNow(reparsed, expected)
+	}
+}
+
+func TestReparseReusesStatements(t *testing.T) {
+	a := assert.New(t)
+
+	src := "a = 1;\nb = 2;\nc = 3;\nd = 4; e = 5;\n"
+	program, err := New(lexer.New([]byte(src))).ParseProgram()
+	a.NilNow(err)
+	old := append([]ast.Statement{}, program.Statements...)
+
+	start := strings.Index(src, "3")
+	_, reparsed, err := reparse(program, src, start, start+1, "30 + 1", Options{})
+	a.NilNow(err)
+	a.EqualNow(len(reparsed.Statements), 5)
+	a.TrueNow(reparsed.Statements[0] == old[0])
+	a.TrueNow(reparsed.Statements[1] != old[1])
+	a.TrueNow(reparsed.Statements[2] != old[2])
+	a.TrueNow(reparsed.Statements[3] == old[3])
+	a.TrueNow(reparsed.Statements[4] == old[4])
+	a.EqualNow(reparsed.Statements[4].Pos(), token.Position{Offset: 33, Line: 4, Col: 8})
+}
+
+func TestReparseModule(t *testing.T) {
+	a := assert.New(t)
+
+	opts := Options{SourceType: ast.SourceModule, FileName: "m.js"}
+	src := "import a from \"a\";\nexport const b = await a;\nexport default b;\n"
+	for _, test := range []struct {
+		at   string
+		text string
+	}{
+		{"await a", "1 + "},
+		{"export default", "let c = b;\n"},
+		{"import", "'use strict';\n"},
+		{"b;\n", "c"},
+	} {
+		p := NewWithOptions(lexer.New([]byte(src)), opts)
+		program, err := p.Parse()
+		a.NilNow(err)
+
+		start := strings.Index(src, test.at)
+		newSrc, reparsed, err := reparse(program, src, start, start, test.text, opts)
+
+		expected, expectedErr := NewWithOptions(lexer.New([]byte(newSrc)), opts).Parse()
+		if expectedErr != nil {
+			a.NotNilNow(err)
+			a.EqualNow(err.Error(), expectedErr.Error())
+			continue
+		}
+		a.NilNow(err)
+		a.DeepEqualNow(reparsed, expected)
+	}
+}
+
+func TestReparseComments(t *testing.T) {
+	a := assert.New(t)
+
+	opts := Options{Comments: true}
+	src := "// a\na = 1; /* b */\nb = 2;\n// c\nc = 3; // d\nd = 4;\n"
+	for _, test := range []struct {
+		at   string
+		del  int
+		text string
+	}{
+		{"2;", 1, "20"},
+		{"3;", 1, "30 /* e */ + 1"},
+		{"// c", 4, ""},
+		{"b = 2", 0, "/* f */\n"},
+		{"d = 4", 0, "e;\n"},
+	} {
+		program, err := NewWithOptions(lexer.New([]byte(src)), opts).Parse()
+		a.NilNow(err)
+		a.EqualNow(len(program.Comments), 4)
+
+		start := strings.Index(src, test.at)
+		newSrc, reparsed, err := reparse(program, src, start, start+test.del, test.text, opts)
+		a.NilNow(err)
+		expected, err := NewWithOptions(lexer.New([]byte(newSrc)), opts).Parse()
+		a.NilNow(err)
+		a.DeepEqualNow(reparsed, expected, newSrc)
+	}
+}
+
+func TestReparseEnums(t *testing.T) {
+	a := assert.New(t)
+
+	opts := Options{TypeScript: true}
+	for _, test := range []struct {
+		src  string
+		at   string
+		text string
+	}{
+		// The enum E after the edit is reparsed with the enum E before it.
+		{"enum E { A }\na = 1;\nb = 2;\nenum E { B = 1 }\n", "2;", "3 + "},
+		// The enum E after the edit is reused, but the edit declares E.
+		{"a = 1;\nb = 2;\nc = 3;\nenum E { B = 1 }\n", "b = 2", "enum E { A }\n"},
+		{"enum E { A }\nvar F = function () {}();\nb = 2;\nc = 3;\n", "3;", "E.A + "},
+	} {
+		program, err := NewWithOptions(lexer.New([]byte(test.src)), opts).Parse()
+		a.NilNow(err)
+
+		start := strings.Index(test.src, test.at)
+		newSrc, reparsed, err := reparse(program, test.src, start, start, test.text, opts)
+		a.NilNow(err)
+		expected, err := NewWithOptions(lexer.New([]byte(newSrc)), opts).Parse()
+		a.NilNow(err)
+		a.DeepEqualNow(reparsed, expected, newSrc)
+	}
+}
+
+func TestReparseInvalidEdit(t *testing.T) {
+	a := assert.New(t)
+
+	program := parseProgram(a, "a;")
+	_, err := Reparse(program, []byte("a;"), Edit{Start: 1, OldEnd: 0, NewEnd: 1}, Options{})
+	a.NotNilNow(err)
+	_, err = Reparse(program, []byte("a;"), Edit{Start: 0, OldEnd: 2, NewEnd: 3}, Options{})
+	a.NotNilNow(err)
+}
+
+func BenchmarkReparse(b *testing.B) {
+	var buf strings.Builder
+	for i := 0; i < 1000; i++ {
+		buf.WriteString("function f" + strconv.Itoa(i) + "(a, b) {\n  return a * b + " + strconv.Itoa(i) + ";\n}\n")
+	}
+	src := buf.String()
+	start := strings.Index(src, "+ 500;") + 2
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		program, err := New(lexer.New([]byte(src))).ParseProgram()
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.StartTimer()
+
+		if _, _, err := reparse(program, src, start, start+3, "1000", Options{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package parser
+
+import (
+	"errors"
+
+	"github.com/ghosind/gjs/ast"
+	lexerrors "github.com/ghosind/gjs/errors"
+	"github.com/ghosind/gjs/token"
+)
+
+// ParseProgramTolerant parses the source as a script like ParseProgram, but
+// does not stop at the first syntax error. The statements and expressions that
+// fail to parse are replaced by ast.BadStatement and ast.BadExpression nodes,
+// and the errors are returned alongside the partial program.
+func (p *Parser) ParseProgramTolerant() (*ast.Program, ErrorList) {
+	p.tolerant = true
+	program, _ := p.ParseProgram()
+	p.errs.Sort()
+	return program, p.errs
+}
+
+// ParseModuleTolerant parses the source as a module like ParseModule, and
+// recovers from syntax errors like ParseProgramTolerant.
+func (p *Parser) ParseModuleTolerant() (*ast.Program, ErrorList) {
+	p.tolerant = true
+	program, _ := p.ParseModule()
+	p.errs.Sort()
+	return program, p.errs
+}
+
+// errAborted is returned in tolerant mode after an error that cannot be
+// recovered from is recorded.
+var errAborted = errors.New("parsing aborted")
+
+// report records err as a diagnostic of the source. An error at the same
+// position as the last recorded one is dropped, as it is usually caused by the
+// same mistake.
+func (p *Parser) report(err error) {
+	var syntaxErr *SyntaxError
+	var list ErrorList
+	switch {
+	case errors.As(err, &list):
+		p.errs = append(p.errs, list...)
+		return
+	case errors.As(err, &syntaxErr):
+	case isLexerError(err):
+		syntaxErr = &SyntaxError{tok: p.current(), msg: "Invalid or unexpected token"}
+	default:
+		syntaxErr = &SyntaxError{tok: p.current(), msg: err.Error()}
+	}
+
+	if n := len(p.errs); n > 0 && syntaxErr.tok != nil && p.errs[n-1].tok != nil &&
+		p.errs[n-1].Line() == syntaxErr.Line() && p.errs[n-1].Column() == syntaxErr.Column() {
+		return
+	}
+	p.errs = append(p.errs, syntaxErr)
+}
+
+// isLexerError reports whether err is raised by the lexer. The token stream
+// cannot be recovered after a lexer error.
+func isLexerError(err error) bool {
+	var lexErr *lexerrors.LexerError
+	return errors.As(err, &lexErr)
+}
+
+// recoverStatement handles the error of a statement starting at start. In
+// tolerant mode, it records the error and skips to the next statement, and
+// returns a placeholder of the statement. Otherwise, or if the error cannot
+// be recovered, it returns the error.
+func (p *Parser) recoverStatement(start *token.Token, err error) (ast.Statement, error) {
+	if abortErr := p.abort(err); abortErr != nil {
+		return nil, abortErr
+	} else if p.isEnd() && p.current() == start {
+		p.report(err)
+		return nil, errAborted
+	}
+
+	p.report(err)
+	last := p.synchronize(start)
+	return &ast.BadStatement{From: *start, To: *last}, nil
+}
+
+// recoverExpression handles the error of an expression starting at start. In
+// tolerant mode, it records the error and skips to the first of the closing
+// tokens outside of nested brackets, and returns a placeholder of the
+// expression. Otherwise, or if the error cannot be recovered, it returns the
+// error.
+func (p *Parser) recoverExpression(start *token.Token, err error, closing ...token.TokenType) (ast.Expression, error) {
+	if abortErr := p.abort(err); abortErr != nil {
+		return nil, abortErr
+	}
+
+	p.report(err)
+	last := start
+	depth := 0
+	for !p.isEnd() {
+		tok := p.current()
+		if depth == 0 && isOneOf(tok, closing...) {
+			break
+		}
+		switch tok.TokenType {
+		case token.TOKEN_LEFT_PAREN, token.TOKEN_LEFT_BRACKET, token.TOKEN_LEFT_BRACE:
+			depth++
+		case token.TOKEN_RIGHT_PAREN, token.TOKEN_RIGHT_BRACKET, token.TOKEN_RIGHT_BRACE:
+			if depth == 0 {
+				return &ast.BadExpression{From: *start, To: *last}, nil
+			}
+			depth--
+		}
+		if p.advance() == nil {
+			p.report(p.err)
+			return nil, errAborted
+		}
+		if !isTrivia(tok) {
+			last = tok
+		}
+	}
+
+	return &ast.BadExpression{From: *start, To: *last}, nil
+}
+
+// abort returns the error to stop parsing with, or nil if err can be
+// recovered from. Unrecoverable errors are recorded in tolerant mode and
+// replaced by errAborted.
+func (p *Parser) abort(err error) error {
+	switch {
+	case !p.tolerant || err == errAborted:
+		return err
+	case isLexerError(err) || p.isSyntaxError():
+		p.report(err)
+		return errAborted
+	}
+	return nil
+}
+
+// synchronize skips the tokens of a statement that failed to parse. It stops
+// after a semicolon, or before the closing brace of the enclosing block or a
+// statement keyword at the start of a line. It returns the last significant
+// token skipped.
+func (p *Parser) synchronize(start *token.Token) *token.Token {
+	last := start
+	depth := 0
+	for !p.isEnd() {
+		tok := p.current()
+		if tok != start && depth == 0 && p.newLine && startsStatement(tok) {
+			break
+		}
+		switch tok.TokenType {
+		case token.TOKEN_LEFT_BRACE:
+			depth++
+		case token.TOKEN_RIGHT_BRACE:
+			if depth == 0 && tok != start {
+				return last
+			} else if depth > 0 {
+				depth--
+			}
+		case token.TOKEN_SEMICOLON:
+			if depth == 0 {
+				p.advance()
+				return tok
+			}
+		}
+		if p.advance() == nil {
+			return last
+		}
+		if !isTrivia(tok) {
+			last = tok
+		}
+	}
+	return last
+}
+
+// startsStatement reports whether tok is a keyword that starts a statement.
+func startsStatement(tok *token.Token) bool {
+	return isOneOf(tok,
+		token.TOKEN_BREAK, token.TOKEN_CLASS, token.TOKEN_CONST, token.TOKEN_CONTINUE,
+		token.TOKEN_DEBUGGER, token.TOKEN_DO, token.TOKEN_EXPORT, token.TOKEN_FOR,
+		token.TOKEN_FUNCTION, token.TOKEN_IF, token.TOKEN_IMPORT, token.TOKEN_LET,
+		token.TOKEN_RETURN, token.TOKEN_SWITCH, token.TOKEN_THROW, token.TOKEN_TRY,
+		token.TOKEN_VAR, token.TOKEN_WHILE, token.TOKEN_WITH,
+	)
+}
+
+func isTrivia(tok *token.Token) bool {
+	return isOneOf(tok, token.TOKEN_SPACE, token.TOKEN_NEW_LINE,
+		token.TOKEN_SINGLE_LINE_COMMENT, token.TOKEN_MULTI_LINE_COMMENT)
+}
+
+func isOneOf(tok *token.Token, types ...token.TokenType) bool {
+	for _, tokType := range types {
+		if tok.TokenType == tokType {
+			return true
+		}
+	}
+	return false
+}
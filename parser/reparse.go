@@ -0,0 +1,258 @@
+package parser
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/lexer"
+	"github.com/ghosind/gjs/token"
+)
+
+// Edit describes a change of a source: the bytes from Start to OldEnd of the
+// old source are replaced by the bytes from Start to NewEnd of the new source.
+// The offsets are byte offsets.
+type Edit struct {
+	Start  int
+	OldEnd int
+	NewEnd int
+}
+
+// Reparse parses src, the source of program after edit, and returns the same
+// program as a full parse with opts would. Only the top-level statements
+// around the edit are lexed and parsed again. The statements before them are
+// reused as they are, and the statements after them are reused with their
+// positions moved, so program must not be used after Reparse returns.
+//
+// The source is parsed in full if the edit changes the hashbang or the
+// directive prologue of the program, or if a TypeScript enum declared after
+// the edit would be reused, as it is lowered depending on the enums declared
+// before it.
+func Reparse(program *ast.Program, src []byte, edit Edit, opts Options) (*ast.Program, error) {
+	if edit.Start < 0 || edit.Start > edit.OldEnd || edit.Start > edit.NewEnd || edit.NewEnd > len(src) {
+		return nil, fmt.Errorf("parser: invalid edit %+v of a source of %d bytes", edit, len(src))
+	}
+
+	stmts := program.Statements
+	// first is the first statement that ends at or after the start of the
+	// edit, which may be extended by it.
+	first := sort.Search(len(stmts), func(i int) bool {
+		return stmts[i].End().Offset >= edit.Start
+	})
+	if first <= len(program.Directives) {
+		return NewWithOptions(lexer.New(src), opts).Parse()
+	}
+
+	// The statement before the first changed one is parsed again too, as it
+	// may end by automatic semicolon insertion before a token that changed.
+	from := stmts[first-1].Pos()
+	p := NewWithOptions(lexer.NewAt(src, from), opts)
+	if program.SourceType == ast.SourceModule {
+		p.module = true
+		p.inAsync = p.opts.EcmaVersion >= 2022
+	}
+	p.strict = program.Strict
+	for _, stmt := range stmts[:first-1] {
+		if name, ok := enumName(stmt); ok {
+			if p.enums == nil {
+				p.enums = make(map[string]bool)
+			}
+			p.enums[name] = true
+		}
+	}
+
+	reparsed := &ast.Program{
+		EndToken:   program.EndToken,
+		HashBang:   program.HashBang,
+		Statements: append(make([]ast.Statement, 0, len(stmts)), stmts[:first-1]...),
+		SourceType: program.SourceType,
+		Strict:     program.Strict,
+		Directives: program.Directives,
+		FileName:   opts.FileName,
+	}
+	if p.opts.Comments {
+		// The comments before the statements parsed again are kept.
+		for _, comment := range program.Comments {
+			if comment.Offset >= from.Offset {
+				break
+			}
+			reparsed.Comments = append(reparsed.Comments, comment)
+		}
+	}
+	reused, err := p.reparseStatements(reparsed, stmts[first-1:], program.Comments, edit)
+	if err != nil {
+		p.locate(err)
+		return nil, err
+	} else if !reused {
+		return NewWithOptions(lexer.New(src), opts).Parse()
+	}
+
+	if err := check(reparsed, p.opts.AllowReturnOutsideFunction); err != nil {
+		p.locate(err)
+		return nil, err
+	}
+	return reparsed, nil
+}
+
+// reparseStatements parses the statements of program from the current token
+// until the end of the source, or until a statement of old after the edit
+// starts at the current token. The statements of old from that one on, and
+// the comments after its start, are appended to program with their positions
+// moved. It reports false if the statements of old may not be reused, as one
+// of them is a TypeScript enum.
+func (p *Parser) reparseStatements(program *ast.Program, old []ast.Statement, comments []token.Token, edit Edit) (bool, error) {
+	if err := p.readFirstToken(); err != nil {
+		return false, err
+	}
+
+	delta := edit.NewEnd - edit.OldEnd
+	starts := make(map[int]int, len(old))
+	for i, stmt := range old {
+		if offset := stmt.Pos().Offset; offset >= edit.OldEnd {
+			starts[offset] = i
+		}
+	}
+
+	for p.skip(); p.current().TokenType != token.TOKEN_EOF; p.skip() {
+		tok := p.current()
+		if i, ok := starts[tok.Offset-delta]; ok && tok.Offset >= edit.NewEnd {
+			for _, stmt := range old[i:] {
+				if _, ok := enumName(stmt); ok {
+					return false, nil
+				}
+			}
+			from := old[i].Pos()
+			shift := newPositionShift(from, tok.Pos())
+			for _, stmt := range old[i:] {
+				shift.node(reflect.ValueOf(stmt))
+			}
+			program.Statements = append(program.Statements, old[i:]...)
+			shift.token(&program.EndToken)
+			if p.opts.Comments {
+				program.Comments = append(program.Comments, p.comments...)
+				for _, comment := range comments {
+					if comment.Offset >= from.Offset {
+						shift.token(&comment)
+						program.Comments = append(program.Comments, comment)
+					}
+				}
+			}
+			return true, nil
+		}
+
+		stmt, err := p.programItem()
+		if err != nil {
+			return false, err
+		} else if stmt != nil {
+			program.Statements = append(program.Statements, stmt)
+		}
+	}
+	if p.isSyntaxError() {
+		return false, p.err
+	}
+	program.EndToken = *p.current()
+	if p.opts.Comments {
+		program.Comments = append(program.Comments, p.comments...)
+	}
+
+	return true, nil
+}
+
+// enumName returns the name of the TypeScript enum declared by stmt, if stmt
+// is an enum declaration lowered by tsEnum. The var statement and the function
+// of a lowered enum both start at the enum declaration, which a var statement
+// of the source does not.
+func enumName(stmt ast.Statement) (string, bool) {
+	if export, ok := stmt.(*ast.ExportNamedDeclaration); ok && export.Declaration != nil {
+		stmt = export.Declaration
+	}
+	v, ok := stmt.(*ast.VarStatement)
+	if !ok || len(v.Declarations) != 1 {
+		return "", false
+	}
+	decl, ok := v.Declarations[0].(*ast.VariableDeclaration)
+	if !ok {
+		return "", false
+	}
+	name, ok := decl.Name.(*ast.Identifier)
+	if !ok {
+		return "", false
+	}
+	call, ok := decl.Value.(*ast.CallExpression)
+	if !ok {
+		return "", false
+	}
+	fn, ok := call.Callee.(*ast.FunctionExpression)
+	if !ok || fn.Token.Offset != v.Token.Offset {
+		return "", false
+	}
+	return name.Value, true
+}
+
+// positionShift moves the positions of the tokens after an edit from the old
+// source to the new one.
+type positionShift struct {
+	// from is the old position of a token after the edit, and to is its new
+	// position.
+	from, to token.Position
+	// seen are the pointers already followed, as a token or a node may be
+	// referred to more than once.
+	seen map[pointer]bool
+}
+
+type pointer struct {
+	typ  reflect.Type
+	addr uintptr
+}
+
+func newPositionShift(from, to token.Position) *positionShift {
+	return &positionShift{from: from, to: to, seen: make(map[pointer]bool)}
+}
+
+var tokenType = reflect.TypeOf(token.Token{})
+
+// node moves the positions of the tokens in v, which holds a node or any of
+// its fields.
+func (s *positionShift) node(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		ptr := pointer{typ: v.Type(), addr: v.Pointer()}
+		if v.IsNil() || s.seen[ptr] {
+			return
+		}
+		s.seen[ptr] = true
+		s.node(v.Elem())
+	case reflect.Interface:
+		if !v.IsNil() {
+			s.node(v.Elem())
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			s.node(v.Index(i))
+		}
+	case reflect.Struct:
+		if v.Type() == tokenType {
+			if v.CanAddr() {
+				s.token(v.Addr().Interface().(*token.Token))
+			}
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if field := v.Field(i); field.CanSet() {
+				s.node(field)
+			}
+		}
+	}
+}
+
+func (s *positionShift) token(tok *token.Token) {
+	if !tok.Pos().IsValid() {
+		return
+	}
+	if tok.Line == s.from.Line {
+		tok.Col += s.to.Col - s.from.Col
+	}
+	tok.Line += s.to.Line - s.from.Line
+	tok.Offset += s.to.Offset - s.from.Offset
+}
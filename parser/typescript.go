@@ -0,0 +1,791 @@
+package parser
+
+import (
+	"strconv"
+
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/token"
+)
+
+// isContextual reports whether tok is the contextual keyword word, such as
+// type or declare, which is scanned as an identifier.
+func isContextual(tok *token.Token, word string) bool {
+	return tok.TokenType == token.TOKEN_IDENTIFIER && tok.Literal == word
+}
+
+// tsStatement parses a TypeScript declaration at the current token, if there
+// is one, and reports whether there was. The declarations of types are erased
+// and return a nil statement, while enums are lowered to a variable statement.
+func (p *Parser) tsStatement() (ast.Statement, bool, error) {
+	if !p.opts.TypeScript {
+		return nil, false, nil
+	}
+
+	tok := p.current()
+	next, newLine, err := p.peekSignificant()
+	if err != nil {
+		return nil, false, err
+	}
+
+	switch {
+	case tok.TokenType == token.TOKEN_INTERFACE && next.TokenType == token.TOKEN_IDENTIFIER:
+		return nil, true, p.tsInterface()
+	case isContextual(tok, "type") && !newLine && p.isIdentifierReference(next):
+		return nil, true, p.tsTypeAlias()
+	case tok.TokenType == token.TOKEN_ENUM:
+		stmt, err := p.tsEnum(tok)
+		return stmt, true, err
+	case tok.TokenType == token.TOKEN_CONST && next.TokenType == token.TOKEN_ENUM:
+		p.advance()
+		p.skip()
+		stmt, err := p.tsEnum(tok)
+		return stmt, true, err
+	case isContextual(tok, "declare") && !newLine && p.isDeclarationStart(next):
+		return nil, true, p.tsDeclare()
+	}
+	return nil, false, nil
+}
+
+// tsTypeOnly consumes the type modifier of an import or export, or of one of
+// their specifiers, and reports whether there was one. The type is the name
+// of the import instead if it is followed by from, as, a comma or a brace.
+func (p *Parser) tsTypeOnly() (bool, error) {
+	if !p.opts.TypeScript || !isContextual(p.current(), "type") {
+		return false, nil
+	}
+	next, _, err := p.peekSignificant()
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case isOneOf(next, token.TOKEN_COMMA, token.TOKEN_RIGHT_BRACE, token.TOKEN_FROM, token.TOKEN_AS):
+		return false, nil
+	case isOneOf(next, token.TOKEN_LEFT_BRACE, token.TOKEN_STAR, token.TOKEN_STRING),
+		next.TokenType == token.TOKEN_IDENTIFIER || next.TokenType.IsKeyword():
+		p.advance()
+		p.skip()
+		return true, p.err
+	}
+	return false, nil
+}
+
+// tsMissingBody returns the error of a function expression or a method that
+// is declared without a body, which is only allowed for declarations.
+func (p *Parser) tsMissingBody() error {
+	return p.newSyntaxErrorf(p.last, "Function implementation is missing or not immediately following the declaration.")
+}
+
+// isDeclarationStart reports whether tok starts a declaration that may follow
+// declare.
+func (p *Parser) isDeclarationStart(tok *token.Token) bool {
+	switch tok.TokenType {
+	case token.TOKEN_VAR, token.TOKEN_LET, token.TOKEN_CONST, token.TOKEN_FUNCTION, token.TOKEN_ASYNC,
+		token.TOKEN_ENUM, token.TOKEN_INTERFACE, token.TOKEN_CLASS:
+		return true
+	}
+	for _, word := range []string{"type", "namespace", "module", "global", "abstract"} {
+		if isContextual(tok, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// tsInterface parses an interface declaration, which is erased.
+func (p *Parser) tsInterface() error {
+	p.advance()
+	if _, err := p.bindingIdentifier(); err != nil {
+		return err
+	}
+	if err := p.tsTypeParameters(); err != nil {
+		return err
+	}
+	if p.skipAndMatch(token.TOKEN_EXTENDS) {
+		for {
+			if err := p.tsType(); err != nil {
+				return err
+			}
+			if !p.skipAndMatch(token.TOKEN_COMMA) {
+				break
+			}
+		}
+	}
+	p.skip()
+	if p.current().TokenType != token.TOKEN_LEFT_BRACE {
+		return p.newExpectedError(token.TOKEN_LEFT_BRACE)
+	}
+	return p.tsSkipGroup()
+}
+
+// tsTypeAlias parses a type alias declaration, which is erased.
+func (p *Parser) tsTypeAlias() error {
+	p.advance()
+	if _, err := p.bindingIdentifier(); err != nil {
+		return err
+	}
+	if err := p.tsTypeParameters(); err != nil {
+		return err
+	}
+	if _, err := p.skipAndConsume(token.TOKEN_EQUAL); err != nil {
+		return err
+	}
+	if err := p.tsType(); err != nil {
+		return err
+	}
+	return p.semicolon()
+}
+
+// tsDeclare parses an ambient declaration after declare, which is erased.
+func (p *Parser) tsDeclare() error {
+	p.advance()
+	p.skip()
+	tok := p.current()
+
+	switch tok.TokenType {
+	case token.TOKEN_VAR, token.TOKEN_LET, token.TOKEN_CONST:
+		p.advance()
+		if p.skip(); p.current().TokenType == token.TOKEN_ENUM {
+			_, err := p.tsEnum(tok)
+			return err
+		}
+		for {
+			if _, err := p.bindingTarget(); err != nil {
+				return err
+			}
+			if err := p.tsTypeAnnotation(); err != nil {
+				return err
+			}
+			// A constant may be declared with the value of a literal type.
+			if _, err := p.initializer(); err != nil {
+				return err
+			}
+			if !p.skipAndMatch(token.TOKEN_COMMA) {
+				break
+			}
+		}
+		return p.semicolon()
+	case token.TOKEN_FUNCTION, token.TOKEN_ASYNC:
+		if tok.TokenType == token.TOKEN_ASYNC {
+			p.advance()
+			p.skip()
+		}
+		_, err := p.functionDeclaration(tok, tok.TokenType == token.TOKEN_ASYNC, false)
+		return err
+	case token.TOKEN_ENUM:
+		_, err := p.tsEnum(tok)
+		return err
+	case token.TOKEN_INTERFACE:
+		return p.tsInterface()
+	}
+
+	if isContextual(tok, "type") {
+		return p.tsTypeAlias()
+	}
+	// The bodies of classes, namespaces and modules are skipped up to their
+	// closing brace. A module may be declared without a body.
+	for p.skip(); !p.isEnd(); p.skip(token.TOKEN_SPACE, token.TOKEN_MULTI_LINE_COMMENT) {
+		switch p.current().TokenType {
+		case token.TOKEN_LEFT_BRACE:
+			return p.tsSkipGroup()
+		case token.TOKEN_SEMICOLON, token.TOKEN_NEW_LINE:
+			return p.semicolon()
+		}
+		p.advance()
+	}
+	return p.semicolon()
+}
+
+// tsEnum parses an enum declaration at its enum keyword, which starts at the
+// token start, and lowers it to a variable statement as the TypeScript
+// compiler does:
+//
+//	var E = (function (E) {
+//		var A = 0;
+//		E[E["A"] = A] = "A";
+//		return E;
+//	})({});
+//
+// The members are also declared as variables in the function, as they may be
+// referred to by the initializers of the members after them.
+func (p *Parser) tsEnum(start *token.Token) (ast.Statement, error) {
+	p.advance()
+	name, err := p.bindingIdentifier()
+	if err != nil {
+		return nil, err
+	}
+	lbrace, err := p.skipAndConsume(token.TOKEN_LEFT_BRACE)
+	if err != nil {
+		return nil, err
+	}
+
+	enum := func(tok *token.Token) *ast.Identifier {
+		return tsIdentifier(name.Value, tok)
+	}
+	body := make([]ast.Statement, 0)
+	// next is the value of a member without an initializer, which is the
+	// value of the member before it plus one.
+	var next ast.Expression = tsNumber(0, lbrace)
+	for !p.skipAndMatch(token.TOKEN_RIGHT_BRACE) {
+		p.skip()
+		tok := p.current()
+		if tok.TokenType != token.TOKEN_STRING && tok.TokenType != token.TOKEN_IDENTIFIER && !tok.TokenType.IsKeyword() {
+			return nil, p.newSyntaxErrorf(tok, "An enum member name must be an identifier or a string literal")
+		}
+		key := tok.Literal
+		p.advance()
+
+		value := next
+		initializer, err := p.initializer()
+		if err != nil {
+			return nil, err
+		} else if initializer != nil {
+			value = initializer
+		} else if value == nil {
+			return nil, p.newSyntaxErrorf(tok, "Enum member must have initializer")
+		}
+
+		if tok.TokenType == token.TOKEN_IDENTIFIER {
+			// var A = value;
+			body = append(body, &ast.VarStatement{
+				Token:        tsToken(token.TOKEN_VAR, "var", tok),
+				EndToken:     *p.previous(),
+				Declarations: []ast.Declaration{&ast.VariableDeclaration{Name: tsIdentifier(key, tok), Value: value}},
+			})
+			value = tsIdentifier(key, tok)
+		}
+
+		// E["A"] = value
+		var member ast.Expression = &ast.AssignmentExpression{
+			Operator: tsOperator(token.TOKEN_EQUAL, "=", tok),
+			Left:     tsIndex(enum(tok), tsString(key, tok)),
+			Right:    value,
+		}
+		if isString(initializer) {
+			next = nil
+		} else {
+			// E[E["A"] = value] = "A"
+			member = &ast.AssignmentExpression{
+				Operator: tsOperator(token.TOKEN_EQUAL, "=", tok),
+				Left:     tsIndex(enum(tok), member),
+				Right:    tsString(key, tok),
+			}
+			next = nextEnumValue(initializer, next, enum(tok), key)
+		}
+		body = append(body, &ast.ExpressionStatement{
+			Token:      *tok,
+			EndToken:   *p.previous(),
+			Expression: member,
+		})
+
+		if !p.skipAndMatch(token.TOKEN_COMMA) {
+			if _, err := p.skipAndConsume(token.TOKEN_RIGHT_BRACE); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	rbrace := p.previous()
+
+	body = append(body, &ast.ReturnStatement{
+		Token:    tsToken(token.TOKEN_RETURN, "return", rbrace),
+		EndToken: *rbrace,
+		Result:   enum(rbrace),
+	})
+	fn := &ast.FunctionExpression{
+		Token:  tsToken(token.TOKEN_FUNCTION, "function", start),
+		Params: []ast.Expression{enum(&name.Token)},
+		Body:   &ast.BlockStatement{LBrace: *lbrace, RBrace: *rbrace, StatementList: body},
+		Strict: p.strict,
+	}
+
+	// The members of an enum declared again are added to the same object.
+	var object ast.Expression = &ast.ObjectLiteral{
+		LBrace: tsToken(token.TOKEN_LEFT_BRACE, "{", rbrace),
+		RBrace: tsToken(token.TOKEN_RIGHT_BRACE, "}", rbrace),
+	}
+	if p.enums[name.Value] {
+		object = enum(rbrace)
+	}
+	if p.enums == nil {
+		p.enums = make(map[string]bool)
+	}
+	p.enums[name.Value] = true
+
+	return &ast.VarStatement{
+		Token:    tsToken(token.TOKEN_VAR, "var", start),
+		EndToken: *rbrace,
+		Declarations: []ast.Declaration{&ast.VariableDeclaration{
+			Name: name,
+			Value: &ast.CallExpression{
+				RParen:    tsToken(token.TOKEN_RIGHT_PAREN, ")", rbrace),
+				Callee:    fn,
+				Arguments: []ast.Expression{object},
+			},
+		}},
+	}, nil
+}
+
+// isString reports whether the initializer of an enum member is a string,
+// whose member has no reverse mapping from its value to its name.
+func isString(initializer ast.Expression) bool {
+	lit, ok := initializer.(*ast.Literal)
+	return ok && lit.Kind == ast.LitString
+}
+
+// nextEnumValue returns the value of the member after the member key, whose
+// value is initializer, or prev if it has no initializer. The value is folded
+// to a number if the value of the member is a number.
+func nextEnumValue(initializer, prev ast.Expression, enum *ast.Identifier, key string) ast.Expression {
+	if initializer == nil {
+		initializer = prev
+	}
+	if lit, ok := initializer.(*ast.Literal); ok && lit.Kind == ast.LitNumber {
+		if n, err := strconv.ParseFloat(lit.Value, 64); err == nil {
+			return tsNumber(n+1, &lit.Token)
+		}
+	}
+	return &ast.BinaryExpression{
+		Operator: tsOperator(token.TOKEN_PLUS, "+", &enum.Token),
+		Left:     tsIndex(enum, tsString(key, &enum.Token)),
+		Right:    tsNumber(1, &enum.Token),
+	}
+}
+
+func tsToken(typ token.TokenType, raw string, pos *token.Token) token.Token {
+	return token.Token{
+		TokenType: typ,
+		Line:      pos.Line,
+		Col:       pos.Col,
+		Literal:   raw,
+		Offset:    pos.Offset,
+		Raw:       raw,
+	}
+}
+
+func tsOperator(typ token.TokenType, raw string, pos *token.Token) *token.Token {
+	tok := tsToken(typ, raw, pos)
+	return &tok
+}
+
+func tsIdentifier(name string, pos *token.Token) *ast.Identifier {
+	return &ast.Identifier{Token: tsToken(token.LookupIdent(name), name, pos), Value: name}
+}
+
+func tsString(s string, pos *token.Token) *ast.Literal {
+	tok := tsToken(token.TOKEN_STRING, s, pos)
+	tok.Raw = strconv.Quote(s)
+	return &ast.Literal{Token: tok, Value: s, Kind: ast.LitString}
+}
+
+func tsNumber(n float64, pos *token.Token) *ast.Literal {
+	s := strconv.FormatFloat(n, 'f', -1, 64)
+	return &ast.Literal{Token: tsToken(token.TOKEN_NUMBER, s, pos), Value: s, Kind: ast.LitNumber}
+}
+
+func tsIndex(object, property ast.Expression) *ast.MemberExpression {
+	return &ast.MemberExpression{Object: object, Property: property, Computed: true}
+}
+
+// tsTypeAnnotation parses the type annotation of a binding, if there is one.
+func (p *Parser) tsTypeAnnotation() error {
+	if !p.opts.TypeScript || !p.skipAndMatch(token.TOKEN_COLON) {
+		return nil
+	}
+	return p.tsType()
+}
+
+// tsReturnType parses the return type of a function, if there is one, which
+// may be a type predicate such as x is string or asserts x.
+func (p *Parser) tsReturnType() error {
+	if !p.opts.TypeScript || !p.skipAndMatch(token.TOKEN_COLON) {
+		return nil
+	}
+
+	p.skip()
+	if tok := p.current(); isContextual(tok, "asserts") {
+		next, newLine, err := p.peekSignificant()
+		if err != nil {
+			return err
+		} else if !newLine && !isContextual(next, "is") && (p.isIdentifierReference(next) || next.TokenType == token.TOKEN_THIS) {
+			p.advance()
+			p.skip()
+			p.advance()
+			if p.skipAndMatch(token.TOKEN_IDENTIFIER) {
+				if !isContextual(p.previous(), "is") {
+					return p.newSyntaxError(p.previous())
+				}
+				return p.tsType()
+			}
+			return p.err
+		}
+	}
+	return p.tsPredicate()
+}
+
+// tsPredicate parses a return type, which may be a type predicate such as
+// x is string.
+func (p *Parser) tsPredicate() error {
+	p.skip()
+	if tok := p.current(); p.isIdentifierReference(tok) || tok.TokenType == token.TOKEN_THIS {
+		next, newLine, err := p.peekSignificant()
+		if err != nil {
+			return err
+		} else if !newLine && isContextual(next, "is") {
+			p.advance()
+			p.skip()
+			p.advance()
+		}
+	}
+	return p.tsType()
+}
+
+// tsTypeParameters parses the type parameters of a declaration, such as
+// <T extends U = V>, if there are any.
+func (p *Parser) tsTypeParameters() error {
+	if !p.opts.TypeScript || !p.skipAndMatch(token.TOKEN_LESS) {
+		return nil
+	}
+
+	for {
+		p.skip()
+		if isGreater(p.current()) {
+			break
+		}
+		// The modifiers of a parameter, unless it is named by one of them.
+		for p.match(token.TOKEN_CONST, token.TOKEN_IN) || isContextual(p.current(), "out") {
+			if isContextual(p.current(), "out") {
+				next, _, err := p.peekSignificant()
+				if err != nil {
+					return err
+				} else if next.TokenType != token.TOKEN_IDENTIFIER {
+					break
+				}
+				p.advance()
+			}
+			p.skip()
+		}
+		if _, err := p.bindingIdentifier(); err != nil {
+			return err
+		}
+		if p.skipAndMatch(token.TOKEN_EXTENDS) {
+			if err := p.tsType(); err != nil {
+				return err
+			}
+		}
+		if p.skipAndMatch(token.TOKEN_EQUAL) {
+			if err := p.tsType(); err != nil {
+				return err
+			}
+		}
+		if !p.skipAndMatch(token.TOKEN_COMMA) {
+			break
+		}
+	}
+	return p.tsCloseAngle()
+}
+
+// tsTypeArguments parses a list of type arguments, such as <string, T[]>,
+// whose < is the current token.
+func (p *Parser) tsTypeArguments() error {
+	p.advance()
+	for {
+		if err := p.tsType(); err != nil {
+			return err
+		}
+		if !p.skipAndMatch(token.TOKEN_COMMA) {
+			break
+		}
+	}
+	return p.tsCloseAngle()
+}
+
+// tsCloseAngle consumes the > that closes a list of type parameters or
+// arguments.
+func (p *Parser) tsCloseAngle() error {
+	p.skip()
+	if !isGreater(p.current()) {
+		return p.newExpectedError(token.TOKEN_GREATER)
+	}
+	_, err := p.splitGreater(nil)
+	return err
+}
+
+// tsCallTypeArguments parses the type arguments of a call, such as f<T>(x),
+// and reports whether there were any. The < is a relational operator instead
+// if a list of type arguments followed by a ( does not start at it.
+func (p *Parser) tsCallTypeArguments() (bool, error) {
+	if !p.opts.TypeScript {
+		return false, nil
+	}
+	p.skip()
+	if p.current().TokenType != token.TOKEN_LESS {
+		return false, nil
+	}
+	return p.try(func() error {
+		if err := p.tsTypeArguments(); err != nil {
+			return err
+		}
+		p.skip()
+		if p.current().TokenType != token.TOKEN_LEFT_PAREN {
+			return p.newSyntaxError(p.current())
+		}
+		return nil
+	})
+}
+
+// tsArrowHead parses the head of an arrow function with typed parameters, as
+// in (x: number): string => x, after its (, and returns its parameters. The
+// parser goes back to the ( if the source is not the head of an arrow
+// function.
+func (p *Parser) tsArrowHead() ([]ast.Expression, bool, error) {
+	if !p.opts.TypeScript {
+		return nil, false, nil
+	}
+
+	var params []ast.Expression
+	ok, err := p.try(func() error {
+		var err error
+		if params, err = p.formalParams(); err != nil {
+			return err
+		}
+		if err := p.tsReturnType(); err != nil {
+			return err
+		}
+		p.skip(token.TOKEN_SPACE, token.TOKEN_MULTI_LINE_COMMENT)
+		if !p.match(token.TOKEN_EQUAL_GREATER) {
+			return p.newSyntaxError(p.current())
+		}
+		return nil
+	})
+	return params, ok, err
+}
+
+// tsAngleExpr parses a generic arrow function such as <T>(x: T) => x, or a
+// type assertion such as <T>x, whose < is the current token.
+func (p *Parser) tsAngleExpr(start *token.Token) (ast.Expression, error) {
+	var params []ast.Expression
+	ok, err := p.try(func() error {
+		if err := p.tsTypeParameters(); err != nil {
+			return err
+		}
+		if _, err := p.skipAndConsume(token.TOKEN_LEFT_PAREN); err != nil {
+			return err
+		}
+		var isArrow bool
+		var err error
+		params, isArrow, err = p.tsArrowHead()
+		if err != nil {
+			return err
+		} else if !isArrow {
+			return p.newSyntaxError(p.current())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	} else if ok {
+		return p.arrowFunction(start, params, false)
+	}
+
+	if err := p.tsTypeArguments(); err != nil {
+		return nil, err
+	}
+	expr, err := p.unaryExpr()
+	if err != nil {
+		return nil, err
+	} else if expr == nil {
+		return nil, p.newSyntaxError(p.current())
+	}
+	return expr, nil
+}
+
+// tsType parses a type, which is erased.
+func (p *Parser) tsType() error {
+	p.skip()
+	tok := p.current()
+
+	// A function type, or a constructor type.
+	if isContextual(tok, "abstract") {
+		p.advance()
+		p.skip()
+		tok = p.current()
+	}
+	if tok.TokenType == token.TOKEN_NEW {
+		p.advance()
+		p.skip()
+		tok = p.current()
+	}
+	if tok.TokenType == token.TOKEN_LESS {
+		if err := p.tsTypeParameters(); err != nil {
+			return err
+		}
+		p.skip()
+		if p.current().TokenType != token.TOKEN_LEFT_PAREN {
+			return p.newExpectedError(token.TOKEN_LEFT_PAREN)
+		}
+	}
+
+	// Only the parameters of a function type are followed by =>, which is
+	// otherwise the arrow of a function whose return type this is.
+	params := p.current().TokenType == token.TOKEN_LEFT_PAREN
+	if err := p.tsUnionType(); err != nil {
+		return err
+	}
+	if params && p.skipAndMatch(token.TOKEN_EQUAL_GREATER) {
+		return p.tsPredicate()
+	}
+
+	// A conditional type.
+	if p.skipAndMatch(token.TOKEN_EXTENDS) {
+		if err := p.tsUnionType(); err != nil {
+			return err
+		}
+		if _, err := p.skipAndConsume(token.TOKEN_QUESTION); err != nil {
+			return err
+		}
+		if err := p.tsType(); err != nil {
+			return err
+		}
+		if _, err := p.skipAndConsume(token.TOKEN_COLON); err != nil {
+			return err
+		}
+		return p.tsType()
+	}
+	return nil
+}
+
+// tsUnionType parses a union or an intersection of types.
+func (p *Parser) tsUnionType() error {
+	p.skipAndMatch(token.TOKEN_PIPE, token.TOKEN_AND)
+	for {
+		if err := p.tsOperandType(); err != nil {
+			return err
+		}
+		if !p.skipAndMatch(token.TOKEN_PIPE, token.TOKEN_AND) {
+			return nil
+		}
+	}
+}
+
+// tsOperandType parses an operand of a union or an intersection type, which
+// may have type operators such as keyof and array or indexed access types.
+func (p *Parser) tsOperandType() error {
+	p.skip()
+	tok := p.current()
+
+	switch {
+	case isContextual(tok, "keyof") || isContextual(tok, "unique") || isContextual(tok, "readonly"):
+		p.advance()
+		return p.tsOperandType()
+	case isContextual(tok, "infer"):
+		p.advance()
+		if _, err := p.bindingIdentifier(); err != nil {
+			return err
+		}
+	case tok.TokenType == token.TOKEN_TYPEOF:
+		p.advance()
+		if err := p.tsTypeName(); err != nil {
+			return err
+		}
+	case tok.TokenType == token.TOKEN_IMPORT:
+		p.advance()
+		p.skip()
+		if p.current().TokenType != token.TOKEN_LEFT_PAREN {
+			return p.newExpectedError(token.TOKEN_LEFT_PAREN)
+		}
+		if err := p.tsSkipGroup(); err != nil {
+			return err
+		}
+		for p.skipAndMatch(token.TOKEN_DOT) {
+			if err := p.tsTypeName(); err != nil {
+				return err
+			}
+		}
+	case isOneOf(tok, token.TOKEN_LEFT_PAREN, token.TOKEN_LEFT_BRACE, token.TOKEN_LEFT_BRACKET):
+		// The parameters of a function type, a parenthesized type, an object
+		// type or a tuple type.
+		if err := p.tsSkipGroup(); err != nil {
+			return err
+		}
+	case isOneOf(tok, token.TOKEN_STRING, token.TOKEN_NUMBER, token.TOKEN_TRUE, token.TOKEN_FALSE,
+		token.TOKEN_NULL, token.TOKEN_VOID, token.TOKEN_THIS):
+		p.advance()
+	case tok.TokenType == token.TOKEN_MINUS:
+		p.advance()
+		if _, err := p.skipAndConsume(token.TOKEN_NUMBER); err != nil {
+			return err
+		}
+	case tok.TokenType == token.TOKEN_IDENTIFIER || tok.TokenType.IsKeyword():
+		if err := p.tsTypeName(); err != nil {
+			return err
+		}
+	default:
+		return p.newSyntaxError(tok)
+	}
+
+	// Array types and indexed access types, whose [ must be on the same line.
+	for {
+		p.skip(token.TOKEN_SPACE, token.TOKEN_MULTI_LINE_COMMENT)
+		if p.current().TokenType != token.TOKEN_LEFT_BRACKET {
+			return nil
+		}
+		if err := p.tsSkipGroup(); err != nil {
+			return err
+		}
+	}
+}
+
+// tsTypeName parses a possibly qualified type name such as a.b.C, and its
+// type arguments.
+func (p *Parser) tsTypeName() error {
+	for {
+		p.skip()
+		if tok := p.current(); tok.TokenType != token.TOKEN_IDENTIFIER && !tok.TokenType.IsKeyword() {
+			return p.newSyntaxError(tok)
+		}
+		p.advance()
+		if !p.skipAndMatch(token.TOKEN_DOT) {
+			break
+		}
+	}
+
+	p.skip(token.TOKEN_SPACE, token.TOKEN_MULTI_LINE_COMMENT)
+	if p.current().TokenType == token.TOKEN_LESS {
+		return p.tsTypeArguments()
+	}
+	return nil
+}
+
+// tsSkipGroup skips the tokens from the current (, [ or { to the bracket that
+// closes it. The members of object types and the elements of tuple types are
+// erased without being parsed.
+func (p *Parser) tsSkipGroup() error {
+	closing := map[token.TokenType]token.TokenType{
+		token.TOKEN_LEFT_PAREN:   token.TOKEN_RIGHT_PAREN,
+		token.TOKEN_LEFT_BRACKET: token.TOKEN_RIGHT_BRACKET,
+		token.TOKEN_LEFT_BRACE:   token.TOKEN_RIGHT_BRACE,
+	}
+
+	stack := make([]token.TokenType, 0)
+	for {
+		tok := p.current()
+		if p.isSyntaxError() {
+			return p.err
+		}
+		switch tok.TokenType {
+		case token.TOKEN_EOF:
+			return p.newSyntaxError(tok)
+		case token.TOKEN_LEFT_PAREN, token.TOKEN_LEFT_BRACKET, token.TOKEN_LEFT_BRACE:
+			stack = append(stack, closing[tok.TokenType])
+		case token.TOKEN_RIGHT_PAREN, token.TOKEN_RIGHT_BRACKET, token.TOKEN_RIGHT_BRACE:
+			if len(stack) == 0 || stack[len(stack)-1] != tok.TokenType {
+				return p.newSyntaxError(tok)
+			}
+			stack = stack[:len(stack)-1]
+		}
+		p.advance()
+		if len(stack) == 0 {
+			return p.err
+		}
+	}
+}
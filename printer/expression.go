@@ -0,0 +1,624 @@
+package printer
+
+import (
+	"strings"
+
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/token"
+)
+
+// precedence is the binding power of an expression. An expression is
+// parenthesized where an expression of a higher precedence is expected.
+type precedence int
+
+const (
+	precLowest precedence = iota
+	precSequence
+	// precAssignment is the precedence of assignments, arrow functions and
+	// yield expressions, which are expected in lists.
+	precAssignment
+	precConditional
+	// precLogicalOr is the precedence of || and ??, which may not be mixed
+	// without parentheses.
+	precLogicalOr
+	precLogicalAnd
+	precBitwiseOr
+	precBitwiseXor
+	precBitwiseAnd
+	precEquality
+	precRelational
+	precShift
+	precAdditive
+	precMultiplicative
+	precExponentiation
+	precUnary
+	precPostfix
+	// precCall is the precedence of calls, member accesses and new
+	// expressions, which are left-hand side expressions.
+	precCall
+	precPrimary
+)
+
+var binaryPrecedences = map[token.TokenType]precedence{
+	token.TOKEN_PIPE_PIPE:               precLogicalOr,
+	token.TOKEN_QUESTION_QUESTION:       precLogicalOr,
+	token.TOKEN_AND_AND:                 precLogicalAnd,
+	token.TOKEN_PIPE:                    precBitwiseOr,
+	token.TOKEN_HAT:                     precBitwiseXor,
+	token.TOKEN_AND:                     precBitwiseAnd,
+	token.TOKEN_EQUAL_EQUAL:             precEquality,
+	token.TOKEN_BANG_EQUAL:              precEquality,
+	token.TOKEN_EQUAL_EQUAL_EQUAL:       precEquality,
+	token.TOKEN_BANG_EQUAL_EQUAL:        precEquality,
+	token.TOKEN_LESS:                    precRelational,
+	token.TOKEN_GREATER:                 precRelational,
+	token.TOKEN_LESS_EQUAL:              precRelational,
+	token.TOKEN_GREATER_EQUAL:           precRelational,
+	token.TOKEN_INSTANCEOF:              precRelational,
+	token.TOKEN_IN:                      precRelational,
+	token.TOKEN_LESS_LESS:               precShift,
+	token.TOKEN_GREATER_GREATER:         precShift,
+	token.TOKEN_GREATER_GREATER_GREATER: precShift,
+	token.TOKEN_PLUS:                    precAdditive,
+	token.TOKEN_MINUS:                   precAdditive,
+	token.TOKEN_STAR:                    precMultiplicative,
+	token.TOKEN_SLASH:                   precMultiplicative,
+	token.TOKEN_PERCENT:                 precMultiplicative,
+	token.TOKEN_STAR_STAR:               precExponentiation,
+}
+
+// precedenceOf returns the precedence of expr.
+func precedenceOf(expr ast.Node) precedence {
+	switch n := expr.(type) {
+	case *ast.SequenceExpression:
+		return precSequence
+	case *ast.AssignmentExpression, *ast.ArrowFunctionExpression, *ast.YieldExpression:
+		return precAssignment
+	case *ast.TernaryExpression:
+		return precConditional
+	case *ast.BinaryExpression:
+		return binaryPrecedences[n.Operator.TokenType]
+	case *ast.LogicalExpression:
+		return binaryPrecedences[n.Operator.TokenType]
+	case *ast.UnaryExpression, *ast.AwaitExpression:
+		return precUnary
+	case *ast.UpdateExpression:
+		if n.Prefix {
+			return precUnary
+		}
+		return precPostfix
+	case *ast.CallExpression, *ast.MemberExpression, *ast.NewExpression, *ast.ImportExpression,
+		*ast.MetaProperty:
+		return precCall
+	}
+	return precPrimary
+}
+
+// operands returns the precedences expected of the operands of a binary or a
+// logical expression.
+func operands(op *token.Token, left, right ast.Expression) (precedence, precedence) {
+	prec := binaryPrecedences[op.TokenType]
+	leftPrec, rightPrec := prec, prec+1
+	if op.TokenType == token.TOKEN_STAR_STAR {
+		// The exponentiation operator is right-associative, and a unary
+		// expression may not be its left operand.
+		leftPrec, rightPrec = precPostfix, prec
+	}
+	if isMixedCoalesce(op, left) {
+		leftPrec = precPrimary
+	}
+	if isMixedCoalesce(op, right) {
+		rightPrec = precPrimary
+	}
+	return leftPrec, rightPrec
+}
+
+// isMixedCoalesce reports whether operand is a logical expression that may not
+// be an operand of op without parentheses, where one of the operators is ??
+// and the other one is && or ||.
+func isMixedCoalesce(op *token.Token, operand ast.Expression) bool {
+	logical, ok := operand.(*ast.LogicalExpression)
+	if !ok {
+		return false
+	}
+	isCoalesce := func(tok *token.Token) bool {
+		return tok.TokenType == token.TOKEN_QUESTION_QUESTION
+	}
+	return isCoalesce(op) != isCoalesce(logical.Operator)
+}
+
+// startOf returns the expression printed first in expr, and the context it
+// is printed in, if it is not parenthesized.
+func startOf(expr ast.Expression) ast.Expression {
+	for {
+		var next ast.Expression
+		prec := precPrimary
+		switch n := expr.(type) {
+		case *ast.SequenceExpression:
+			next, prec = n.Expressions[0], precAssignment
+		case *ast.AssignmentExpression:
+			next, prec = n.Left, precCall
+		case *ast.BinaryExpression:
+			next = n.Left
+			prec, _ = operands(n.Operator, n.Left, n.Right)
+		case *ast.LogicalExpression:
+			next = n.Left
+			prec, _ = operands(n.Operator, n.Left, n.Right)
+		case *ast.TernaryExpression:
+			next, prec = n.Condition, precLogicalOr
+		case *ast.MemberExpression:
+			next, prec = n.Object, precCall
+		case *ast.CallExpression:
+			next, prec = n.Callee, precCall
+		case *ast.UpdateExpression:
+			if !n.Prefix {
+				next, prec = n.Argument, precCall
+			}
+		}
+		if next == nil || precedenceOf(next) < prec {
+			return expr
+		}
+		expr = next
+	}
+}
+
+// startOfStatement returns the expression to parenthesize at the start of an
+// expression statement, which would otherwise be taken as a block, a
+// declaration or a destructuring pattern, or nil if there is none.
+func startOfStatement(expr ast.Expression) ast.Expression {
+	switch n := startOf(expr).(type) {
+	case *ast.ObjectLiteral, *ast.FunctionExpression:
+		return n
+	case *ast.ObjectPattern:
+		// A parenthesized pattern is not an assignment target.
+		return expr
+	case *ast.Identifier:
+		if n.Value == "let" {
+			return n
+		}
+	}
+	return nil
+}
+
+// startOfArrowBody returns the expression to parenthesize at the start of the
+// concise body of an arrow function, which would otherwise be taken as a
+// block, or nil if there is none.
+func startOfArrowBody(expr ast.Expression) ast.Expression {
+	switch n := startOf(expr).(type) {
+	case *ast.ObjectLiteral:
+		return n
+	case *ast.ObjectPattern:
+		return expr
+	}
+	return nil
+}
+
+// expr prints the expression node, parenthesized if its precedence is lower
+// than prec.
+func (p *printer) expr(node ast.Node, prec precedence) {
+	if pos := node.Pos(); pos.IsValid() {
+		p.inlineComments(pos, false)
+	}
+
+	parens := precedenceOf(node) < prec
+	if node == p.wrap {
+		parens = true
+	}
+	if parens {
+		p.print("(")
+		p.wrap = nil
+	}
+	p.mark(node.Pos())
+	p.expression(node)
+	if parens {
+		p.print(")")
+	}
+	p.setLine(node.End())
+}
+
+func (p *printer) expression(node ast.Node) {
+	switch n := node.(type) {
+	case *ast.Identifier:
+		p.print(n.Value)
+
+	case *ast.Literal:
+		p.text(literal(n))
+
+	case *ast.Elision:
+		// The comma after the hole is printed by the list.
+
+	case *ast.ThisExpression:
+		p.print("this")
+
+	case *ast.SpreadElement:
+		p.print("...")
+		p.expr(n.Value, precAssignment)
+
+	case *ast.ArrayLiteral:
+		p.elements(n.ElementList, n.RBracket.Pos())
+
+	case *ast.ArrayPattern:
+		p.elements(n.Elements, n.RBracket.Pos())
+
+	case *ast.ObjectLiteral:
+		p.properties(n.Properties, n.LBrace, n.RBrace)
+
+	case *ast.ObjectPattern:
+		p.properties(n.Properties, n.LBrace, n.RBrace)
+
+	case *ast.Property:
+		p.property(n)
+
+	case *ast.UnaryExpression:
+		op := n.Operator.TokenType
+		p.print(op.Text())
+		switch op {
+		case token.TOKEN_DELETE, token.TOKEN_VOID, token.TOKEN_TYPEOF:
+			p.print(" ")
+		case token.TOKEN_PLUS, token.TOKEN_MINUS:
+			// Keep + + a from being printed as ++a.
+			if startsWithSign(n.Value, op) {
+				p.print(" ")
+			}
+		}
+		p.expr(n.Value, precUnary)
+
+	case *ast.AwaitExpression:
+		p.print("await ")
+		p.expr(n.Argument, precUnary)
+
+	case *ast.UpdateExpression:
+		if n.Prefix {
+			p.print(n.Operator.TokenType.Text())
+			p.expr(n.Argument, precUnary)
+		} else {
+			p.expr(n.Argument, precCall)
+			p.print(n.Operator.TokenType.Text())
+		}
+
+	case *ast.BinaryExpression:
+		p.binary(n.Operator, n.Left, n.Right)
+
+	case *ast.LogicalExpression:
+		p.binary(n.Operator, n.Left, n.Right)
+
+	case *ast.TernaryExpression:
+		p.expr(n.Condition, precLogicalOr)
+		p.print(" ? ")
+		p.expr(n.TrueBranch, precAssignment)
+		p.print(" : ")
+		p.expr(n.FalseBranch, precAssignment)
+
+	case *ast.AssignmentExpression:
+		p.expr(n.Left, precCall)
+		p.print(" " + n.Operator.TokenType.Text() + " ")
+		p.expr(n.Right, precAssignment)
+
+	case *ast.AssignmentPattern:
+		p.expr(n.Left, precCall)
+		p.print(" = ")
+		p.expr(n.Right, precAssignment)
+
+	case *ast.RestElement:
+		p.print("...")
+		p.expr(n.Argument, precCall)
+
+	case *ast.SequenceExpression:
+		for i, expr := range n.Expressions {
+			if i > 0 {
+				p.print(", ")
+			}
+			p.expr(expr, precAssignment)
+		}
+
+	case *ast.YieldExpression:
+		p.print("yield")
+		if n.Delegate {
+			p.print("*")
+		}
+		if n.Argument != nil {
+			p.print(" ")
+			p.expr(n.Argument, precAssignment)
+		}
+
+	case *ast.FunctionExpression:
+		p.function(n.Name, n.Params, n.Body, n.Async, n.Generator)
+
+	case *ast.ArrowFunctionExpression:
+		if n.Async {
+			p.print("async ")
+		}
+		p.params(n.Params)
+		p.print(" => ")
+		if body, ok := n.Body.(*ast.BlockStatement); ok {
+			p.block(body)
+		} else {
+			wrap := p.wrap
+			p.wrap = startOfArrowBody(n.Body)
+			p.expr(n.Body, precAssignment)
+			p.wrap = wrap
+		}
+
+	case *ast.MemberExpression:
+		if isInteger(n.Object) {
+			// The dot would be taken as a decimal point.
+			p.print("(")
+			p.expr(n.Object, precLowest)
+			p.print(")")
+		} else {
+			p.expr(n.Object, precCall)
+		}
+		if n.Computed {
+			p.print("[")
+			p.expr(n.Property, precLowest)
+			p.print("]")
+		} else {
+			p.print(".")
+			p.expr(n.Property, precPrimary)
+		}
+
+	case *ast.CallExpression:
+		p.expr(n.Callee, precCall)
+		p.arguments(n.Arguments, n.RParen.Pos())
+
+	case *ast.NewExpression:
+		p.print("new ")
+		prec := precCall
+		if hasCall(n.Callee) {
+			// The arguments of the call would be taken as the arguments of
+			// new.
+			prec = precPrimary
+		}
+		p.expr(n.Callee, prec)
+		var rparen token.Position
+		if n.RParen != nil {
+			rparen = n.RParen.Pos()
+		}
+		p.arguments(n.Arguments, rparen)
+
+	case *ast.ImportExpression:
+		p.print("import(")
+		p.expr(n.Source, precAssignment)
+		if n.Options != nil {
+			p.print(", ")
+			p.expr(n.Options, precAssignment)
+		}
+		p.inlineComments(n.RParen.Pos(), true)
+		p.print(")")
+
+	case *ast.MetaProperty:
+		p.expr(n.Meta, precPrimary)
+		p.print(".")
+		p.expr(n.Property, precPrimary)
+
+	case *ast.VariableDeclaration:
+		p.expr(n.Name, precCall)
+		if n.Value != nil {
+			if p.noIn && containsIn(n.Value) {
+				p.wrap = n.Value
+			}
+			p.print(" = ")
+			p.expr(n.Value, precAssignment)
+		}
+
+	case *ast.ImportSpecifier, *ast.ImportDefaultSpecifier, *ast.ImportNamespaceSpecifier,
+		*ast.ImportAttribute, *ast.ExportSpecifier:
+		p.specifier(n)
+
+	case *ast.JSXElement, *ast.JSXOpeningElement, *ast.JSXClosingElement, *ast.JSXFragment,
+		*ast.JSXOpeningFragment, *ast.JSXClosingFragment, *ast.JSXIdentifier, *ast.JSXNamespacedName,
+		*ast.JSXMemberExpression, *ast.JSXAttribute, *ast.JSXSpreadAttribute,
+		*ast.JSXExpressionContainer, *ast.JSXEmptyExpression, *ast.JSXSpreadChild, *ast.JSXText:
+		p.jsx(n)
+
+	case *ast.BadExpression:
+		p.fail("cannot print a bad expression at %s", n.Pos())
+
+	default:
+		p.fail("unexpected expression %T", n)
+	}
+}
+
+func (p *printer) binary(op *token.Token, left, right ast.Expression) {
+	leftPrec, rightPrec := operands(op, left, right)
+	p.expr(left, leftPrec)
+	p.print(" " + op.TokenType.Text() + " ")
+	p.expr(right, rightPrec)
+}
+
+// literal returns the source of lit, as written if it was parsed.
+func literal(lit *ast.Literal) string {
+	raw := lit.Token.Raw
+	switch lit.Kind {
+	case ast.LitString:
+		if raw != "" && strings.ContainsRune(`"'`+"`", rune(raw[0])) {
+			return raw
+		}
+		return quote(lit.Value)
+	case ast.LitNumber:
+		if lit.Token.TokenType == token.TOKEN_NUMBER {
+			return raw
+		}
+	}
+	return lit.Value
+}
+
+// isInteger reports whether expr is a number literal without a decimal point.
+func isInteger(expr ast.Expression) bool {
+	lit, ok := expr.(*ast.Literal)
+	if !ok || lit.Kind != ast.LitNumber {
+		return false
+	}
+	return strings.Trim(literal(lit), "0123456789") == ""
+}
+
+// startsWithSign reports whether the operand of a unary + or - operator
+// starts with the same sign, when printed.
+func startsWithSign(expr ast.Expression, op token.TokenType) bool {
+	switch n := expr.(type) {
+	case *ast.UnaryExpression:
+		return n.Operator.TokenType == op
+	case *ast.UpdateExpression:
+		return n.Prefix && (n.Operator.TokenType == token.TOKEN_PLUS_PLUS) == (op == token.TOKEN_PLUS)
+	case *ast.Literal:
+		return strings.HasPrefix(literal(n), op.Text())
+	}
+	return false
+}
+
+// hasCall reports whether the callee of a new expression contains a call
+// outside of parentheses.
+func hasCall(expr ast.Expression) bool {
+	for {
+		switch n := expr.(type) {
+		case *ast.CallExpression:
+			return true
+		case *ast.MemberExpression:
+			expr = n.Object
+		default:
+			return false
+		}
+	}
+}
+
+// elements prints the elements of an array literal or pattern, with the
+// holes of the array.
+func (p *printer) elements(list []ast.Expression, rbracket token.Position) {
+	p.print("[")
+	for i, elem := range list {
+		if i > 0 {
+			p.print(" ")
+		}
+		p.expr(elem, precAssignment)
+		if _, ok := elem.(*ast.Elision); ok || i < len(list)-1 {
+			p.print(",")
+		}
+	}
+	p.inlineComments(rbracket, true)
+	p.print("]")
+}
+
+// properties prints the properties of an object literal or pattern. They are
+// printed on lines of their own if the first one is not on the line of the
+// opening brace in the source, or if there are methods.
+func (p *printer) properties(list []ast.Expression, lbrace, rbrace token.Token) {
+	p.print("{")
+	if len(list) == 0 {
+		p.inlineComments(rbrace.Pos(), true)
+		p.print("}")
+		return
+	}
+
+	multiline := false
+	if pos := list[0].Pos(); pos.IsValid() && lbrace.Pos().IsValid() && pos.Line > lbrace.Line {
+		multiline = true
+	}
+	for _, prop := range list {
+		if prop, ok := prop.(*ast.Property); ok && (prop.Method || prop.Kind != ast.PropertyInit) {
+			multiline = true
+		}
+	}
+
+	if !multiline || p.Compact {
+		for i, prop := range list {
+			if i > 0 {
+				p.print(", ")
+			}
+			p.expr(prop, precAssignment)
+		}
+		p.inlineComments(rbrace.Pos(), true)
+		p.print("}")
+		return
+	}
+
+	p.setLine(lbrace.End())
+	p.level++
+	for i, prop := range list {
+		p.lineBreak(prop.Pos(), i == 0, false)
+		p.expr(prop, precAssignment)
+		p.print(",")
+	}
+	p.lineBreak(rbrace.Pos(), false, true)
+	p.level--
+	p.print("}")
+}
+
+func (p *printer) property(n *ast.Property) {
+	if n.Shorthand {
+		p.expr(n.Value, precAssignment)
+		return
+	}
+
+	fn, _ := n.Value.(*ast.FunctionExpression)
+	switch {
+	case n.Kind == ast.PropertyGet:
+		p.print("get ")
+	case n.Kind == ast.PropertySet:
+		p.print("set ")
+	case n.Method && fn != nil:
+		if fn.Async {
+			p.print("async ")
+		}
+		if fn.Generator {
+			p.print("*")
+		}
+	}
+
+	if n.Computed {
+		p.print("[")
+		p.expr(n.Key, precAssignment)
+		p.print("]")
+	} else {
+		p.expr(n.Key, precPrimary)
+	}
+
+	if fn != nil && (n.Method || n.Kind != ast.PropertyInit) {
+		p.params(fn.Params)
+		p.print(" ")
+		p.block(fn.Body)
+		return
+	}
+	p.print(": ")
+	p.expr(n.Value, precAssignment)
+}
+
+func (p *printer) function(name *ast.Identifier, params []ast.Expression, body *ast.BlockStatement,
+	async, generator bool) {
+	if async {
+		p.print("async ")
+	}
+	p.print("function")
+	if generator {
+		p.print("*")
+	}
+	p.print(" ")
+	if name != nil {
+		p.expr(name, precPrimary)
+	}
+	p.params(params)
+	p.print(" ")
+	p.block(body)
+}
+
+func (p *printer) params(params []ast.Expression) {
+	p.print("(")
+	for i, param := range params {
+		if i > 0 {
+			p.print(", ")
+		}
+		p.expr(param, precAssignment)
+	}
+	p.print(")")
+}
+
+func (p *printer) arguments(args []ast.Expression, rparen token.Position) {
+	p.print("(")
+	for i, arg := range args {
+		if i > 0 {
+			p.print(", ")
+		}
+		p.expr(arg, precAssignment)
+	}
+	p.inlineComments(rparen, true)
+	p.print(")")
+}
@@ -0,0 +1,135 @@
+package printer
+
+import (
+	"strings"
+
+	"github.com/ghosind/gjs/ast"
+)
+
+func (p *printer) jsx(node ast.Node) {
+	switch n := node.(type) {
+	case *ast.JSXElement:
+		p.expr(n.OpeningElement, precPrimary)
+		p.jsxChildren(n.Children)
+		if n.ClosingElement != nil {
+			p.expr(n.ClosingElement, precPrimary)
+		}
+
+	case *ast.JSXOpeningElement:
+		p.print("<")
+		p.expr(n.Name, precPrimary)
+		for _, attr := range n.Attributes {
+			p.print(" ")
+			p.expr(attr, precPrimary)
+		}
+		if n.SelfClosing {
+			p.print(" />")
+		} else {
+			p.print(">")
+		}
+
+	case *ast.JSXClosingElement:
+		p.print("</")
+		p.expr(n.Name, precPrimary)
+		p.print(">")
+
+	case *ast.JSXFragment:
+		p.expr(n.OpeningFragment, precPrimary)
+		p.jsxChildren(n.Children)
+		p.expr(n.ClosingFragment, precPrimary)
+
+	case *ast.JSXOpeningFragment:
+		p.print("<>")
+
+	case *ast.JSXClosingFragment:
+		p.print("</>")
+
+	case *ast.JSXIdentifier:
+		p.print(n.Name)
+
+	case *ast.JSXNamespacedName:
+		p.expr(n.Namespace, precPrimary)
+		p.print(":")
+		p.expr(n.Name, precPrimary)
+
+	case *ast.JSXMemberExpression:
+		p.expr(n.Object, precPrimary)
+		p.print(".")
+		p.expr(n.Property, precPrimary)
+
+	case *ast.JSXAttribute:
+		p.expr(n.Name, precPrimary)
+		if n.Value == nil {
+			break
+		}
+		p.print("=")
+		if lit, ok := n.Value.(*ast.Literal); ok {
+			p.text(jsxString(lit))
+		} else {
+			p.expr(n.Value, precPrimary)
+		}
+
+	case *ast.JSXSpreadAttribute:
+		p.print("{...")
+		p.expr(n.Argument, precAssignment)
+		p.inlineComments(n.RBrace.Pos(), true)
+		p.print("}")
+
+	case *ast.JSXExpressionContainer:
+		p.print("{")
+		if _, ok := n.Expression.(*ast.JSXEmptyExpression); ok {
+			p.jsx(n.Expression)
+		} else {
+			p.expr(n.Expression, precAssignment)
+		}
+		p.inlineComments(n.RBrace.Pos(), true)
+		p.print("}")
+
+	case *ast.JSXEmptyExpression:
+		// The comments in the braces are kept as they are, as the single line
+		// ones may not be moved out of the braces.
+		for {
+			c, ok := p.nextComment(n.RBrace.Pos())
+			if !ok {
+				break
+			}
+			p.print(c.Raw)
+			if strings.HasPrefix(c.Raw, "//") {
+				p.newline()
+			}
+		}
+
+	case *ast.JSXSpreadChild:
+		p.print("{...")
+		p.expr(n.Expression, precAssignment)
+		p.inlineComments(n.RBrace.Pos(), true)
+		p.print("}")
+
+	case *ast.JSXText:
+		if n.Token.Raw != "" {
+			p.text(n.Token.Raw)
+		} else {
+			p.text(jsxEscaper.Replace(n.Value))
+		}
+	}
+}
+
+// jsxChildren prints the children of an element or a fragment. The text
+// between them is printed as it is, as its white space may be significant.
+func (p *printer) jsxChildren(children []ast.Expression) {
+	for _, child := range children {
+		p.expr(child, precPrimary)
+	}
+}
+
+var jsxEscaper = strings.NewReplacer("&", "&amp;", "{", "&#123;", "}", "&#125;", "<", "&lt;", ">", "&gt;")
+
+// jsxString returns the source of a string attribute value, as written if it
+// was parsed.
+func jsxString(lit *ast.Literal) string {
+	raw := lit.Token.Raw
+	if raw != "" && (raw[0] == '"' || raw[0] == '\'') {
+		return raw
+	}
+	return `"` + strings.ReplaceAll(lit.Value, `"`, "&quot;") + `"`
+}
@@ -0,0 +1,148 @@
+package printer
+
+import (
+	"github.com/ghosind/gjs/ast"
+)
+
+func (p *printer) moduleItem(node ast.Node) {
+	switch n := node.(type) {
+	case *ast.ImportDeclaration:
+		p.print("import ")
+		named := make([]ast.Expression, 0, len(n.Specifiers))
+		for _, spec := range n.Specifiers {
+			switch spec.(type) {
+			case *ast.ImportDefaultSpecifier, *ast.ImportNamespaceSpecifier:
+				if len(named) > 0 || spec != n.Specifiers[0] {
+					p.print(", ")
+				}
+				p.expr(spec, precPrimary)
+			default:
+				named = append(named, spec)
+			}
+		}
+		if len(named) > 0 {
+			if len(named) < len(n.Specifiers) {
+				p.print(", ")
+			}
+			p.specifiers(named)
+		}
+		if len(n.Specifiers) > 0 {
+			p.print(" from ")
+		}
+		p.expr(n.Source, precPrimary)
+		p.attributes(n.Attributes)
+		p.print(";")
+
+	case *ast.ExportNamedDeclaration:
+		p.print("export ")
+		if n.Declaration != nil {
+			p.statement(n.Declaration)
+			break
+		}
+		specs := make([]ast.Expression, len(n.Specifiers))
+		for i, spec := range n.Specifiers {
+			specs[i] = spec
+		}
+		p.specifiers(specs)
+		if n.Source != nil {
+			p.print(" from ")
+			p.expr(n.Source, precPrimary)
+			p.attributes(n.Attributes)
+		}
+		p.print(";")
+
+	case *ast.ExportDefaultDeclaration:
+		p.print("export default ")
+		if fn, ok := n.Declaration.(*ast.FunctionDeclaration); ok {
+			p.statement(fn)
+			break
+		}
+		switch start := startOf(n.Declaration).(type) {
+		case *ast.FunctionExpression:
+			// It would be taken as a function declaration.
+			p.wrap = start
+		}
+		p.expr(n.Declaration, precAssignment)
+		p.wrap = nil
+		p.print(";")
+
+	case *ast.ExportAllDeclaration:
+		p.print("export *")
+		if n.Exported != nil {
+			p.print(" as ")
+			p.expr(n.Exported, precPrimary)
+		}
+		p.print(" from ")
+		p.expr(n.Source, precPrimary)
+		p.attributes(n.Attributes)
+		p.print(";")
+	}
+}
+
+// specifiers prints a list of import or export specifiers in braces.
+func (p *printer) specifiers(list []ast.Expression) {
+	p.print("{")
+	for i, spec := range list {
+		if i > 0 {
+			p.print(", ")
+		}
+		p.expr(spec, precPrimary)
+	}
+	p.print("}")
+}
+
+// attributes prints the import attributes of a module request.
+func (p *printer) attributes(list []*ast.ImportAttribute) {
+	if len(list) == 0 {
+		return
+	}
+	p.print(" with {")
+	for i, attr := range list {
+		if i > 0 {
+			p.print(", ")
+		}
+		p.expr(attr, precPrimary)
+	}
+	p.print("}")
+}
+
+func (p *printer) specifier(node ast.Node) {
+	switch n := node.(type) {
+	case *ast.ImportSpecifier:
+		p.expr(n.Imported, precPrimary)
+		if !sameName(n.Imported, n.Local) {
+			p.print(" as ")
+			p.expr(n.Local, precPrimary)
+		}
+
+	case *ast.ImportDefaultSpecifier:
+		p.expr(n.Local, precPrimary)
+
+	case *ast.ImportNamespaceSpecifier:
+		p.print("* as ")
+		p.expr(n.Local, precPrimary)
+
+	case *ast.ImportAttribute:
+		p.expr(n.Key, precPrimary)
+		p.print(": ")
+		p.expr(n.Value, precPrimary)
+
+	case *ast.ExportSpecifier:
+		p.expr(n.Local, precPrimary)
+		if !sameName(n.Local, n.Exported) {
+			p.print(" as ")
+			p.expr(n.Exported, precPrimary)
+		}
+	}
+}
+
+// sameName reports whether the names of a specifier are the same
+// identifier, so the second one is omitted.
+func sameName(a, b ast.Expression) bool {
+	x, ok := a.(*ast.Identifier)
+	if !ok {
+		return false
+	}
+	y, ok := b.(*ast.Identifier)
+	return ok && x.Value == y.Value
+}
@@ -0,0 +1,404 @@
+// Package printer prints the AST as formatted JavaScript source.
+//
+// The output of a printed program parses to the same program, with the
+// parentheses required by the precedence of the operators, and printing it
+// again gives the same output.
+package printer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/sourcemap"
+	"github.com/ghosind/gjs/token"
+)
+
+// Config configures the output of a printer.
+type Config struct {
+	// Indent is the indentation of a level of nesting. It is two spaces if
+	// empty.
+	Indent string
+	// Compact prints the source on a line, without the comments and the
+	// white space that is not required between the tokens.
+	Compact bool
+	// SourceMap receives the mappings from the output to the positions of
+	// the nodes printed in the source, if it is not nil. The columns of the
+	// output are counted in characters, as in the positions of the tokens.
+	SourceMap *sourcemap.Generator
+	// Source is the name of the source in the mappings. It is the FileName of
+	// the program printed if empty.
+	Source string
+}
+
+// Fprint prints node to w with the default configuration.
+func Fprint(w io.Writer, node ast.Node) error {
+	return new(Config).Fprint(w, node)
+}
+
+// Fprint prints node to w. The comments of a program are printed where they
+// appear in the source, if it was parsed with comments, and a single blank
+// line is kept where the source has blank lines between statements.
+func (cfg *Config) Fprint(w io.Writer, node ast.Node) error {
+	p := &printer{Config: *cfg, genLine: 1, genCol: 1}
+	if p.Indent == "" {
+		p.Indent = "  "
+	}
+	if program, ok := node.(*ast.Program); ok {
+		if !p.Compact {
+			p.comments = program.Comments
+		}
+		if p.Source == "" {
+			p.Source = program.FileName
+		}
+	}
+
+	if isStatement(node) {
+		p.statement(node)
+		p.lineBreak(eof, false, true)
+	} else {
+		p.expr(node, precLowest)
+	}
+	if p.err != nil {
+		return p.err
+	}
+	_, err := w.Write(p.buf.Bytes())
+	return err
+}
+
+// eof is a position after the end of every source.
+var eof = token.Position{Offset: math.MaxInt, Line: math.MaxInt, Col: 1}
+
+type printer struct {
+	Config
+	buf bytes.Buffer
+	err error
+
+	// level is the level of indentation, and bol is set at the beginning of
+	// a line, before its indentation is printed.
+	level int
+	bol   bool
+
+	// comments are the comments of the source that are not printed yet, and
+	// pending the single line comments to print at the end of the line.
+	comments []token.Token
+	pending  []token.Token
+	// line is the line in the source of the last node or comment printed,
+	// to place the comments and the blank lines after it.
+	line int
+
+	// wrap is an expression to parenthesize, as it would be taken as the
+	// start of another construct at the start of a statement.
+	wrap ast.Expression
+	// space is set in compact mode when a space is left out after the last
+	// character printed.
+	space bool
+	// noIn is set while printing the declarations of the head of a for
+	// statement, whose initializers may not contain the in operator.
+	noIn bool
+
+	// mapped is the position in the source of the node to print next, to be
+	// mapped to the position of its first character in the output. genLine
+	// and genCol are the position in the output after the first counted
+	// bytes.
+	mapped  token.Position
+	genLine int
+	genCol  int
+	counted int
+}
+
+func (p *printer) print(s string) {
+	if s == "" {
+		return
+	}
+	if p.bol {
+		for i := 0; i < p.level; i++ {
+			p.buf.WriteString(p.Indent)
+		}
+		p.bol = false
+	}
+	if !p.Compact {
+		if p.mapped.IsValid() {
+			trimmed := strings.TrimLeft(s, " ")
+			p.buf.WriteString(s[:len(s)-len(trimmed)])
+			s = trimmed
+			if s != "" {
+				p.addMapping()
+			}
+		}
+		p.buf.WriteString(s)
+		return
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] == ' ' {
+			p.space = true
+			continue
+		}
+		p.separate(s[i])
+		p.addMapping()
+		p.buf.WriteByte(s[i])
+	}
+}
+
+// text prints s as it is, such as the source of a string literal, whose
+// spaces are kept in compact mode.
+func (p *printer) text(s string) {
+	if p.Compact && s != "" {
+		p.separate(s[0])
+		p.addMapping()
+		p.buf.WriteString(s)
+		return
+	}
+	p.print(s)
+}
+
+// mark records the position in the source of the node to print next, if the
+// source map is generated.
+func (p *printer) mark(pos token.Position) {
+	if p.SourceMap != nil && pos.IsValid() {
+		p.mapped = pos
+	}
+}
+
+// addMapping maps the end of the output to the position marked.
+func (p *printer) addMapping() {
+	if !p.mapped.IsValid() {
+		return
+	}
+	b := p.buf.Bytes()[p.counted:]
+	for {
+		i := bytes.IndexByte(b, '\n')
+		if i < 0 {
+			break
+		}
+		p.genLine++
+		p.genCol = 1
+		b = b[i+1:]
+	}
+	p.genCol += utf8.RuneCount(b)
+	p.counted = p.buf.Len()
+
+	p.SourceMap.AddMapping(sourcemap.Mapping{
+		GeneratedLine: p.genLine,
+		GeneratedCol:  p.genCol,
+		Source:        p.Source,
+		Line:          p.mapped.Line,
+		Col:           p.mapped.Col,
+	})
+	p.mapped = token.Position{}
+}
+
+// separate prints the space left out in compact mode before c, if c would
+// be taken as a part of the last token without it.
+func (p *printer) separate(c byte) {
+	if !p.space {
+		return
+	}
+	p.space = false
+	b := p.buf.Bytes()
+	if len(b) == 0 {
+		return
+	}
+	last := b[len(b)-1]
+	switch {
+	case isWordChar(last) && isWordChar(c),
+		last == '+' && c == '+', last == '-' && c == '-',
+		last == '/' && (c == '/' || c == '*'):
+		p.buf.WriteByte(' ')
+	}
+}
+
+// isWordChar reports whether c may be a character of an identifier, a
+// keyword or a number.
+func isWordChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '_' || c == '$' || c == '\\' || c >= utf8.RuneSelf
+}
+
+func (p *printer) newline() {
+	p.flushPending()
+	if p.Compact {
+		p.space = true
+		return
+	}
+	p.buf.WriteByte('\n')
+	p.bol = true
+}
+
+func (p *printer) blankLine() {
+	if !p.bol {
+		p.newline()
+	}
+	p.buf.WriteByte('\n')
+}
+
+// setLine records the end of a node printed from pos in the source.
+func (p *printer) setLine(pos token.Position) {
+	if pos.IsValid() {
+		p.line = pos.Line
+	}
+}
+
+func (p *printer) fail(format string, args ...any) {
+	if p.err == nil {
+		p.err = fmt.Errorf("printer: "+format, args...)
+	}
+}
+
+// nextComment returns the next comment to print, if it starts before pos.
+func (p *printer) nextComment(pos token.Position) (token.Token, bool) {
+	if !p.peekComment(pos) {
+		return token.Token{}, false
+	}
+	c := p.comments[0]
+	p.comments = p.comments[1:]
+	return c, true
+}
+
+// peekComment reports whether a comment is to be printed before pos.
+func (p *printer) peekComment(pos token.Position) bool {
+	return len(p.comments) > 0 && pos.IsValid() && p.comments[0].Offset < pos.Offset
+}
+
+func (p *printer) flushPending() {
+	for _, c := range p.pending {
+		p.buf.WriteString(" ")
+		p.buf.WriteString(c.Raw)
+	}
+	p.pending = p.pending[:0]
+}
+
+// trailingComments prints the comments before pos that start on the line of
+// the last node printed, at the end of the current line.
+func (p *printer) trailingComments(pos token.Position) {
+	p.flushPending()
+	for len(p.comments) > 0 && p.comments[0].Line == p.line {
+		c, ok := p.nextComment(pos)
+		if !ok {
+			break
+		}
+		p.print(" " + c.Raw)
+		p.setLine(c.End())
+	}
+}
+
+// lineBreak ends the current line before the node at pos, and prints the
+// comments before it, each on a line of its own unless it follows the last
+// node printed on its line. A blank line of the source is kept before the
+// comments and the node, unless first is set for the first node of a block.
+// The closing brace of a block is at pos if closing is set, and no blank line
+// is kept before it.
+func (p *printer) lineBreak(pos token.Position, first, closing bool) {
+	if p.Compact {
+		p.space = true
+		return
+	}
+	p.trailingComments(pos)
+	if !p.bol && p.buf.Len() > 0 {
+		p.newline()
+	}
+	for {
+		c, ok := p.nextComment(pos)
+		if !ok {
+			break
+		}
+		if !first && c.Line > p.line+1 {
+			p.blankLine()
+		}
+		first = false
+		p.print(c.Raw)
+		p.setLine(c.End())
+		if c.TokenType == token.TOKEN_MULTI_LINE_COMMENT && !closing && c.End().Line == pos.Line {
+			p.print(" ")
+		} else {
+			p.newline()
+		}
+	}
+	if !first && !closing && pos.IsValid() && pos.Line > p.line+1 && p.bol {
+		p.blankLine()
+	}
+}
+
+// inlineComments prints the comments before pos inside of a line. The single
+// line comments are moved to the end of the line, and the others are printed
+// before the node at pos, or before the closing token at pos if closing is
+// set.
+func (p *printer) inlineComments(pos token.Position, closing bool) {
+	for {
+		c, ok := p.nextComment(pos)
+		if !ok {
+			return
+		}
+		p.setLine(c.End())
+		switch {
+		case c.TokenType == token.TOKEN_SINGLE_LINE_COMMENT && p.bol:
+			p.print(c.Raw)
+			p.newline()
+		case c.TokenType == token.TOKEN_SINGLE_LINE_COMMENT:
+			p.pending = append(p.pending, c)
+		case closing && !p.afterOpening():
+			p.print(" " + c.Raw)
+		case closing:
+			p.print(c.Raw)
+		default:
+			p.print(c.Raw + " ")
+		}
+	}
+}
+
+// afterOpening reports whether the last character printed is an opening
+// bracket.
+func (p *printer) afterOpening() bool {
+	b := p.buf.Bytes()
+	return len(b) > 0 && strings.IndexByte("([{", b[len(b)-1]) >= 0
+}
+
+// isStatement reports whether node is printed as a statement, on lines of its
+// own.
+func isStatement(node ast.Node) bool {
+	switch node.(type) {
+	case *ast.Program, *ast.BlockStatement, *ast.VarStatement, *ast.LexicalDeclaration,
+		*ast.EmptyStatement, *ast.ExpressionStatement, *ast.IfStatement, *ast.ForStatement,
+		*ast.ForInStatement, *ast.ForOfStatement, *ast.WhileStatement, *ast.WithStatement,
+		*ast.DoWhileStatement, *ast.ContinueStatement, *ast.BreakStatement, *ast.ReturnStatement,
+		*ast.SwitchStatement, *ast.SwitchCase, *ast.LabeledStatement, *ast.ThrowStatement,
+		*ast.TryStatement, *ast.CatchClause, *ast.DebuggerStatement, *ast.BadStatement,
+		*ast.FunctionDeclaration, *ast.ImportDeclaration, *ast.ExportNamedDeclaration,
+		*ast.ExportDefaultDeclaration, *ast.ExportAllDeclaration:
+		return true
+	}
+	return false
+}
+
+// quote returns s as a string literal in double quotes.
+func quote(s string) string {
+	var buf strings.Builder
+	buf.WriteByte('"')
+	for _, c := range s {
+		switch c {
+		case '"', '\\':
+			buf.WriteByte('\\')
+			buf.WriteRune(c)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		case '\u2028', '\u2029':
+			fmt.Fprintf(&buf, `\u%04x`, c)
+		default:
+			if c < 0x20 || c == 0x7f {
+				fmt.Fprintf(&buf, `\x%02x`, c)
+			} else {
+				buf.WriteRune(c)
+			}
+		}
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
@@ -0,0 +1,283 @@
+package printer_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/lexer"
+	"github.com/ghosind/gjs/parser"
+	"github.com/ghosind/gjs/printer"
+	"github.com/ghosind/gjs/sourcemap"
+	"github.com/ghosind/gjs/token"
+	"github.com/ghosind/go-assert"
+)
+
+func format(a *assert.Assertion, src string, opts parser.Options) (string, *ast.Program) {
+	opts.Comments = true
+	program, err := parser.NewWithOptions(lexer.New([]byte(src)), opts).Parse()
+	a.NilNow(err, src)
+	buf := new(bytes.Buffer)
+	a.NilNow(printer.Fprint(buf, program), src)
+	return buf.String(), program
+}
+
+// shape returns the ESTree encoding of program without the locations of its
+// nodes, to compare programs parsed from different sources.
+func shape(a *assert.Assertion, program *ast.Program) any {
+	data, err := ast.MarshalESTree(program)
+	a.NilNow(err)
+	var v any
+	a.NilNow(json.Unmarshal(data, &v))
+
+	var strip func(v any)
+	strip = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			delete(v, "loc")
+			delete(v, "range")
+			for _, field := range v {
+				strip(field)
+			}
+		case []any:
+			for _, elem := range v {
+				strip(elem)
+			}
+		}
+	}
+	strip(v)
+	return v
+}
+
+// testFormat checks that src is formatted as expected, and that the output
+// parses to the same program and is formatted again as it is.
+func testFormat(a *assert.Assertion, src, expected string, opts parser.Options) {
+	out, program := format(a, src, opts)
+	a.EqualNow(out, expected, src)
+
+	again, reparsed := format(a, out, opts)
+	a.EqualNow(again, out, src)
+	a.DeepEqualNow(shape(a, reparsed), shape(a, program), src)
+}
+
+func TestPrintStatements(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"var a=1,b;let c;const d=2", "var a = 1, b;\nlet c;\nconst d = 2;\n"},
+		{"if(a)b();else if(c){d()}else e()", "if (a)\n  b();\nelse if (c) {\n  d();\n} else\n  e();\n"},
+		{"if (a) if (b) c(); else d();", "if (a)\n  if (b)\n    c();\n  else\n    d();\n"},
+		{"if (a) { if (b) c(); } else d();", "if (a) {\n  if (b)\n    c();\n} else\n  d();\n"},
+		{"for(var i=0;i<n;i++){}for(;;);for(x in o)for(const y of z)continue", "for (var i = 0; i < n; i++) {}\n" +
+			"for (;;);\nfor (x in o)\n  for (const y of z)\n    continue;\n"},
+		{"for (var a = (b in c);;);for ((a in b);;);", "for (var a = (b in c);;);\nfor ((a in b);;);\n"},
+		{"while(a)b--;do{a()}while(b);do a();while(b)", "while (a)\n  b--;\ndo {\n  a();\n} while (b);\n" +
+			"do\n  a();\nwhile (b);\n"},
+		{"l:for(;;){break l}", "l: for (;;) {\n  break l;\n}\n"},
+		{"function f(a,b=1,...c){return}function*g(){yield;yield*a}async function h(){await a}",
+			"function f(a, b = 1, ...c) {\n  return;\n}\nfunction* g() {\n  yield;\n  yield* a;\n}\n" +
+				"async function h() {\n  await a;\n}\n"},
+		{"with(a)b;debugger;;", "with (a)\n  b;\ndebugger;\n;\n"},
+		{"'use strict';('a');\"b\"", "'use strict';\n('a');\n\"b\";\n"},
+		{"a\n\n\nb\nc", "a;\n\nb;\nc;\n"},
+	}
+	for _, test := range tests {
+		testFormat(a, test.input, test.expected, parser.Options{})
+	}
+}
+
+func TestPrintExpressions(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"(a+b)*c;a+(b*c);a-(b-c);(a-b)-c", "(a + b) * c;\na + b * c;\na - (b - c);\na - b - c;\n"},
+		{"(a**b)**c;a**(b**c);(-a)**b;(++a)**b;(a++)**b", "(a ** b) ** c;\na ** b ** c;\n(-a) ** b;\n(++a) ** b;\na++ ** b;\n"},
+		{"(a||b)??c;a??(b&&c);(a&&b)||c;a&&(b||c)", "(a || b) ?? c;\na ?? (b && c);\na && b || c;\na && (b || c);\n"},
+		{"(a,b);f((a,b));x=(a,b);(a=b)?c:d;a?b:(c,d);(a?b:c)?d:e", "a, b;\nf((a, b));\nx = (a, b);\n" +
+			"(a = b) ? c : d;\na ? b : (c, d);\n(a ? b : c) ? d : e;\n"},
+		{"-(-a);+(+a);-(--a);+(-a);!(!a);typeof(a);void(0);delete(a.b)", "- -a;\n+ +a;\n- --a;\n+-a;\n!!a;\n" +
+			"typeof a;\nvoid 0;\ndelete a.b;\n"},
+		{"(a.b)();(a())();new (a())();new (a.b().c)();new a.b();new a;(new a).b", "a.b();\na()();\n" +
+			"new (a())();\nnew (a.b().c)();\nnew a.b();\nnew a();\nnew a().b;\n"},
+		{"(1).a;1.5.a;(a+b).c;(a=>a).b;(async()=>{})()", "(1).a;\n1.5.a;\n(a + b).c;\n((a) => a).b;\n(async () => {})();\n"},
+		{"({}).a;({a}=b);(function(){})();(function(){}).call();[a]=b", "({}).a;\n({a} = b);\n(function () {})();\n" +
+			"(function () {}).call();\n[a] = b;\n"},
+		{"x=()=>({});x=()=>({}).a;x=async(a,[b],{c})=>a,b", "x = () => ({});\nx = () => ({}).a;\n" +
+			"x = async (a, [b], {c}) => a, b;\n"},
+		{"x=[,];x=[a,,];x=[,a];x=[...a,b]", "x = [,];\nx = [a, ,];\nx = [, a];\nx = [...a, b];\n"},
+		{"x={a,b:1,[c]:2,'d':3,4:e,...f}", "x = {a, b: 1, [c]: 2, 'd': 3, 4: e, ...f};\n"},
+		{"x={get a(){return 1},set a(v){},m(){},async*n(){},[o](){}}", "x = {\n  get a() {\n    return 1;\n  },\n" +
+			"  set a(v) {},\n  m() {},\n  async *n() {},\n  [o]() {},\n};\n"},
+		{"x={\na:1}", "x = {\n  a: 1,\n};\n"},
+		{"var {a,b:[c=1,...d],...e}=f", "var {a, b: [c = 1, ...d], ...e} = f;\n"},
+		{"a+=b=c;a=b?c:d;a||=b;x=yield=>1", "a += b = c;\na = b ? c : d;\na ||= b;\nx = (yield) => 1;\n"},
+		{"x=function*(){yield a,b;yield(yield a)}", "x = function* () {\n  yield a, b;\n  yield yield a;\n};\n"},
+		{"x=async function(){await(a+b);await a+b}", "x = async function () {\n  await (a + b);\n  await a + b;\n};\n"},
+		{"a in b;!(a in b);a instanceof b", "a in b;\n!(a in b);\na instanceof b;\n"},
+		{"x='a';x=\"b\";x=1.50;x=true;x=null;x=this", "x = 'a';\nx = \"b\";\nx = 1.50;\nx = true;\nx = null;\nx = this;\n"},
+	}
+	for _, test := range tests {
+		testFormat(a, test.input, test.expected, parser.Options{})
+	}
+}
+
+func TestPrintModules(t *testing.T) {
+	a := assert.New(t)
+
+	testFormat(a, "import a,{b as c,d,'e' as f} from 'm' with {type:'json'};import*as ns from 'n';import 'o';"+
+		"import g,*as h from 'p';export default function(){};export{a as b,c};"+
+		"export*from 'q';export*as r from 's';export const t=1;export function u(){}",
+		"import a, {b as c, d, 'e' as f} from 'm' with {type: 'json'};\nimport * as ns from 'n';\n"+
+			"import 'o';\nimport g, * as h from 'p';\nexport default function () {}\n;\n"+
+			"export {a as b, c};\nexport * from 'q';\nexport * as r from 's';\nexport const t = 1;\n"+
+			"export function u() {}\n", parser.Options{SourceType: ast.SourceModule})
+
+	testFormat(a, "var x;export default(function(){});export{x as y};import.meta;import('a')",
+		"var x;\nexport default (function () {});\nexport {x as y};\nimport.meta;\nimport('a');\n",
+		parser.Options{SourceType: ast.SourceModule})
+}
+
+func TestPrintJSX(t *testing.T) {
+	a := assert.New(t)
+
+	testFormat(a, "x=<div a=\"1\" {...p} b={x}>hi &amp; {y}{/* c */}{...z}<a.b c:d='2'/><></></div>",
+		"x = <div a=\"1\" {...p} b={x}>hi &amp; {y}{/* c */}{...z}<a.b c:d='2' /><></></div>;\n",
+		parser.Options{JSX: true})
+	testFormat(a, "x=<a>\n  <b />\n</a>", "x = <a>\n  <b />\n</a>;\n", parser.Options{JSX: true})
+}
+
+func TestPrintComments(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"// a\nx; // b\n\n/* c */\ny /* d */;\n// e", "// a\nx; // b\n\n/* c */\ny; /* d */\n// e\n"},
+		{"function f() { // a\n  // b\n  return /* c */ 1;\n  // d\n}", "function f() { // a\n  // b\n" +
+			"  return /* c */ 1;\n  // d\n}\n"},
+		{"f(a, // a\n  b);", "f(a, b); // a\n"},
+		{"if (a) { // a\n} else { /* b */ }", "if (a) { // a\n} else { /* b */ }\n"},
+		{"x = {\n  // a\n  a: 1, // b\n\n  b: 2,\n  // c\n};", "x = {\n  // a\n  a: 1, // b\n\n  b: 2,\n  // c\n};\n"},
+		{"f(/* a */)", "f(/* a */);\n"},
+		{"/* a */ x;", "/* a */ x;\n"},
+	}
+	for _, test := range tests {
+		testFormat(a, test.input, test.expected, parser.Options{})
+	}
+}
+
+func TestPrintNodes(t *testing.T) {
+	a := assert.New(t)
+
+	id := func(name string) *ast.Identifier {
+		return &ast.Identifier{Value: name}
+	}
+	num := func(v string) *ast.Literal {
+		return &ast.Literal{Value: v, Kind: ast.LitNumber}
+	}
+	call := func(name string) ast.Statement {
+		return &ast.ExpressionStatement{Expression: &ast.CallExpression{Callee: id(name)}}
+	}
+
+	node := &ast.TryStatement{
+		Block: &ast.BlockStatement{StatementList: []ast.Statement{
+			&ast.SwitchStatement{
+				Discriminant: id("x"),
+				Cases: []ast.SwitchCase{
+					{Test: num("1"), Consequent: []ast.Statement{call("a")}},
+					{Test: num("2")},
+				},
+				DefaultCase: &ast.SwitchCase{Consequent: []ast.Statement{&ast.ThrowStatement{Argument: id("e")}}},
+			},
+		}},
+		CatchClause: &ast.CatchClause{Param: id("e"), Body: &ast.BlockStatement{}},
+		Finally:     &ast.BlockStatement{StatementList: []ast.Statement{call("b")}},
+	}
+	buf := new(bytes.Buffer)
+	a.NilNow((&printer.Config{Indent: "\t"}).Fprint(buf, node))
+	a.EqualNow(buf.String(), "try {\n\tswitch (x) {\n\t\tcase 1:\n\t\t\ta();\n\t\tcase 2:\n\t\tdefault:\n\t\t\tthrow e;\n\t}\n"+
+		"} catch (e) {} finally {\n\tb();\n}\n")
+
+	buf.Reset()
+	a.NilNow(printer.Fprint(buf, &ast.BinaryExpression{
+		Operator: &token.Token{TokenType: token.TOKEN_STAR},
+		Left:     &ast.BinaryExpression{Operator: &token.Token{TokenType: token.TOKEN_PLUS}, Left: id("a"), Right: id("b")},
+		Right:    id("c"),
+	}))
+	a.EqualNow(buf.String(), "(a + b) * c")
+
+	a.NotNilNow(printer.Fprint(buf, &ast.BadStatement{}))
+}
+
+func TestPrintCompact(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"var a = 1, b; // a\nlet c = 'x y';\n\nif (a) b(); else { c(); }", "var a=1,b;let c='x y';if(a)b();else{c();}"},
+		{"a + +b; a - -b; a++ + b; a + ++b; a - b", "a+ +b;a- -b;a++ +b;a+ ++b;a-b;"},
+		{"function f(a) { return typeof a in b; }", "function f(a){return typeof a in b;}"},
+		{"x = {\n  a: 1,\n  m() {},\n};\ndo a(); while (b)", "x={a:1,m(){}};do a();while(b);"},
+		{"#!/usr/bin/env gjs\nx", "#!/usr/bin/env gjs\nx;"},
+	}
+	for _, test := range tests {
+		program, err := parser.NewWithOptions(lexer.New([]byte(test.input)), parser.Options{
+			AllowHashBang: true,
+			Comments:      true,
+		}).Parse()
+		a.NilNow(err)
+		buf := new(bytes.Buffer)
+		a.NilNow((&printer.Config{Compact: true}).Fprint(buf, program))
+		a.EqualNow(buf.String(), test.expected)
+
+		reparsed, err := parser.NewWithOptions(lexer.New(buf.Bytes()), parser.Options{AllowHashBang: true}).Parse()
+		a.NilNow(err)
+		a.DeepEqualNow(shape(a, reparsed), shape(a, program))
+	}
+}
+
+func TestPrintSourceMap(t *testing.T) {
+	a := assert.New(t)
+
+	src := "function f(a) {\n  return a;\n}\n\nf(1);\n"
+	program, err := parser.NewWithOptions(lexer.New([]byte(src)), parser.Options{FileName: "main.js"}).Parse()
+	a.NilNow(err)
+
+	tests := []struct {
+		compact  bool
+		expected string
+		mappings [][4]int
+	}{
+		{true, "function f(a){return a;}f(1);", [][4]int{
+			{1, 1, 1, 1}, {1, 10, 1, 10}, {1, 12, 1, 12}, {1, 15, 2, 3}, {1, 22, 2, 10}, {1, 25, 5, 1}, {1, 27, 5, 3},
+		}},
+		{false, "function f(a) {\n  return a;\n}\n\nf(1);\n", [][4]int{
+			{1, 10, 1, 10}, {2, 3, 2, 3}, {2, 10, 2, 10}, {5, 1, 5, 1}, {5, 3, 5, 3},
+		}},
+	}
+	for _, test := range tests {
+		g := sourcemap.NewGenerator("out.js")
+		buf := new(bytes.Buffer)
+		a.NilNow((&printer.Config{Compact: test.compact, SourceMap: g}).Fprint(buf, program))
+		a.EqualNow(buf.String(), test.expected)
+
+		m := g.Map()
+		a.DeepEqualNow(m.Sources, []string{"main.js"})
+		for _, pos := range test.mappings {
+			mapping, ok := m.Lookup(pos[0], pos[1])
+			a.TrueNow(ok, pos)
+			a.EqualNow(mapping.Source, "main.js", pos)
+			a.EqualNow([2]int{mapping.Line, mapping.Col}, [2]int{pos[2], pos[3]}, pos)
+		}
+	}
+}
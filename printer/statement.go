@@ -0,0 +1,432 @@
+package printer
+
+import (
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/token"
+)
+
+func (p *printer) statement(node ast.Node) {
+	p.mark(node.Pos())
+	switch n := node.(type) {
+	case *ast.Program:
+		first := true
+		if n.HashBang != nil {
+			p.text(n.HashBang.Raw)
+			p.setLine(n.HashBang.End())
+			if p.Compact {
+				p.buf.WriteByte('\n')
+			}
+			first = false
+		}
+		p.statementList(n.Statements, first)
+
+	case *ast.BlockStatement:
+		p.block(n)
+
+	case *ast.VarStatement:
+		p.varStatement(n)
+		p.print(";")
+
+	case *ast.LexicalDeclaration:
+		p.lexicalDeclaration(n)
+		p.print(";")
+
+	case *ast.EmptyStatement:
+		p.print(";")
+
+	case *ast.ExpressionStatement:
+		p.wrap = startOfStatement(n.Expression)
+		if lit, ok := n.Expression.(*ast.Literal); ok && lit.Kind == ast.LitString &&
+			n.Token.TokenType == token.TOKEN_LEFT_PAREN {
+			// A parenthesized string is not a directive.
+			p.wrap = lit
+		}
+		p.expr(n.Expression, precLowest)
+		p.wrap = nil
+		p.print(";")
+
+	case *ast.IfStatement:
+		p.print("if (")
+		p.expr(n.Condition, precLowest)
+		p.print(")")
+		trueBranch := n.TrueBranch
+		if n.FalseBranch != nil && hasDanglingIf(trueBranch) {
+			// The else would be taken by the nested if statement.
+			trueBranch = &ast.BlockStatement{StatementList: []ast.Statement{trueBranch}}
+		}
+		p.body(trueBranch)
+		if n.FalseBranch == nil {
+			break
+		}
+		if _, ok := trueBranch.(*ast.BlockStatement); ok {
+			p.print(" ")
+		} else {
+			p.trailingComments(n.FalseBranch.Pos())
+			p.newline()
+		}
+		p.print("else")
+		if _, ok := n.FalseBranch.(*ast.IfStatement); ok {
+			p.print(" ")
+			p.statement(n.FalseBranch)
+		} else {
+			p.body(n.FalseBranch)
+		}
+
+	case *ast.ForStatement:
+		p.print("for (")
+		switch init := n.Init.(type) {
+		case nil:
+		case *ast.VarStatement:
+			p.noIn = true
+			p.varStatement(init)
+			p.noIn = false
+		case *ast.LexicalDeclaration:
+			p.noIn = true
+			p.lexicalDeclaration(init)
+			p.noIn = false
+		case *ast.ExpressionStatement:
+			p.forInit(init.Expression)
+		default:
+			p.forInit(init)
+		}
+		p.print(";")
+		if n.Condition != nil {
+			p.print(" ")
+			p.expr(n.Condition, precLowest)
+		}
+		p.print(";")
+		if n.Update != nil {
+			p.print(" ")
+			p.expr(n.Update, precLowest)
+		}
+		p.print(")")
+		p.body(n.Body)
+
+	case *ast.ForInStatement:
+		p.print("for (")
+		p.forLeft(n.Left)
+		p.print(" in ")
+		p.expr(n.Right, precLowest)
+		p.print(")")
+		p.body(n.Body)
+
+	case *ast.ForOfStatement:
+		p.print("for ")
+		if n.Await {
+			p.print("await ")
+		}
+		p.print("(")
+		p.forLeft(n.Left)
+		p.print(" of ")
+		p.expr(n.Right, precAssignment)
+		p.print(")")
+		p.body(n.Body)
+
+	case *ast.WhileStatement:
+		p.print("while (")
+		p.expr(n.Condition, precLowest)
+		p.print(")")
+		p.body(n.Body)
+
+	case *ast.WithStatement:
+		p.print("with (")
+		p.expr(n.Object, precLowest)
+		p.print(")")
+		p.body(n.Body)
+
+	case *ast.DoWhileStatement:
+		p.print("do")
+		p.body(n.Body)
+		if _, ok := n.Body.(*ast.BlockStatement); ok {
+			p.print(" ")
+		} else {
+			p.trailingComments(n.Condition.Pos())
+			p.newline()
+		}
+		p.print("while (")
+		p.expr(n.Condition, precLowest)
+		p.print(");")
+
+	case *ast.ContinueStatement:
+		p.print("continue")
+		if n.Label != nil {
+			p.print(" ")
+			p.expr(n.Label, precLowest)
+		}
+		p.print(";")
+
+	case *ast.BreakStatement:
+		p.print("break")
+		if n.Label != nil {
+			p.print(" ")
+			p.expr(n.Label, precLowest)
+		}
+		p.print(";")
+
+	case *ast.ReturnStatement:
+		p.print("return")
+		if n.Result != nil {
+			p.print(" ")
+			p.expr(n.Result, precLowest)
+		}
+		p.print(";")
+
+	case *ast.SwitchStatement:
+		p.print("switch (")
+		p.expr(n.Discriminant, precLowest)
+		p.print(") {")
+		p.setLine(n.Discriminant.End())
+		p.level++
+		for i, c := range switchCases(n) {
+			p.lineBreak(c.Pos(), i == 0, false)
+			p.statement(c)
+		}
+		p.lineBreak(n.RBrace.Pos(), false, true)
+		p.level--
+		p.print("}")
+
+	case *ast.SwitchCase:
+		if n.Test != nil {
+			p.print("case ")
+			p.expr(n.Test, precLowest)
+			p.print(":")
+		} else {
+			p.print("default:")
+		}
+		p.setLine(n.Colon.End())
+		p.level++
+		p.statementList(n.Consequent, true)
+		p.level--
+
+	case *ast.LabeledStatement:
+		p.expr(n.Label, precLowest)
+		p.print(": ")
+		p.statement(n.Statement)
+
+	case *ast.ThrowStatement:
+		p.print("throw ")
+		p.expr(n.Argument, precLowest)
+		p.print(";")
+
+	case *ast.TryStatement:
+		p.print("try ")
+		p.block(n.Block)
+		if n.CatchClause != nil {
+			p.print(" ")
+			p.statement(n.CatchClause)
+		}
+		if n.Finally != nil {
+			p.print(" finally ")
+			p.block(n.Finally)
+		}
+
+	case *ast.CatchClause:
+		p.print("catch ")
+		if n.Param != nil {
+			p.print("(")
+			p.expr(n.Param, precLowest)
+			p.print(") ")
+		}
+		p.block(n.Body)
+
+	case *ast.DebuggerStatement:
+		p.print("debugger;")
+
+	case *ast.FunctionDeclaration:
+		p.function(n.Name, n.Params, n.Body, n.Async, n.Generator)
+
+	case *ast.ImportDeclaration, *ast.ExportNamedDeclaration, *ast.ExportDefaultDeclaration,
+		*ast.ExportAllDeclaration:
+		p.moduleItem(n)
+
+	case *ast.BadStatement:
+		p.fail("cannot print a bad statement at %s", n.Pos())
+
+	default:
+		p.fail("unexpected statement %T", n)
+	}
+}
+
+// statementList prints list, a statement a line.
+func (p *printer) statementList(list []ast.Statement, first bool) {
+	for _, stmt := range list {
+		p.lineBreak(stmt.Pos(), first, false)
+		p.statement(stmt)
+		p.setLine(stmt.End())
+		first = false
+	}
+}
+
+func (p *printer) block(b *ast.BlockStatement) {
+	p.print("{")
+	if len(b.StatementList) == 0 && p.inlineBlock(b) {
+		empty := true
+		for {
+			c, ok := p.nextComment(b.RBrace.Pos())
+			if !ok {
+				break
+			}
+			p.print(" " + c.Raw)
+			empty = false
+		}
+		if !empty {
+			p.print(" ")
+		}
+		p.print("}")
+		return
+	}
+	p.setLine(b.LBrace.End())
+	p.level++
+	p.statementList(b.StatementList, true)
+	p.lineBreak(b.RBrace.Pos(), len(b.StatementList) == 0, true)
+	p.level--
+	p.print("}")
+}
+
+// inlineBlock reports whether the empty block b is printed on a line, as it
+// has no comments but the ones between its braces on a line.
+func (p *printer) inlineBlock(b *ast.BlockStatement) bool {
+	for _, c := range p.comments {
+		if c.Offset >= b.RBrace.Offset {
+			break
+		}
+		if c.TokenType != token.TOKEN_MULTI_LINE_COMMENT || c.Line != b.LBrace.Line ||
+			c.End().Line != b.RBrace.Line {
+			return false
+		}
+	}
+	return true
+}
+
+// body prints the body of a compound statement, after the head printed on
+// the current line.
+func (p *printer) body(stmt ast.Statement) {
+	switch stmt.(type) {
+	case *ast.BlockStatement:
+		p.print(" ")
+		p.statement(stmt)
+	case *ast.EmptyStatement:
+		p.print(";")
+	default:
+		p.level++
+		p.lineBreak(stmt.Pos(), true, false)
+		p.statement(stmt)
+		p.setLine(stmt.End())
+		p.level--
+	}
+}
+
+// hasDanglingIf reports whether stmt ends with an if statement without an
+// else branch.
+func hasDanglingIf(stmt ast.Statement) bool {
+	for {
+		switch n := stmt.(type) {
+		case *ast.IfStatement:
+			if n.FalseBranch == nil {
+				return true
+			}
+			stmt = n.FalseBranch
+		case *ast.ForStatement:
+			stmt = n.Body
+		case *ast.ForInStatement:
+			stmt = n.Body
+		case *ast.ForOfStatement:
+			stmt = n.Body
+		case *ast.WhileStatement:
+			stmt = n.Body
+		case *ast.WithStatement:
+			stmt = n.Body
+		case *ast.LabeledStatement:
+			stmt = n.Statement
+		default:
+			return false
+		}
+	}
+}
+
+// switchCases returns the cases of a switch statement in the order of the
+// source, where the default case may be between the other ones.
+func switchCases(n *ast.SwitchStatement) []*ast.SwitchCase {
+	cases := make([]*ast.SwitchCase, 0, len(n.Cases)+1)
+	for i := range n.Cases {
+		c := &n.Cases[i]
+		if n.DefaultCase != nil && len(cases) == i && n.DefaultCase.Token.Pos().IsValid() &&
+			c.Token.Pos().IsValid() && n.DefaultCase.Token.Offset < c.Token.Offset {
+			cases = append(cases, n.DefaultCase)
+		}
+		cases = append(cases, c)
+	}
+	if n.DefaultCase != nil && len(cases) == len(n.Cases) {
+		cases = append(cases, n.DefaultCase)
+	}
+	return cases
+}
+
+func (p *printer) varStatement(n *ast.VarStatement) {
+	p.print("var ")
+	p.declarations(n.Declarations)
+}
+
+func (p *printer) lexicalDeclaration(n *ast.LexicalDeclaration) {
+	if n.Const {
+		p.print("const ")
+	} else {
+		p.print("let ")
+	}
+	p.declarations(n.Declarations)
+}
+
+func (p *printer) declarations(list []ast.Declaration) {
+	for i, decl := range list {
+		if i > 0 {
+			p.print(", ")
+		}
+		p.expr(decl, precLowest)
+	}
+}
+
+// forInit prints the expression that initializes a for statement, which is
+// parenthesized if it contains the in operator.
+func (p *printer) forInit(expr ast.Expression) {
+	if containsIn(expr) {
+		p.print("(")
+		p.expr(expr, precLowest)
+		p.print(")")
+	} else {
+		p.expr(expr, precLowest)
+	}
+}
+
+// forLeft prints the left side of a for-in or a for-of statement.
+func (p *printer) forLeft(left ast.Node) {
+	switch n := left.(type) {
+	case *ast.VarStatement:
+		p.varStatement(n)
+	case *ast.LexicalDeclaration:
+		p.lexicalDeclaration(n)
+	default:
+		if id, ok := n.(*ast.Identifier); ok && (id.Value == "let" || id.Value == "async") {
+			p.wrap = id
+		}
+		p.expr(n, precCall)
+		p.wrap = nil
+	}
+}
+
+// containsIn reports whether the in operator is used in expr outside of a
+// function.
+func containsIn(expr ast.Expression) bool {
+	found := false
+	ast.Inspect(expr, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.BinaryExpression:
+			if n.Operator.TokenType == token.TOKEN_IN {
+				found = true
+			}
+		case *ast.FunctionExpression:
+			return false
+		}
+		return !found
+	})
+	return found
+}
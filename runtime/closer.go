@@ -0,0 +1,47 @@
+package runtime
+
+import "sync"
+
+// closerSet holds the functions that release the resources of a runtime, such
+// as the goroutines of suspended coroutines, when the runtime is closed.
+type closerSet struct {
+	mu   sync.Mutex
+	next int
+	fns  map[int]func()
+}
+
+// OnClose registers fn to be called when the runtime is closed, and returns a
+// function that unregisters it.
+func (e *Runtime) OnClose(fn func()) func() {
+	c := e.closers
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.fns == nil {
+		c.fns = make(map[int]func())
+	}
+	id := c.next
+	c.next++
+	c.fns[id] = fn
+
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.fns, id)
+	}
+}
+
+// Close discards the pending jobs of the runtime and calls the functions
+// registered by OnClose.
+func (e *Runtime) Close() {
+	c := e.closers
+	c.mu.Lock()
+	fns := c.fns
+	c.fns = nil
+	c.mu.Unlock()
+
+	e.jobs.jobs = nil
+	for _, fn := range fns {
+		fn()
+	}
+}
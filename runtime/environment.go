@@ -1,34 +1,197 @@
 package runtime
 
 import (
+	"errors"
+
+	"github.com/ghosind/gjs/sourcemap"
 	"github.com/ghosind/gjs/value"
 )
 
+var (
+	ErrAssignToConstant = errors.New("Assignment to constant variable.")
+	ErrNotDefined       = errors.New("is not defined")
+)
+
 type Runtime struct {
-	store map[string]value.Value
-	outer *Runtime
+	store  map[string]value.Value
+	consts map[string]bool
+	outer  *Runtime
+	jobs   *JobQueue
+	// closers is shared by a runtime and all of its enclosed environments.
+	closers *closerSet
+	// object is the binding object of the global environment, or of an
+	// object environment created by a with statement.
+	object *value.Object
+	// module is the record of the module of a module environment.
+	module *Module
+	// sourceMaps are the source maps of the programs by their file names,
+	// registered in the global environment.
+	sourceMaps map[string]*sourcemap.Map
 }
 
+// Module is the record of a module evaluated in a runtime.
+type Module struct {
+	// Default is the value of the default export, or nil if the module has
+	// no default export.
+	Default value.Value
+	// Meta is the import.meta object of the module, created when it is first
+	// accessed.
+	Meta *value.Object
+}
+
+// New returns a global environment, whose var bindings are the properties of
+// a new global object.
 func New() *Runtime {
-	s := make(map[string]value.Value)
-	return &Runtime{store: s, outer: nil}
+	env := newEnvironment()
+	env.jobs = new(JobQueue)
+	env.closers = new(closerSet)
+	env.object = value.NewObject()
+	return env
+}
+
+func newEnvironment() *Runtime {
+	return &Runtime{store: make(map[string]value.Value)}
 }
 
 func NewEnclosedEnvironment(outer *Runtime) *Runtime {
-	env := New()
+	env := newEnvironment()
 	env.outer = outer
+	env.jobs = outer.jobs
+	env.closers = outer.closers
+	return env
+}
+
+// NewObjectEnvironment returns an environment whose bindings are the
+// properties of obj, as created by a with statement.
+func NewObjectEnvironment(obj *value.Object, outer *Runtime) *Runtime {
+	env := NewEnclosedEnvironment(outer)
+	env.object = obj
 	return env
 }
 
+// NewModuleEnvironment returns the environment of the top level of a module,
+// which holds the bindings and the record of the module.
+func NewModuleEnvironment(outer *Runtime) *Runtime {
+	env := NewEnclosedEnvironment(outer)
+	env.module = new(Module)
+	return env
+}
+
+// Module returns the record of the module that e belongs to, or nil if e is
+// not in a module.
+func (e *Runtime) Module() *Module {
+	for env := e; env != nil; env = env.outer {
+		if env.module != nil {
+			return env.module
+		}
+	}
+	return nil
+}
+
+func (e *Runtime) Jobs() *JobQueue {
+	return e.jobs
+}
+
+// GlobalObject returns the global object of the global environment of e.
+func (e *Runtime) GlobalObject() *value.Object {
+	return e.Global().object
+}
+
+// lookup returns the value of name bound in this environment, ignoring the
+// outer environments. The declarative bindings shadow the properties of the
+// binding object.
+func (e *Runtime) lookup(name string) (value.Value, bool) {
+	if val, ok := e.store[name]; ok {
+		return val, true
+	} else if e.object != nil {
+		return e.object.Get(name)
+	}
+	return nil, false
+}
+
 func (e *Runtime) Get(name string) (value.Value, bool) {
-	obj, ok := e.store[name]
-	if !ok && e.outer != nil {
-		return e.outer.Get(name)
+	for env := e; env != nil; env = env.outer {
+		if val, ok := env.lookup(name); ok {
+			return val, true
+		}
 	}
-	return obj, ok
+	return nil, false
+}
+
+// Has reports whether name is bound in this environment, ignoring the outer
+// environments.
+func (e *Runtime) Has(name string) bool {
+	_, ok := e.lookup(name)
+	return ok
 }
 
+// Set binds name in this environment. In the global environment, the binding
+// is a property of the global object.
 func (e *Runtime) Set(name string, val value.Value) value.Value {
+	if e.object != nil {
+		e.object.Set(name, val)
+		return val
+	}
+	return e.SetLexical(name, val)
+}
+
+// SetLexical binds name in the declarative part of this environment, as let
+// declarations do. It is not visible as a property of the global object.
+func (e *Runtime) SetLexical(name string, val value.Value) value.Value {
 	e.store[name] = val
 	return val
 }
+
+func (e *Runtime) SetConst(name string, val value.Value) value.Value {
+	if e.consts == nil {
+		e.consts = make(map[string]bool)
+	}
+	e.consts[name] = true
+	return e.SetLexical(name, val)
+}
+
+// Assign updates the nearest binding of name. It returns ErrNotDefined if the
+// name cannot be resolved.
+func (e *Runtime) Assign(name string, val value.Value) error {
+	for env := e; env != nil; env = env.outer {
+		if _, ok := env.store[name]; ok {
+			if env.consts[name] {
+				return ErrAssignToConstant
+			}
+			env.store[name] = val
+			return nil
+		}
+		if env.object != nil {
+			if _, ok := env.object.Get(name); ok {
+				env.object.Set(name, val)
+				return nil
+			}
+		}
+	}
+
+	return ErrNotDefined
+}
+
+func (e *Runtime) Global() *Runtime {
+	env := e
+	for env.outer != nil {
+		env = env.outer
+	}
+	return env
+}
+
+// Copy returns a new environment with the same outer environment and a copy
+// of the bindings of e.
+func (e *Runtime) Copy() *Runtime {
+	env := newEnvironment()
+	env.outer = e.outer
+	env.jobs = e.jobs
+	env.closers = e.closers
+	for name, val := range e.store {
+		env.store[name] = val
+	}
+	for name := range e.consts {
+		env.SetConst(name, e.store[name])
+	}
+	return env
+}
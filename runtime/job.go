@@ -0,0 +1,42 @@
+package runtime
+
+// Job is a unit of work waiting in a job queue, such as a promise reaction.
+type Job func()
+
+// JobQueue is the FIFO microtask queue shared by a runtime and all of its
+// enclosed environments. The host drives it after each evaluation.
+type JobQueue struct {
+	jobs []Job
+}
+
+func (q *JobQueue) Enqueue(job Job) {
+	q.jobs = append(q.jobs, job)
+}
+
+func (q *JobQueue) Len() int {
+	return len(q.jobs)
+}
+
+// RunNext runs the oldest pending job, and reports whether there was one.
+func (q *JobQueue) RunNext() bool {
+	if len(q.jobs) == 0 {
+		return false
+	}
+
+	job := q.jobs[0]
+	q.jobs[0] = nil
+	q.jobs = q.jobs[1:]
+	job()
+
+	return true
+}
+
+// Run runs jobs until the queue is empty, including the jobs enqueued by the
+// running ones, and returns the number of jobs it ran.
+func (q *JobQueue) Run() int {
+	n := 0
+	for q.RunNext() {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,29 @@
+package runtime
+
+import "github.com/ghosind/gjs/sourcemap"
+
+// SetSourceMap registers m as the source map of the program from file, whose
+// positions are reported as the positions in the sources it maps to.
+func (e *Runtime) SetSourceMap(file string, m *sourcemap.Map) {
+	global := e.Global()
+	if global.sourceMaps == nil {
+		global.sourceMaps = make(map[string]*sourcemap.Map)
+	}
+	global.sourceMaps[file] = m
+}
+
+// OriginalPosition returns the position in the original source of the
+// position at line and col in file, by the source map registered for file.
+// The position is returned as it is if file has no source map, or if the
+// position maps to no source.
+func (e *Runtime) OriginalPosition(file string, line, col int) (string, int, int) {
+	m := e.Global().sourceMaps[file]
+	if m == nil {
+		return file, line, col
+	}
+	mapping, ok := m.Lookup(line, col)
+	if !ok {
+		return file, line, col
+	}
+	return mapping.Source, mapping.Line, mapping.Col
+}
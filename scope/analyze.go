@@ -0,0 +1,493 @@
+package scope
+
+import (
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/token"
+)
+
+type analyzer struct {
+	info  *Info
+	scope *Scope
+	refs  []*Reference
+}
+
+// Analyze analyzes the scopes of program. A module has a module scope in
+// the global scope, which is the scope of the program in Info.Scopes.
+//
+// The function declarations in blocks are bound in the blocks, as in strict
+// mode code. The references are resolved to their declarations in the scopes
+// around them, ignoring the objects of with statements, and the names that
+// are not declared are resolved to implicit variables of the global scope.
+func Analyze(program *ast.Program) *Info {
+	a := &analyzer{info: &Info{
+		Scopes:       make(map[ast.Node]*Scope),
+		Declarations: make(map[*ast.Identifier]*Variable),
+		References:   make(map[*ast.Identifier]*Reference),
+	}}
+	a.push(Global, program)
+	a.info.Global = a.scope
+	if program.SourceType == ast.SourceModule {
+		a.push(Module, program)
+	}
+	a.children(program)
+	a.resolve()
+	return a.info
+}
+
+// resolve resolves the references to the variables visible in their scopes.
+func (a *analyzer) resolve() {
+	for _, ref := range a.refs {
+		v := ref.Scope.Lookup(ref.Name)
+		if v == nil {
+			v = a.info.Global.declare(ref.Name, DeclImplicit)
+		}
+		ref.Resolved = v
+		v.References = append(v.References, ref)
+		for s := ref.Scope; s != v.Scope; s = s.Parent {
+			s.Through = append(s.Through, ref)
+		}
+		if ref.Scope.FunctionScope() != v.Scope.FunctionScope() {
+			v.Captured = true
+		}
+	}
+}
+
+// declare returns the variable named name in s, declaring it with kind if it
+// is not declared yet.
+func (s *Scope) declare(name string, kind DeclKind) *Variable {
+	v, ok := s.variables[name]
+	if !ok {
+		v = &Variable{Name: name, Kind: kind, Scope: s}
+		s.variables[name] = v
+		s.Variables = append(s.Variables, v)
+	}
+	return v
+}
+
+func (a *analyzer) push(kind Kind, node ast.Node) {
+	a.scope = newScope(kind, node, a.scope)
+	a.info.Scopes[node] = a.scope
+}
+
+func (a *analyzer) pop() {
+	a.scope = a.scope.Parent
+}
+
+// declare declares the variable of id, in the scope of the function for var
+// declarations, or in the current scope.
+func (a *analyzer) declare(id *ast.Identifier, kind DeclKind) {
+	s := a.scope
+	if kind == DeclVar {
+		s = s.FunctionScope()
+	}
+	v := s.declare(id.Value, kind)
+	v.Identifiers = append(v.Identifiers, id)
+	a.info.Declarations[id] = v
+}
+
+// reference records the reference of node to name in the current scope.
+func (a *analyzer) reference(node ast.Node, name string, read, write bool) {
+	ref := &Reference{Node: node, Name: name, Scope: a.scope, Read: read, Write: write}
+	a.scope.References = append(a.scope.References, ref)
+	a.refs = append(a.refs, ref)
+	if id, ok := node.(*ast.Identifier); ok {
+		a.info.References[id] = ref
+	}
+}
+
+func (a *analyzer) visit(node ast.Node) {
+	ast.Inspect(node, a.inspect)
+}
+
+// children visits the children of node in the current scope.
+func (a *analyzer) children(node ast.Node) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		if n == node {
+			return true
+		}
+		return a.inspect(n)
+	})
+}
+
+// inspect records the scopes, the declarations and the references of node,
+// and reports whether its children are still to be visited.
+func (a *analyzer) inspect(node ast.Node) bool {
+	switch n := node.(type) {
+	case *ast.Identifier:
+		a.reference(n, n.Value, true, false)
+		return false
+
+	case *ast.FunctionDeclaration:
+		if n.Name != nil {
+			a.declare(n.Name, DeclFunction)
+		}
+		a.function(n, n.Params, n.Body)
+		return false
+
+	case *ast.FunctionExpression:
+		if n.Name != nil {
+			// The name of a function expression is bound in a scope of its
+			// own, around the scope of the function.
+			a.scope = newScope(FunctionName, n, a.scope)
+			a.declare(n.Name, DeclFunctionName)
+			a.function(n, n.Params, n.Body)
+			a.pop()
+		} else {
+			a.function(n, n.Params, n.Body)
+		}
+		return false
+
+	case *ast.ArrowFunctionExpression:
+		a.function(n, n.Params, n.Body)
+		return false
+
+	case *ast.BlockStatement:
+		a.push(Block, n)
+		a.children(n)
+		a.pop()
+		return false
+
+	case *ast.ForStatement:
+		// The let and const declarations of the head are bound in a scope
+		// around the loop.
+		a.push(Block, n)
+		a.children(n)
+		a.pop()
+		return false
+
+	case *ast.ForInStatement:
+		a.push(Block, n)
+		a.forLeft(n.Left)
+		a.visit(n.Right)
+		a.visit(n.Body)
+		a.pop()
+		return false
+
+	case *ast.ForOfStatement:
+		a.push(Block, n)
+		a.forLeft(n.Left)
+		a.visit(n.Right)
+		a.visit(n.Body)
+		a.pop()
+		return false
+
+	case *ast.SwitchStatement:
+		a.visit(n.Discriminant)
+		a.push(Block, n)
+		for i := range n.Cases {
+			a.visit(&n.Cases[i])
+		}
+		if n.DefaultCase != nil {
+			a.visit(n.DefaultCase)
+		}
+		a.pop()
+		return false
+
+	case *ast.CatchClause:
+		a.push(Catch, n)
+		if n.Param != nil {
+			a.declare(n.Param, DeclCatchParam)
+		}
+		a.visit(n.Body)
+		a.pop()
+		return false
+
+	case *ast.WithStatement:
+		a.visit(n.Object)
+		a.push(With, n)
+		a.visit(n.Body)
+		a.pop()
+		return false
+
+	case *ast.VarStatement:
+		a.declarations(n.Declarations, DeclVar)
+		return false
+
+	case *ast.LexicalDeclaration:
+		kind := DeclLet
+		if n.Const {
+			kind = DeclConst
+		}
+		a.declarations(n.Declarations, kind)
+		return false
+
+	case *ast.AssignmentExpression:
+		id, ok := n.Left.(*ast.Identifier)
+		if ok {
+			a.reference(id, id.Value, n.Operator.TokenType != token.TOKEN_EQUAL, true)
+		} else {
+			a.target(n.Left)
+		}
+		a.visit(n.Right)
+		return false
+
+	case *ast.UpdateExpression:
+		if id, ok := n.Argument.(*ast.Identifier); ok {
+			a.reference(id, id.Value, true, true)
+			return false
+		}
+
+	case *ast.MemberExpression:
+		a.visit(n.Object)
+		if n.Computed {
+			a.visit(n.Property)
+		}
+		return false
+
+	case *ast.Property:
+		if n.Computed {
+			a.visit(n.Key)
+		}
+		a.visit(n.Value)
+		return false
+
+	case *ast.LabeledStatement:
+		a.visit(n.Statement)
+		return false
+
+	case *ast.BreakStatement, *ast.ContinueStatement, *ast.MetaProperty, *ast.ExportAllDeclaration:
+		return false
+
+	case *ast.ImportDeclaration:
+		for _, spec := range n.Specifiers {
+			switch spec := spec.(type) {
+			case *ast.ImportSpecifier:
+				a.declare(spec.Local, DeclImport)
+			case *ast.ImportDefaultSpecifier:
+				a.declare(spec.Local, DeclImport)
+			case *ast.ImportNamespaceSpecifier:
+				a.declare(spec.Local, DeclImport)
+			}
+		}
+		return false
+
+	case *ast.ExportNamedDeclaration:
+		if n.Declaration != nil {
+			a.visit(n.Declaration)
+			return false
+		}
+		if n.Source != nil {
+			return false
+		}
+		for _, spec := range n.Specifiers {
+			if local, ok := spec.Local.(*ast.Identifier); ok {
+				a.reference(local, local.Value, true, false)
+			}
+		}
+		return false
+
+	case *ast.JSXOpeningElement:
+		a.jsxName(n.Name)
+		for _, attr := range n.Attributes {
+			a.visit(attr)
+		}
+		return false
+
+	case *ast.JSXClosingElement:
+		a.jsxName(n.Name)
+		return false
+
+	case *ast.JSXAttribute:
+		if n.Value != nil {
+			a.visit(n.Value)
+		}
+		return false
+	}
+	return true
+}
+
+// function records the scope of a function, which holds its parameters and
+// the declarations of its body.
+func (a *analyzer) function(node ast.Node, params []ast.Expression, body ast.Node) {
+	a.push(Function, node)
+	for _, param := range params {
+		a.pattern(param, DeclParam, false)
+	}
+	if block, ok := body.(*ast.BlockStatement); ok {
+		a.children(block)
+	} else {
+		a.visit(body)
+	}
+	a.pop()
+}
+
+func (a *analyzer) declarations(list []ast.Declaration, kind DeclKind) {
+	for _, decl := range list {
+		decl := decl.(*ast.VariableDeclaration)
+		a.pattern(decl.Name, kind, decl.Value != nil)
+		if decl.Value != nil {
+			a.visit(decl.Value)
+		}
+	}
+}
+
+// forLeft records the head of a for-in or a for-of statement, whose bindings
+// or targets are written by each iteration.
+func (a *analyzer) forLeft(left ast.Node) {
+	switch n := left.(type) {
+	case *ast.VarStatement:
+		a.declarations(n.Declarations, DeclVar)
+		a.writeDeclarations(n.Declarations)
+	case *ast.LexicalDeclaration:
+		kind := DeclLet
+		if n.Const {
+			kind = DeclConst
+		}
+		a.declarations(n.Declarations, kind)
+		a.writeDeclarations(n.Declarations)
+	case ast.Expression:
+		a.target(n)
+	}
+}
+
+// writeDeclarations records the writes of the bindings of the declarations
+// of the head of a for-in or a for-of statement, which have no initializers.
+func (a *analyzer) writeDeclarations(list []ast.Declaration) {
+	for _, decl := range list {
+		decl := decl.(*ast.VariableDeclaration)
+		if decl.Value == nil {
+			for _, id := range boundNames(decl.Name) {
+				a.reference(id, id.Value, false, true)
+			}
+		}
+	}
+}
+
+// pattern declares the bindings of a binding pattern with kind, and records
+// their writes if the pattern is initialized.
+func (a *analyzer) pattern(node ast.Expression, kind DeclKind, init bool) {
+	switch n := node.(type) {
+	case *ast.Identifier:
+		a.declare(n, kind)
+		if init {
+			a.reference(n, n.Value, false, true)
+		}
+	case *ast.AssignmentPattern:
+		a.pattern(n.Left, kind, true)
+		a.visit(n.Right)
+	case *ast.ArrayPattern:
+		for _, elem := range n.Elements {
+			if elem != nil {
+				a.pattern(elem, kind, init)
+			}
+		}
+	case *ast.ObjectPattern:
+		for _, prop := range n.Properties {
+			if p, ok := prop.(*ast.Property); ok {
+				if p.Computed {
+					a.visit(p.Key)
+				}
+				a.pattern(p.Value, kind, init)
+			} else {
+				a.pattern(prop, kind, init)
+			}
+		}
+	case *ast.RestElement:
+		a.pattern(n.Argument, kind, init)
+	case *ast.Elision:
+	default:
+		a.visit(n)
+	}
+}
+
+// target records the writes of the identifiers of the target of an
+// assignment, which may be a destructuring pattern.
+func (a *analyzer) target(node ast.Expression) {
+	switch n := node.(type) {
+	case *ast.Identifier:
+		a.reference(n, n.Value, false, true)
+	case *ast.AssignmentPattern:
+		a.target(n.Left)
+		a.visit(n.Right)
+	case *ast.ArrayPattern:
+		for _, elem := range n.Elements {
+			if elem != nil {
+				a.target(elem)
+			}
+		}
+	case *ast.ArrayLiteral:
+		for _, elem := range n.ElementList {
+			if elem != nil {
+				a.target(elem)
+			}
+		}
+	case *ast.ObjectPattern:
+		for _, prop := range n.Properties {
+			a.targetProperty(prop)
+		}
+	case *ast.ObjectLiteral:
+		for _, prop := range n.Properties {
+			a.targetProperty(prop)
+		}
+	case *ast.RestElement:
+		a.target(n.Argument)
+	case *ast.SpreadElement:
+		a.target(n.Value)
+	case *ast.Elision:
+	default:
+		a.visit(n)
+	}
+}
+
+func (a *analyzer) targetProperty(prop ast.Expression) {
+	p, ok := prop.(*ast.Property)
+	if !ok {
+		a.target(prop)
+		return
+	}
+	if p.Computed {
+		a.visit(p.Key)
+	}
+	a.target(p.Value)
+}
+
+// jsxName records the reference of the name of a JSX element to a component,
+// whose name does not start with a lower case letter, or to the object of a
+// member expression.
+func (a *analyzer) jsxName(name ast.Expression) {
+	switch n := name.(type) {
+	case *ast.JSXIdentifier:
+		if n.Name != "" && (n.Name[0] < 'a' || n.Name[0] > 'z') {
+			a.reference(n, n.Name, true, false)
+		}
+	case *ast.JSXMemberExpression:
+		for {
+			member, ok := n.Object.(*ast.JSXMemberExpression)
+			if !ok {
+				break
+			}
+			n = member
+		}
+		if id, ok := n.Object.(*ast.JSXIdentifier); ok {
+			a.reference(id, id.Name, true, false)
+		}
+	}
+}
+
+// boundNames returns the identifiers bound by a binding pattern.
+func boundNames(node ast.Expression) []*ast.Identifier {
+	var ids []*ast.Identifier
+	switch n := node.(type) {
+	case *ast.Identifier:
+		ids = append(ids, n)
+	case *ast.AssignmentPattern:
+		ids = append(ids, boundNames(n.Left)...)
+	case *ast.ArrayPattern:
+		for _, elem := range n.Elements {
+			if elem != nil {
+				ids = append(ids, boundNames(elem)...)
+			}
+		}
+	case *ast.ObjectPattern:
+		for _, prop := range n.Properties {
+			if p, ok := prop.(*ast.Property); ok {
+				ids = append(ids, boundNames(p.Value)...)
+			} else {
+				ids = append(ids, boundNames(prop)...)
+			}
+		}
+	case *ast.RestElement:
+		ids = append(ids, boundNames(n.Argument)...)
+	}
+	return ids
+}
@@ -0,0 +1,232 @@
+// Package scope analyzes the scopes of a program. It builds the tree of the
+// scopes, records the variables declared in each scope, and resolves the
+// identifiers that reference them, as the bindings are resolved when the
+// program is evaluated.
+package scope
+
+import "github.com/ghosind/gjs/ast"
+
+// Kind is the kind of a scope.
+type Kind int
+
+const (
+	// Global is the scope of a script, and the root of every scope tree.
+	Global Kind = iota
+	// Module is the scope of the top level of a module.
+	Module
+	// Function is the scope of the parameters and the body of a function.
+	Function
+	// FunctionName is the scope around a named function expression, where
+	// its name is bound.
+	FunctionName
+	// Block is the scope of a block, of the head of a for statement, or of
+	// the cases of a switch statement.
+	Block
+	// Catch is the scope of the parameter of a catch clause.
+	Catch
+	// With is the scope of the body of a with statement, whose object may
+	// hold any name.
+	With
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Global:
+		return "global"
+	case Module:
+		return "module"
+	case Function:
+		return "function"
+	case FunctionName:
+		return "function-name"
+	case Block:
+		return "block"
+	case Catch:
+		return "catch"
+	case With:
+		return "with"
+	}
+	return "unknown"
+}
+
+// DeclKind is the kind of the declaration of a variable.
+type DeclKind int
+
+const (
+	DeclVar DeclKind = iota
+	DeclLet
+	DeclConst
+	DeclFunction
+	DeclParam
+	DeclCatchParam
+	DeclImport
+	// DeclFunctionName is the name of a named function expression.
+	DeclFunctionName
+	// DeclImplicit is a global variable that is referenced but not declared
+	// in the program.
+	DeclImplicit
+)
+
+func (k DeclKind) String() string {
+	switch k {
+	case DeclVar:
+		return "var"
+	case DeclLet:
+		return "let"
+	case DeclConst:
+		return "const"
+	case DeclFunction:
+		return "function"
+	case DeclParam:
+		return "param"
+	case DeclCatchParam:
+		return "catch-param"
+	case DeclImport:
+		return "import"
+	case DeclFunctionName:
+		return "function-name"
+	case DeclImplicit:
+		return "implicit"
+	}
+	return "unknown"
+}
+
+// Scope is a scope of the variables of a program.
+type Scope struct {
+	Kind Kind
+	// Node is the node that creates the scope, which is the program for the
+	// global and the module scopes.
+	Node     ast.Node
+	Parent   *Scope
+	Children []*Scope
+	// Variables are the variables declared in the scope, in the order they
+	// are declared.
+	Variables []*Variable
+	// References are the references made in the scope, not in its children.
+	References []*Reference
+	// Through are the references made in the scope or in its children that
+	// are resolved out of the scope.
+	Through []*Reference
+
+	variables map[string]*Variable
+}
+
+func newScope(kind Kind, node ast.Node, parent *Scope) *Scope {
+	s := &Scope{Kind: kind, Node: node, Parent: parent, variables: make(map[string]*Variable)}
+	if parent != nil {
+		parent.Children = append(parent.Children, s)
+	}
+	return s
+}
+
+// Variable returns the variable named name declared in s, or nil.
+func (s *Scope) Variable(name string) *Variable {
+	return s.variables[name]
+}
+
+// Lookup returns the variable named name visible in s, or nil if name is not
+// declared in s or in the scopes around it.
+func (s *Scope) Lookup(name string) *Variable {
+	for ; s != nil; s = s.Parent {
+		if v, ok := s.variables[name]; ok {
+			return v
+		}
+	}
+	return nil
+}
+
+// FunctionScope returns the scope of the function that s belongs to, or the
+// global or the module scope at the top level, where var declarations are
+// bound.
+func (s *Scope) FunctionScope() *Scope {
+	for s.Kind != Function && s.Kind != Module && s.Kind != Global {
+		s = s.Parent
+	}
+	return s
+}
+
+// FreeVariables returns the variables referenced in s or in its children that
+// are declared out of s, in the order they are first referenced. The free
+// variables of a function scope are the variables its closures capture.
+func (s *Scope) FreeVariables() []*Variable {
+	var vars []*Variable
+	seen := make(map[*Variable]bool)
+	for _, ref := range s.Through {
+		if !seen[ref.Resolved] {
+			seen[ref.Resolved] = true
+			vars = append(vars, ref.Resolved)
+		}
+	}
+	return vars
+}
+
+// Dynamic reports whether the variables referenced in s may be resolved at
+// run time to other bindings than their declarations, as in the scope of a
+// with statement.
+func (s *Scope) Dynamic() bool {
+	for ; s != nil; s = s.Parent {
+		if s.Kind == With {
+			return true
+		}
+	}
+	return false
+}
+
+// Variable is a name declared in a scope, with the identifiers that declare
+// it and the references to it.
+type Variable struct {
+	Name  string
+	Kind  DeclKind
+	Scope *Scope
+	// Identifiers are the identifiers that declare the variable. A variable
+	// declared more than once with var has an identifier for each
+	// declaration, and an implicit variable has none.
+	Identifiers []*ast.Identifier
+	References  []*Reference
+	// Captured is set if the variable is referenced in a function nested in
+	// the function, or the top level, that declares it.
+	Captured bool
+}
+
+// Reference is an identifier that references a variable.
+type Reference struct {
+	// Node is the *ast.Identifier, or the *ast.JSXIdentifier of the name of
+	// a JSX element.
+	Node ast.Node
+	Name string
+	// Scope is the scope the reference is made in.
+	Scope *Scope
+	// Resolved is the variable the reference resolves to.
+	Resolved *Variable
+	// Read and Write report whether the value of the variable is read or
+	// written. A compound assignment and an update both read and write it,
+	// and a declaration with an initializer writes it.
+	Read  bool
+	Write bool
+}
+
+// Info is the result of the analysis of a program.
+type Info struct {
+	// Global is the global scope, the root of the scope tree.
+	Global *Scope
+	// Scopes maps the nodes that create scopes to their scopes.
+	Scopes map[ast.Node]*Scope
+	// Declarations maps the identifiers that declare variables to the
+	// variables.
+	Declarations map[*ast.Identifier]*Variable
+	// References maps the identifiers that reference variables to the
+	// references.
+	References map[*ast.Identifier]*Reference
+}
+
+// Implicit returns the global variables that are referenced but not declared
+// in the program.
+func (info *Info) Implicit() []*Variable {
+	var vars []*Variable
+	for _, v := range info.Global.Variables {
+		if v.Kind == DeclImplicit {
+			vars = append(vars, v)
+		}
+	}
+	return vars
+}
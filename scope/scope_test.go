@@ -0,0 +1,203 @@
+package scope_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/lexer"
+	"github.com/ghosind/gjs/parser"
+	"github.com/ghosind/gjs/scope"
+	"github.com/ghosind/go-assert"
+)
+
+func analyze(a *assert.Assertion, src string, opts parser.Options) (*ast.Program, *scope.Info) {
+	program, err := parser.NewWithOptions(lexer.New([]byte(src)), opts).Parse()
+	a.NilNow(err, src)
+	return program, scope.Analyze(program)
+}
+
+// dump returns the scope tree of s, with the variables of each scope and the
+// number of their references.
+func dump(s *scope.Scope) string {
+	buf := new(strings.Builder)
+	var walk func(s *scope.Scope)
+	walk = func(s *scope.Scope) {
+		buf.WriteString(s.Kind.String())
+		buf.WriteString("[")
+		for i, v := range s.Variables {
+			if i > 0 {
+				buf.WriteString(" ")
+			}
+			fmt.Fprintf(buf, "%s:%s:%d", v.Kind, v.Name, len(v.References))
+		}
+		buf.WriteString("]")
+		if len(s.Children) > 0 {
+			buf.WriteString("{")
+			for i, child := range s.Children {
+				if i > 0 {
+					buf.WriteString(" ")
+				}
+				walk(child)
+			}
+			buf.WriteString("}")
+		}
+	}
+	walk(s)
+	return buf.String()
+}
+
+func names(vars []*scope.Variable) []string {
+	list := make([]string, 0, len(vars))
+	for _, v := range vars {
+		list = append(list, v.Name)
+	}
+	return list
+}
+
+func TestAnalyze(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"var a = 1; let b; a = b;", "global[var:a:2 let:b:1]"},
+		{"var {a, ...r} = o; r; function f({b, ...s}) { return s; }",
+			"global[var:a:1 var:r:2 function:f:0 implicit:o:1]{function[param:b:0 param:s:1]}"},
+		{"function f(a, b = a) { var c = a; { let a = c; var d; } }",
+			"global[function:f:0]{function[param:a:2 param:b:1 var:c:2 var:d:0]{block[let:a:1]}}"},
+		{"x = function g(a) { return g; }; y = (b) => b;",
+			"global[implicit:x:1 implicit:y:1]{function-name[function-name:g:1]{function[param:a:0]} function[param:b:1]}"},
+		{"for (let i = 0; i < 1; i++) { let j = i; } for (var k in o) k;",
+			"global[var:k:2 implicit:o:1]{block[let:i:4]{block[let:j:1]} block[]}"},
+		{"with (o) { var a = b; }", "global[var:a:1 implicit:o:1 implicit:b:1]{with[]{block[]}}"},
+		{"{ function f() {} } f();", "global[implicit:f:1]{block[function:f:0]{function[]}}"},
+		{"label: for (;;) { break label; } o.p; ({p: 1, [q]: 2, r});", "global[implicit:o:1 implicit:q:1 implicit:r:1]{block[]{block[]}}"},
+	}
+	for _, test := range tests {
+		_, info := analyze(a, test.input, parser.Options{})
+		a.EqualNow(dump(info.Global), test.expected, test.input)
+	}
+}
+
+func TestAnalyzeTryAndSwitch(t *testing.T) {
+	a := assert.New(t)
+
+	// The parser does not parse try and switch statements, so the programs
+	// are built by hand.
+	// try { a(); } catch (e) { e; } finally {}
+	program := &ast.Program{Statements: []ast.Statement{&ast.TryStatement{
+		Block: &ast.BlockStatement{StatementList: []ast.Statement{
+			&ast.ExpressionStatement{Expression: &ast.CallExpression{Callee: &ast.Identifier{Value: "a"}}},
+		}},
+		CatchClause: &ast.CatchClause{
+			Param: &ast.Identifier{Value: "e"},
+			Body: &ast.BlockStatement{StatementList: []ast.Statement{
+				&ast.ExpressionStatement{Expression: &ast.Identifier{Value: "e"}},
+			}},
+		},
+		Finally: &ast.BlockStatement{},
+	}}}
+	info := scope.Analyze(program)
+	a.EqualNow(dump(info.Global), "global[implicit:a:1]{block[] catch[catch-param:e:1]{block[]} block[]}")
+
+	// switch (a) { case 1: let b = 1; default: b; }
+	program = &ast.Program{Statements: []ast.Statement{&ast.SwitchStatement{
+		Discriminant: &ast.Identifier{Value: "a"},
+		Cases: []ast.SwitchCase{{
+			Test: &ast.Literal{Kind: ast.LitNumber, Value: "1"},
+			Consequent: []ast.Statement{&ast.LexicalDeclaration{Declarations: []ast.Declaration{
+				&ast.VariableDeclaration{Name: &ast.Identifier{Value: "b"}, Value: &ast.Literal{Kind: ast.LitNumber, Value: "1"}},
+			}}},
+		}},
+		DefaultCase: &ast.SwitchCase{Consequent: []ast.Statement{
+			&ast.ExpressionStatement{Expression: &ast.Identifier{Value: "b"}},
+		}},
+	}}}
+	info = scope.Analyze(program)
+	a.EqualNow(dump(info.Global), "global[implicit:a:1]{block[let:b:2]}")
+}
+
+func TestAnalyzeModule(t *testing.T) {
+	a := assert.New(t)
+
+	program, info := analyze(a, "import d, {a as b} from 'm'; import * as n from 'n'; const c = b; "+
+		"export {c as e}; export default function () { return d + n + x; }", parser.Options{SourceType: ast.SourceModule})
+	a.EqualNow(dump(info.Global),
+		"global[implicit:x:1]{module[import:d:1 import:b:1 import:n:1 const:c:2]{function[]}}")
+	a.EqualNow(info.Scopes[program], info.Global.Children[0])
+	a.DeepEqualNow(names(info.Implicit()), []string{"x"})
+}
+
+func TestReferences(t *testing.T) {
+	a := assert.New(t)
+
+	_, info := analyze(a, "var a = 1, b; a += b; b = a; a++; [a, {b}] = c; for (b of c);", parser.Options{})
+
+	var flags []string
+	for _, ref := range info.Global.References {
+		flag := ref.Name + ":"
+		if ref.Read {
+			flag += "r"
+		}
+		if ref.Write {
+			flag += "w"
+		}
+		flags = append(flags, flag)
+	}
+	a.DeepEqualNow(flags, []string{"a:w", "a:rw", "b:r", "b:w", "a:r", "a:rw", "a:w", "b:w", "c:r"})
+	loop := info.Global.Children[0].References
+	a.EqualNow(len(loop), 2)
+	a.TrueNow(loop[0].Name == "b" && loop[0].Write && !loop[0].Read)
+
+	for id, ref := range info.References {
+		a.EqualNow(ref.Node, ast.Node(id))
+		a.EqualNow(ref.Resolved, info.Global.Lookup(id.Value))
+	}
+	for id, v := range info.Declarations {
+		a.EqualNow(v.Name, id.Value)
+	}
+}
+
+func TestClosures(t *testing.T) {
+	a := assert.New(t)
+
+	program, info := analyze(a, `
+var count = 0;
+function counter(step) {
+  let total = 0;
+  function add() { total = total + step; count++; return total; }
+  function reset() { let total = 0; return total; }
+  return [add, reset];
+}
+`, parser.Options{})
+
+	counter := info.Scopes[program.Statements[1]]
+	a.EqualNow(counter.Kind, scope.Function)
+	a.DeepEqualNow(names(counter.FreeVariables()), []string{"count"})
+
+	add := info.Scopes[counter.Node.(*ast.FunctionDeclaration).Body.StatementList[1]]
+	a.DeepEqualNow(names(add.FreeVariables()), []string{"total", "step", "count"})
+	reset := info.Scopes[counter.Node.(*ast.FunctionDeclaration).Body.StatementList[2]]
+	a.DeepEqualNow(names(reset.FreeVariables()), []string{})
+
+	a.TrueNow(counter.Variable("total").Captured)
+	a.TrueNow(counter.Variable("step").Captured)
+	a.NotTrueNow(counter.Variable("add").Captured)
+	a.TrueNow(info.Global.Variable("count").Captured)
+	a.NotTrueNow(reset.Variable("total").Captured)
+	a.EqualNow(add.FunctionScope(), add)
+	a.EqualNow(reset.Lookup("total"), reset.Variable("total"))
+}
+
+func TestDynamic(t *testing.T) {
+	a := assert.New(t)
+
+	program, info := analyze(a, "with (o) { a; } b;", parser.Options{})
+	with := info.Scopes[program.Statements[0]]
+	a.EqualNow(with.Kind, scope.With)
+	a.TrueNow(with.Children[0].Dynamic())
+	a.NotTrueNow(info.Global.Dynamic())
+}
@@ -0,0 +1,113 @@
+package sourcemap
+
+import "sort"
+
+// Generator collects the mappings of a generated source to build its source
+// map.
+type Generator struct {
+	file     string
+	mappings []Mapping
+	contents map[string]string
+}
+
+// NewGenerator returns a generator of the source map of the generated source
+// file.
+func NewGenerator(file string) *Generator {
+	return &Generator{file: file, contents: make(map[string]string)}
+}
+
+// AddMapping adds a mapping from a position in the generated source. The
+// mapping is left out if it adds nothing to the last mapping added.
+func (g *Generator) AddMapping(m Mapping) {
+	if n := len(g.mappings); n > 0 {
+		last := g.mappings[n-1]
+		if last.GeneratedLine == m.GeneratedLine && last.Source == m.Source &&
+			last.Line == m.Line && last.Col == m.Col && (m.Name == "" || m.Name == last.Name) {
+			return
+		}
+	}
+	g.mappings = append(g.mappings, m)
+}
+
+// SetSourceContent includes the content of source in the map.
+func (g *Generator) SetSourceContent(source, content string) {
+	g.contents[source] = content
+}
+
+// Map returns the source map of the mappings added.
+func (g *Generator) Map() *Map {
+	m := &Map{Version: 3, File: g.file, Sources: []string{}, Names: []string{}}
+	mappings := make([]Mapping, len(g.mappings))
+	copy(mappings, g.mappings)
+	sort.SliceStable(mappings, func(i, j int) bool {
+		if mappings[i].GeneratedLine != mappings[j].GeneratedLine {
+			return mappings[i].GeneratedLine < mappings[j].GeneratedLine
+		}
+		return mappings[i].GeneratedCol < mappings[j].GeneratedCol
+	})
+
+	sources := make(map[string]int)
+	names := make(map[string]int)
+	var buf []byte
+	var genLine, genCol, source, line, col, name int
+	genLine = 1
+	for _, mapping := range mappings {
+		for ; genLine < mapping.GeneratedLine; genLine++ {
+			buf = append(buf, ';')
+			genCol = 0
+		}
+		if len(buf) > 0 && buf[len(buf)-1] != ';' {
+			buf = append(buf, ',')
+		}
+		buf = appendVLQ(buf, mapping.GeneratedCol-1-genCol)
+		genCol = mapping.GeneratedCol - 1
+		if mapping.Source == "" {
+			continue
+		}
+
+		index, ok := sources[mapping.Source]
+		if !ok {
+			index = len(m.Sources)
+			sources[mapping.Source] = index
+			m.Sources = append(m.Sources, mapping.Source)
+		}
+		buf = appendVLQ(buf, index-source)
+		buf = appendVLQ(buf, mapping.Line-1-line)
+		buf = appendVLQ(buf, mapping.Col-1-col)
+		source, line, col = index, mapping.Line-1, mapping.Col-1
+		if mapping.Name == "" {
+			continue
+		}
+
+		index, ok = names[mapping.Name]
+		if !ok {
+			index = len(m.Names)
+			names[mapping.Name] = index
+			m.Names = append(m.Names, mapping.Name)
+		}
+		buf = appendVLQ(buf, index-name)
+		name = index
+	}
+	m.Mappings = string(buf)
+
+	if len(g.contents) > 0 {
+		// The sources that have no mappings are listed for their contents.
+		var rest []string
+		for source := range g.contents {
+			if _, ok := sources[source]; !ok {
+				rest = append(rest, source)
+			}
+		}
+		sort.Strings(rest)
+		m.Sources = append(m.Sources, rest...)
+		m.SourcesContent = make([]*string, len(m.Sources))
+		for i, source := range m.Sources {
+			if content, ok := g.contents[source]; ok {
+				m.SourcesContent[i] = &content
+			}
+		}
+	}
+
+	m.decode()
+	return m
+}
@@ -0,0 +1,157 @@
+// Package sourcemap generates and reads source maps of version 3, which map
+// the positions in a generated source, such as a minified program, back to
+// the positions in the sources it was generated from.
+//
+// The lines and the columns of the mappings start at 1, as in the positions
+// of the tokens, and are stored from 0 in the encoded mappings.
+package sourcemap
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Map is a source map, as it is encoded in JSON.
+type Map struct {
+	Version    int      `json:"version"`
+	File       string   `json:"file,omitempty"`
+	SourceRoot string   `json:"sourceRoot,omitempty"`
+	Sources    []string `json:"sources"`
+	// SourcesContent holds the contents of the sources, or nil for the
+	// sources whose content is not included.
+	SourcesContent []*string `json:"sourcesContent,omitempty"`
+	Names          []string  `json:"names"`
+	Mappings       string    `json:"mappings"`
+
+	// lines are the decoded mappings of each generated line.
+	lines [][]Mapping
+}
+
+// Mapping maps a position in the generated source to a position in one of the
+// sources. Source is empty for a position that maps to no source.
+type Mapping struct {
+	GeneratedLine int
+	GeneratedCol  int
+	Source        string
+	Line          int
+	Col           int
+	// Name is the original name of the identifier at the position, if any.
+	Name string
+}
+
+// Parse decodes a source map from its JSON encoding.
+func Parse(data []byte) (*Map, error) {
+	m := new(Map)
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("sourcemap: %w", err)
+	}
+	if m.Version != 3 {
+		return nil, fmt.Errorf("sourcemap: unsupported version %d", m.Version)
+	}
+	if err := m.decode(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// decode decodes the mappings of m into its lines.
+func (m *Map) decode() error {
+	m.lines = nil
+	var source, line, col, name int
+	for i, group := range strings.Split(m.Mappings, ";") {
+		var segments []Mapping
+		genCol := 0
+		for _, segment := range strings.Split(group, ",") {
+			if segment == "" {
+				continue
+			}
+			fields := make([]int, 0, 5)
+			for rest := segment; rest != ""; {
+				n, next, err := decodeVLQ(rest)
+				if err != nil {
+					return fmt.Errorf("sourcemap: %w in segment %q", err, segment)
+				}
+				fields = append(fields, n)
+				rest = next
+			}
+			if len(fields) != 1 && len(fields) != 4 && len(fields) != 5 {
+				return fmt.Errorf("sourcemap: segment %q has %d fields", segment, len(fields))
+			}
+
+			genCol += fields[0]
+			mapping := Mapping{GeneratedLine: i + 1, GeneratedCol: genCol + 1}
+			if len(fields) > 1 {
+				source += fields[1]
+				line += fields[2]
+				col += fields[3]
+				if source < 0 || source >= len(m.Sources) {
+					return fmt.Errorf("sourcemap: segment %q has source index %d out of range", segment, source)
+				}
+				mapping.Source = m.source(source)
+				mapping.Line = line + 1
+				mapping.Col = col + 1
+			}
+			if len(fields) > 4 {
+				name += fields[4]
+				if name < 0 || name >= len(m.Names) {
+					return fmt.Errorf("sourcemap: segment %q has name index %d out of range", segment, name)
+				}
+				mapping.Name = m.Names[name]
+			}
+			segments = append(segments, mapping)
+		}
+		sort.SliceStable(segments, func(i, j int) bool {
+			return segments[i].GeneratedCol < segments[j].GeneratedCol
+		})
+		m.lines = append(m.lines, segments)
+	}
+	return nil
+}
+
+// source returns the name of the source at index i, under the source root.
+func (m *Map) source(i int) string {
+	if m.SourceRoot == "" || strings.HasSuffix(m.SourceRoot, "/") {
+		return m.SourceRoot + m.Sources[i]
+	}
+	return m.SourceRoot + "/" + m.Sources[i]
+}
+
+// Lookup returns the mapping of the position at line and col in the generated
+// source, which is the last mapping on the line that starts at or before col.
+// It reports false if the position maps to no source. The mappings are
+// decoded by Parse and Generator.Map.
+func (m *Map) Lookup(line, col int) (Mapping, bool) {
+	if line < 1 || line > len(m.lines) {
+		return Mapping{}, false
+	}
+	segments := m.lines[line-1]
+	i := sort.Search(len(segments), func(i int) bool {
+		return segments[i].GeneratedCol > col
+	})
+	if i == 0 || segments[i-1].Source == "" {
+		return Mapping{}, false
+	}
+	return segments[i-1], true
+}
+
+// Decoded returns the decoded mappings of m, in the order of their generated
+// positions.
+func (m *Map) Decoded() []Mapping {
+	var mappings []Mapping
+	for _, segments := range m.lines {
+		mappings = append(mappings, segments...)
+	}
+	return mappings
+}
+
+// Content returns the content of source included in the map, if any.
+func (m *Map) Content(source string) (string, bool) {
+	for i := range m.Sources {
+		if m.source(i) == source && i < len(m.SourcesContent) && m.SourcesContent[i] != nil {
+			return *m.SourcesContent[i], true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,110 @@
+package sourcemap
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ghosind/go-assert"
+)
+
+func TestVLQ(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []struct {
+		n       int
+		encoded string
+	}{
+		{0, "A"}, {1, "C"}, {-1, "D"}, {15, "e"}, {16, "gB"}, {-16, "hB"}, {1000, "w+B"},
+	}
+	for _, test := range tests {
+		a.EqualNow(string(appendVLQ(nil, test.n)), test.encoded)
+		n, rest, err := decodeVLQ(test.encoded + "A")
+		a.NilNow(err)
+		a.EqualNow(n, test.n)
+		a.EqualNow(rest, "A")
+	}
+
+	_, _, err := decodeVLQ("g")
+	a.NotNilNow(err)
+	_, _, err = decodeVLQ("!")
+	a.NotNilNow(err)
+}
+
+func TestGenerator(t *testing.T) {
+	a := assert.New(t)
+
+	g := NewGenerator("out.js")
+	g.AddMapping(Mapping{GeneratedLine: 1, GeneratedCol: 1, Source: "a.js", Line: 1, Col: 1})
+	g.AddMapping(Mapping{GeneratedLine: 1, GeneratedCol: 5, Source: "a.js", Line: 2, Col: 3, Name: "value"})
+	g.AddMapping(Mapping{GeneratedLine: 1, GeneratedCol: 9, Source: "a.js", Line: 2, Col: 3})
+	g.AddMapping(Mapping{GeneratedLine: 3, GeneratedCol: 2, Source: "b.js", Line: 1, Col: 1})
+	g.AddMapping(Mapping{GeneratedLine: 3, GeneratedCol: 4})
+	g.SetSourceContent("b.js", "b()")
+	g.SetSourceContent("c.js", "c()")
+
+	m := g.Map()
+	a.EqualNow(m.Mappings, "AAAA,IACEA;;CCDF,E")
+	a.DeepEqualNow(m.Sources, []string{"a.js", "b.js", "c.js"})
+	a.DeepEqualNow(m.Names, []string{"value"})
+
+	data, err := json.Marshal(m)
+	a.NilNow(err)
+	parsed, err := Parse(data)
+	a.NilNow(err)
+	a.DeepEqualNow(parsed.Decoded(), []Mapping{
+		{GeneratedLine: 1, GeneratedCol: 1, Source: "a.js", Line: 1, Col: 1},
+		{GeneratedLine: 1, GeneratedCol: 5, Source: "a.js", Line: 2, Col: 3, Name: "value"},
+		{GeneratedLine: 3, GeneratedCol: 2, Source: "b.js", Line: 1, Col: 1},
+		{GeneratedLine: 3, GeneratedCol: 4},
+	})
+
+	content, ok := parsed.Content("c.js")
+	a.TrueNow(ok)
+	a.EqualNow(content, "c()")
+	_, ok = parsed.Content("a.js")
+	a.NotTrueNow(ok)
+}
+
+func TestLookup(t *testing.T) {
+	a := assert.New(t)
+
+	m, err := Parse([]byte(`{"version":3,"sourceRoot":"src","sources":["a.js"],"names":[],"mappings":"AAAA,IACE,C;;E"}`))
+	a.NilNow(err)
+
+	tests := []struct {
+		line, col int
+		ok        bool
+		expected  Mapping
+	}{
+		{1, 1, true, Mapping{GeneratedLine: 1, GeneratedCol: 1, Source: "src/a.js", Line: 1, Col: 1}},
+		{1, 4, true, Mapping{GeneratedLine: 1, GeneratedCol: 1, Source: "src/a.js", Line: 1, Col: 1}},
+		{1, 5, true, Mapping{GeneratedLine: 1, GeneratedCol: 5, Source: "src/a.js", Line: 2, Col: 3}},
+		{1, 7, false, Mapping{}},
+		{2, 1, false, Mapping{}},
+		{3, 2, false, Mapping{}},
+		{3, 3, false, Mapping{}},
+		{4, 1, false, Mapping{}},
+	}
+	for _, test := range tests {
+		mapping, ok := m.Lookup(test.line, test.col)
+		a.EqualNow(ok, test.ok, test.line, test.col)
+		a.EqualNow(mapping, test.expected, test.line, test.col)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []string{
+		`{"version":2,"sources":[],"names":[],"mappings":""}`,
+		`{"version":3,"sources":[],"names":[],"mappings":"AAAA"}`,
+		`{"version":3,"sources":["a.js"],"names":[],"mappings":"AA"}`,
+		`{"version":3,"sources":["a.js"],"names":[],"mappings":"AAAAC"}`,
+		`{"version":3,"sources":["a.js"],"names":[],"mappings":"A!"}`,
+		`{"version":3`,
+	}
+	for _, test := range tests {
+		_, err := Parse([]byte(test))
+		a.NotNilNow(err, test)
+	}
+}
@@ -0,0 +1,57 @@
+package sourcemap
+
+import (
+	"errors"
+	"strings"
+)
+
+const base64Chars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+const (
+	vlqShift    = 5
+	vlqContinue = 1 << vlqShift
+	vlqMask     = vlqContinue - 1
+)
+
+var errInvalidVLQ = errors.New("invalid base64 VLQ")
+
+// appendVLQ appends n to buf in base64 VLQ, the lowest bit of the first digit
+// being its sign.
+func appendVLQ(buf []byte, n int) []byte {
+	v := n << 1
+	if n < 0 {
+		v = -n<<1 | 1
+	}
+	for {
+		digit := v & vlqMask
+		v >>= vlqShift
+		if v > 0 {
+			digit |= vlqContinue
+		}
+		buf = append(buf, base64Chars[digit])
+		if v == 0 {
+			return buf
+		}
+	}
+}
+
+// decodeVLQ decodes the base64 VLQ at the start of s, and returns the number
+// and the rest of s.
+func decodeVLQ(s string) (int, string, error) {
+	v, shift := 0, 0
+	for i := 0; i < len(s); i++ {
+		digit := strings.IndexByte(base64Chars, s[i])
+		if digit < 0 || shift > 30 {
+			return 0, s, errInvalidVLQ
+		}
+		v |= (digit & vlqMask) << shift
+		shift += vlqShift
+		if digit&vlqContinue == 0 {
+			if v&1 != 0 {
+				return -(v >> 1), s[i+1:], nil
+			}
+			return v >> 1, s[i+1:], nil
+		}
+	}
+	return 0, s, errInvalidVLQ
+}
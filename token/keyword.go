@@ -67,3 +67,7 @@ func LookupIdent(ident string) TokenType {
 
 	return TOKEN_IDENTIFIER
 }
+
+func (ty TokenType) IsKeyword() bool {
+	return ty >= TOKEN_ARGUMENTS && ty <= TOKEN_YIELD
+}
@@ -69,3 +69,15 @@ func TestLookupIdent(t *testing.T) {
 
 	a.EqualNow(LookupIdent("notKeyword"), TOKEN_IDENTIFIER)
 }
+
+func TestIsKeyword(t *testing.T) {
+	a := assert.New(t)
+
+	a.TrueNow(TOKEN_ARGUMENTS.IsKeyword())
+	a.TrueNow(TOKEN_FUNCTION.IsKeyword())
+	a.TrueNow(TOKEN_YIELD.IsKeyword())
+
+	a.NotTrueNow(TOKEN_IDENTIFIER.IsKeyword())
+	a.NotTrueNow(TOKEN_NUMBER.IsKeyword())
+	a.NotTrueNow(TOKEN_NEW_LINE.IsKeyword())
+}
@@ -1,6 +1,9 @@
 package token
 
-import "strconv"
+import (
+	"strconv"
+	"strings"
+)
 
 type TokenType int
 
@@ -28,6 +31,7 @@ const (
 	TOKEN_EQUAL                         // =
 	TOKEN_EQUAL_EQUAL                   // ==
 	TOKEN_EQUAL_EQUAL_EQUAL             // ===
+	TOKEN_EQUAL_GREATER                 // =>
 	TOKEN_GREATER                       // >
 	TOKEN_GREATER_EQUAL                 // >=
 	TOKEN_GREATER_GREATER               // >>
@@ -133,6 +137,9 @@ const (
 	TOKEN_SPACE
 	TOKEN_SINGLE_LINE_COMMENT
 	TOKEN_MULTI_LINE_COMMENT
+
+	// TOKEN_JSX_TEXT is the text of the children of a JSX element.
+	TOKEN_JSX_TEXT
 )
 
 type Token struct {
@@ -140,27 +147,81 @@ type Token struct {
 	Line      int
 	Col       int
 	Literal   string
+	// Offset is the byte offset of the token in the source, and Raw is the
+	// source text of the token.
+	Offset int
+	Raw    string
+}
+
+// Position is a location in the source. Line and Col are counted from 1, and
+// Offset is the byte offset from the start of the source.
+type Position struct {
+	Offset int
+	Line   int
+	Col    int
+}
+
+// IsValid reports whether the position is a location in the source.
+func (pos Position) IsValid() bool {
+	return pos.Line > 0
+}
+
+func (pos Position) String() string {
+	if !pos.IsValid() {
+		return "-"
+	}
+	return strconv.Itoa(pos.Line) + ":" + strconv.Itoa(pos.Col)
+}
+
+// Pos returns the position of the first character of the token.
+func (t *Token) Pos() Position {
+	return Position{Offset: t.Offset, Line: t.Line, Col: t.Col}
+}
+
+// End returns the position immediately after the token.
+func (t *Token) End() Position {
+	pos := t.Pos()
+	if pos.IsValid() {
+		pos.Offset += len(t.Raw)
+		for i, c := range t.Raw {
+			switch {
+			case c == '\r' && strings.HasPrefix(t.Raw[i+1:], "\n"):
+			case c == '\n' || c == '\r' || c == '\u2028' || c == '\u2029':
+				pos.Line++
+				pos.Col = 1
+			default:
+				pos.Col++
+			}
+		}
+	}
+	return pos
 }
 
-var tokenTypeString = "EOF(){}[]&&&&&=&=!!=!==:,....======>>=>>>>=>>>>>>=##!^^=<<=<<<<=--=--%%=||=" +
+var tokenTypeString = "EOF(){}[]&&&&&=&=!!=!==:,....=======>>>=>>>>=>>>>>>=##!^^=<<=<<<<=--=--%%=||=" +
 	"||||=++=++??.????=;//=**=****=~identifierstringnumberargumentsasasyncawaitbreakcasecatch" +
 	"classconstcontinuedebuggerdefaultdeletedoelseenumevalexportextendsfalsefinallyforfromfunction" +
 	"getifimplementsimportininstanceofinterfaceletmetanewnullofpackageprivateprotectedpublicreturn" +
 	"setstaticsuperswitchtargetthisthrowtruetrytypeofundefinedvarvoidwhilewithyield" +
-	"newlinespacecommentcomment"
+	"newlinespacecommentcommentjsxtext"
 
 var tokenTypeIndex = [...]int{0, 3, 4, 5, 6, 7, 8, 9, 10, 12, 15, 17, 18, 20, 23, 24, 25, 26, 29,
-	30, 32, 35, 36, 38, 40, 43, 46, 50, 51, 53, 54, 56, 57, 59, 61, 64, 65, 67, 69, 70, 72, 73, 75,
-	77, 80, 81, 83, 85, 86, 88, 90, 93, 94, 95, 97, 98, 100, 102, 105, 106, 116, 122, 128, 137, 139,
-	144, 149, 154, 158, 163, 168, 173, 181, 189, 196, 202, 204, 208, 212, 216, 222, 229, 234, 241,
-	244, 248, 256, 259, 261, 271, 277, 279, 289, 298, 301, 305, 308, 312, 314, 321, 328, 337, 343,
-	349, 352, 358, 363, 369, 375, 379, 384, 388, 391, 397, 406, 409, 413, 418, 422, 427, 434, 439,
-	446, 453,
+	30, 32, 35, 37, 38, 40, 42, 45, 48, 52, 53, 55, 56, 58, 59, 61, 63, 66, 67, 69, 71, 72, 74, 75,
+	77, 79, 82, 83, 85, 87, 88, 90, 92, 95, 96, 97, 99, 100, 102, 104, 107, 108, 118, 124, 130, 139,
+	141, 146, 151, 156, 160, 165, 170, 175, 183, 191, 198, 204, 206, 210, 214, 218, 224, 231, 236,
+	243, 246, 250, 258, 261, 263, 273, 279, 281, 291, 300, 303, 307, 310, 314, 316, 323, 330, 339,
+	345, 351, 354, 360, 365, 371, 377, 381, 386, 390, 393, 399, 408, 411, 415, 420, 424, 429, 436,
+	441, 448, 455, 462,
 }
 
 func (ty TokenType) String() string {
+	return "token<" + ty.Text() + ">"
+}
+
+// Text returns the source text of a punctuator or a keyword type, or the name
+// of the other types.
+func (ty TokenType) Text() string {
 	if ty < TOKEN_EOF || int(ty) >= len(tokenTypeIndex)-1 {
-		return "token<unknown " + strconv.FormatInt(int64(ty), 10) + ">"
+		return "unknown " + strconv.FormatInt(int64(ty), 10)
 	}
-	return "token<" + tokenTypeString[tokenTypeIndex[ty]:tokenTypeIndex[ty+1]] + ">"
+	return tokenTypeString[tokenTypeIndex[ty]:tokenTypeIndex[ty+1]]
 }
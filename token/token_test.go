@@ -29,6 +29,7 @@ func TestTokenTypeString(t *testing.T) {
 	a.EqualNow(TOKEN_EQUAL.String(), "token<=>")
 	a.EqualNow(TOKEN_EQUAL_EQUAL.String(), "token<==>")
 	a.EqualNow(TOKEN_EQUAL_EQUAL_EQUAL.String(), "token<===>")
+	a.EqualNow(TOKEN_EQUAL_GREATER.String(), "token<=>>")
 	a.EqualNow(TOKEN_GREATER.String(), "token<>>")
 	a.EqualNow(TOKEN_GREATER_EQUAL.String(), "token<>=>")
 	a.EqualNow(TOKEN_GREATER_GREATER.String(), "token<>>>")
@@ -131,6 +132,7 @@ func TestTokenTypeString(t *testing.T) {
 	a.EqualNow(TOKEN_SPACE.String(), "token<space>")
 	a.EqualNow(TOKEN_SINGLE_LINE_COMMENT.String(), "token<comment>")
 	a.EqualNow(TOKEN_MULTI_LINE_COMMENT.String(), "token<comment>")
+	a.EqualNow(TOKEN_JSX_TEXT.String(), "token<jsxtext>")
 }
 
 func TestInvalidTokenTypeString(t *testing.T) {
@@ -138,3 +140,28 @@ func TestInvalidTokenTypeString(t *testing.T) {
 	a.EqualNow(TokenType(999).String(), "token<unknown 999>")
 	a.EqualNow(TokenType(-1).String(), "token<unknown -1>")
 }
+
+func TestTokenTypeText(t *testing.T) {
+	a := assert.New(t)
+
+	a.EqualNow(TOKEN_RIGHT_PAREN.Text(), ")")
+	a.EqualNow(TOKEN_GREATER_GREATER_GREATER_EQUAL.Text(), ">>>=")
+	a.EqualNow(TOKEN_IDENTIFIER.Text(), "identifier")
+	a.EqualNow(TOKEN_FUNCTION.Text(), "function")
+	a.EqualNow(TokenType(999).Text(), "unknown 999")
+}
+
+func TestTokenPosition(t *testing.T) {
+	a := assert.New(t)
+
+	tok := &Token{TokenType: TOKEN_IDENTIFIER, Line: 2, Col: 3, Offset: 10, Literal: "abc", Raw: "abc"}
+	a.EqualNow(tok.Pos(), Position{Offset: 10, Line: 2, Col: 3})
+	a.EqualNow(tok.End(), Position{Offset: 13, Line: 2, Col: 6})
+	a.EqualNow(tok.End().String(), "2:6")
+
+	tok = &Token{TokenType: TOKEN_MULTI_LINE_COMMENT, Line: 1, Col: 5, Offset: 4, Raw: "/* a\r\n b */"}
+	a.EqualNow(tok.End(), Position{Offset: 15, Line: 2, Col: 6})
+
+	a.NotTrueNow(Position{}.IsValid())
+	a.EqualNow(Position{}.String(), "-")
+}
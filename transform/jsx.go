@@ -0,0 +1,316 @@
+// Package transform rewrites parsed programs, such as lowering the syntax
+// extensions of the parser to plain ECMAScript the evaluator can run.
+package transform
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/token"
+)
+
+// JSXOptions configures the JSX transform.
+type JSXOptions struct {
+	// Factory is the function that creates an element, which is called with
+	// the type, the props and the children of the element. It is
+	// React.createElement if empty.
+	Factory string
+	// Fragment is the type of the elements created for fragments. It is
+	// React.Fragment if empty.
+	Fragment string
+}
+
+// JSX rewrites every JSX element and fragment of program to a call of the
+// factory, as in the classic runtime of React:
+//
+//	<div className="a">{text}</div>
+//
+// is rewritten to
+//
+//	React.createElement("div", {className: "a"}, text)
+//
+// The names of elements that start with a lowercase letter, or contain a dash
+// or a colon, are passed as strings. Other names are passed as references.
+func JSX(program *ast.Program, opts JSXOptions) {
+	if opts.Factory == "" {
+		opts.Factory = "React.createElement"
+	}
+	if opts.Fragment == "" {
+		opts.Fragment = "React.Fragment"
+	}
+	t := &jsxTransform{opts: opts}
+	t.walk(reflect.ValueOf(program))
+}
+
+type jsxTransform struct {
+	opts JSXOptions
+}
+
+var tokenType = reflect.TypeOf(token.Token{})
+
+// walk replaces the JSX nodes in v, which holds a node or any of its fields.
+func (t *jsxTransform) walk(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			t.walk(v.Elem())
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		if expr, ok := v.Interface().(ast.Expression); ok && isJSX(expr) && v.CanSet() {
+			v.Set(reflect.ValueOf(t.expression(expr)))
+			return
+		}
+		t.walk(v.Elem())
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			t.walk(v.Index(i))
+		}
+	case reflect.Struct:
+		if v.Type() == tokenType {
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if field := v.Field(i); field.CanSet() {
+				t.walk(field)
+			}
+		}
+	}
+}
+
+func isJSX(expr ast.Expression) bool {
+	switch expr.(type) {
+	case *ast.JSXElement, *ast.JSXFragment:
+		return true
+	}
+	return false
+}
+
+// expression returns expr with its JSX nodes replaced.
+func (t *jsxTransform) expression(expr ast.Expression) ast.Expression {
+	switch expr := expr.(type) {
+	case *ast.JSXElement:
+		return t.element(expr)
+	case *ast.JSXFragment:
+		return t.fragment(expr)
+	}
+	t.walk(reflect.ValueOf(expr))
+	return expr
+}
+
+func (t *jsxTransform) element(elem *ast.JSXElement) ast.Expression {
+	opening := elem.OpeningElement
+	args := []ast.Expression{elementType(opening.Name), t.props(opening)}
+	return &ast.CallExpression{
+		RParen:    closingToken(elem.End()),
+		Callee:    reference(t.opts.Factory, opening.LessThan),
+		Arguments: append(args, t.children(elem.Children)...),
+	}
+}
+
+func (t *jsxTransform) fragment(frag *ast.JSXFragment) ast.Expression {
+	opening := frag.OpeningFragment
+	args := []ast.Expression{
+		reference(t.opts.Fragment, opening.LessThan),
+		&ast.Literal{Token: newToken(token.TOKEN_NULL, "null", opening.LessThan), Value: "null", Kind: ast.LitNull},
+	}
+	return &ast.CallExpression{
+		RParen:    closingToken(frag.End()),
+		Callee:    reference(t.opts.Factory, opening.LessThan),
+		Arguments: append(args, t.children(frag.Children)...),
+	}
+}
+
+// props returns the object of the attributes of an element, or null if it has
+// no attributes.
+func (t *jsxTransform) props(opening *ast.JSXOpeningElement) ast.Expression {
+	if len(opening.Attributes) == 0 {
+		return &ast.Literal{Token: newToken(token.TOKEN_NULL, "null", opening.GreaterThan), Value: "null", Kind: ast.LitNull}
+	}
+
+	obj := &ast.ObjectLiteral{
+		LBrace: newToken(token.TOKEN_LEFT_BRACE, "{", opening.LessThan),
+		RBrace: newToken(token.TOKEN_RIGHT_BRACE, "}", opening.GreaterThan),
+	}
+	for _, attr := range opening.Attributes {
+		switch attr := attr.(type) {
+		case *ast.JSXSpreadAttribute:
+			obj.Properties = append(obj.Properties, &ast.SpreadElement{
+				Token: newToken(token.TOKEN_DOT_DOT_DOT, "...", attr.LBrace),
+				Value: t.expression(attr.Argument),
+			})
+		case *ast.JSXAttribute:
+			start := attr.Pos()
+			prop := &ast.Property{
+				Token: token.Token{TokenType: token.TOKEN_IDENTIFIER, Line: start.Line, Col: start.Col, Offset: start.Offset},
+				Key:   propertyKey(attr.Name),
+				Kind:  ast.PropertyInit,
+			}
+			prop.Token.Literal, prop.Token.Raw = attr.Name.String(), attr.Name.String()
+			switch value := attr.Value.(type) {
+			case nil:
+				prop.Value = &ast.Literal{Token: newToken(token.TOKEN_TRUE, "true", prop.Token), Value: "true", Kind: ast.LitBoolean}
+			case *ast.JSXExpressionContainer:
+				prop.Value = t.expression(value.Expression)
+			default:
+				prop.Value = t.expression(value)
+			}
+			obj.Properties = append(obj.Properties, prop)
+		}
+	}
+	return obj
+}
+
+// children returns the arguments of the children of an element. Text that
+// is only white space and empty expressions are left out.
+func (t *jsxTransform) children(children []ast.Expression) []ast.Expression {
+	args := make([]ast.Expression, 0, len(children))
+	for _, child := range children {
+		switch child := child.(type) {
+		case *ast.JSXText:
+			if text := cleanJSXText(child.Value); text != "" {
+				args = append(args, stringLiteral(text, child.Token))
+			}
+		case *ast.JSXExpressionContainer:
+			if _, ok := child.Expression.(*ast.JSXEmptyExpression); !ok {
+				args = append(args, t.expression(child.Expression))
+			}
+		case *ast.JSXSpreadChild:
+			args = append(args, &ast.SpreadElement{
+				Token: newToken(token.TOKEN_DOT_DOT_DOT, "...", child.LBrace),
+				Value: t.expression(child.Expression),
+			})
+		default:
+			args = append(args, t.expression(child))
+		}
+	}
+	return args
+}
+
+// elementType returns the first argument of the factory for the element
+// named name.
+func elementType(name ast.Expression) ast.Expression {
+	switch name := name.(type) {
+	case *ast.JSXIdentifier:
+		r, _ := utf8.DecodeRuneInString(name.Name)
+		if unicode.IsLower(r) || strings.Contains(name.Name, "-") {
+			return stringLiteral(name.Name, name.Token)
+		}
+		return identifier(name)
+	case *ast.JSXMemberExpression:
+		// The object of a member expression is a reference even if it starts
+		// with a lowercase letter, as in <foo.Bar />.
+		var object ast.Expression
+		if id, ok := name.Object.(*ast.JSXIdentifier); ok {
+			object = identifier(id)
+		} else {
+			object = elementType(name.Object)
+		}
+		return &ast.MemberExpression{Object: object, Property: identifier(name.Property)}
+	case *ast.JSXNamespacedName:
+		return stringLiteral(name.String(), name.Namespace.Token)
+	}
+	return name
+}
+
+var identifierName = regexp.MustCompile(`^[\p{L}\p{Nl}$_][\p{L}\p{Nl}\p{Mn}\p{Mc}\p{Nd}\p{Pc}$]*$`)
+
+// propertyKey returns the key of the property of an attribute, which is a
+// string if the name of the attribute is not an identifier name.
+func propertyKey(name ast.Expression) ast.Expression {
+	if id, ok := name.(*ast.JSXIdentifier); ok && identifierName.MatchString(id.Name) {
+		return identifier(id)
+	}
+	start := name.Pos()
+	return stringLiteral(name.String(), token.Token{Line: start.Line, Col: start.Col, Offset: start.Offset})
+}
+
+func identifier(id *ast.JSXIdentifier) *ast.Identifier {
+	tok := newToken(token.LookupIdent(id.Name), id.Name, id.Token)
+	return &ast.Identifier{Token: tok, Value: id.Name}
+}
+
+// reference returns the expression of a dotted name, such as
+// React.createElement, positioned at pos.
+func reference(name string, pos token.Token) ast.Expression {
+	parts := strings.Split(name, ".")
+	var expr ast.Expression = &ast.Identifier{Token: newToken(token.TOKEN_IDENTIFIER, parts[0], pos), Value: parts[0]}
+	for _, part := range parts[1:] {
+		expr = &ast.MemberExpression{
+			Object:   expr,
+			Property: &ast.Identifier{Token: newToken(token.TOKEN_IDENTIFIER, part, pos), Value: part},
+		}
+	}
+	return expr
+}
+
+func stringLiteral(s string, pos token.Token) *ast.Literal {
+	tok := newToken(token.TOKEN_STRING, s, pos)
+	tok.Raw = strconv.Quote(s)
+	return &ast.Literal{Token: tok, Value: s, Kind: ast.LitString}
+}
+
+// newToken returns a token of typ and raw, positioned at pos.
+func newToken(typ token.TokenType, raw string, pos token.Token) token.Token {
+	return token.Token{
+		TokenType: typ,
+		Line:      pos.Line,
+		Col:       pos.Col,
+		Literal:   raw,
+		Offset:    pos.Offset,
+		Raw:       raw,
+	}
+}
+
+// closingToken returns the closing parenthesis of a call, which ends at end.
+func closingToken(end token.Position) token.Token {
+	return token.Token{
+		TokenType: token.TOKEN_RIGHT_PAREN,
+		Line:      end.Line,
+		Col:       end.Col - 1,
+		Literal:   ")",
+		Offset:    end.Offset - 1,
+		Raw:       ")",
+	}
+}
+
+var lineBreak = regexp.MustCompile(`\r\n|\n|\r`)
+
+// cleanJSXText returns the string of the text of a child, in which the lines
+// are trimmed and joined by a space, and the lines of only white space are
+// left out.
+func cleanJSXText(text string) string {
+	lines := lineBreak.Split(text, -1)
+	lastNonEmpty := 0
+	for i, line := range lines {
+		if strings.TrimLeft(line, " \t") != "" {
+			lastNonEmpty = i
+		}
+	}
+
+	buf := new(strings.Builder)
+	for i, line := range lines {
+		line = strings.ReplaceAll(line, "\t", " ")
+		if i > 0 {
+			line = strings.TrimLeft(line, " ")
+		}
+		if i < len(lines)-1 {
+			line = strings.TrimRight(line, " ")
+		}
+		if line == "" {
+			continue
+		}
+		buf.WriteString(line)
+		if i != lastNonEmpty {
+			buf.WriteString(" ")
+		}
+	}
+	return buf.String()
+}
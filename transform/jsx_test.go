@@ -0,0 +1,105 @@
+package transform
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ghosind/gjs/ast"
+	"github.com/ghosind/gjs/evaluator"
+	"github.com/ghosind/gjs/lexer"
+	"github.com/ghosind/gjs/parser"
+	"github.com/ghosind/gjs/printer"
+	"github.com/ghosind/gjs/runtime"
+	"github.com/ghosind/go-assert"
+)
+
+func parseJSX(a *assert.Assertion, input string) *ast.Program {
+	program, err := parser.NewWithOptions(lexer.New([]byte(input)), parser.Options{JSX: true}).Parse()
+	a.NilNow(err, input)
+	return program
+}
+
+// source returns the source of node, in which the strings are quoted unlike
+// in the result of its String method.
+func source(a *assert.Assertion, node ast.Node) string {
+	buf := new(bytes.Buffer)
+	a.NilNow(printer.Fprint(buf, node))
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func TestJSX(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`<div />;`, `React.createElement("div", null);`},
+		{`<Foo a="1" b={c} d />;`, `React.createElement(Foo, {a: "1", b: c, d: true});`},
+		{`<foo.bar {...p} data-x="y" />;`, `React.createElement(foo.bar, {...p, "data-x": "y"});`},
+		{`<svg:rect />;`, `React.createElement("svg:rect", null);`},
+		{`<a>{x}{/* c */}{...y}</a>;`, `React.createElement("a", null, x, ...y);`},
+		{`<><b /></>;`, `React.createElement(React.Fragment, null, React.createElement("b", null));`},
+		{`f(() => <a />);`, `f(() => React.createElement("a", null));`},
+	}
+
+	for _, test := range tests {
+		program := parseJSX(a, test.input)
+		JSX(program, JSXOptions{})
+		a.EqualNow(source(a, program.Statements[0]), test.expected, test.input)
+	}
+}
+
+func TestJSXOptions(t *testing.T) {
+	a := assert.New(t)
+
+	program := parseJSX(a, `<><a /></>;`)
+	JSX(program, JSXOptions{Factory: "h", Fragment: "Fragment"})
+	a.EqualNow(source(a, program.Statements[0]), `h(Fragment, null, h("a", null));`)
+}
+
+func TestJSXText(t *testing.T) {
+	a := assert.New(t)
+
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"<a>  Hello  </a>;", []string{`"  Hello  "`}},
+		{"<a>\n  Hello\n  world\n</a>;", []string{`"Hello world"`}},
+		{"<a>\n  \n</a>;", []string{}},
+		{"<a>\n  Hello, {name}!\n</a>;", []string{`"Hello, "`, "name", `"!"`}},
+		{"<a>&lt;&amp;&gt; &unknown;</a>;", []string{`"<&> &unknown;"`}},
+	}
+
+	for _, test := range tests {
+		program := parseJSX(a, test.input)
+		JSX(program, JSXOptions{})
+		call := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.CallExpression)
+		children := make([]string, 0)
+		for _, arg := range call.Arguments[2:] {
+			children = append(children, source(a, arg))
+		}
+		a.DeepEqualNow(children, test.expected, test.input)
+	}
+}
+
+func TestJSXEval(t *testing.T) {
+	a := assert.New(t)
+
+	program := parseJSX(a, `
+function h(type, props, ...children) { return { type, props, children }; }
+function Item(props) { return h('li', null, props.value); }
+const items = [<Item value={1} />, <Item value="2" />];
+<ul class="list">
+  {...items}
+</ul>;
+`)
+	JSX(program, JSXOptions{Factory: "h"})
+
+	res := evaluator.New(runtime.New()).Eval(program)
+	a.EqualNow(res.Inspect(), `{type: ul, props: {class: list}, children: [`+
+		`{type: [Function: Item], props: {value: 1}, children: []}, `+
+		`{type: [Function: Item], props: {value: 2}, children: []}]}`)
+}
@@ -0,0 +1,60 @@
+package value
+
+import "github.com/ghosind/gjs/ast"
+
+type Environment interface {
+	Get(name string) (Value, bool)
+	Set(name string, val Value) Value
+}
+
+type Function struct {
+	Name      string
+	Params    []ast.Expression
+	Body      ast.Node
+	Env       Environment
+	Async     bool
+	Generator bool
+	Arrow     bool
+	Strict    bool
+	// File is the name of the source file of the function.
+	File string
+}
+
+func (f *Function) Type() DataType {
+	return DataType_Object
+}
+
+func (f *Function) Inspect() string {
+	kind := "Function"
+	switch {
+	case f.Async && f.Generator:
+		kind = "AsyncGeneratorFunction"
+	case f.Async:
+		kind = "AsyncFunction"
+	case f.Generator:
+		kind = "GeneratorFunction"
+	}
+	return inspectFunction(kind, f.Name)
+}
+
+type NativeFunction struct {
+	Name       string
+	Fn         func(this Value, args []Value) Value
+	Construct  func(args []Value) Value
+	Properties map[string]Value
+}
+
+func (f *NativeFunction) Type() DataType {
+	return DataType_Object
+}
+
+func (f *NativeFunction) Inspect() string {
+	return inspectFunction("Function", f.Name)
+}
+
+func inspectFunction(kind, name string) string {
+	if name == "" {
+		return "[" + kind + " (anonymous)]"
+	}
+	return "[" + kind + ": " + name + "]"
+}
@@ -0,0 +1,32 @@
+package value
+
+type PromiseState int
+
+const (
+	PromiseStatePending PromiseState = iota
+	PromiseStateFulfilled
+	PromiseStateRejected
+)
+
+type PromiseReaction func(state PromiseState, result Value)
+
+type Promise struct {
+	State     PromiseState
+	Result    Value
+	Reactions []PromiseReaction
+}
+
+func (p *Promise) Type() DataType {
+	return DataType_Object
+}
+
+func (p *Promise) Inspect() string {
+	switch p.State {
+	case PromiseStateFulfilled:
+		return "Promise { " + p.Result.Inspect() + " }"
+	case PromiseStateRejected:
+		return "Promise { <rejected> " + p.Result.Inspect() + " }"
+	default:
+		return "Promise { <pending> }"
+	}
+}
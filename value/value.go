@@ -2,7 +2,11 @@ package value
 
 import (
 	"bytes"
+	"math"
+	"sort"
 	"strconv"
+	"strings"
+	"sync/atomic"
 )
 
 type DataType int
@@ -79,6 +83,35 @@ func (s *String) Inspect() string {
 
 type Symbol struct {
 	Description string
+	key         string
+}
+
+var symbolCount uint64
+
+var (
+	SymbolIterator      = NewSymbol("Symbol.iterator")
+	SymbolAsyncIterator = NewSymbol("Symbol.asyncIterator")
+)
+
+func NewSymbol(description string) *Symbol {
+	id := atomic.AddUint64(&symbolCount, 1)
+	return &Symbol{
+		Description: description,
+		key:         symbolKeyPrefix + strconv.FormatUint(id, 10) + ":" + description,
+	}
+}
+
+// symbolKeyPrefix starts the property keys of symbols, which cannot be
+// produced from a string key.
+const symbolKeyPrefix = "\x00@@"
+
+// Key returns the property key of the symbol.
+func (s *Symbol) Key() string {
+	return s.key
+}
+
+func IsSymbolKey(key string) bool {
+	return strings.HasPrefix(key, symbolKeyPrefix)
 }
 
 func (s *Symbol) Type() DataType {
@@ -106,25 +139,172 @@ func (n *Number) Inspect() string {
 
 type Object struct {
 	Properties map[string]Value
+	keys       []string
+}
+
+func NewObject() *Object {
+	return &Object{Properties: make(map[string]Value)}
 }
 
 func (o *Object) Type() DataType {
 	return DataType_Object
 }
 
+func (o *Object) Get(key string) (Value, bool) {
+	val, ok := o.Properties[key]
+	return val, ok
+}
+
+func (o *Object) Set(key string, val Value) {
+	if o.Properties == nil {
+		o.Properties = make(map[string]Value)
+	}
+	if _, ok := o.Properties[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.Properties[key] = val
+}
+
+// Keys returns the string property keys in the order of the spec: the array
+// index keys in ascending order, then the other keys in insertion order.
+// Properties added to the map directly are listed after the tracked ones in
+// lexical order.
+func (o *Object) Keys() []string {
+	keys := make([]string, 0, len(o.Properties))
+	seen := make(map[string]bool, len(o.keys))
+	for _, key := range o.keys {
+		if IsSymbolKey(key) {
+			continue
+		}
+		if _, ok := o.Properties[key]; ok && !seen[key] {
+			keys = append(keys, key)
+			seen[key] = true
+		}
+	}
+
+	untracked := make([]string, 0)
+	for key := range o.Properties {
+		if !seen[key] && !IsSymbolKey(key) {
+			untracked = append(untracked, key)
+		}
+	}
+	sort.Strings(untracked)
+	keys = append(keys, untracked...)
+
+	indices := make([]string, 0)
+	names := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if isArrayIndex(key) {
+			indices = append(indices, key)
+		} else {
+			names = append(names, key)
+		}
+	}
+	sort.Slice(indices, func(i, j int) bool {
+		if len(indices[i]) != len(indices[j]) {
+			return len(indices[i]) < len(indices[j])
+		}
+		return indices[i] < indices[j]
+	})
+
+	return append(indices, names...)
+}
+
+// isArrayIndex reports whether key is the canonical form of an integer in
+// the range of array indices, from 0 to 2^32-2.
+func isArrayIndex(key string) bool {
+	if key == "" || len(key) > 1 && key[0] == '0' {
+		return false
+	}
+	n, err := strconv.ParseUint(key, 10, 32)
+	return err == nil && n < math.MaxUint32
+}
+
 func (o *Object) Inspect() string {
+	return o.inspect(make(map[Value]bool))
+}
+
+func (o *Object) inspect(seen map[Value]bool) string {
+	seen[o] = true
+	defer delete(seen, o)
+
 	buf := new(bytes.Buffer)
 	buf.WriteString("{")
-	first := true
-	for key, value := range o.Properties {
-		if !first {
+	for i, key := range o.Keys() {
+		if i > 0 {
 			buf.WriteString(", ")
 		}
 		buf.WriteString(key)
 		buf.WriteString(": ")
-		buf.WriteString(value.Inspect())
-		first = false
+		buf.WriteString(inspect(o.Properties[key], seen))
 	}
 	buf.WriteString("}")
 	return buf.String()
 }
+
+// inspect formats a nested value, printing the objects that contain
+// themselves, such as the global object, as [Circular].
+func inspect(val Value, seen map[Value]bool) string {
+	switch val := val.(type) {
+	case *Object:
+		if seen[val] {
+			return "[Circular]"
+		}
+		return val.inspect(seen)
+	case *Array:
+		if seen[val] {
+			return "[Circular]"
+		}
+		return val.inspect(seen)
+	}
+	return val.Inspect()
+}
+
+type Array struct {
+	Elements []Value
+}
+
+func (a *Array) Type() DataType {
+	return DataType_Object
+}
+
+func (a *Array) Inspect() string {
+	return a.inspect(make(map[Value]bool))
+}
+
+func (a *Array) inspect(seen map[Value]bool) string {
+	seen[a] = true
+	defer delete(seen, a)
+
+	buf := new(bytes.Buffer)
+	buf.WriteString("[")
+	for i, elem := range a.Elements {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(inspect(elem, seen))
+	}
+	buf.WriteString("]")
+	return buf.String()
+}
+
+// Accessor is an object property defined by a getter and/or a setter.
+type Accessor struct {
+	Get Value
+	Set Value
+}
+
+func (a *Accessor) Type() DataType {
+	return DataType_Object
+}
+
+func (a *Accessor) Inspect() string {
+	switch {
+	case a.Get != nil && a.Set != nil:
+		return "[Getter/Setter]"
+	case a.Get != nil:
+		return "[Getter]"
+	default:
+		return "[Setter]"
+	}
+}